@@ -1,9 +1,20 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"github.com/lhducc/bookmark-management/internal/api"
+	"github.com/lhducc/bookmark-management/internal/config"
 	"github.com/lhducc/bookmark-management/pkg/logger"
 	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/lhducc/bookmark-management/pkg/tracing"
+	"github.com/rs/zerolog/log"
+	"os"
+	"os/signal"
+	"syscall"
+	// The time windows of the link rules name IANA time zones, the runtime image has no time zone database.
+	_ "time/tzdata"
 )
 
 // @title Bookmark Management API
@@ -12,18 +23,74 @@ import (
 // @host localhost:8080
 // @BasePath /
 func main() {
-	logger.SetLogLevel()
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file, environment variables override it")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-config file] [config print]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	conf, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+
+	switch args := flag.Args(); {
+	case len(args) == 0:
+	case len(args) == 2 && args[0] == "config" && args[1] == "print":
+		if err := conf.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	logger.SetLevel(conf.Log.Level)
+	cfg := &conf.API
 
-	cfg, err := api.NewConfig()
+	shutdownTracing, err := tracing.SetupWithConfig(context.Background(), &conf.Tracing, cfg.ServiceName)
 	if err != nil {
 		panic(err)
 	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error().Err(err).Msg("Failed to flush traces")
+		}
+	}()
 
-	redisClient, err := redisPkg.NewClient("")
+	redisClient, err := redisPkg.NewClientWithConfig(&conf.Redis)
 	if err != nil {
 		panic(err)
 	}
 
 	app := api.New(cfg, redisClient)
-	app.Start()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- app.Start()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			log.Error().Err(err).Msg("HTTP server failed")
+		}
+	case <-ctx.Done():
+		log.Info().Msg("Shutdown signal received")
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownGracePeriod)
+	defer cancel()
+	if err := app.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Graceful shutdown failed")
+	}
+	log.Info().Msg("Server stopped")
 }
@@ -1,28 +1,99 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	_ "github.com/lhducc/bookmark-management/docs"
 	"github.com/lhducc/bookmark-management/internal/handler"
+	"github.com/lhducc/bookmark-management/internal/metrics"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
 	"github.com/lhducc/bookmark-management/internal/repository"
 	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/lhducc/bookmark-management/pkg/geoip"
 	"github.com/lhducc/bookmark-management/pkg/stringutils"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"net"
 	"net/http"
+	"sync"
+	"time"
 )
 
+const (
+	rateLimitRouteShorten  = "shorten"
+	rateLimitRouteRedirect = "redirect"
+)
+
+// workerBeatGrace is added to the max age of the workers, so a slow Redis does not fail the readiness on its own.
+const workerBeatGrace = 30 * time.Second
+
+const (
+	// clickRecordTimeout bounds the Redis calls recording a click, the idle click recorder beats every clickBeatInterval.
+	clickRecordTimeout = 5 * time.Second
+	clickBeatInterval  = 10 * time.Second
+)
+
+// rootRoutes lists the first path segment of every route registered at the root, GET /:code cannot serve these codes.
+var rootRoutes = []string{"gen-pass", "health-check", "livez", "readyz", "metrics", "swagger", "v1"}
+
 type Engine interface {
 	Start() error
+	Shutdown(ctx context.Context) error
 	ServeHTTP(w http.ResponseWriter, r *http.Request)
 }
 
+// Option customizes the api built by New.
+type Option func(a *api)
+
+// WithTXTResolver replaces the DNS resolver used to verify custom domains, net.DefaultResolver by default.
+func WithTXTResolver(resolver service.TXTResolver) Option {
+	return func(a *api) {
+		a.txtResolver = resolver
+	}
+}
+
+// WithWebhookTransport replaces the transport of the webhook requests, service.NewWebhookTransport by default.
+func WithWebhookTransport(transport http.RoundTripper) Option {
+	return func(a *api) {
+		a.webhookTransport = transport
+	}
+}
+
+// WithCountryResolver replaces the country lookup of the link rules, the GeoIPDatabase of the config by default.
+func WithCountryResolver(resolver service.CountryResolver) Option {
+	return func(a *api) {
+		a.countryResolver = resolver
+	}
+}
+
 type api struct {
 	app         *gin.Engine
+	server      *http.Server
 	cfg         *Config
-	redisClient *redis.Client
+	redisClient redis.UniversalClient
+	metrics     *metrics.Metrics
+	healthCheck service.HealthCheck
+	readiness   service.Readiness
+	heartbeat   service.Heartbeat
+	txtResolver service.TXTResolver
+
+	countryResolver service.CountryResolver
+	// webhookTransport sends the webhook requests, nil for the dispatcher default.
+	webhookTransport http.RoundTripper
+	// geoip is the database opened from the GeoIPDatabase of the config, closed on shutdown.
+	geoip *geoip.Reader
+
+	// ctx is canceled to stop the background workers, workers tracks them until they return.
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
 }
 
 // New returns a new instance of the api, which implements the Engine interface.
@@ -30,21 +101,116 @@ type api struct {
 // The api is created with a gin.Engine instance, which is used to start the server.
 // The registerEP method is called on the returned api to register the endpoints for the API.
 // The returned api is ready to be used and does not require any additional setup before starting the server.
-func New(cfg *Config, redisClient *redis.Client) Engine {
+// opts are applied before the endpoints are registered.
+func New(cfg *Config, redisClient redis.UniversalClient, opts ...Option) Engine {
+	ctx, cancel := context.WithCancel(context.Background())
 	a := &api{
 		app:         gin.New(),
 		cfg:         cfg,
 		redisClient: redisClient,
+		metrics:     metrics.New(),
+		readiness:   service.NewReadiness(cfg.ReadyzCacheTTL),
+		heartbeat:   service.NewHeartbeat(),
+		txtResolver: net.DefaultResolver,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.countryResolver == nil && cfg.GeoIPDatabase != "" {
+		reader, err := geoip.Open(cfg.GeoIPDatabase)
+		if err != nil {
+			log.Error().Err(err).Msg("Cannot open the GeoIP database, rules with countries will not match")
+		} else {
+			a.countryResolver, a.geoip = reader, reader
+		}
+	}
+	// Handlers pass the gin.Context to the services, the fallback lets it expose the request context set by otelgin.
+	a.app.ContextWithFallback = true
+	if err := a.app.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Error().Err(err).Msg("Invalid trusted proxies, not trusting any proxy")
+		_ = a.app.SetTrustedProxies(nil)
+	}
+	a.app.Use(
+		otelgin.Middleware(cfg.ServiceName),
+		middleware.RequestID(),
+		middleware.AccessLog(),
+		a.metrics.Middleware(),
+		middleware.Recovery(),
+		middleware.Identity(cfg.AuthUserHeader, cfg.TrustedProxies),
+	)
+	if redisClient != nil {
+		redisClient.AddHook(a.metrics.RedisHook())
+		if err := redisotel.InstrumentTracing(redisClient); err != nil {
+			log.Error().Err(err).Msg("Failed to instrument redis client for tracing")
+		}
 	}
 	a.registerEP()
+	a.server = &http.Server{
+		Addr:              fmt.Sprintf(":%s", cfg.AppPort),
+		Handler:           a.app,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+	}
 	return a
 }
 
-// Start starts the HTTP server and listens for incoming requests on port 8080.
-// It returns an error if there was an issue starting the server.
-// The server is started using the gin.Engine instance stored in the api struct.
+// Start starts the HTTP server and listens for incoming requests on the configured port.
+// It blocks until the server fails or Shutdown is called, in which case it returns nil.
 func (a *api) Start() error {
-	return a.app.Run(fmt.Sprintf(":%s", a.cfg.AppPort))
+	log.Info().Str("addr", a.server.Addr).Msg("Starting HTTP server")
+	if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the api gracefully.
+// The health check reports draining for the configured drain delay first, so load balancers stop sending traffic,
+// then the server stops accepting connections and waits for in-flight requests.
+// The background workers are stopped and the Redis client and the GeoIP database are closed last.
+// ctx bounds the whole shutdown, the server is closed forcefully once it is done.
+func (a *api) Shutdown(ctx context.Context) error {
+	a.healthCheck.Drain()
+	a.readiness.Drain()
+	log.Info().Dur("drain_delay", a.cfg.ShutdownDrainDelay).Msg("Draining before shutdown")
+	select {
+	case <-time.After(a.cfg.ShutdownDrainDelay):
+	case <-ctx.Done():
+	}
+
+	var errs []error
+	if err := a.server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("shutdown http server: %w", err))
+		_ = a.server.Close()
+	}
+
+	a.cancel()
+	workersDone := make(chan struct{})
+	go func() {
+		a.workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("stop background workers: %w", ctx.Err()))
+	}
+
+	if a.redisClient != nil {
+		if err := a.redisClient.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close redis client: %w", err))
+		}
+	}
+	if a.geoip != nil {
+		if err := a.geoip.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close geoip database: %w", err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // ServeHTTP serves HTTP requests to the gin.Engine instance.
@@ -57,26 +223,212 @@ func (a *api) registerEP() {
 	//Repository
 	urlRepo := repository.NewUrlStorage(a.redisClient)
 	healthCheckRepo := repository.NewHealthCheck(a.redisClient)
+	rateLimitRepo := repository.NewRateLimiter(a.redisClient)
+	workspaceRepo := repository.NewWorkspaceStorage(a.redisClient)
+	domainRepo := repository.NewDomainStorage(a.redisClient)
+	webhookRepo := repository.NewWebhookStorage(a.redisClient)
+	webhookQueue := repository.NewWebhookQueue(a.redisClient)
+	linkIndex := repository.NewLinkIndex(a.redisClient)
+	linkExpiryIndex := repository.NewLinkExpiryIndex(a.redisClient)
+	linkTrash := repository.NewLinkTrash(a.redisClient)
 
 	// Service
 	passSvc := service.NewPassword()
 	healthCheckSvc := service.NewHealthCheck(a.cfg.ServiceName, a.cfg.InstanceID, healthCheckRepo)
-	urlShortenSvc := service.NewShortenUrl(urlRepo, stringutils.NewKeyGen())
+	a.healthCheck = healthCheckSvc
+	a.registerReadiness(healthCheckRepo)
+	var urlCache service.UrlCache
+	if a.cfg.UrlCacheSize > 0 {
+		urlCache = service.NewUrlCache(a.cfg.UrlCacheSize, a.cfg.UrlCacheTTL, repository.NewUrlInvalidation(a.redisClient))
+		const name = "url cache invalidation"
+		a.runWorker(name, 0, func(ctx context.Context, beat func()) error {
+			return urlCache.Watch(ctx, beat, func(err error) {
+				log.Warn().Str("worker", name).Err(err).Msg("Background worker reconnecting")
+				a.heartbeat.Fail(name, err)
+			})
+		})
+	}
+	var keyspaceLengthRepo repository.KeyspaceLength
+	if a.redisClient != nil {
+		keyspaceLengthRepo = repository.NewKeyspaceLength(a.redisClient)
+	}
+	keyspaceMonitor := service.NewKeyspaceMonitor(keyspaceLengthRepo, a.keyGenAlphabetSize(), service.UrlCodeLength, a.cfg.KeyspaceWarnOccupancy, a.cfg.KeyspaceGrowOccupancy)
+	if err := keyspaceMonitor.Refresh(a.ctx); err != nil {
+		log.Error().Err(err).Msg("Cannot read the code length, starting from the default one")
+	}
+	urlShortenSvc := service.NewShortenUrl(urlRepo, a.newKeyGen(), urlCache, keyspaceMonitor, append(rootRoutes, a.cfg.ReservedAliases...))
+	a.metrics.RegisterKeyspace(keyspaceMonitor)
+	workspaceSvc := service.NewWorkspace(workspaceRepo)
+	domainSvc := service.NewDomain(domainRepo, a.txtResolver, a.cfg.DomainCacheTTL)
+	linkRouter := service.NewLinkRouter(a.countryResolver)
+	webhookSvc := service.NewWebhook(webhookRepo, webhookQueue)
+	if a.cfg.WebhookPollInterval > 0 {
+		webhookDispatcher := service.NewWebhookDispatcher(webhookRepo, webhookQueue, a.cfg.WebhookTimeout, service.WebhookRetryPolicy{
+			MaxAttempts: a.cfg.WebhookMaxAttempts,
+			BaseDelay:   a.cfg.WebhookRetryBase,
+			MaxDelay:    a.cfg.WebhookRetryMax,
+		}, a.cfg.WebhookPollInterval, a.webhookTransport)
+		// A pass is bounded by the requests of a batch, or by the lease of its deliveries when requests have no timeout.
+		batchTimeout := a.cfg.WebhookTimeout
+		if batchTimeout == 0 {
+			batchTimeout = time.Minute
+		}
+		a.runWorker("webhook dispatcher", workerMaxAge(a.cfg.WebhookPollInterval, batchTimeout), webhookDispatcher.Run)
+	}
+	clickRecorder := service.NewClickRecorder(urlShortenSvc, webhookSvc, a.cfg.ClickQueueSize, clickRecordTimeout, clickBeatInterval)
+	a.runWorker("click recorder", workerMaxAge(clickBeatInterval, clickRecordTimeout), clickRecorder.Run)
+	linkListSvc := service.NewLinkList(linkIndex, linkTrash)
+	if a.cfg.LinkExpiryPollInterval > 0 {
+		expiryNotifier := service.NewExpiryNotifier(linkExpiryIndex, urlRepo, webhookSvc, a.cfg.LinkExpiryNotice, a.cfg.LinkExpiryPollInterval)
+		a.runWorker("link expiry notifier", workerMaxAge(a.cfg.LinkExpiryPollInterval, 0), expiryNotifier.Run)
+	}
+	trashSvc := service.NewTrash(urlRepo, linkTrash, linkExpiryIndex, urlCache, webhookSvc)
+	if a.cfg.TrashPurgeInterval > 0 {
+		trashPurger := service.NewTrashPurger(urlRepo, linkTrash, a.cfg.TrashRetention, a.cfg.TrashPurgeInterval)
+		a.runWorker("trash purger", workerMaxAge(a.cfg.TrashPurgeInterval, 0), trashPurger.Run)
+	}
+	rateLimiter := service.NewRateLimiter(rateLimitRepo, map[string]model.RateLimitPolicy{
+		rateLimitRouteShorten:  {IP: a.cfg.RateLimitShortenIP, User: a.cfg.RateLimitShortenUser},
+		rateLimitRouteRedirect: {IP: a.cfg.RateLimitRedirectIP, User: a.cfg.RateLimitRedirectUser},
+	})
 
 	// Handler
 	passHandler := handler.NewPassword(passSvc)
-	healthCheckHandler := handler.NewHealthCheckHandler(healthCheckSvc)
-	urlShortenHandler := handler.NewUrlShortenHandler(urlShortenSvc)
+	healthCheckHandler := handler.NewHealthCheckHandler(healthCheckSvc, a.metrics)
+	urlShortenHandler := handler.NewUrlShortenHandler(urlShortenSvc, linkRouter, clickRecorder, webhookSvc, a.metrics)
+	keyspaceHandler := handler.NewKeyspaceHandler(keyspaceMonitor)
+	probeHandler := handler.NewProbeHandler(a.readiness)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceSvc)
+	domainHandler := handler.NewDomainHandler(domainSvc)
+	webhookHandler := handler.NewWebhookHandler(webhookSvc)
+	linkListHandler := handler.NewLinkListHandler(linkListSvc)
+	trashHandler := handler.NewTrashHandler(trashSvc)
+	qrCodeHandler := handler.NewQRCodeHandler(urlShortenSvc, domainSvc, service.NewQRCode(), a.cfg.PublicURL, a.cfg.RootRedirect)
 
 	// Router
 	a.app.GET("/gen-pass", passHandler.GenPass)
 	a.app.GET("/health-check", healthCheckHandler.Check)
+	a.app.GET("/livez", probeHandler.Livez)
+	a.app.GET("/readyz", probeHandler.Readyz)
+	a.app.GET("/metrics", gin.WrapH(a.metrics.Handler()))
+	// Custom domains of the workspaces serve their codes at the root, and so does the service host when RootRedirect is on.
+	a.app.GET("/:code",
+		middleware.RateLimit(rateLimiter, rateLimitRouteRedirect),
+		middleware.CustomDomain(domainSvc, a.cfg.RootRedirect),
+		urlShortenHandler.GetUrl,
+	)
 	v1Routers := a.app.Group("/v1")
 	{
-		v1Routers.POST("/links/shorten", urlShortenHandler.ShortenUrl)
-		v1Routers.GET("/links/redirect/:code", urlShortenHandler.GetUrl)
+		v1Routers.GET("/links", linkListHandler.List)
+		v1Routers.POST("/links/shorten", middleware.RateLimit(rateLimiter, rateLimitRouteShorten), urlShortenHandler.ShortenUrl)
+		v1Routers.GET("/links/redirect/:code", middleware.RateLimit(rateLimiter, rateLimitRouteRedirect), urlShortenHandler.GetUrl)
+		v1Routers.GET("/links/keyspace", keyspaceHandler.Stats)
+		v1Routers.GET("/links/clicks/:code", urlShortenHandler.Clicks)
+		v1Routers.GET("/links/:code/qr", middleware.RateLimit(rateLimiter, rateLimitRouteRedirect), qrCodeHandler.Render)
+		v1Routers.DELETE("/links/:code", trashHandler.Delete)
+
+		v1Routers.GET("/trash", trashHandler.List)
+		v1Routers.POST("/trash/:code/restore", trashHandler.Restore)
+		v1Routers.DELETE("/trash/:code", trashHandler.Purge)
+
+		v1Routers.POST("/webhooks", webhookHandler.Create)
+		v1Routers.GET("/webhooks", webhookHandler.List)
+		v1Routers.DELETE("/webhooks/:webhook", webhookHandler.Delete)
+		v1Routers.GET("/webhooks/:webhook/deliveries", webhookHandler.Deliveries)
+
+		v1Routers.POST("/workspaces", workspaceHandler.Create)
+		v1Routers.GET("/workspaces", workspaceHandler.List)
+		workspaceRouters := v1Routers.Group("/workspaces/:workspace")
+		{
+			workspaceRouters.GET("", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleViewer), workspaceHandler.Get)
+			workspaceRouters.GET("/members", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleViewer), workspaceHandler.Members)
+			workspaceRouters.PUT("/members/:user", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleAdmin), workspaceHandler.SetMember)
+			workspaceRouters.DELETE("/members/:user", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleAdmin), workspaceHandler.RemoveMember)
+			workspaceRouters.POST("/links/shorten",
+				middleware.RateLimit(rateLimiter, rateLimitRouteShorten),
+				middleware.RequireWorkspaceRole(workspaceSvc, model.RoleMember),
+				urlShortenHandler.ShortenUrl,
+			)
+			workspaceRouters.GET("/links", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleViewer), linkListHandler.List)
+			workspaceRouters.GET("/links/clicks/:code", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleViewer), urlShortenHandler.Clicks)
+			workspaceRouters.DELETE("/links/:code", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleMember), trashHandler.Delete)
+			workspaceRouters.GET("/trash", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleViewer), trashHandler.List)
+			workspaceRouters.POST("/trash/:code/restore", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleMember), trashHandler.Restore)
+			workspaceRouters.DELETE("/trash/:code", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleAdmin), trashHandler.Purge)
+			workspaceRouters.GET("/domains", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleViewer), domainHandler.List)
+			workspaceRouters.POST("/domains", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleAdmin), domainHandler.Register)
+			workspaceRouters.POST("/domains/:host/verify", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleAdmin), domainHandler.Verify)
+			workspaceRouters.DELETE("/domains/:host", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleAdmin), domainHandler.Delete)
+			workspaceRouters.POST("/webhooks", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleAdmin), webhookHandler.Create)
+			workspaceRouters.GET("/webhooks", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleAdmin), webhookHandler.List)
+			workspaceRouters.DELETE("/webhooks/:webhook", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleAdmin), webhookHandler.Delete)
+			workspaceRouters.GET("/webhooks/:webhook/deliveries", middleware.RequireWorkspaceRole(workspaceSvc, model.RoleAdmin), webhookHandler.Deliveries)
+			// Redirects are public like the global ones, the workspace only namespaces the code.
+			workspaceRouters.GET("/links/redirect/:code", middleware.RateLimit(rateLimiter, rateLimitRouteRedirect), urlShortenHandler.GetUrl)
+			workspaceRouters.GET("/links/:code/qr", middleware.RateLimit(rateLimiter, rateLimitRouteRedirect), qrCodeHandler.Render)
+		}
 	}
 
 	// Swagger
 	a.app.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 }
+
+// registerReadiness registers the readiness checkers: Redis answers, Redis accepts writes and the background workers are running.
+func (a *api) registerReadiness(healthCheckRepo repository.HealthCheck) {
+	if a.redisClient != nil {
+		a.readiness.Register("redis", a.cfg.ReadyzCheckTimeout, healthCheckRepo.Ping)
+		a.readiness.Register("storage", a.cfg.ReadyzCheckTimeout, func(ctx context.Context) error {
+			return healthCheckRepo.WriteProbe(ctx, a.cfg.InstanceID)
+		})
+	}
+	a.readiness.Register("workers", a.cfg.ReadyzCheckTimeout, a.heartbeat.Check)
+}
+
+// newKeyGen returns the stringutils.KeyGen selected by the KeyGenStrategy of the config.
+// Unknown strategies are rejected by Config.Validate, an empty strategy falls back to random base62 codes.
+func (a *api) newKeyGen() stringutils.KeyGen {
+	switch a.cfg.KeyGenStrategy {
+	case KeyGenStrategyHuman:
+		return stringutils.NewRandomKeyGen(stringutils.HumanFriendlyAlphabet)
+	case KeyGenStrategyCounter:
+		return stringutils.NewCounterKeyGen(repository.NewCodeSequence(a.redisClient), stringutils.Base62Alphabet, []byte(a.cfg.KeyGenSecret))
+	case KeyGenStrategyHashids:
+		return stringutils.NewHashidsKeyGen(repository.NewCodeSequence(a.redisClient), a.cfg.KeyGenSecret)
+	default:
+		return stringutils.NewKeyGen()
+	}
+}
+
+// keyGenAlphabetSize returns the number of characters the configured KeyGen draws codes from.
+func (a *api) keyGenAlphabetSize() int {
+	if a.cfg.KeyGenStrategy == KeyGenStrategyHuman {
+		return len(stringutils.HumanFriendlyAlphabet)
+	}
+	return len(stringutils.Base62Alphabet)
+}
+
+// workerMaxAge returns how long a worker ticking every interval, whose batches take up to batchTimeout, can go without
+// beating before it is considered stuck. It leaves room for a missed tick and for the Redis calls of a batch.
+func workerMaxAge(interval, batchTimeout time.Duration) time.Duration {
+	return 2*interval + batchTimeout + workerBeatGrace
+}
+
+// runWorker runs fn in the background until the api context is canceled.
+// fn calls beat to show it is alive, the readiness fails once it has not for maxAge, 0 for workers that block.
+// An error returned by fn before that is logged and fails the readiness, the worker is not restarted.
+// Shutdown waits for fn to return.
+func (a *api) runWorker(name string, maxAge time.Duration, fn func(ctx context.Context, beat func()) error) {
+	a.workers.Add(1)
+	a.heartbeat.Start(name, maxAge)
+	go func() {
+		defer a.workers.Done()
+		err := fn(a.ctx, func() { a.heartbeat.Beat(name) })
+		if a.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Error().Str("worker", name).Err(err).Msg("Background worker stopped")
+		}
+		a.heartbeat.Stop(name, err)
+	}()
+}
@@ -1,31 +1,191 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
-	"github.com/kelseyhightower/envconfig"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 )
 
 type Config struct {
-	AppPort     string `default:"8080" envconfig:"APP_PORT"`
-	ServiceName string `default:"bookmark-management" envconfig:"SERVICE_NAME"`
-	InstanceID  string `default:"" envconfig:"INSTANCE_ID"`
+	AppPort     string `default:"8080" envconfig:"APP_PORT" yaml:"app_port"`
+	ServiceName string `default:"bookmark-management" envconfig:"SERVICE_NAME" yaml:"service_name"`
+	InstanceID  string `default:"" envconfig:"INSTANCE_ID" yaml:"instance_id"`
+
+	// HTTP* bound how long a connection may take to send a request, to receive the response and to sit idle.
+	HTTPReadHeaderTimeout time.Duration `default:"5s" envconfig:"HTTP_READ_HEADER_TIMEOUT" yaml:"http_read_header_timeout"`
+	HTTPReadTimeout       time.Duration `default:"15s" envconfig:"HTTP_READ_TIMEOUT" yaml:"http_read_timeout"`
+	HTTPWriteTimeout      time.Duration `default:"15s" envconfig:"HTTP_WRITE_TIMEOUT" yaml:"http_write_timeout"`
+	HTTPIdleTimeout       time.Duration `default:"60s" envconfig:"HTTP_IDLE_TIMEOUT" yaml:"http_idle_timeout"`
+
+	// ShutdownDrainDelay is how long the health check reports draining before the server stops accepting connections,
+	// so load balancers notice it. ShutdownGracePeriod bounds the whole shutdown, in-flight requests included.
+	ShutdownDrainDelay  time.Duration `default:"5s" envconfig:"SHUTDOWN_DRAIN_DELAY" yaml:"shutdown_drain_delay"`
+	ShutdownGracePeriod time.Duration `default:"30s" envconfig:"SHUTDOWN_GRACE_PERIOD" yaml:"shutdown_grace_period"`
+
+	// ReadyzCheckTimeout bounds every readiness checker, ReadyzCacheTTL is how long a readiness report is reused.
+	ReadyzCheckTimeout time.Duration `default:"1s" envconfig:"READYZ_CHECK_TIMEOUT" yaml:"readyz_check_timeout"`
+	ReadyzCacheTTL     time.Duration `default:"1s" envconfig:"READYZ_CACHE_TTL" yaml:"readyz_cache_ttl"`
+
+	// UrlCacheSize bounds the in-process redirect cache, 0 disables it.
+	UrlCacheSize int           `default:"0" envconfig:"URL_CACHE_SIZE" yaml:"url_cache_size"`
+	UrlCacheTTL  time.Duration `default:"30s" envconfig:"URL_CACHE_TTL" yaml:"url_cache_ttl"`
+
+	// ClickQueueSize bounds the clicks waiting to be recorded in the background, the clicks past it are dropped.
+	ClickQueueSize int `default:"10000" envconfig:"CLICK_QUEUE_SIZE" yaml:"click_queue_size"`
+
+	// KeyGenStrategy selects how short codes are generated, see the KeyGenStrategy* constants.
+	// KeyGenSecret is the Feistel key of the counter strategy and the salt of the hashids strategy.
+	KeyGenStrategy string `default:"random" envconfig:"KEYGEN_STRATEGY" yaml:"keygen_strategy"`
+	KeyGenSecret   string `default:"" envconfig:"KEYGEN_SECRET" yaml:"keygen_secret" secret:"true"`
+
+	// KeyspaceWarnOccupancy and KeyspaceGrowOccupancy are the estimated keyspace occupancies at which
+	// a warning is logged and new codes get one more character, 0 disables them.
+	KeyspaceWarnOccupancy float64 `default:"0.05" envconfig:"KEYSPACE_WARN_OCCUPANCY" yaml:"keyspace_warn_occupancy"`
+	KeyspaceGrowOccupancy float64 `default:"0.1" envconfig:"KEYSPACE_GROW_OCCUPANCY" yaml:"keyspace_grow_occupancy"`
+
+	// RateLimit* are the per route limits in the "<limit>/<period>" format, "0" disables a limit.
+	// Authenticated clients are counted by user ID, anonymous clients by IP address.
+	RateLimitShortenIP    model.RateLimit `default:"60/1m" envconfig:"RATE_LIMIT_SHORTEN_IP" yaml:"rate_limit_shorten_ip"`
+	RateLimitShortenUser  model.RateLimit `default:"600/1m" envconfig:"RATE_LIMIT_SHORTEN_USER" yaml:"rate_limit_shorten_user"`
+	RateLimitRedirectIP   model.RateLimit `default:"600/1m" envconfig:"RATE_LIMIT_REDIRECT_IP" yaml:"rate_limit_redirect_ip"`
+	RateLimitRedirectUser model.RateLimit `default:"6000/1m" envconfig:"RATE_LIMIT_REDIRECT_USER" yaml:"rate_limit_redirect_user"`
+
+	// TrustedProxies lists the proxy IPs and CIDRs whose X-Forwarded-For header is used as the client IP.
+	// With none, the client IP is the peer address, so clients cannot spoof their IP to escape the rate limits.
+	TrustedProxies []string `default:"" envconfig:"TRUSTED_PROXIES" yaml:"trusted_proxies"`
+
+	// AuthUserHeader is the header carrying the ID of the user authenticated by the gateway in front of the service.
+	// It is only trusted on requests from the TrustedProxies, so the gateway must be one of them and strip it from
+	// client requests. Empty, the default, disables it, every request is then anonymous.
+	AuthUserHeader string `default:"" envconfig:"AUTH_USER_HEADER" yaml:"auth_user_header"`
+
+	// RootRedirect serves the codes at GET /:code next to /v1/links/redirect/:code, custom domains always serve them there.
+	// ReservedAliases adds codes to the ones never handed out because a root route of the service would shadow them.
+	RootRedirect    bool     `default:"true" envconfig:"ROOT_REDIRECT" yaml:"root_redirect"`
+	ReservedAliases []string `default:"" envconfig:"RESERVED_ALIASES" yaml:"reserved_aliases"`
+
+	// PublicURL is the scheme and host, such as https://sho.rt, the full short URLs encoded in the QR codes are built on.
+	// Empty takes them from each request, which is wrong behind a proxy terminating TLS.
+	PublicURL string `default:"" envconfig:"PUBLIC_URL" yaml:"public_url"`
+
+	// DomainCacheTTL is how long the workspace of a custom domain host is reused before Redis is asked again, 0 disables the cache.
+	DomainCacheTTL time.Duration `default:"30s" envconfig:"DOMAIN_CACHE_TTL" yaml:"domain_cache_ttl"`
+
+	// GeoIPDatabase is the path of the MaxMind DB file, such as GeoLite2-Country.mmdb, the countries of the visitors are
+	// looked up in for the link rules. Empty disables the lookup, rules with countries then never match.
+	GeoIPDatabase string `default:"" envconfig:"GEOIP_DATABASE" yaml:"geoip_database"`
+
+	// LinkExpiryNotice is how long before their expiry the link.expiring event of the links is published, 0 disables it.
+	// The expiry index is polled every LinkExpiryPollInterval, 0 leaves the expiry events to the other instances.
+	LinkExpiryNotice       time.Duration `default:"24h" envconfig:"LINK_EXPIRY_NOTICE" yaml:"link_expiry_notice"`
+	LinkExpiryPollInterval time.Duration `default:"1m" envconfig:"LINK_EXPIRY_POLL_INTERVAL" yaml:"link_expiry_poll_interval"`
+
+	// TrashRetention is how long deleted links stay in the trash before they are purged, and their codes freed.
+	// The trash is purged every TrashPurgeInterval, 0 leaves the purge to the other instances.
+	TrashRetention     time.Duration `default:"720h" envconfig:"TRASH_RETENTION" yaml:"trash_retention"`
+	TrashPurgeInterval time.Duration `default:"1h" envconfig:"TRASH_PURGE_INTERVAL" yaml:"trash_purge_interval"`
+
+	// WebhookMaxAttempts bounds the attempts of a webhook delivery, after which it is marked dead.
+	// A failed attempt n is retried after WebhookRetryBase*2^(n-1), capped at WebhookRetryMax.
+	WebhookMaxAttempts int           `default:"8" envconfig:"WEBHOOK_MAX_ATTEMPTS" yaml:"webhook_max_attempts"`
+	WebhookRetryBase   time.Duration `default:"10s" envconfig:"WEBHOOK_RETRY_BASE" yaml:"webhook_retry_base"`
+	WebhookRetryMax    time.Duration `default:"1h" envconfig:"WEBHOOK_RETRY_MAX" yaml:"webhook_retry_max"`
+	// WebhookTimeout bounds every webhook request, the delivery queue is polled every WebhookPollInterval.
+	// A WebhookPollInterval of 0 leaves the deliveries to the other instances, events are still queued.
+	WebhookTimeout      time.Duration `default:"10s" envconfig:"WEBHOOK_TIMEOUT" yaml:"webhook_timeout"`
+	WebhookPollInterval time.Duration `default:"1s" envconfig:"WEBHOOK_POLL_INTERVAL" yaml:"webhook_poll_interval"`
 }
 
-// NewConfig returns a new instance of Config, which is used to configure the API.
-// It populates the fields of the returned Config instance with values from environment variables.
-// If an error occurs while populating the fields, it returns an error immediately.
-// The returned Config instance is ready to be used and does not require any additional setup before starting the API.
-// If the InstanceID field is empty, it generates a random UUID and assigns it to the field.
-func NewConfig() (*Config, error) {
-	cfg := &Config{}
-	err := envconfig.Process("", cfg)
-	if err != nil {
-		return nil, err
+const (
+	KeyGenStrategyRandom  = "random"
+	KeyGenStrategyHuman   = "human"
+	KeyGenStrategyCounter = "counter"
+	KeyGenStrategyHashids = "hashids"
+)
+
+// SetDefaults fills the fields whose default is computed, the InstanceID gets a random UUID if it is empty.
+func (c *Config) SetDefaults() {
+	if c.InstanceID == "" {
+		c.InstanceID = uuid.NewString()
 	}
+}
 
-	if cfg.InstanceID == "" {
-		cfg.InstanceID = uuid.NewString()
+// Validate returns every invalid field of the config at once, joined with errors.Join.
+func (c *Config) Validate() error {
+	var errs []error
+	if port, err := strconv.Atoi(c.AppPort); err != nil || port < 0 || port > 65535 {
+		errs = append(errs, fmt.Errorf("APP_PORT must be a port number, got %q", c.AppPort))
+	}
+	if c.ServiceName == "" {
+		errs = append(errs, errors.New("SERVICE_NAME must not be empty"))
 	}
 
-	return cfg, nil
+	for _, timeout := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"HTTP_READ_HEADER_TIMEOUT", c.HTTPReadHeaderTimeout},
+		{"HTTP_READ_TIMEOUT", c.HTTPReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", c.HTTPWriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", c.HTTPIdleTimeout},
+		{"SHUTDOWN_DRAIN_DELAY", c.ShutdownDrainDelay},
+		{"SHUTDOWN_GRACE_PERIOD", c.ShutdownGracePeriod},
+		{"READYZ_CHECK_TIMEOUT", c.ReadyzCheckTimeout},
+		{"READYZ_CACHE_TTL", c.ReadyzCacheTTL},
+		{"URL_CACHE_TTL", c.UrlCacheTTL},
+		{"DOMAIN_CACHE_TTL", c.DomainCacheTTL},
+		{"LINK_EXPIRY_NOTICE", c.LinkExpiryNotice},
+		{"LINK_EXPIRY_POLL_INTERVAL", c.LinkExpiryPollInterval},
+		{"TRASH_RETENTION", c.TrashRetention},
+		{"TRASH_PURGE_INTERVAL", c.TrashPurgeInterval},
+		{"WEBHOOK_RETRY_BASE", c.WebhookRetryBase},
+		{"WEBHOOK_RETRY_MAX", c.WebhookRetryMax},
+		{"WEBHOOK_TIMEOUT", c.WebhookTimeout},
+		{"WEBHOOK_POLL_INTERVAL", c.WebhookPollInterval},
+	} {
+		if timeout.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", timeout.name, timeout.value))
+		}
+	}
+	if c.UrlCacheSize < 0 {
+		errs = append(errs, fmt.Errorf("URL_CACHE_SIZE must not be negative, got %d", c.UrlCacheSize))
+	}
+
+	if c.ClickQueueSize < 1 {
+		errs = append(errs, fmt.Errorf("CLICK_QUEUE_SIZE must be at least 1, got %d", c.ClickQueueSize))
+	}
+
+	if c.WebhookMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be at least 1, got %d", c.WebhookMaxAttempts))
+	}
+
+	switch c.KeyGenStrategy {
+	case KeyGenStrategyRandom, KeyGenStrategyHuman, KeyGenStrategyCounter, KeyGenStrategyHashids:
+	default:
+		errs = append(errs, fmt.Errorf("unknown KEYGEN_STRATEGY %q", c.KeyGenStrategy))
+	}
+	if c.KeyspaceWarnOccupancy < 0 || c.KeyspaceWarnOccupancy > 1 {
+		errs = append(errs, fmt.Errorf("KEYSPACE_WARN_OCCUPANCY must be between 0 and 1, got %g", c.KeyspaceWarnOccupancy))
+	}
+	if c.KeyspaceGrowOccupancy < 0 || c.KeyspaceGrowOccupancy > 1 {
+		errs = append(errs, fmt.Errorf("KEYSPACE_GROW_OCCUPANCY must be between 0 and 1, got %g", c.KeyspaceGrowOccupancy))
+	}
+
+	if c.PublicURL != "" {
+		if u, err := url.Parse(c.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("PUBLIC_URL must be an absolute http or https URL, got %q", c.PublicURL))
+		}
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES entry %q is neither an IP nor a CIDR", proxy))
+		}
+	}
+	return errors.Join(errs...)
 }
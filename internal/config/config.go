@@ -0,0 +1,218 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/lhducc/bookmark-management/internal/api"
+	"github.com/lhducc/bookmark-management/pkg/logger"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/lhducc/bookmark-management/pkg/tracing"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// Config is the whole configuration of the service, one section per package.
+type Config struct {
+	Log     logger.Config   `yaml:"log"`
+	API     api.Config      `yaml:"api"`
+	Redis   redisPkg.Config `yaml:"redis"`
+	Tracing tracing.Config  `yaml:"tracing"`
+}
+
+type validator interface {
+	Validate() error
+}
+
+// Load returns the Config read from the YAML or TOML file at path, picked by its extension, then overridden by the environment variables.
+// Fields missing from both keep their default, an empty path only reads the environment variables.
+// It returns an error listing every invalid field at once, whether it cannot be parsed or is out of range, unknown keys
+// in the file are invalid too.
+func Load(path string) (*Config, error) {
+	var errs []error
+	fromEnv := &Config{}
+	for _, section := range fromEnv.sections() {
+		errs = append(errs, processEnv(section))
+	}
+
+	cfg := *fromEnv
+	if path != "" {
+		errs = append(errs, decodeFile(path, &cfg))
+		// The file has overwritten the variables set in the environment, which take precedence.
+		for i, section := range cfg.sections() {
+			overrideFromEnv(reflect.ValueOf(section).Elem(), reflect.ValueOf(fromEnv.sections()[i]).Elem())
+		}
+	}
+
+	errs = append(errs, cfg.Validate())
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	cfg.API.SetDefaults()
+	return &cfg, nil
+}
+
+// Validate returns every invalid field of every section at once, joined with errors.Join.
+func (c *Config) Validate() error {
+	var errs []error
+	for _, section := range c.sections() {
+		if v, ok := section.(validator); ok {
+			errs = append(errs, v.Validate())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Print writes the config to w as YAML, with the fields tagged secret redacted.
+func (c *Config) Print(w io.Writer) error {
+	printed := *c
+	for _, section := range printed.sections() {
+		redact(reflect.ValueOf(section).Elem())
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&printed); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func (c *Config) sections() []any {
+	return []any{&c.Log, &c.API, &c.Redis, &c.Tracing}
+}
+
+// processEnv fills section from the environment variables and the defaults.
+// envconfig stops at the first field it cannot parse, so every field is processed on its own to report them all.
+func processEnv(section any) error {
+	v := reflect.ValueOf(section).Elem()
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		single := reflect.New(reflect.StructOf([]reflect.StructField{{Name: field.Name, Type: field.Type, Tag: field.Tag}}))
+		if err := envconfig.Process("", single.Interface()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		v.Field(i).Set(single.Elem().Field(0))
+	}
+	return errors.Join(errs...)
+}
+
+// decodeFile decodes the file at path into cfg, and returns every key it cannot decode at once.
+// TOML is converted to YAML first, so both formats share the yaml tags and decoding rules, durations such as "30s" included.
+func decodeFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	case ".toml":
+		var doc map[string]any
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("parse config file %s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(doc); err != nil {
+			return fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	if !isMapping(doc.Content[0]) {
+		return fmt.Errorf("parse config file %s: the file must be a mapping of sections", path)
+	}
+
+	// The keys are decoded one by one, the decoder stops at the first value it cannot parse.
+	var errs []error
+	eachKey(doc.Content[0], func(key string, node *yaml.Node) {
+		section, ok := fieldByYAMLKey(reflect.ValueOf(cfg).Elem(), key)
+		if !ok {
+			errs = append(errs, fmt.Errorf("parse config file %s: line %d: field %s not found", path, node.Line, key))
+			return
+		}
+		if !isMapping(node) {
+			errs = append(errs, fmt.Errorf("parse config file %s: line %d: %s must be a mapping", path, node.Line, key))
+			return
+		}
+		eachKey(node, func(name string, node *yaml.Node) {
+			field, ok := fieldByYAMLKey(section, name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("parse config file %s: line %d: field %s not found in %s", path, node.Line, name, key))
+				return
+			}
+			if err := node.Decode(field.Addr().Interface()); err != nil {
+				errs = append(errs, fmt.Errorf("parse config file %s: %s.%s: %w", path, key, name, err))
+			}
+		})
+	})
+	return errors.Join(errs...)
+}
+
+// isMapping returns whether node is a mapping, an empty value counts as an empty mapping.
+func isMapping(node *yaml.Node) bool {
+	return node.Kind == yaml.MappingNode || node.ShortTag() == "!!null"
+}
+
+// eachKey calls fn with every key of the mapping node and its value, in file order. Other nodes have no keys.
+func eachKey(node *yaml.Node, fn func(key string, value *yaml.Node)) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fn(node.Content[i].Value, node.Content[i+1])
+	}
+}
+
+// fieldByYAMLKey returns the field of the struct v whose yaml tag names key.
+func fieldByYAMLKey(v reflect.Value, key string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+		if name == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// overrideFromEnv copies from env into dst every field whose environment variable is set.
+func overrideFromEnv(dst, env reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		name := dst.Type().Field(i).Tag.Get("envconfig")
+		if name == "" {
+			continue
+		}
+		if _, ok := os.LookupEnv(name); ok {
+			dst.Field(i).Set(env.Field(i))
+		}
+	}
+}
+
+// redact replaces the non-empty string fields tagged secret:"true" of the struct v.
+func redact(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if v.Type().Field(i).Tag.Get("secret") == "true" && field.Kind() == reflect.String && field.String() != "" {
+			field.SetString(redacted)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"bytes"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLoad sets environment variables, so it does not run in parallel.
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		fileName string
+		file     string
+		env      map[string]string
+
+		expectErr []string
+		verify    func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "defaults",
+
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "8080", cfg.API.AppPort)
+				assert.Equal(t, model.RateLimit{Limit: 60, Period: time.Minute}, cfg.API.RateLimitShortenIP)
+				assert.NotEmpty(t, cfg.API.InstanceID)
+				assert.Equal(t, "localhost:6379", cfg.Redis.Address)
+				assert.Equal(t, 3*time.Second, cfg.Redis.ReadTimeout)
+				assert.Equal(t, "info", cfg.Log.Level)
+			},
+		},
+		{
+			name: "yaml file",
+
+			fileName: "config.yaml",
+			file: `
+api:
+  app_port: "9090"
+  url_cache_ttl: 1m
+  rate_limit_shorten_ip: 10/1s
+  trusted_proxies: [10.0.0.0/8]
+redis:
+  pool_size: 20
+  tls_enabled: true
+`,
+
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "9090", cfg.API.AppPort)
+				assert.Equal(t, time.Minute, cfg.API.UrlCacheTTL)
+				assert.Equal(t, model.RateLimit{Limit: 10, Period: time.Second}, cfg.API.RateLimitShortenIP)
+				assert.Equal(t, []string{"10.0.0.0/8"}, cfg.API.TrustedProxies)
+				assert.Equal(t, 20, cfg.Redis.PoolSize)
+				assert.True(t, cfg.Redis.TLSEnabled)
+				assert.Equal(t, "bookmark-management", cfg.API.ServiceName)
+			},
+		},
+		{
+			name: "toml file",
+
+			fileName: "config.toml",
+			file: `
+[api]
+app_port = "9090"
+url_cache_ttl = "1m"
+keyspace_grow_occupancy = 0.2
+
+[redis]
+pool_size = 20
+`,
+
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "9090", cfg.API.AppPort)
+				assert.Equal(t, time.Minute, cfg.API.UrlCacheTTL)
+				assert.Equal(t, 0.2, cfg.API.KeyspaceGrowOccupancy)
+				assert.Equal(t, 20, cfg.Redis.PoolSize)
+			},
+		},
+		{
+			name: "environment overrides the file",
+
+			fileName: "config.yaml",
+			file: `
+api:
+  app_port: "9090"
+  service_name: from-file
+redis:
+  address: file:6379
+`,
+			env: map[string]string{"APP_PORT": "7070", "REDIS_ADDR": "env:6379"},
+
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "7070", cfg.API.AppPort)
+				assert.Equal(t, "from-file", cfg.API.ServiceName)
+				assert.Equal(t, "env:6379", cfg.Redis.Address)
+			},
+		},
+		{
+			name: "every invalid field is reported",
+
+			fileName: "config.yaml",
+			file: `
+api:
+  keygen_strategy: sequential
+  public_url: sho.rt
+  webhook_max_attempts: 0
+redis:
+  db: -1
+  tls_cert_file: client.pem
+`,
+			env: map[string]string{"APP_PORT": "http", "OTEL_TRACES_EXPORTER": "zipkin"},
+
+			expectErr: []string{
+				`APP_PORT must be a port number, got "http"`,
+				`unknown KEYGEN_STRATEGY "sequential"`,
+				`PUBLIC_URL must be an absolute http or https URL, got "sho.rt"`,
+				"WEBHOOK_MAX_ATTEMPTS must be at least 1, got 0",
+				"REDIS_DB must not be negative, got -1",
+				"REDIS_TLS_CERT_FILE and REDIS_TLS_KEY_FILE must be set together",
+				"REDIS_TLS_* options require REDIS_TLS_ENABLED",
+				`unknown OTEL_TRACES_EXPORTER "zipkin"`,
+			},
+		},
+		{
+			name: "parse errors are reported with the invalid fields",
+
+			fileName: "config.yaml",
+			file: `
+api:
+  rate_limit_shorten_ip: lots
+  keyspace_warn_occupancy: 2
+`,
+			env: map[string]string{"URL_CACHE_TTL": "soon", "REDIS_POOL_SIZE": "many"},
+
+			expectErr: []string{
+				"assigning URL_CACHE_TTL to UrlCacheTTL",
+				"assigning REDIS_POOL_SIZE to PoolSize",
+				"api.rate_limit_shorten_ip",
+				"KEYSPACE_WARN_OCCUPANCY must be between 0 and 1, got 2",
+			},
+		},
+		{
+			name: "unknown key",
+
+			fileName: "config.yaml",
+			file: `
+api:
+  app_prot: "9090"
+`,
+
+			expectErr: []string{"field app_prot not found"},
+		},
+		{
+			name: "unsupported format",
+
+			fileName: "config.json",
+			file:     `{}`,
+
+			expectErr: []string{"must be .yaml, .yml or .toml"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			var path string
+			if tc.fileName != "" {
+				path = filepath.Join(t.TempDir(), tc.fileName)
+				require.NoError(t, os.WriteFile(path, []byte(tc.file), 0o600))
+			}
+
+			cfg, err := Load(path)
+			if len(tc.expectErr) > 0 {
+				require.Error(t, err)
+				for _, expected := range tc.expectErr {
+					assert.Contains(t, err.Error(), expected)
+				}
+				return
+			}
+			require.NoError(t, err)
+			tc.verify(t, cfg)
+		})
+	}
+}
+
+func TestConfig_Print(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+	cfg.API.AppPort = "8080"
+	cfg.API.KeyGenSecret = "feistel-key"
+	cfg.API.RateLimitShortenIP = model.RateLimit{Limit: 60, Period: time.Minute}
+	cfg.Redis.Address = "localhost:6379"
+	cfg.Redis.Password = "hunter2"
+	cfg.Redis.ReadTimeout = 3 * time.Second
+
+	var buf bytes.Buffer
+	require.NoError(t, cfg.Print(&buf))
+
+	out := buf.String()
+	assert.Contains(t, out, "keygen_secret: REDACTED")
+	assert.Contains(t, out, "password: REDACTED")
+	assert.Contains(t, out, "rate_limit_shorten_ip: 60/1m0s")
+	assert.Contains(t, out, "read_timeout: 3s")
+	assert.NotContains(t, out, "feistel-key")
+	assert.NotContains(t, out, "hunter2")
+	assert.Equal(t, "hunter2", cfg.Redis.Password, "printing must not modify the config")
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+type registerDomainRequest struct {
+	Host string `json:"host" binding:"required"`
+}
+
+type domainVerificationResponse struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type domainResponse struct {
+	Host         string                     `json:"host"`
+	Verified     bool                       `json:"verified"`
+	CreatedAt    time.Time                  `json:"createdAt"`
+	VerifiedAt   *time.Time                 `json:"verifiedAt,omitempty"`
+	Verification domainVerificationResponse `json:"verification"`
+}
+
+type domainListResponse struct {
+	Domains []domainResponse `json:"domains"`
+}
+
+type DomainHandler interface {
+	Register(c *gin.Context)
+	List(c *gin.Context)
+	Verify(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type domainHandler struct {
+	domains service.Domain
+}
+
+// NewDomainHandler returns a new instance of the domainHandler, which implements the DomainHandler interface.
+// Its handlers expect middleware.RequireWorkspaceRole in front of them.
+func NewDomainHandler(domains service.Domain) DomainHandler {
+	return &domainHandler{domains: domains}
+}
+
+// Register registers a custom domain for a workspace
+// @Summary Register custom domain
+// @Description Registers a custom short domain for a workspace. Codes of the workspace are served at GET /{code} on the domain once it is verified, publish the returned TXT record then call the verify endpoint. A domain pending verification for another workspace is claimed, the first workspace to verify it gets it.
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Param workspace path string true "Workspace ID"
+// @Param registerDomainRequest body registerDomainRequest true "Domain to register"
+// @Success 201 {object} domainResponse
+// @Failure 400 {object} map[string]string "Bad Request - invalid domain"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace not found"
+// @Failure 409 {object} map[string]string "Conflict - domain already registered by the workspace or verified by another one"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/workspaces/{workspace}/domains [post]
+func (h *domainHandler) Register(c *gin.Context) {
+	var req registerDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request"})
+		return
+	}
+
+	d, err := h.domains.Register(c, c.Param("workspace"), req.Host)
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newDomainResponse(d))
+}
+
+// List returns the custom domains of a workspace
+// @Summary List custom domains
+// @Description Lists the custom domains of a workspace, sorted by host.
+// @Tags Workspaces
+// @Produce json
+// @Param workspace path string true "Workspace ID"
+// @Success 200 {object} domainListResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Workspace not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/workspaces/{workspace}/domains [get]
+func (h *domainHandler) List(c *gin.Context) {
+	domains, err := h.domains.List(c, c.Param("workspace"))
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	resp := domainListResponse{Domains: make([]domainResponse, 0, len(domains))}
+	for _, d := range domains {
+		resp.Domains = append(resp.Domains, newDomainResponse(d))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Verify checks the verification TXT record of a custom domain
+// @Summary Verify custom domain
+// @Description Looks up the verification TXT record of a custom domain and marks the domain verified if it holds the expected value, taking it over from the workspace that registered it first.
+// @Tags Workspaces
+// @Produce json
+// @Param workspace path string true "Workspace ID"
+// @Param host path string true "Domain"
+// @Success 200 {object} domainResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace or domain not found"
+// @Failure 409 {object} map[string]string "Conflict - domain verified by another workspace first"
+// @Failure 422 {object} map[string]string "Unprocessable Entity - the TXT record is missing or holds another value"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/workspaces/{workspace}/domains/{host}/verify [post]
+func (h *domainHandler) Verify(c *gin.Context) {
+	d, err := h.domains.Verify(c, c.Param("workspace"), c.Param("host"))
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newDomainResponse(d))
+}
+
+// Delete removes a custom domain of a workspace
+// @Summary Delete custom domain
+// @Description Removes a custom domain of a workspace, its codes stop being served on it.
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID"
+// @Param host path string true "Domain"
+// @Success 204
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace or domain not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/workspaces/{workspace}/domains/{host} [delete]
+func (h *domainHandler) Delete(c *gin.Context) {
+	if err := h.domains.Delete(c, c.Param("workspace"), c.Param("host")); err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// errorResponse writes the response of err, an error of the domain service.
+func (h *domainHandler) errorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidDomain):
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid domain"})
+	case errors.Is(err, service.ErrDomainTaken):
+		c.JSON(http.StatusConflict, gin.H{"message": "domain already registered"})
+	case errors.Is(err, service.ErrDomainNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "domain not found"})
+	case errors.Is(err, service.ErrDomainNotVerified):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "verification record not found"})
+	default:
+		log.Ctx(c).Error().Err(err).Msg("Service return error on custom domains")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+	}
+}
+
+func newDomainResponse(d model.Domain) domainResponse {
+	name, value := service.DomainVerificationRecord(d)
+	resp := domainResponse{
+		Host:         d.Host,
+		Verified:     d.Verified,
+		CreatedAt:    d.CreatedAt,
+		Verification: domainVerificationResponse{Type: "TXT", Name: name, Value: value},
+	}
+	if d.Verified {
+		resp.VerifiedAt = &d.VerifiedAt
+	}
+	return resp
+}
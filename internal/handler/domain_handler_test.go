@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDomainHandler_Register(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		body         string
+		setupMockSvc func(t *testing.T) *mocks.Domain
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "success -> 201",
+
+			body: `{"host":"go.team.io"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Register", mock.Anything, "ws1", "go.team.io").Return(model.Domain{
+					Host:      "go.team.io",
+					Workspace: "ws1",
+					Token:     "tok",
+					CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+				}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusCreated,
+			expectedResponseBody: `{"host":"go.team.io","verified":false,"createdAt":"2025-01-02T03:04:05Z",` +
+				`"verification":{"type":"TXT","name":"_bookmark-verify.go.team.io","value":"bookmark-verify=tok"}}`,
+		},
+		{
+			name: "missing host -> 400",
+
+			body: `{}`,
+			setupMockSvc: func(t *testing.T) *mocks.Domain {
+				return mocks.NewDomain(t)
+			},
+
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseBody: `{"message":"Invalid request"}`,
+		},
+		{
+			name: "invalid domain -> 400",
+
+			body: `{"host":"localhost"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Register", mock.Anything, "ws1", "localhost").Return(model.Domain{}, service.ErrInvalidDomain).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid domain"}`,
+		},
+		{
+			name: "taken -> 409",
+
+			body: `{"host":"go.team.io"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Register", mock.Anything, "ws1", "go.team.io").Return(model.Domain{}, service.ErrDomainTaken).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusConflict,
+			expectedResponseBody: `{"message":"domain already registered"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodPost, "/v1/workspaces/ws1/domains", strings.NewReader(tc.body))
+			gc.Params = gin.Params{{Key: "workspace", Value: "ws1"}}
+
+			testHandler := NewDomainHandler(tc.setupMockSvc(t))
+			testHandler.Register(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
+
+func TestDomainHandler_Verify(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		setupMockSvc func(t *testing.T) *mocks.Domain
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "verified",
+
+			setupMockSvc: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Verify", mock.Anything, "ws1", "go.team.io").Return(model.Domain{
+					Host:       "go.team.io",
+					Workspace:  "ws1",
+					Token:      "tok",
+					Verified:   true,
+					CreatedAt:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+					VerifiedAt: time.Date(2025, 1, 2, 4, 0, 0, 0, time.UTC),
+				}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"host":"go.team.io","verified":true,"createdAt":"2025-01-02T03:04:05Z","verifiedAt":"2025-01-02T04:00:00Z",` +
+				`"verification":{"type":"TXT","name":"_bookmark-verify.go.team.io","value":"bookmark-verify=tok"}}`,
+		},
+		{
+			name: "record missing -> 422",
+
+			setupMockSvc: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Verify", mock.Anything, "ws1", "go.team.io").Return(model.Domain{}, service.ErrDomainNotVerified).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"message":"verification record not found"}`,
+		},
+		{
+			name: "not found -> 404",
+
+			setupMockSvc: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Verify", mock.Anything, "ws1", "go.team.io").Return(model.Domain{}, service.ErrDomainNotFound).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponseBody: `{"message":"domain not found"}`,
+		},
+		{
+			name: "lookup error -> 500",
+
+			setupMockSvc: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Verify", mock.Anything, "ws1", "go.team.io").Return(model.Domain{}, errors.New("i/o timeout")).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodPost, "/v1/workspaces/ws1/domains/go.team.io/verify", nil)
+			gc.Params = gin.Params{{Key: "workspace", Value: "ws1"}, {Key: "host", Value: "go.team.io"}}
+
+			testHandler := NewDomainHandler(tc.setupMockSvc(t))
+			testHandler.Verify(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
+
+func TestDomainHandler_List(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	gc, _ := gin.CreateTestContext(rec)
+	gc.Request = httptest.NewRequest(http.MethodGet, "/v1/workspaces/ws1/domains", nil)
+	gc.Params = gin.Params{{Key: "workspace", Value: "ws1"}}
+
+	svc := mocks.NewDomain(t)
+	svc.On("List", mock.Anything, "ws1").Return([]model.Domain{}, nil).Once()
+
+	NewDomainHandler(svc).List(gc)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"domains":[]}`, rec.Body.String())
+}
+
+func TestDomainHandler_Delete(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		err error
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "success -> 204",
+
+			expectedResponseCode: http.StatusNoContent,
+		},
+		{
+			name: "not found -> 404",
+
+			err: service.ErrDomainNotFound,
+
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponseBody: `{"message":"domain not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodDelete, "/v1/workspaces/ws1/domains/go.team.io", nil)
+			gc.Params = gin.Params{{Key: "workspace", Value: "ws1"}, {Key: "host", Value: "go.team.io"}}
+
+			svc := mocks.NewDomain(t)
+			svc.On("Delete", mock.Anything, "ws1", "go.team.io").Return(tc.err).Once()
+
+			NewDomainHandler(svc).Delete(gc)
+			gc.Writer.WriteHeaderNow()
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/metrics"
 	"github.com/lhducc/bookmark-management/internal/service"
 	"github.com/rs/zerolog/log"
 	"net/http"
@@ -20,16 +22,20 @@ type healthCheckErrorResponse struct {
 	InstanceID  string `json:"instance_id"`
 }
 type healthCheckHandler struct {
-	svc service.HealthCheck
+	svc     service.HealthCheck
+	metrics *metrics.Metrics
 }
 
 type HealthCheckHandler interface {
 	Check(c *gin.Context)
 }
 
-func NewHealthCheckHandler(svc service.HealthCheck) HealthCheckHandler {
+// NewHealthCheckHandler returns a new instance of the healthCheckHandler, which implements the HealthCheckHandler interface.
+// The metrics are optional, health check results are not recorded when it is nil.
+func NewHealthCheckHandler(svc service.HealthCheck, m *metrics.Metrics) HealthCheckHandler {
 	return &healthCheckHandler{
-		svc: svc,
+		svc:     svc,
+		metrics: m,
 	}
 }
 
@@ -38,11 +44,23 @@ func NewHealthCheckHandler(svc service.HealthCheck) HealthCheckHandler {
 // @Description Check health of the service
 // @Tags Health Check
 // @Success 200 {object} healthCheckResponse
+// @Failure 503 {object} healthCheckErrorResponse "Redis is unreachable, or the instance is draining before shutdown"
 // @Router /health-check [get]
 func (h *healthCheckHandler) Check(c *gin.Context) {
 	message, serviceName, instanceID, err := h.svc.Check(c)
+	h.metrics.ObserveHealthCheck(err == nil)
+	if errors.Is(err, service.ErrDraining) {
+		log.Ctx(c).Info().Msg("Health check reports draining")
+		c.JSON(http.StatusServiceUnavailable, healthCheckErrorResponse{
+			Error:       "Service Unavailable",
+			Message:     message,
+			ServiceName: serviceName,
+			InstanceID:  instanceID,
+		})
+		return
+	}
 	if err != nil {
-		log.Error().Err(err).Msg("Service return error on Check")
+		log.Ctx(c).Error().Err(err).Msg("Service return error on Check")
 		c.JSON(http.StatusServiceUnavailable, healthCheckErrorResponse{
 			Error:       "Internal Server Error",
 			Message:     message,
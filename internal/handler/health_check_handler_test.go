@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/service"
 	"github.com/lhducc/bookmark-management/internal/service/mocks"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -55,6 +56,21 @@ func TestHealthCheckHandler_Check(t *testing.T) {
 			expectedResponseCode: http.StatusServiceUnavailable,
 			expectedResponseBody: `{"error":"Internal Server Error","message":"NOT OK","service_name":"bookmark-management","instance_id":"2025"}`,
 		},
+		{
+			name: "draining case",
+
+			setupRequest: func(ctx *gin.Context) {
+				ctx.Request = httptest.NewRequest(http.MethodGet, "/health_check", nil)
+			},
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.HealthCheck {
+				mockSvc := mocks.NewHealthCheck(t)
+				mockSvc.On("Check", ctx).Return("DRAINING", "bookmark-management", "2025", service.ErrDraining)
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusServiceUnavailable,
+			expectedResponseBody: `{"error":"Service Unavailable","message":"DRAINING","service_name":"bookmark-management","instance_id":"2025"}`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -65,7 +81,7 @@ func TestHealthCheckHandler_Check(t *testing.T) {
 			gc, _ := gin.CreateTestContext(rec)
 			tc.setupRequest(gc)
 			mockSvc := tc.setupMockSvc(t, gc)
-			testHandler := NewHealthCheckHandler(mockSvc)
+			testHandler := NewHealthCheckHandler(mockSvc, nil)
 			testHandler.Check(gc)
 
 			assert.Equal(t, tc.expectedResponseCode, rec.Code)
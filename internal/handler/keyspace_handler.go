@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"net/http"
+)
+
+type keyspaceLengthResponse struct {
+	Length             int     `json:"length"`
+	Capacity           float64 `json:"capacity"`
+	Attempts           uint64  `json:"attempts"`
+	Collisions         uint64  `json:"collisions"`
+	EstimatedOccupancy float64 `json:"estimatedOccupancy"`
+	EstimatedCodes     float64 `json:"estimatedCodes"`
+	Status             string  `json:"status"`
+}
+
+type keyspaceResponse struct {
+	CodeLength int                      `json:"codeLength"`
+	Lengths    []keyspaceLengthResponse `json:"lengths"`
+}
+
+type KeyspaceHandler interface {
+	Stats(c *gin.Context)
+}
+
+type keyspaceHandler struct {
+	monitor service.KeyspaceMonitor
+}
+
+// NewKeyspaceHandler returns a new instance of the keyspaceHandler, which implements the KeyspaceHandler interface.
+func NewKeyspaceHandler(monitor service.KeyspaceMonitor) KeyspaceHandler {
+	return &keyspaceHandler{monitor: monitor}
+}
+
+// Stats returns the collision statistics and estimated occupancy of every code length
+// @Summary Keyspace saturation
+// @Description Collision counts per code length and the keyspace occupancy estimated from them. A length turns "warning" as it fills up and "saturated" once new codes have moved to a longer length.
+// @Tags URL Shortener
+// @Produce json
+// @Success 200 {object} keyspaceResponse
+// @Router /v1/links/keyspace [get]
+func (h *keyspaceHandler) Stats(c *gin.Context) {
+	stats := h.monitor.Stats()
+
+	resp := keyspaceResponse{
+		CodeLength: h.monitor.CodeLength(),
+		Lengths:    make([]keyspaceLengthResponse, 0, len(stats)),
+	}
+	for _, s := range stats {
+		resp.Lengths = append(resp.Lengths, keyspaceLengthResponse{
+			Length:             s.Length,
+			Capacity:           s.Capacity,
+			Attempts:           s.Attempts,
+			Collisions:         s.Collisions,
+			EstimatedOccupancy: s.EstimatedOccupancy,
+			EstimatedCodes:     s.EstimatedCodes,
+			Status:             s.Status,
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKeyspaceHandler_Stats(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMockSvc func(t *testing.T) *mocks.KeyspaceMonitor
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "no codes generated yet",
+
+			setupMockSvc: func(t *testing.T) *mocks.KeyspaceMonitor {
+				mockSvc := mocks.NewKeyspaceMonitor(t)
+				mockSvc.On("Stats").Return([]model.KeyspaceStats{})
+				mockSvc.On("CodeLength").Return(7)
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"codeLength":7,"lengths":[]}`,
+		},
+		{
+			name: "saturated length",
+
+			setupMockSvc: func(t *testing.T) *mocks.KeyspaceMonitor {
+				mockSvc := mocks.NewKeyspaceMonitor(t)
+				mockSvc.On("Stats").Return([]model.KeyspaceStats{
+					{Length: 2, Capacity: 100, Attempts: 200, Collisions: 60, EstimatedOccupancy: 0.25, EstimatedCodes: 25, Status: model.KeyspaceStatusSaturated},
+					{Length: 3, Capacity: 1000, Attempts: 10, Status: model.KeyspaceStatusOK},
+				})
+				mockSvc.On("CodeLength").Return(3)
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"codeLength":3,"lengths":[` +
+				`{"length":2,"capacity":100,"attempts":200,"collisions":60,"estimatedOccupancy":0.25,"estimatedCodes":25,"status":"saturated"},` +
+				`{"length":3,"capacity":1000,"attempts":10,"collisions":0,"estimatedOccupancy":0,"estimatedCodes":0,"status":"ok"}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodGet, "/v1/links/keyspace", nil)
+
+			testHandler := NewKeyspaceHandler(tc.setupMockSvc(t))
+			testHandler.Stats(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
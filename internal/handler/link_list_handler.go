@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+type linkListRequest struct {
+	Sort           string        `form:"sort" enums:"created,expiry,clicks"`
+	Host           string        `form:"host"`
+	Status         string        `form:"status" enums:"active,expired,revoked"`
+	Tag            string        `form:"tag"`
+	ExpiringWithin time.Duration `form:"expiring_within"`
+	Cursor         string        `form:"cursor"`
+	Limit          int           `form:"limit"`
+}
+
+type linkSummaryResponse struct {
+	Code      string    `json:"code"`
+	URL       string    `json:"url"`
+	Tags      []string  `json:"tags"`
+	Clicks    int64     `json:"clicks"`
+	Status    string    `json:"status" enums:"active,expired,revoked"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type linkListResponse struct {
+	Links []linkSummaryResponse `json:"links"`
+	Next  string                `json:"next,omitempty"`
+}
+
+type LinkListHandler interface {
+	List(c *gin.Context)
+}
+
+type linkListHandler struct {
+	links service.LinkList
+	now   func() time.Time
+}
+
+// NewLinkListHandler returns a new instance of the linkListHandler, which implements the LinkListHandler interface.
+// On the workspace routes it expects middleware.RequireWorkspaceRole in front of it, elsewhere it lists the global links
+// of the authenticated user.
+func NewLinkListHandler(links service.LinkList) LinkListHandler {
+	return &linkListHandler{links: links, now: time.Now}
+}
+
+// List returns a page of links
+// @Summary List links
+// @Description Lists the links of a workspace, or the global links created by the user outside the workspace routes, newest first, soonest to expire first or most clicked first.
+// @Description Links are filtered by destination host, status, tag and expiry window, every filter given has to match. Expired links are listed for 30 days. Revoked links are the deleted links kept in the trash, listed the latest deleted first in the default sort only.
+// @Description Every page but the last has a next cursor, pass it as cursor with the same sort to get the following links. A page may hold fewer links than the limit, or none, when few links match the filters.
+// @Tags URL Shortener
+// @Produce json
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Param sort query string false "Order of the links, created by default" Enums(created, expiry, clicks)
+// @Param host query string false "Host name of the destination, such as example.com"
+// @Param status query string false "Status of the links" Enums(active, expired, revoked)
+// @Param tag query string false "Tag of the links"
+// @Param expiring_within query string false "Keeps the active links expiring within this window from now, as a Go duration such as 72h"
+// @Param cursor query string false "Next cursor of the previous page"
+// @Param limit query int false "Links per page, 1 to 100, 20 by default"
+// @Success 200 {object} linkListResponse
+// @Failure 400 {object} map[string]string "Bad Request - invalid sort, status, window, limit or cursor"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/links [get]
+// @Router /v1/workspaces/{workspace}/links [get]
+func (h *linkListHandler) List(c *gin.Context) {
+	workspace, owner := c.Param("workspace"), c.GetString(middleware.UserIDKey)
+	if workspace == "" && owner == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "authentication required"})
+		return
+	}
+
+	var query linkListRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request"})
+		return
+	}
+
+	page, err := h.links.List(c, workspace, owner, model.LinkQuery{
+		Sort:           query.Sort,
+		Host:           query.Host,
+		Status:         query.Status,
+		Tag:            query.Tag,
+		ExpiringWithin: query.ExpiringWithin,
+		Cursor:         query.Cursor,
+		Limit:          query.Limit,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLinkQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request"})
+			return
+		}
+		log.Ctx(c).Error().Err(err).Msg("Service return error on List")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+
+	now := h.now()
+	resp := linkListResponse{Links: make([]linkSummaryResponse, 0, len(page.Links)), Next: page.Next}
+	for _, link := range page.Links {
+		resp.Links = append(resp.Links, newLinkSummaryResponse(link, now))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func newLinkSummaryResponse(link model.LinkSummary, now time.Time) linkSummaryResponse {
+	tags := link.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return linkSummaryResponse{
+		Code:      link.Code,
+		URL:       link.URL,
+		Tags:      tags,
+		Clicks:    link.Clicks,
+		Status:    link.Status(now),
+		CreatedAt: link.CreatedAt,
+		ExpiresAt: link.ExpiresAt,
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLinkListHandler_List(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+
+		workspace    string
+		userID       string
+		query        string
+		setupMockSvc func(t *testing.T) *mocks.LinkList
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "user links -> 200",
+
+			userID: "u1",
+			query:  "?sort=clicks&host=example.com&status=active&tag=promo&expiring_within=72h&cursor=abc&limit=2",
+			setupMockSvc: func(t *testing.T) *mocks.LinkList {
+				svc := mocks.NewLinkList(t)
+				svc.On("List", mock.Anything, "", "u1", model.LinkQuery{
+					Sort:           model.LinkSortClicks,
+					Host:           "example.com",
+					Status:         model.LinkStatusActive,
+					Tag:            "promo",
+					ExpiringWithin: 72 * time.Hour,
+					Cursor:         "abc",
+					Limit:          2,
+				}).Return(model.LinkPage{
+					Links: []model.LinkSummary{
+						{Code: "soon", URL: "https://example.com", Tags: []string{"promo"}, Clicks: 3, CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+						{Code: "old", URL: "https://example.com/old", Clicks: 1, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+					},
+					Next: "def",
+				}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"links":[` +
+				`{"code":"soon","url":"https://example.com","tags":["promo"],"clicks":3,"status":"active","createdAt":"2025-01-02T02:04:05Z","expiresAt":"2025-01-02T04:04:05Z"},` +
+				`{"code":"old","url":"https://example.com/old","tags":[],"clicks":1,"status":"expired","createdAt":"2025-01-02T01:04:05Z","expiresAt":"2025-01-02T02:04:05Z"}],` +
+				`"next":"def"}`,
+		},
+		{
+			name: "workspace links, last page -> 200",
+
+			workspace: "ws1",
+			setupMockSvc: func(t *testing.T) *mocks.LinkList {
+				svc := mocks.NewLinkList(t)
+				svc.On("List", mock.Anything, "ws1", "", model.LinkQuery{}).Return(model.LinkPage{}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"links":[]}`,
+		},
+		{
+			name: "anonymous -> 401",
+
+			setupMockSvc: func(t *testing.T) *mocks.LinkList {
+				return mocks.NewLinkList(t)
+			},
+
+			expectedResponseCode: http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"authentication required"}`,
+		},
+		{
+			name: "malformed window -> 400",
+
+			userID: "u1",
+			query:  "?expiring_within=soon",
+			setupMockSvc: func(t *testing.T) *mocks.LinkList {
+				return mocks.NewLinkList(t)
+			},
+
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseBody: `{"message":"Invalid request"}`,
+		},
+		{
+			name: "invalid query -> 400",
+
+			userID: "u1",
+			query:  "?sort=name",
+			setupMockSvc: func(t *testing.T) *mocks.LinkList {
+				svc := mocks.NewLinkList(t)
+				svc.On("List", mock.Anything, "", "u1", model.LinkQuery{Sort: "name"}).Return(model.LinkPage{}, service.ErrInvalidLinkQuery).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseBody: `{"message":"Invalid request"}`,
+		},
+		{
+			name: "service error -> 500",
+
+			userID: "u1",
+			setupMockSvc: func(t *testing.T) *mocks.LinkList {
+				svc := mocks.NewLinkList(t)
+				svc.On("List", mock.Anything, "", "u1", model.LinkQuery{}).Return(model.LinkPage{}, errors.New("redis down")).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodGet, "/v1/links"+tc.query, nil)
+			if tc.workspace != "" {
+				gc.Params = gin.Params{{Key: "workspace", Value: tc.workspace}}
+			}
+			if tc.userID != "" {
+				gc.Set(middleware.UserIDKey, tc.userID)
+			}
+
+			testHandler := &linkListHandler{links: tc.setupMockSvc(t), now: func() time.Time { return now }}
+			testHandler.List(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
@@ -34,7 +34,7 @@ func NewPassword(svc service.Password) Password {
 func (h *passwordHandler) GenPass(c *gin.Context) {
 	pass, err := h.svc.GeneratePassword()
 	if err != nil {
-		log.Error().Err(err).Msg("Service return error on GenPass")
+		log.Ctx(c).Error().Err(err).Msg("Service return error on GenPass")
 		c.String(http.StatusInternalServerError, "err")
 		return
 	}
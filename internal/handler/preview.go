@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"github.com/lhducc/bookmark-management/internal/model"
+	"html/template"
+)
+
+// previewSuffix appended to a code asks for its preview page instead of the redirect, as in /abc1234+.
+const previewSuffix = "+"
+
+type previewPage struct {
+	Code   string
+	URL    string
+	Title  string
+	Safety model.LinkSafety
+}
+
+// previewTemplate renders the preview page of a link, shown instead of the redirect.
+// html/template escapes every value, the destination included, so a link cannot inject markup into the page.
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>Link preview - {{.Code}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 3rem auto; padding: 0 1rem; color: #1f2328; }
+.destination { word-break: break-all; font-family: ui-monospace, monospace; background: #f6f8fa; padding: .75rem; border-radius: .375rem; }
+.safe { color: #1a7f37; }
+.warning { color: #9a6700; }
+a.continue { display: inline-block; margin-top: 1rem; padding: .5rem 1rem; background: #0969da; color: #fff; border-radius: .375rem; text-decoration: none; }
+</style>
+</head>
+<body>
+<h1>{{if .Title}}{{.Title}}{{else}}Link preview{{end}}</h1>
+<p>The short link <strong>{{.Code}}</strong> leads to:</p>
+<p class="destination">{{.URL}}</p>
+<h2>Safety</h2>
+{{if .Safety.Safe}}<p class="safe">No issue found by the checks of this service.</p>
+{{else}}<ul class="warning">
+{{range .Safety.Warnings}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}<p>The checks only look at the address, they cannot tell whether the page behind it is trustworthy.</p>
+<a class="continue" href="{{.URL}}" rel="noopener noreferrer nofollow">Continue to the destination</a>
+</body>
+</html>
+`))
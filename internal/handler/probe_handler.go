@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"net/http"
+	"time"
+)
+
+type probeCheckResponse struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type probeResponse struct {
+	Status    string               `json:"status"`
+	CheckedAt *time.Time           `json:"checkedAt,omitempty"`
+	Checks    []probeCheckResponse `json:"checks,omitempty"`
+}
+
+type ProbeHandler interface {
+	Livez(c *gin.Context)
+	Readyz(c *gin.Context)
+}
+
+type probeHandler struct {
+	readiness service.Readiness
+}
+
+// NewProbeHandler returns a new instance of the probeHandler, which implements the ProbeHandler interface.
+func NewProbeHandler(readiness service.Readiness) ProbeHandler {
+	return &probeHandler{readiness: readiness}
+}
+
+// Livez reports that the process is up and serving requests, it never checks the dependencies
+// @Summary Liveness probe
+// @Description Always 200 while the process serves requests. Dependencies are not checked, so a Redis outage does not get the instance restarted.
+// @Tags Health Check
+// @Produce json
+// @Success 200 {object} probeResponse
+// @Router /livez [get]
+func (h *probeHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, probeResponse{Status: model.CheckStatusOK})
+}
+
+// Readyz reports whether the instance should receive traffic
+// @Summary Readiness probe
+// @Description Runs the readiness checkers (Redis ping, Redis write probe, background workers), results are cached briefly. The per-check breakdown is only returned with ?verbose.
+// @Tags Health Check
+// @Produce json
+// @Param verbose query bool false "Include the result of every check"
+// @Success 200 {object} probeResponse
+// @Failure 503 {object} probeResponse "A check failed or the instance is draining"
+// @Router /readyz [get]
+func (h *probeHandler) Readyz(c *gin.Context) {
+	report := h.readiness.Check(c)
+
+	status := http.StatusOK
+	if report.Status != model.CheckStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	resp := probeResponse{Status: report.Status}
+	if _, verbose := c.GetQuery("verbose"); verbose {
+		resp.CheckedAt = &report.CheckedAt
+		resp.Checks = make([]probeCheckResponse, 0, len(report.Checks))
+		for _, check := range report.Checks {
+			resp.Checks = append(resp.Checks, probeCheckResponse{
+				Name:      check.Name,
+				Status:    check.Status,
+				LatencyMs: float64(check.Latency.Microseconds()) / 1000,
+				Error:     check.Error,
+			})
+		}
+	}
+
+	c.JSON(status, resp)
+}
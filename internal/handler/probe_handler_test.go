@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProbeHandler_Livez(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	gc, _ := gin.CreateTestContext(rec)
+	gc.Request = httptest.NewRequest(http.MethodGet, "/livez", nil)
+
+	testHandler := NewProbeHandler(mocks.NewReadiness(t))
+	testHandler.Livez(gc)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func TestProbeHandler_Readyz(t *testing.T) {
+	t.Parallel()
+
+	checkedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	failing := model.ReadinessReport{
+		Status:    model.CheckStatusFail,
+		CheckedAt: checkedAt,
+		Checks: []model.CheckResult{
+			{Name: "redis", Status: model.CheckStatusOK, Latency: 1500 * time.Microsecond},
+			{Name: "storage", Status: model.CheckStatusFail, Latency: time.Second, Error: "context deadline exceeded"},
+		},
+	}
+
+	testCases := []struct {
+		name string
+
+		path         string
+		setupMockSvc func(t *testing.T) *mocks.Readiness
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "ready",
+
+			path: "/readyz",
+			setupMockSvc: func(t *testing.T) *mocks.Readiness {
+				mockSvc := mocks.NewReadiness(t)
+				mockSvc.On("Check", mock.Anything).Return(model.ReadinessReport{Status: model.CheckStatusOK, CheckedAt: checkedAt})
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+		{
+			name: "not ready",
+
+			path: "/readyz",
+			setupMockSvc: func(t *testing.T) *mocks.Readiness {
+				mockSvc := mocks.NewReadiness(t)
+				mockSvc.On("Check", mock.Anything).Return(failing)
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusServiceUnavailable,
+			expectedResponseBody: `{"status":"fail"}`,
+		},
+		{
+			name: "verbose breakdown",
+
+			path: "/readyz?verbose",
+			setupMockSvc: func(t *testing.T) *mocks.Readiness {
+				mockSvc := mocks.NewReadiness(t)
+				mockSvc.On("Check", mock.Anything).Return(failing)
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusServiceUnavailable,
+			expectedResponseBody: `{"status":"fail","checkedAt":"2025-01-02T03:04:05Z","checks":[` +
+				`{"name":"redis","status":"ok","latencyMs":1.5},` +
+				`{"name":"storage","status":"fail","latencyMs":1000,"error":"context deadline exceeded"}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodGet, tc.path, nil)
+
+			testHandler := NewProbeHandler(tc.setupMockSvc(t))
+			testHandler.Readyz(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strings"
+)
+
+// qrCodeMaxAge is how long clients and shared caches may reuse a QR code, the short URL of a code never changes.
+const qrCodeMaxAge = 24 * 60 * 60
+
+type qrCodeRequest struct {
+	Size   int    `form:"size,default=256" binding:"min=64,max=2048"`
+	Format string `form:"format,default=png" binding:"oneof=png svg" enums:"png,svg"`
+	ECC    string `form:"ecc,default=M" binding:"oneof=L M Q H" enums:"L,M,Q,H"`
+}
+
+type QRCodeHandler interface {
+	Render(c *gin.Context)
+}
+
+type qrCodeHandler struct {
+	urlService   service.ShortenUrl
+	domains      service.Domain
+	qr           service.QRCode
+	publicURL    string
+	rootRedirect bool
+}
+
+// NewQRCodeHandler returns a new instance of the qrCodeHandler, which implements the QRCodeHandler interface.
+// publicURL is the scheme and host the short URLs are built on, such as https://sho.rt, empty to take them from the request.
+// rootRedirect tells whether the codes are served at GET /:code on that host, or only at /v1/links/redirect/:code.
+func NewQRCodeHandler(urlSvc service.ShortenUrl, domains service.Domain, qr service.QRCode, publicURL string, rootRedirect bool) QRCodeHandler {
+	return &qrCodeHandler{
+		urlService:   urlSvc,
+		domains:      domains,
+		qr:           qr,
+		publicURL:    strings.TrimSuffix(publicURL, "/"),
+		rootRedirect: rootRedirect,
+	}
+}
+
+// Render renders the QR code of the short URL of a code.
+// @Summary QR code of a link
+// @Description Renders a QR code of the full short URL of a code, as a PNG image or an SVG document. Workspace codes use the first verified custom domain of the workspace when it has one.
+// @Description The image only depends on the short URL and the options, the ETag header lets clients revalidate it with If-None-Match.
+// @Tags URL Shortener
+// @Produce png,image/svg+xml
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Param code path string true "Url code"
+// @Param size query int false "Width and height in pixels, 64 to 2048" default(256)
+// @Param format query string false "Image format" Enums(png, svg) default(png)
+// @Param ecc query string false "Error correction level" Enums(L, M, Q, H) default(M)
+// @Success 200 {file} file "QR code"
+// @Success 304 "Not Modified - the ETag of If-None-Match is current"
+// @Failure 400 {object} map[string]string "Bad Request - invalid size, format or error correction level"
+// @Failure 404 {object} map[string]string "URL not found"
+// @Failure 429 {object} map[string]string "Too Many Requests - retry after the Retry-After header"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/links/{code}/qr [get]
+// @Router /v1/workspaces/{workspace}/links/{code}/qr [get]
+func (h *qrCodeHandler) Render(c *gin.Context) {
+	var query qrCodeRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request"})
+		return
+	}
+
+	workspace, code := c.Param("workspace"), c.Param("code")
+	if _, err := h.urlService.GetLink(c, workspace, code); err != nil {
+		if errors.Is(err, service.ErrCodeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "url not found"})
+			return
+		}
+		log.Ctx(c).Error().Err(err).Msg("Service return error on GetLink")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+
+	shortURL, err := h.shortURL(c, workspace, code)
+	if err != nil {
+		log.Ctx(c).Error().Err(err).Msg("Cannot build the short URL")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+
+	req := model.QRCodeRequest{Content: shortURL, Format: query.Format, Size: query.Size, ECC: query.ECC}
+	etag := qrCodeETag(req)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		setQRCodeCacheHeaders(c, workspace, etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	image, err := h.qr.Render(req)
+	if err != nil {
+		log.Ctx(c).Error().Err(err).Str("url", shortURL).Msg("Service return error on Render")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+
+	setQRCodeCacheHeaders(c, workspace, etag)
+	contentType := "image/png"
+	if req.Format == model.QRFormatSVG {
+		contentType = "image/svg+xml"
+	}
+	c.Data(http.StatusOK, contentType, image)
+}
+
+// setQRCodeCacheHeaders lets clients reuse the QR code tagged etag for qrCodeMaxAge, shared caches only for global codes.
+func setQRCodeCacheHeaders(c *gin.Context, workspace, etag string) {
+	cacheability := "public"
+	if workspace != "" {
+		cacheability = "private"
+	}
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("%s, max-age=%d", cacheability, qrCodeMaxAge))
+}
+
+// shortURL returns the full short URL of code in workspace.
+// Workspace codes are served at the root of the first verified custom domain of their workspace, or on the workspace
+// route of the service otherwise. Global codes are served at the root of the service unless the root redirect is off.
+func (h *qrCodeHandler) shortURL(c *gin.Context, workspace, code string) (string, error) {
+	if workspace != "" {
+		domains, err := h.domains.List(c, workspace)
+		if err != nil {
+			return "", err
+		}
+		for _, d := range domains {
+			if d.Verified {
+				return "https://" + d.Host + "/" + code, nil
+			}
+		}
+		return h.baseURL(c) + "/v1/workspaces/" + workspace + "/links/redirect/" + code, nil
+	}
+	if h.rootRedirect {
+		return h.baseURL(c) + "/" + code, nil
+	}
+	return h.baseURL(c) + "/v1/links/redirect/" + code, nil
+}
+
+// baseURL returns the configured public URL, or the scheme and host the request was sent to.
+func (h *qrCodeHandler) baseURL(c *gin.Context) string {
+	if h.publicURL != "" {
+		return h.publicURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// qrCodeETag returns the strong ETag of the QR code of req, which is fully determined by req.
+func qrCodeETag(req model.QRCodeRequest) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00%s", req.Content, req.Format, req.Size, req.ECC)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether the If-None-Match header ifNoneMatch lists etag, weakly compared, or is "*".
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
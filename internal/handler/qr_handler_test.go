@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQRCodeHandler_Render(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	pngRequest := model.QRCodeRequest{Content: "https://sho.rt/abc1234", Format: model.QRFormatPNG, Size: 256, ECC: model.QRECCMedium}
+
+	testCases := []struct {
+		name string
+
+		path        string
+		params      gin.Params
+		ifNoneMatch string
+		publicURL   string
+
+		setupMockSvc     func(t *testing.T, ctx context.Context) *mocks.ShortenUrl
+		setupMockDomains func(t *testing.T, ctx context.Context) *mocks.Domain
+		setupMockQR      func(t *testing.T) *mocks.QRCode
+
+		expectedStatus       int
+		expectedContentType  string
+		expectedCacheControl string
+		expectedBody         string
+	}{
+		{
+			name: "png of a global code with the defaults",
+
+			path:      "/v1/links/abc1234/qr",
+			params:    gin.Params{{Key: "code", Value: "abc1234"}},
+			publicURL: "https://sho.rt/",
+
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				svc := mocks.NewShortenUrl(t)
+				svc.On("GetLink", ctx, "", "abc1234").Return(model.Link{URL: "https://example.com"}, nil).Once()
+				return svc
+			},
+			setupMockQR: func(t *testing.T) *mocks.QRCode {
+				qr := mocks.NewQRCode(t)
+				qr.On("Render", pngRequest).Return([]byte("png"), nil).Once()
+				return qr
+			},
+
+			expectedStatus:       http.StatusOK,
+			expectedContentType:  "image/png",
+			expectedCacheControl: "public, max-age=86400",
+			expectedBody:         "png",
+		},
+		{
+			name: "svg of a workspace code on its verified domain",
+
+			path:   "/v1/workspaces/ws1/links/launch/qr?format=svg&size=512&ecc=H",
+			params: gin.Params{{Key: "workspace", Value: "ws1"}, {Key: "code", Value: "launch"}},
+
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				svc := mocks.NewShortenUrl(t)
+				svc.On("GetLink", ctx, "ws1", "launch").Return(model.Link{URL: "https://example.com"}, nil).Once()
+				return svc
+			},
+			setupMockDomains: func(t *testing.T, ctx context.Context) *mocks.Domain {
+				domains := mocks.NewDomain(t)
+				domains.On("List", ctx, "ws1").Return([]model.Domain{{Host: "pending.team.io"}, {Host: "go.team.io", Verified: true}}, nil).Once()
+				return domains
+			},
+			setupMockQR: func(t *testing.T) *mocks.QRCode {
+				qr := mocks.NewQRCode(t)
+				qr.On("Render", model.QRCodeRequest{Content: "https://go.team.io/launch", Format: model.QRFormatSVG, Size: 512, ECC: model.QRECCHigh}).
+					Return([]byte("<svg/>"), nil).
+					Once()
+				return qr
+			},
+
+			expectedStatus:       http.StatusOK,
+			expectedContentType:  "image/svg+xml",
+			expectedCacheControl: "private, max-age=86400",
+			expectedBody:         "<svg/>",
+		},
+		{
+			name: "current etag -> 304 without rendering",
+
+			path:        "/v1/links/abc1234/qr",
+			params:      gin.Params{{Key: "code", Value: "abc1234"}},
+			ifNoneMatch: `"other", ` + qrCodeETag(pngRequest),
+			publicURL:   "https://sho.rt",
+
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				svc := mocks.NewShortenUrl(t)
+				svc.On("GetLink", ctx, "", "abc1234").Return(model.Link{URL: "https://example.com"}, nil).Once()
+				return svc
+			},
+
+			expectedStatus:       http.StatusNotModified,
+			expectedCacheControl: "public, max-age=86400",
+		},
+		{
+			name: "invalid size -> 400",
+
+			path:   "/v1/links/abc1234/qr?size=10000",
+			params: gin.Params{{Key: "code", Value: "abc1234"}},
+
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name: "code not found -> 404",
+
+			path:   "/v1/links/missing/qr",
+			params: gin.Params{{Key: "code", Value: "missing"}},
+
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				svc := mocks.NewShortenUrl(t)
+				svc.On("GetLink", ctx, "", "missing").Return(model.Link{}, service.ErrCodeNotFound).Once()
+				return svc
+			},
+
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"url not found"}`,
+		},
+		{
+			name: "render error -> 500",
+
+			path:      "/v1/links/abc1234/qr",
+			params:    gin.Params{{Key: "code", Value: "abc1234"}},
+			publicURL: "https://sho.rt",
+
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				svc := mocks.NewShortenUrl(t)
+				svc.On("GetLink", ctx, "", "abc1234").Return(model.Link{URL: "https://example.com"}, nil).Once()
+				return svc
+			},
+			setupMockQR: func(t *testing.T) *mocks.QRCode {
+				qr := mocks.NewQRCode(t)
+				qr.On("Render", pngRequest).Return(nil, errors.New("content too long")).Once()
+				return qr
+			},
+
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodGet, tc.path, nil)
+			gc.Request.Header.Set("If-None-Match", tc.ifNoneMatch)
+			gc.Params = tc.params
+
+			svc := mocks.NewShortenUrl(t)
+			if tc.setupMockSvc != nil {
+				svc = tc.setupMockSvc(t, gc)
+			}
+			domains := mocks.NewDomain(t)
+			if tc.setupMockDomains != nil {
+				domains = tc.setupMockDomains(t, gc)
+			}
+			qr := mocks.NewQRCode(t)
+			if tc.setupMockQR != nil {
+				qr = tc.setupMockQR(t)
+			}
+
+			testHandler := NewQRCodeHandler(svc, domains, qr, tc.publicURL, true)
+			testHandler.Render(gc)
+			gc.Writer.WriteHeaderNow()
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, rec.Header().Get("Content-Type"))
+			}
+			assert.Equal(t, tc.expectedCacheControl, rec.Header().Get("Cache-Control"))
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+type trashedLinkResponse struct {
+	Code      string     `json:"code"`
+	URL       string     `json:"url"`
+	Tags      []string   `json:"tags"`
+	Clicks    int64      `json:"clicks"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	DeletedAt time.Time  `json:"deletedAt"`
+}
+
+type trashListResponse struct {
+	Links []trashedLinkResponse `json:"links"`
+}
+
+type TrashHandler interface {
+	Delete(c *gin.Context)
+	List(c *gin.Context)
+	Restore(c *gin.Context)
+	Purge(c *gin.Context)
+}
+
+type trashHandler struct {
+	trash service.Trash
+}
+
+// NewTrashHandler returns a new instance of the trashHandler, which implements the TrashHandler interface.
+// On the workspace routes its handlers expect middleware.RequireWorkspaceRole in front of them, elsewhere they manage
+// the global links of the authenticated user.
+func NewTrashHandler(trash service.Trash) TrashHandler {
+	return &trashHandler{trash: trash}
+}
+
+// Delete moves a link to the trash
+// @Summary Delete link
+// @Description Moves a link of a workspace, or a global link created by the user outside the workspace routes, to the trash. It stops redirecting and a link.deleted event is published.
+// @Description The code stays taken while the link is in the trash, the link can be restored until it is purged, by default 30 days after its deletion. A link still expires in the trash.
+// @Tags Trash
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Param code path string true "Code of the link"
+// @Success 204
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace or link not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/links/{code} [delete]
+// @Router /v1/workspaces/{workspace}/links/{code} [delete]
+func (h *trashHandler) Delete(c *gin.Context) {
+	workspace, owner, ok := trashScope(c)
+	if !ok {
+		return
+	}
+
+	if err := h.trash.DeleteLink(c, workspace, owner, c.Param("code")); err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// List returns the links in the trash
+// @Summary List trash
+// @Description Lists the links in the trash of a workspace, or the global links of the user in the trash outside the workspace routes, the latest deleted first, up to 100.
+// @Tags Trash
+// @Produce json
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Success 200 {object} trashListResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/trash [get]
+// @Router /v1/workspaces/{workspace}/trash [get]
+func (h *trashHandler) List(c *gin.Context) {
+	workspace, owner, ok := trashScope(c)
+	if !ok {
+		return
+	}
+
+	links, err := h.trash.List(c, workspace, owner)
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	resp := trashListResponse{Links: make([]trashedLinkResponse, 0, len(links))}
+	for _, link := range links {
+		resp.Links = append(resp.Links, newTrashedLinkResponse(link))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Restore moves a link back from the trash
+// @Summary Restore link
+// @Description Restores a link from the trash, it redirects again with its clicks and options. A link that expired in the trash cannot be restored.
+// @Tags Trash
+// @Produce json
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Param code path string true "Code of the link"
+// @Success 200 {object} trashedLinkResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace or trashed link not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/trash/{code}/restore [post]
+// @Router /v1/workspaces/{workspace}/trash/{code}/restore [post]
+func (h *trashHandler) Restore(c *gin.Context) {
+	workspace, owner, ok := trashScope(c)
+	if !ok {
+		return
+	}
+
+	link, err := h.trash.Restore(c, workspace, owner, c.Param("code"))
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newTrashedLinkResponse(link))
+}
+
+// Purge deletes a link from the trash for good
+// @Summary Purge link
+// @Description Deletes a link from the trash for good, its code can be used again.
+// @Tags Trash
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Param code path string true "Code of the link"
+// @Success 204
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace or trashed link not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/trash/{code} [delete]
+// @Router /v1/workspaces/{workspace}/trash/{code} [delete]
+func (h *trashHandler) Purge(c *gin.Context) {
+	workspace, owner, ok := trashScope(c)
+	if !ok {
+		return
+	}
+
+	if err := h.trash.Purge(c, workspace, owner, c.Param("code")); err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// trashScope returns the workspace of the path, or the authenticated user outside the workspace routes.
+// It writes 401 and returns false when there is neither.
+func trashScope(c *gin.Context) (workspace, owner string, ok bool) {
+	workspace, owner = c.Param("workspace"), c.GetString(middleware.UserIDKey)
+	if workspace == "" && owner == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "authentication required"})
+		return "", "", false
+	}
+	return workspace, owner, true
+}
+
+// errorResponse writes the response of err, an error of the trash service.
+func (h *trashHandler) errorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCodeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "url not found"})
+	case errors.Is(err, service.ErrTrashedLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "trashed link not found"})
+	default:
+		log.Ctx(c).Error().Err(err).Msg("Service return error on trash")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+	}
+}
+
+// newTrashedLinkResponse returns the response of link, the times of links deleted before links were indexed are unknown.
+func newTrashedLinkResponse(link model.TrashedLink) trashedLinkResponse {
+	resp := trashedLinkResponse{
+		Code:      link.Code,
+		URL:       link.URL,
+		Tags:      link.Tags,
+		Clicks:    link.Clicks,
+		DeletedAt: link.DeletedAt,
+	}
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+	if !link.CreatedAt.IsZero() {
+		resp.CreatedAt = &link.CreatedAt
+	}
+	if !link.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &link.ExpiresAt
+	}
+	return resp
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTrashHandler_Delete(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		workspace    string
+		userID       string
+		setupMockSvc func(t *testing.T) *mocks.Trash
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "workspace link -> 204",
+
+			workspace: "ws1",
+			userID:    "u1",
+			setupMockSvc: func(t *testing.T) *mocks.Trash {
+				svc := mocks.NewTrash(t)
+				svc.On("DeleteLink", mock.Anything, "ws1", "u1", "launch").Return(nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusNoContent,
+		},
+		{
+			name: "anonymous -> 401",
+
+			setupMockSvc: func(t *testing.T) *mocks.Trash {
+				return mocks.NewTrash(t)
+			},
+
+			expectedResponseCode: http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"authentication required"}`,
+		},
+		{
+			name: "not found -> 404",
+
+			userID: "u1",
+			setupMockSvc: func(t *testing.T) *mocks.Trash {
+				svc := mocks.NewTrash(t)
+				svc.On("DeleteLink", mock.Anything, "", "u1", "launch").Return(service.ErrCodeNotFound).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponseBody: `{"message":"url not found"}`,
+		},
+		{
+			name: "service error -> 500",
+
+			userID: "u1",
+			setupMockSvc: func(t *testing.T) *mocks.Trash {
+				svc := mocks.NewTrash(t)
+				svc.On("DeleteLink", mock.Anything, "", "u1", "launch").Return(errors.New("boom")).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodDelete, "/v1/links/launch", nil)
+			gc.Params = gin.Params{{Key: "code", Value: "launch"}}
+			if tc.workspace != "" {
+				gc.Params = append(gc.Params, gin.Param{Key: "workspace", Value: tc.workspace})
+			}
+			if tc.userID != "" {
+				gc.Set(middleware.UserIDKey, tc.userID)
+			}
+
+			testHandler := NewTrashHandler(tc.setupMockSvc(t))
+			testHandler.Delete(gc)
+			gc.Writer.WriteHeaderNow()
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
+
+func TestTrashHandler_Restore(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+
+		setupMockSvc func(t *testing.T) *mocks.Trash
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "success -> 200",
+
+			setupMockSvc: func(t *testing.T) *mocks.Trash {
+				svc := mocks.NewTrash(t)
+				svc.On("Restore", mock.Anything, "", "u1", "launch").Return(model.TrashedLink{
+					LinkSummary: model.LinkSummary{Code: "launch", URL: "https://example.com", Clicks: 3, CreatedAt: createdAt, ExpiresAt: createdAt.Add(time.Hour)},
+					Owner:       "u1",
+					DeletedAt:   createdAt.Add(time.Minute),
+				}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"code":"launch","url":"https://example.com","tags":[],"clicks":3,` +
+				`"createdAt":"2025-01-02T03:04:05Z","expiresAt":"2025-01-02T04:04:05Z","deletedAt":"2025-01-02T03:05:05Z"}`,
+		},
+		{
+			name: "link deleted before links were indexed -> 200 without times",
+
+			setupMockSvc: func(t *testing.T) *mocks.Trash {
+				svc := mocks.NewTrash(t)
+				svc.On("Restore", mock.Anything, "", "u1", "launch").Return(model.TrashedLink{
+					LinkSummary: model.LinkSummary{Code: "launch", URL: "https://example.com", Tags: []string{"promo"}},
+					Owner:       "u1",
+					DeletedAt:   createdAt,
+				}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"code":"launch","url":"https://example.com","tags":["promo"],"clicks":0,"deletedAt":"2025-01-02T03:04:05Z"}`,
+		},
+		{
+			name: "not in the trash -> 404",
+
+			setupMockSvc: func(t *testing.T) *mocks.Trash {
+				svc := mocks.NewTrash(t)
+				svc.On("Restore", mock.Anything, "", "u1", "launch").Return(model.TrashedLink{}, service.ErrTrashedLinkNotFound).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponseBody: `{"message":"trashed link not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodPost, "/v1/trash/launch/restore", nil)
+			gc.Params = gin.Params{{Key: "code", Value: "launch"}}
+			gc.Set(middleware.UserIDKey, "u1")
+
+			testHandler := NewTrashHandler(tc.setupMockSvc(t))
+			testHandler.Restore(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
@@ -1,16 +1,37 @@
 package handler
 
 import (
+	"bytes"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lhducc/bookmark-management/internal/metrics"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
 	"github.com/lhducc/bookmark-management/internal/service"
 	"github.com/rs/zerolog/log"
 	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const (
+	// visitorCookie identifies a visitor across redirects, so they keep the variant of a link first assigned to them.
+	visitorCookie       = "bm_visitor"
+	visitorCookieMaxAge = 365 * 24 * 60 * 60
 )
 
 type urlShortenRequest struct {
-	Url string `json:"url" binding:"required,url"`
-	Exp int    `json:"exp" binding:"required,gte=604800"`
+	Url            string            `json:"url" binding:"required,url"`
+	Exp            int               `json:"exp" binding:"required,gte=604800"`
+	Alias          string            `json:"alias"`
+	RedirectStatus int               `json:"redirectStatus" enums:"301,302,307,308"`
+	Title          string            `json:"title" binding:"max=200"`
+	Params         map[string]string `json:"params"`
+	PassQuery      bool              `json:"passQuery"`
+	Rules          []model.Rule      `json:"rules"`
+	Variants       []model.Variant   `json:"variants"`
+	Tags           []string          `json:"tags"`
 }
 
 type urlShortenResponse struct {
@@ -18,79 +39,288 @@ type urlShortenResponse struct {
 	Code    string `json:"code"`
 }
 
+type linkClicksResponse struct {
+	Clicks   int64            `json:"clicks"`
+	Variants map[string]int64 `json:"variants"`
+}
+
 type UrlShortenHandler interface {
 	ShortenUrl(c *gin.Context)
 	GetUrl(c *gin.Context)
+	Clicks(c *gin.Context)
 }
 
 type urlShortenHandler struct {
 	urlService service.ShortenUrl
+	router     service.LinkRouter
+	clicks     service.ClickRecorder
+	webhooks   service.Webhook
+	metrics    *metrics.Metrics
 }
 
-func NewUrlShortenHandler(svc service.ShortenUrl) UrlShortenHandler {
-	return &urlShortenHandler{urlService: svc}
+// NewUrlShortenHandler returns a new instance of the urlShortenHandler, which implements the UrlShortenHandler interface.
+// The router is optional, when it is nil the rules of the links are ignored and every visitor goes to their URL.
+// The clicks are optional, when it is nil redirects are neither counted in the click analytics nor published as
+// link.clicked events. The webhooks are optional, when it is nil no link.created events are published.
+// The metrics are optional, redirects and shorten failures are not counted when it is nil.
+func NewUrlShortenHandler(svc service.ShortenUrl, router service.LinkRouter, clicks service.ClickRecorder, webhooks service.Webhook, m *metrics.Metrics) UrlShortenHandler {
+	return &urlShortenHandler{urlService: svc, router: router, clicks: clicks, webhooks: webhooks, metrics: m}
 }
 
 // ShortenUrl shortens a given URL and returns a shortened URL code.
+// On the workspace routes the code is created in the workspace of the path, which the caller must be a member of.
 // @Summary Shorten URL
-// @Description Shortens a given URL and returns a shortened URL code.
+// @Description Shortens a given URL and returns a shortened URL code. An alias of 3 to 64 letters, digits, '-' or '_' is used as the code instead of a generated one, it only has to be unique within its workspace.
+// @Description The redirect status is 302 unless redirectStatus is 301, 307 or 308, and the title is shown on the preview page of the link.
+// @Description Up to 20 params are merged into the query string of the URL at redirect time, replacing the parameters of the same name. With passQuery the query string of the request to the code is merged in last.
+// @Description Up to 20 rules are evaluated in order at redirect time, the first one whose platforms, languages, countries and time window all match the visitor replaces the URL, which is the fallback. Every rule needs at least one condition.
+// @Description 2 to 10 named variants with weights split the visitors no rule matched across their URLs, each visitor keeps the variant first assigned to them.
+// @Description Up to 10 distinct tags of 1 to 32 lowercase letters, digits, '-' or '_' label the link in the link listing.
 // @Tags URL Shortener
 // @Accept json
 // @Produce json
+// @Param workspace path string false "Workspace ID, on the workspace route only"
 // @Param urlShortenRequest body urlShortenRequest true "URL to shorten"
 // @Success 200 {object} urlShortenResponse
-// @Failure 400 {object} map[string]string "Bad Request - invalid URL or validation error"
+// @Failure 400 {object} map[string]string "Bad Request - invalid URL, alias, redirect status, params, rules, variants, tags or validation error"
+// @Failure 401 {object} map[string]string "Unauthorized - the workspace route requires an authenticated user"
+// @Failure 403 {object} map[string]string "Forbidden - the role of the user in the workspace is below member"
+// @Failure 404 {object} map[string]string "Workspace not found"
+// @Failure 409 {object} map[string]string "Conflict - alias already taken in the workspace, or reserved for a route of the service"
+// @Failure 429 {object} map[string]string "Too Many Requests - retry after the Retry-After header"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /v1/links/shorten [post]
+// @Router /v1/workspaces/{workspace}/links/shorten [post]
 func (h *urlShortenHandler) ShortenUrl(c *gin.Context) {
 	var req urlShortenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
+		h.metrics.ObserveShortenFailure(metrics.ShortenFailureInvalidRequest)
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request"})
 		return
 	}
 
-	code, err := h.urlService.ShortenUrl(c, req.Url, req.Exp)
+	code, err := h.urlService.ShortenUrl(c, model.ShortenRequest{
+		Workspace: c.Param("workspace"),
+		Alias:     req.Alias,
+		Exp:       req.Exp,
+		Link: model.Link{
+			Owner:          c.GetString(middleware.UserIDKey),
+			URL:            req.Url,
+			RedirectStatus: req.RedirectStatus,
+			Title:          req.Title,
+			Params:         req.Params,
+			PassQuery:      req.PassQuery,
+			Rules:          req.Rules,
+			Variants:       req.Variants,
+			Tags:           req.Tags,
+		},
+	})
 	if err != nil {
-		log.Error().Str("url", req.Url).Err(err).Msg("Service return error on ShortenUrl")
+		if errors.Is(err, service.ErrInvalidAlias) {
+			h.metrics.ObserveShortenFailure(metrics.ShortenFailureInvalidRequest)
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid alias"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidRedirectStatus) {
+			h.metrics.ObserveShortenFailure(metrics.ShortenFailureInvalidRequest)
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid redirect status"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidLinkParams) {
+			h.metrics.ObserveShortenFailure(metrics.ShortenFailureInvalidRequest)
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid params"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidLinkRules) {
+			h.metrics.ObserveShortenFailure(metrics.ShortenFailureInvalidRequest)
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid rules"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidLinkVariants) {
+			h.metrics.ObserveShortenFailure(metrics.ShortenFailureInvalidRequest)
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid variants"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidLinkTags) {
+			h.metrics.ObserveShortenFailure(metrics.ShortenFailureInvalidRequest)
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid tags"})
+			return
+		}
+		if errors.Is(err, service.ErrAliasTaken) {
+			h.metrics.ObserveShortenFailure(metrics.ShortenFailureAliasTaken)
+			c.JSON(http.StatusConflict, gin.H{"message": "alias already taken"})
+			return
+		}
+		if errors.Is(err, service.ErrAliasReserved) {
+			h.metrics.ObserveShortenFailure(metrics.ShortenFailureAliasTaken)
+			c.JSON(http.StatusConflict, gin.H{"message": "alias is reserved"})
+			return
+		}
+		if errors.Is(err, service.ErrShortenURLFailed) {
+			h.metrics.ObserveShortenFailure(metrics.ShortenFailureCollisions)
+		} else {
+			h.metrics.ObserveShortenFailure(metrics.ShortenFailureInternal)
+		}
+		log.Ctx(c).Error().Str("url", req.Url).Err(err).Msg("Service return error on ShortenUrl")
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
 		return
 	}
+	h.publish(c, model.Event{
+		Type:      model.EventLinkCreated,
+		Workspace: c.Param("workspace"),
+		Owner:     c.GetString(middleware.UserIDKey),
+		Code:      code,
+		URL:       req.Url,
+	})
 	c.JSON(http.StatusOK, urlShortenResponse{
 		Message: "Shorten URL generated successfully!",
 		Code:    code,
 	})
 }
 
-// GetUrl shortens a given URL and returns a shortened URL code.
+// GetUrl redirects to the URL of a code, or renders its preview page.
 // @Summary Get URL
-// @Description Get URL by code
+// @Description Get URL by code. GET /{code} is the short form, on the verified custom domains of the workspaces it resolves within the workspace of the domain.
+// @Description The destination is the URL of the first rule of the link matching the user agent, the preferred language, the country and the time of the request, or the variant assigned to the visitor when the link splits its traffic, or the URL of the link. The variant is kept in the visitor cookie and counted in the clicks of the link. The redirect status is the one chosen for the link, 302 by default. The params of the link, and the query string of the request when the link passes it through, are merged into the destination. A code ending with '+' or the preview query parameter renders an HTML page showing the destination, the title and the safety checks of the link instead.
 // @Tags URL Shortener
 // @Accept json
-// @Produce json
-// @Param code path string true "Url code" Format(string)
+// @Produce json,html
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Param code path string true "Url code, with a trailing '+' for the preview page" Format(string)
+// @Param preview query bool false "Render the preview page instead of redirecting"
+// @Success 200 {string} string "Preview page"
+// @Success 301
 // @Success 302
+// @Success 307
+// @Success 308
 // @Failure 400  "Bad Request - invalid URL or validation error"
 // @Failure 404  "URL not found"
+// @Failure 429  "Too Many Requests - retry after the Retry-After header"
 // @Failure 500  "Internal Server Error"
 // @Router /v1/links/redirect/{code} [get]
+// @Router /v1/workspaces/{workspace}/links/redirect/{code} [get]
+// @Router /{code} [get]
 func (h *urlShortenHandler) GetUrl(c *gin.Context) {
-	code := c.Param("code")
+	code, preview := strings.CutSuffix(c.Param("code"), previewSuffix)
+	preview = preview || c.Query(service.PreviewQueryParam) == "1" || c.Query(service.PreviewQueryParam) == "true"
 
 	if code == "" {
+		h.metrics.ObserveRedirect(metrics.RedirectInvalid)
 		c.JSON(http.StatusBadRequest, gin.H{"message": "wrong format"})
 		return
 	}
 
-	url, err := h.urlService.GetUrl(c, code)
+	link, err := h.urlService.GetLink(c, c.Param("workspace"), code)
 	if err != nil {
 		if errors.Is(err, service.ErrCodeNotFound) {
+			h.metrics.ObserveRedirect(metrics.RedirectNotFound)
 			c.JSON(http.StatusNotFound, gin.H{"message": "url not found"})
 			return
 		}
 
-		log.Error().Err(err).Msg("Service return error on GetUrl")
+		h.metrics.ObserveRedirect(metrics.RedirectError)
+		log.Ctx(c).Error().Err(err).Msg("Service return error on GetUrl")
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+
+	variant := ""
+	if h.router != nil {
+		v, known := visitor(c)
+		link.URL, variant = h.router.Route(c, code, link, v)
+		if variant != "" && !known {
+			c.SetSameSite(http.SameSiteLaxMode)
+			c.SetCookie(visitorCookie, v.ID, visitorCookieMaxAge, "/", "", c.Request.TLS != nil, true)
+		}
+	}
+	destination := service.Destination(link, c.Request.URL.RawQuery)
+	if preview {
+		h.metrics.ObserveRedirect(metrics.RedirectPreview)
+		h.renderPreview(c, code, link.Title, destination)
+		return
+	}
+
+	// The click is recorded in the background, the redirect does not wait for Redis.
+	if h.clicks != nil {
+		h.clicks.Record(c.Request.Context(), model.Event{
+			Type:      model.EventLinkClicked,
+			Workspace: c.Param("workspace"),
+			Owner:     link.Owner,
+			Code:      code,
+			URL:       destination,
+			Variant:   variant,
+		})
+	}
+	h.metrics.ObserveRedirect(metrics.RedirectFound)
+	c.Redirect(link.StatusCode(), destination)
+}
+
+// Clicks returns the click analytics of a code.
+// @Summary Link clicks
+// @Description Counts the redirects of a code, in total and per variant served. Previews are not counted, and the counts expire with the link. Outside the workspace routes, the clicks of a link created by a user are only shown to that user.
+// @Tags URL Shortener
+// @Produce json
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Param code path string true "Url code"
+// @Success 200 {object} linkClicksResponse
+// @Failure 401 {object} map[string]string "Unauthorized - the workspace route requires an authenticated user"
+// @Failure 403 {object} map[string]string "Forbidden - the user is not a member of the workspace"
+// @Failure 404 {object} map[string]string "URL not found, or created by another user"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/links/clicks/{code} [get]
+// @Router /v1/workspaces/{workspace}/links/clicks/{code} [get]
+func (h *urlShortenHandler) Clicks(c *gin.Context) {
+	stats, err := h.urlService.GetClicks(c, c.Param("workspace"), c.GetString(middleware.UserIDKey), c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrCodeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "url not found"})
+			return
+		}
+		log.Ctx(c).Error().Err(err).Msg("Service return error on GetClicks")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, linkClicksResponse{Clicks: stats.Total, Variants: stats.Variants})
+}
+
+// visitor returns the client of the request as seen by the rules and the variants of a link.
+// The ID comes from the visitor cookie, a new one is generated when the request has none and known is false.
+func visitor(c *gin.Context) (_ model.Visitor, known bool) {
+	id, err := c.Cookie(visitorCookie)
+	known = err == nil && id != ""
+	if !known {
+		id = uuid.NewString()
+	}
+	ip, _ := netip.ParseAddr(c.ClientIP())
+	return model.Visitor{
+		ID:             id,
+		UserAgent:      c.Request.UserAgent(),
+		AcceptLanguage: c.GetHeader("Accept-Language"),
+		IP:             ip,
+	}, known
+}
+
+// publish queues event for the webhooks, a failure is logged and does not fail the request.
+func (h *urlShortenHandler) publish(c *gin.Context, event model.Event) {
+	if h.webhooks == nil {
+		return
+	}
+	if err := h.webhooks.Publish(c, event); err != nil {
+		log.Ctx(c).Warn().Err(err).Str("code", event.Code).Str("event", event.Type).Msg("Cannot publish the webhook event")
+	}
+}
+
+// renderPreview writes the preview page of code, a link titled title redirecting to destination.
+func (h *urlShortenHandler) renderPreview(c *gin.Context, code, title, destination string) {
+	var page bytes.Buffer
+	err := previewTemplate.Execute(&page, previewPage{Code: code, URL: destination, Title: title, Safety: service.CheckLinkSafety(destination)})
+	if err != nil {
+		log.Ctx(c).Error().Err(err).Msg("Cannot render the preview page")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
 	}
 
-	c.Redirect(http.StatusFound, url)
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
 }
@@ -6,11 +6,15 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
 	"github.com/lhducc/bookmark-management/internal/service"
 	"github.com/lhducc/bookmark-management/internal/service/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"testing"
 )
 
@@ -43,8 +47,7 @@ func TestUrlStorageHandler_ShortenUrl(t *testing.T) {
 				svcMock := mocks.NewShortenUrl(t)
 				svcMock.On("ShortenUrl",
 					ctx,
-					"https://example.com",
-					604800).Return("123", nil)
+					model.ShortenRequest{Exp: 604800, Link: model.Link{URL: "https://example.com"}}).Return("123", nil)
 				return svcMock
 			},
 
@@ -69,8 +72,7 @@ func TestUrlStorageHandler_ShortenUrl(t *testing.T) {
 				svcMock := mocks.NewShortenUrl(t)
 				svcMock.On("ShortenUrl",
 					ctx,
-					"https://example.com",
-					604800).Return("", assert.AnError)
+					model.ShortenRequest{Exp: 604800, Link: model.Link{URL: "https://example.com"}}).Return("", assert.AnError)
 				return svcMock
 			},
 
@@ -79,6 +81,164 @@ func TestUrlStorageHandler_ShortenUrl(t *testing.T) {
 				"message": "internal server error",
 			},
 		},
+		{
+			name: "alias in workspace",
+
+			setupRequest: func(ctx *gin.Context) {
+				body := map[string]any{
+					"url":   "https://example.com",
+					"exp":   604800,
+					"alias": "launch",
+				}
+				jsonBody, _ := json.Marshal(body)
+				ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/workspaces/ws1/links/shorten", bytes.NewReader(jsonBody))
+				ctx.Params = gin.Params{{Key: "workspace", Value: "ws1"}}
+			},
+			setupMockSvc: func(ctx context.Context) *mocks.ShortenUrl {
+				svcMock := mocks.NewShortenUrl(t)
+				svcMock.On("ShortenUrl",
+					ctx,
+					model.ShortenRequest{Workspace: "ws1", Alias: "launch", Exp: 604800, Link: model.Link{URL: "https://example.com"}}).Return("launch", nil)
+				return svcMock
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]any{
+				"message": "Shorten URL generated successfully!",
+				"code":    "launch",
+			},
+		},
+		{
+			name: "alias taken -> 409",
+
+			setupRequest: func(ctx *gin.Context) {
+				body := map[string]any{
+					"url":   "https://example.com",
+					"exp":   604800,
+					"alias": "launch",
+				}
+				jsonBody, _ := json.Marshal(body)
+				ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/links/shorten", bytes.NewReader(jsonBody))
+			},
+			setupMockSvc: func(ctx context.Context) *mocks.ShortenUrl {
+				svcMock := mocks.NewShortenUrl(t)
+				svcMock.On("ShortenUrl",
+					ctx,
+					model.ShortenRequest{Alias: "launch", Exp: 604800, Link: model.Link{URL: "https://example.com"}}).Return("", service.ErrAliasTaken)
+				return svcMock
+			},
+
+			expectedStatus: http.StatusConflict,
+			expectedBody: map[string]any{
+				"message": "alias already taken",
+			},
+		},
+		{
+			name: "reserved alias -> 409",
+
+			setupRequest: func(ctx *gin.Context) {
+				body := map[string]any{
+					"url":   "https://example.com",
+					"exp":   604800,
+					"alias": "swagger",
+				}
+				jsonBody, _ := json.Marshal(body)
+				ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/links/shorten", bytes.NewReader(jsonBody))
+			},
+			setupMockSvc: func(ctx context.Context) *mocks.ShortenUrl {
+				svcMock := mocks.NewShortenUrl(t)
+				svcMock.On("ShortenUrl",
+					ctx,
+					model.ShortenRequest{Alias: "swagger", Exp: 604800, Link: model.Link{URL: "https://example.com"}}).Return("", service.ErrAliasReserved)
+				return svcMock
+			},
+
+			expectedStatus: http.StatusConflict,
+			expectedBody: map[string]any{
+				"message": "alias is reserved",
+			},
+		},
+		{
+			name: "invalid alias -> 400",
+
+			setupRequest: func(ctx *gin.Context) {
+				body := map[string]any{
+					"url":   "https://example.com",
+					"exp":   604800,
+					"alias": "a",
+				}
+				jsonBody, _ := json.Marshal(body)
+				ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/links/shorten", bytes.NewReader(jsonBody))
+			},
+			setupMockSvc: func(ctx context.Context) *mocks.ShortenUrl {
+				svcMock := mocks.NewShortenUrl(t)
+				svcMock.On("ShortenUrl",
+					ctx,
+					model.ShortenRequest{Alias: "a", Exp: 604800, Link: model.Link{URL: "https://example.com"}}).Return("", service.ErrInvalidAlias)
+				return svcMock
+			},
+
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]any{
+				"message": "Invalid alias",
+			},
+		},
+		{
+			name: "invalid redirect status -> 400",
+
+			setupRequest: func(ctx *gin.Context) {
+				body := map[string]any{
+					"url":            "https://example.com",
+					"exp":            604800,
+					"redirectStatus": 303,
+					"title":          "Launch",
+				}
+				jsonBody, _ := json.Marshal(body)
+				ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/links/shorten", bytes.NewReader(jsonBody))
+			},
+			setupMockSvc: func(ctx context.Context) *mocks.ShortenUrl {
+				svcMock := mocks.NewShortenUrl(t)
+				svcMock.On("ShortenUrl",
+					ctx,
+					model.ShortenRequest{Exp: 604800, Link: model.Link{URL: "https://example.com", RedirectStatus: 303, Title: "Launch"}}).
+					Return("", service.ErrInvalidRedirectStatus)
+				return svcMock
+			},
+
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]any{
+				"message": "Invalid redirect status",
+			},
+		},
+		{
+			name: "invalid rules -> 400",
+
+			setupRequest: func(ctx *gin.Context) {
+				body := map[string]any{
+					"url":   "https://example.com",
+					"exp":   604800,
+					"rules": []map[string]any{{"url": "https://example.com/ios", "platforms": []string{"symbian"}}},
+				}
+				jsonBody, _ := json.Marshal(body)
+				ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/links/shorten", bytes.NewReader(jsonBody))
+			},
+			setupMockSvc: func(ctx context.Context) *mocks.ShortenUrl {
+				svcMock := mocks.NewShortenUrl(t)
+				svcMock.On("ShortenUrl",
+					ctx,
+					model.ShortenRequest{Exp: 604800, Link: model.Link{
+						URL:   "https://example.com",
+						Rules: []model.Rule{{URL: "https://example.com/ios", Platforms: []string{"symbian"}}},
+					}}).
+					Return("", service.ErrInvalidLinkRules)
+				return svcMock
+			},
+
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]any{
+				"message": "Invalid rules",
+			},
+		},
 		{
 			name: "wrong input",
 
@@ -109,7 +269,7 @@ func TestUrlStorageHandler_ShortenUrl(t *testing.T) {
 			gc, _ := gin.CreateTestContext(rec)
 			tc.setupRequest(gc)
 			mockSvc := tc.setupMockSvc(gc)
-			testHandler := NewUrlShortenHandler(mockSvc)
+			testHandler := NewUrlShortenHandler(mockSvc, nil, nil, nil, nil)
 
 			testHandler.ShortenUrl(gc)
 
@@ -131,12 +291,16 @@ func TestUrlShortenHandler_GetUrl(t *testing.T) {
 	testCases := []struct {
 		name string
 
-		setupRequest func(ctx *gin.Context)
-		setupMockSvc func(t *testing.T, ctx context.Context) *mocks.ShortenUrl
+		setupRequest    func(ctx *gin.Context)
+		setupMockSvc    func(t *testing.T, ctx context.Context) *mocks.ShortenUrl
+		setupMockRouter func(t *testing.T, ctx context.Context) *mocks.LinkRouter
 
 		expectedResponseCode int
 		expectedResponseBody string
 		expectedLocation     string
+		expectedCookie       string
+		expectedClick        model.Event
+		expectedBodyContains []string
 	}{
 		{
 			name: "empty code -> 400",
@@ -153,7 +317,7 @@ func TestUrlShortenHandler_GetUrl(t *testing.T) {
 			expectedResponseBody: `{"message":"wrong format"}`,
 		},
 		{
-			name: "code not found -> 400",
+			name: "code not found -> 404",
 
 			setupRequest: func(ctx *gin.Context) {
 				ctx.Request = httptest.NewRequest(http.MethodGet, "/v1/links/redirect/notfound", nil)
@@ -161,13 +325,13 @@ func TestUrlShortenHandler_GetUrl(t *testing.T) {
 			},
 			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
 				mockSvc := mocks.NewShortenUrl(t)
-				mockSvc.On("GetUrl", ctx, "notfound").
-					Return("", service.ErrCodeNotFound).
+				mockSvc.On("GetLink", ctx, "", "notfound").
+					Return(model.Link{}, service.ErrCodeNotFound).
 					Once()
 				return mockSvc
 			},
 
-			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseCode: http.StatusNotFound,
 			expectedResponseBody: `{"message":"url not found"}`,
 		},
 		{
@@ -179,8 +343,8 @@ func TestUrlShortenHandler_GetUrl(t *testing.T) {
 			},
 			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
 				mockSvc := mocks.NewShortenUrl(t)
-				mockSvc.On("GetUrl", ctx, "boom").
-					Return("", errors.New("some error")).
+				mockSvc.On("GetLink", ctx, "", "boom").
+					Return(model.Link{}, errors.New("some error")).
 					Once()
 				return mockSvc
 			},
@@ -197,14 +361,188 @@ func TestUrlShortenHandler_GetUrl(t *testing.T) {
 			},
 			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
 				mockSvc := mocks.NewShortenUrl(t)
-				mockSvc.On("GetUrl", ctx, "abc1234").
-					Return("https://google.com", nil).
+				mockSvc.On("GetLink", ctx, "", "abc1234").
+					Return(model.Link{URL: "https://google.com"}, nil).
 					Once()
 				return mockSvc
 			},
 
 			expectedResponseCode: http.StatusFound,
 			expectedLocation:     "https://google.com",
+			expectedClick:        model.Event{Type: model.EventLinkClicked, Code: "abc1234", URL: "https://google.com"},
+		},
+		{
+			name: "workspace code -> 302 redirect",
+
+			setupRequest: func(ctx *gin.Context) {
+				ctx.Request = httptest.NewRequest(http.MethodGet, "/v1/workspaces/ws1/links/redirect/launch", nil)
+				ctx.Params = gin.Params{{Key: "workspace", Value: "ws1"}, {Key: "code", Value: "launch"}}
+			},
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				mockSvc := mocks.NewShortenUrl(t)
+				mockSvc.On("GetLink", ctx, "ws1", "launch").
+					Return(model.Link{URL: "https://example.com", Owner: "u1"}, nil).
+					Once()
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusFound,
+			expectedLocation:     "https://example.com",
+			expectedClick:        model.Event{Type: model.EventLinkClicked, Workspace: "ws1", Owner: "u1", Code: "launch", URL: "https://example.com"},
+		},
+		{
+			name: "per-link status -> 308 redirect",
+
+			setupRequest: func(ctx *gin.Context) {
+				ctx.Request = httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+				ctx.Params = gin.Params{{Key: "code", Value: "abc1234"}}
+			},
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				mockSvc := mocks.NewShortenUrl(t)
+				mockSvc.On("GetLink", ctx, "", "abc1234").
+					Return(model.Link{URL: "https://google.com", RedirectStatus: http.StatusPermanentRedirect}, nil).
+					Once()
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusPermanentRedirect,
+			expectedLocation:     "https://google.com",
+			expectedClick:        model.Event{Type: model.EventLinkClicked, Code: "abc1234", URL: "https://google.com"},
+		},
+		{
+			name: "params and passed through query -> merged destination",
+
+			setupRequest: func(ctx *gin.Context) {
+				ctx.Request = httptest.NewRequest(http.MethodGet, "/abc1234?utm_source=ads", nil)
+				ctx.Params = gin.Params{{Key: "code", Value: "abc1234"}}
+			},
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				mockSvc := mocks.NewShortenUrl(t)
+				mockSvc.On("GetLink", ctx, "", "abc1234").
+					Return(model.Link{
+						URL:       "https://google.com/?id=7",
+						Params:    map[string]string{"utm_source": "news", "utm_medium": "email"},
+						PassQuery: true,
+					}, nil).
+					Once()
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusFound,
+			expectedLocation:     "https://google.com/?id=7&utm_medium=email&utm_source=ads",
+			expectedClick:        model.Event{Type: model.EventLinkClicked, Code: "abc1234", URL: "https://google.com/?id=7&utm_medium=email&utm_source=ads"},
+		},
+		{
+			name: "rules -> routed destination with params",
+
+			setupRequest: func(ctx *gin.Context) {
+				ctx.Request = httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+				ctx.Request.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)")
+				ctx.Request.Header.Set("Accept-Language", "fr-FR,fr;q=0.9")
+				ctx.Request.RemoteAddr = "203.0.113.7:1234"
+				ctx.Request.AddCookie(&http.Cookie{Name: visitorCookie, Value: "visitor-1"})
+				ctx.Params = gin.Params{{Key: "code", Value: "abc1234"}}
+			},
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				mockSvc := mocks.NewShortenUrl(t)
+				mockSvc.On("GetLink", ctx, "", "abc1234").
+					Return(model.Link{
+						URL:    "https://example.com",
+						Params: map[string]string{"utm_source": "news"},
+						Rules:  []model.Rule{{URL: "https://apps.apple.com/app/id1", Platforms: []string{model.PlatformIOS}}},
+					}, nil).
+					Once()
+				return mockSvc
+			},
+			setupMockRouter: func(t *testing.T, ctx context.Context) *mocks.LinkRouter {
+				mockRouter := mocks.NewLinkRouter(t)
+				mockRouter.On("Route", ctx, "abc1234", mock.Anything, model.Visitor{
+					ID:             "visitor-1",
+					UserAgent:      "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)",
+					AcceptLanguage: "fr-FR,fr;q=0.9",
+					IP:             netip.MustParseAddr("203.0.113.7"),
+				}).
+					Return("https://apps.apple.com/app/id1", "").
+					Once()
+				return mockRouter
+			},
+
+			expectedResponseCode: http.StatusFound,
+			expectedLocation:     "https://apps.apple.com/app/id1?utm_source=news",
+			expectedClick:        model.Event{Type: model.EventLinkClicked, Code: "abc1234", URL: "https://apps.apple.com/app/id1?utm_source=news"},
+		},
+		{
+			name: "variant -> visitor cookie set and variant recorded",
+
+			setupRequest: func(ctx *gin.Context) {
+				ctx.Request = httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+				ctx.Params = gin.Params{{Key: "code", Value: "abc1234"}}
+			},
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				mockSvc := mocks.NewShortenUrl(t)
+				mockSvc.On("GetLink", ctx, "", "abc1234").
+					Return(model.Link{
+						URL:      "https://example.com",
+						Variants: []model.Variant{{Name: "a", URL: "https://example.com/a", Weight: 70}, {Name: "b", URL: "https://example.com/b", Weight: 30}},
+					}, nil).
+					Once()
+				return mockSvc
+			},
+			setupMockRouter: func(t *testing.T, ctx context.Context) *mocks.LinkRouter {
+				mockRouter := mocks.NewLinkRouter(t)
+				mockRouter.On("Route", ctx, "abc1234", mock.Anything, mock.AnythingOfType("model.Visitor")).
+					Return("https://example.com/b", "b").
+					Once()
+				return mockRouter
+			},
+
+			expectedResponseCode: http.StatusFound,
+			expectedLocation:     "https://example.com/b",
+			expectedClick:        model.Event{Type: model.EventLinkClicked, Code: "abc1234", URL: "https://example.com/b", Variant: "b"},
+			expectedCookie:       visitorCookie + "=",
+		},
+		{
+			name: "plus suffix -> preview page",
+
+			setupRequest: func(ctx *gin.Context) {
+				ctx.Request = httptest.NewRequest(http.MethodGet, "/abc1234+", nil)
+				ctx.Params = gin.Params{{Key: "code", Value: "abc1234+"}}
+			},
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				mockSvc := mocks.NewShortenUrl(t)
+				mockSvc.On("GetLink", ctx, "", "abc1234").
+					Return(model.Link{URL: "http://example.com/a?b=1&c=2", Title: "Spring launch"}, nil).
+					Once()
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedBodyContains: []string{
+				"<h1>Spring launch</h1>",
+				`<p class="destination">http://example.com/a?b=1&amp;c=2</p>`,
+				service.SafetyWarningNotHTTPS,
+			},
+		},
+		{
+			name: "preview query -> escaped preview page",
+
+			setupRequest: func(ctx *gin.Context) {
+				ctx.Request = httptest.NewRequest(http.MethodGet, "/v1/links/redirect/abc1234?preview=1", nil)
+				ctx.Params = gin.Params{{Key: "code", Value: "abc1234"}}
+			},
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				mockSvc := mocks.NewShortenUrl(t)
+				mockSvc.On("GetLink", ctx, "", "abc1234").
+					Return(model.Link{URL: "https://example.com", Title: "<script>alert(1)</script>"}, nil).
+					Once()
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedBodyContains: []string{
+				"<h1>&lt;script&gt;alert(1)&lt;/script&gt;</h1>",
+				"No issue found",
+			},
 		},
 	}
 
@@ -217,17 +555,109 @@ func TestUrlShortenHandler_GetUrl(t *testing.T) {
 
 			tc.setupRequest(gc)
 			mockSvc := tc.setupMockSvc(t, gc)
+			var router service.LinkRouter
+			if tc.setupMockRouter != nil {
+				router = tc.setupMockRouter(t, gc)
+			}
 
-			testHandler := NewUrlShortenHandler(mockSvc)
+			clicks := mocks.NewClickRecorder(t)
+			if tc.expectedClick.Code != "" {
+				clicks.On("Record", gc.Request.Context(), tc.expectedClick).Once()
+			}
+
+			testHandler := NewUrlShortenHandler(mockSvc, router, clicks, nil, nil)
 			testHandler.GetUrl(gc)
 
 			assert.Equal(t, tc.expectedResponseCode, rec.Code)
 
-			if tc.expectedResponseCode == http.StatusFound {
+			if tc.expectedCookie != "" {
+				assert.Contains(t, rec.Header().Get("Set-Cookie"), tc.expectedCookie)
+			} else {
+				assert.Empty(t, rec.Header().Get("Set-Cookie"))
+			}
+			if tc.expectedLocation != "" {
 				assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
 				return
 			}
+			if tc.expectedBodyContains != nil {
+				assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+				for _, fragment := range tc.expectedBodyContains {
+					assert.Contains(t, rec.Body.String(), fragment)
+				}
+				return
+			}
+
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
+
+func TestUrlShortenHandler_Clicks(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
 
+		setupMockSvc func(t *testing.T, ctx context.Context) *mocks.ShortenUrl
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "clicks per variant -> 200",
+
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				mockSvc := mocks.NewShortenUrl(t)
+				mockSvc.On("GetClicks", ctx, "", "alice", "abc1234").
+					Return(model.ClickStats{Total: 10, Variants: map[string]int64{"a": 7, "b": 3}}, nil).
+					Once()
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"clicks":10,"variants":{"a":7,"b":3}}`,
+		},
+		{
+			name: "code not found -> 404",
+
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				mockSvc := mocks.NewShortenUrl(t)
+				mockSvc.On("GetClicks", ctx, "", "alice", "abc1234").Return(model.ClickStats{}, service.ErrCodeNotFound).Once()
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponseBody: `{"message":"url not found"}`,
+		},
+		{
+			name: "service returns other error -> 500",
+
+			setupMockSvc: func(t *testing.T, ctx context.Context) *mocks.ShortenUrl {
+				mockSvc := mocks.NewShortenUrl(t)
+				mockSvc.On("GetClicks", ctx, "", "alice", "abc1234").Return(model.ClickStats{}, errors.New("some error")).Once()
+				return mockSvc
+			},
+
+			expectedResponseCode: http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodGet, "/v1/links/clicks/abc1234", nil)
+			gc.Params = gin.Params{{Key: "code", Value: "abc1234"}}
+			gc.Set(middleware.UserIDKey, "alice")
+
+			testHandler := NewUrlShortenHandler(tc.setupMockSvc(t, gc), nil, nil, nil, nil)
+			testHandler.Clicks(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
 			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
 		})
 	}
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+type createWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required" enums:"link.created,link.clicked,link.expiring,link.expired,link.deleted"`
+}
+
+type webhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type webhookListResponse struct {
+	Webhooks []webhookResponse `json:"webhooks"`
+}
+
+type webhookDeliveryResponse struct {
+	ID            string     `json:"id"`
+	EventID       string     `json:"eventId"`
+	Event         string     `json:"event"`
+	Status        string     `json:"status" enums:"pending,delivered,dead"`
+	Attempts      int        `json:"attempts"`
+	LastStatus    int        `json:"lastStatus,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+}
+
+type webhookDeliveryListResponse struct {
+	Deliveries []webhookDeliveryResponse `json:"deliveries"`
+}
+
+type WebhookHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Delete(c *gin.Context)
+	Deliveries(c *gin.Context)
+}
+
+type webhookHandler struct {
+	webhooks service.Webhook
+}
+
+// NewWebhookHandler returns a new instance of the webhookHandler, which implements the WebhookHandler interface.
+// On the workspace routes its handlers expect middleware.RequireWorkspaceRole in front of them, elsewhere they manage
+// the webhooks of the global links of the authenticated user.
+func NewWebhookHandler(webhooks service.Webhook) WebhookHandler {
+	return &webhookHandler{webhooks: webhooks}
+}
+
+// Create subscribes an endpoint to link events
+// @Summary Create webhook
+// @Description Subscribes an http or https endpoint to events of the links of a workspace, or of the global links created by the user outside the workspace routes. At most 20 webhooks per workspace or user.
+// @Description Events are POSTed as JSON with the X-Webhook-Id, X-Webhook-Event, X-Webhook-Delivery, X-Webhook-Timestamp and X-Webhook-Signature headers. The signature is "sha256=" followed by the hex HMAC-SHA256 of the timestamp, a '.' and the body, keyed with the secret, which is only returned here.
+// @Description A delivery answered with a non-2xx status is retried with an exponential backoff, and dead after the configured number of attempts.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Param createWebhookRequest body createWebhookRequest true "Endpoint and events"
+// @Success 201 {object} webhookResponse
+// @Failure 400 {object} map[string]string "Bad Request - invalid URL or events"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace not found"
+// @Failure 409 {object} map[string]string "Conflict - too many webhooks"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/webhooks [post]
+// @Router /v1/workspaces/{workspace}/webhooks [post]
+func (h *webhookHandler) Create(c *gin.Context) {
+	workspace, owner, ok := webhookScope(c)
+	if !ok {
+		return
+	}
+
+	var req createWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request"})
+		return
+	}
+
+	hook, err := h.webhooks.Create(c, workspace, owner, req.URL, req.Events)
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newWebhookResponse(hook))
+}
+
+// List returns the webhooks of a workspace or a user
+// @Summary List webhooks
+// @Description Lists the webhooks of a workspace, or of the global links of the user outside the workspace routes, oldest first. Secrets are not returned.
+// @Tags Webhooks
+// @Produce json
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Success 200 {object} webhookListResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/webhooks [get]
+// @Router /v1/workspaces/{workspace}/webhooks [get]
+func (h *webhookHandler) List(c *gin.Context) {
+	workspace, owner, ok := webhookScope(c)
+	if !ok {
+		return
+	}
+
+	hooks, err := h.webhooks.List(c, workspace, owner)
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	resp := webhookListResponse{Webhooks: make([]webhookResponse, 0, len(hooks))}
+	for _, hook := range hooks {
+		resp.Webhooks = append(resp.Webhooks, newWebhookResponse(hook))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Delete removes a webhook
+// @Summary Delete webhook
+// @Description Removes a webhook, its pending deliveries are dropped.
+// @Tags Webhooks
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Param webhook path string true "Webhook ID"
+// @Success 204
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace or webhook not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/webhooks/{webhook} [delete]
+// @Router /v1/workspaces/{workspace}/webhooks/{webhook} [delete]
+func (h *webhookHandler) Delete(c *gin.Context) {
+	workspace, owner, ok := webhookScope(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhooks.Delete(c, workspace, owner, c.Param("webhook")); err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries returns the delivery log of a webhook
+// @Summary Webhook deliveries
+// @Description Lists the latest 100 deliveries of a webhook, newest first, with the outcome of their last attempt. Deliveries are kept for 7 days after their last attempt.
+// @Tags Webhooks
+// @Produce json
+// @Param workspace path string false "Workspace ID, on the workspace route only"
+// @Param webhook path string true "Webhook ID"
+// @Success 200 {object} webhookDeliveryListResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace or webhook not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/webhooks/{webhook}/deliveries [get]
+// @Router /v1/workspaces/{workspace}/webhooks/{webhook}/deliveries [get]
+func (h *webhookHandler) Deliveries(c *gin.Context) {
+	workspace, owner, ok := webhookScope(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhooks.Deliveries(c, workspace, owner, c.Param("webhook"))
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	resp := webhookDeliveryListResponse{Deliveries: make([]webhookDeliveryResponse, 0, len(deliveries))}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, newWebhookDeliveryResponse(d))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// webhookScope returns the workspace of the path and the authenticated user. Outside the workspace routes it answers
+// 401 and returns false when the request is anonymous.
+func webhookScope(c *gin.Context) (workspace, owner string, ok bool) {
+	workspace, owner = c.Param("workspace"), c.GetString(middleware.UserIDKey)
+	if workspace == "" && owner == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "authentication required"})
+		return "", "", false
+	}
+	return workspace, owner, true
+}
+
+// errorResponse writes the response of err, an error of the webhook service.
+func (h *webhookHandler) errorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhook):
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid webhook"})
+	case errors.Is(err, service.ErrTooManyWebhooks):
+		c.JSON(http.StatusConflict, gin.H{"message": "too many webhooks"})
+	case errors.Is(err, service.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "webhook not found"})
+	default:
+		log.Ctx(c).Error().Err(err).Msg("Service return error on webhooks")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+	}
+}
+
+func newWebhookResponse(hook model.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:        hook.ID,
+		URL:       hook.URL,
+		Events:    hook.Events,
+		Secret:    hook.Secret,
+		CreatedAt: hook.CreatedAt,
+	}
+}
+
+func newWebhookDeliveryResponse(d model.WebhookDelivery) webhookDeliveryResponse {
+	resp := webhookDeliveryResponse{
+		ID:         d.ID,
+		EventID:    d.EventID,
+		Event:      d.EventType,
+		Status:     d.Status,
+		Attempts:   d.Attempts,
+		LastStatus: d.LastStatus,
+		LastError:  d.LastError,
+		CreatedAt:  d.CreatedAt,
+	}
+	if d.Status == model.DeliveryPending {
+		resp.NextAttemptAt = &d.NextAttemptAt
+	}
+	return resp
+}
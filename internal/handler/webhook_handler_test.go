@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookHandler_Create(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		workspace    string
+		userID       string
+		body         string
+		setupMockSvc func(t *testing.T) *mocks.Webhook
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "user webhook -> 201",
+
+			userID: "u1",
+			body:   `{"url":"https://hooks.example.com/in","events":["link.created"]}`,
+			setupMockSvc: func(t *testing.T) *mocks.Webhook {
+				svc := mocks.NewWebhook(t)
+				svc.On("Create", mock.Anything, "", "u1", "https://hooks.example.com/in", []string{model.EventLinkCreated}).Return(model.Webhook{
+					ID:        "wh1",
+					Owner:     "u1",
+					URL:       "https://hooks.example.com/in",
+					Secret:    "s3cret",
+					Events:    []string{model.EventLinkCreated},
+					CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+				}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusCreated,
+			expectedResponseBody: `{"id":"wh1","url":"https://hooks.example.com/in","events":["link.created"],"secret":"s3cret","createdAt":"2025-01-02T03:04:05Z"}`,
+		},
+		{
+			name: "anonymous -> 401",
+
+			body: `{"url":"https://hooks.example.com/in","events":["link.created"]}`,
+			setupMockSvc: func(t *testing.T) *mocks.Webhook {
+				return mocks.NewWebhook(t)
+			},
+
+			expectedResponseCode: http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"authentication required"}`,
+		},
+		{
+			name: "missing events -> 400",
+
+			workspace: "ws1",
+			userID:    "u1",
+			body:      `{"url":"https://hooks.example.com/in"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Webhook {
+				return mocks.NewWebhook(t)
+			},
+
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseBody: `{"message":"Invalid request"}`,
+		},
+		{
+			name: "invalid webhook -> 400",
+
+			workspace: "ws1",
+			userID:    "u1",
+			body:      `{"url":"ftp://hooks.example.com","events":["link.created"]}`,
+			setupMockSvc: func(t *testing.T) *mocks.Webhook {
+				svc := mocks.NewWebhook(t)
+				svc.On("Create", mock.Anything, "ws1", "u1", "ftp://hooks.example.com", []string{model.EventLinkCreated}).Return(model.Webhook{}, service.ErrInvalidWebhook).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid webhook"}`,
+		},
+		{
+			name: "too many webhooks -> 409",
+
+			workspace: "ws1",
+			userID:    "u1",
+			body:      `{"url":"https://hooks.example.com/in","events":["link.created"]}`,
+			setupMockSvc: func(t *testing.T) *mocks.Webhook {
+				svc := mocks.NewWebhook(t)
+				svc.On("Create", mock.Anything, "ws1", "u1", "https://hooks.example.com/in", []string{model.EventLinkCreated}).Return(model.Webhook{}, service.ErrTooManyWebhooks).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusConflict,
+			expectedResponseBody: `{"message":"too many webhooks"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodPost, "/v1/webhooks", strings.NewReader(tc.body))
+			if tc.workspace != "" {
+				gc.Params = gin.Params{{Key: "workspace", Value: tc.workspace}}
+			}
+			if tc.userID != "" {
+				gc.Set(middleware.UserIDKey, tc.userID)
+			}
+
+			testHandler := NewWebhookHandler(tc.setupMockSvc(t))
+			testHandler.Create(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
+
+func TestWebhookHandler_Deliveries(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		setupMockSvc func(t *testing.T) *mocks.Webhook
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "success -> 200",
+
+			setupMockSvc: func(t *testing.T) *mocks.Webhook {
+				svc := mocks.NewWebhook(t)
+				svc.On("Deliveries", mock.Anything, "", "u1", "wh1").Return([]model.WebhookDelivery{
+					{
+						ID:            "d2",
+						EventID:       "ev2",
+						EventType:     model.EventLinkClicked,
+						Status:        model.DeliveryPending,
+						Attempts:      1,
+						LastStatus:    http.StatusInternalServerError,
+						LastError:     "unexpected status 500",
+						CreatedAt:     time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+						NextAttemptAt: time.Date(2025, 1, 2, 3, 4, 15, 0, time.UTC),
+					},
+					{
+						ID:         "d1",
+						EventID:    "ev1",
+						EventType:  model.EventLinkCreated,
+						Status:     model.DeliveryDelivered,
+						Attempts:   1,
+						LastStatus: http.StatusOK,
+						CreatedAt:  time.Date(2025, 1, 2, 3, 4, 0, 0, time.UTC),
+					},
+				}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"deliveries":[` +
+				`{"id":"d2","eventId":"ev2","event":"link.clicked","status":"pending","attempts":1,"lastStatus":500,"lastError":"unexpected status 500","createdAt":"2025-01-02T03:04:05Z","nextAttemptAt":"2025-01-02T03:04:15Z"},` +
+				`{"id":"d1","eventId":"ev1","event":"link.created","status":"delivered","attempts":1,"lastStatus":200,"createdAt":"2025-01-02T03:04:00Z"}]}`,
+		},
+		{
+			name: "not found -> 404",
+
+			setupMockSvc: func(t *testing.T) *mocks.Webhook {
+				svc := mocks.NewWebhook(t)
+				svc.On("Deliveries", mock.Anything, "", "u1", "wh1").Return(nil, service.ErrWebhookNotFound).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponseBody: `{"message":"webhook not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodGet, "/v1/webhooks/wh1/deliveries", nil)
+			gc.Params = gin.Params{{Key: "webhook", Value: "wh1"}}
+			gc.Set(middleware.UserIDKey, "u1")
+
+			testHandler := NewWebhookHandler(tc.setupMockSvc(t))
+			testHandler.Deliveries(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+type createWorkspaceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type setMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type workspaceResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type workspaceListResponse struct {
+	Workspaces []workspaceResponse `json:"workspaces"`
+}
+
+type workspaceMemberResponse struct {
+	UserID string `json:"userId"`
+	Role   string `json:"role"`
+}
+
+type workspaceMembersResponse struct {
+	Members []workspaceMemberResponse `json:"members"`
+}
+
+type WorkspaceHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Get(c *gin.Context)
+	Members(c *gin.Context)
+	SetMember(c *gin.Context)
+	RemoveMember(c *gin.Context)
+}
+
+type workspaceHandler struct {
+	workspaces service.Workspace
+}
+
+// NewWorkspaceHandler returns a new instance of the workspaceHandler, which implements the WorkspaceHandler interface.
+// Except for Create and List, its handlers expect middleware.RequireWorkspaceRole in front of them.
+func NewWorkspaceHandler(workspaces service.Workspace) WorkspaceHandler {
+	return &workspaceHandler{workspaces: workspaces}
+}
+
+// Create creates a workspace owned by the authenticated user
+// @Summary Create workspace
+// @Description Creates a workspace with the authenticated user as its owner.
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Param createWorkspaceRequest body createWorkspaceRequest true "Workspace to create"
+// @Success 201 {object} workspaceResponse
+// @Failure 400 {object} map[string]string "Bad Request - invalid name"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/workspaces [post]
+func (h *workspaceHandler) Create(c *gin.Context) {
+	userID := c.GetString(middleware.UserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "authentication required"})
+		return
+	}
+
+	var req createWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request"})
+		return
+	}
+
+	ws, err := h.workspaces.Create(c, req.Name, userID)
+	if errors.Is(err, service.ErrInvalidWorkspaceName) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request"})
+		return
+	}
+	if err != nil {
+		log.Ctx(c).Error().Err(err).Msg("Service return error on Create workspace")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+	c.JSON(http.StatusCreated, newWorkspaceResponse(ws))
+}
+
+// List returns the workspaces of the authenticated user
+// @Summary List workspaces
+// @Description Lists the workspaces the authenticated user is a member of, sorted by name.
+// @Tags Workspaces
+// @Produce json
+// @Success 200 {object} workspaceListResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/workspaces [get]
+func (h *workspaceHandler) List(c *gin.Context) {
+	userID := c.GetString(middleware.UserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "authentication required"})
+		return
+	}
+
+	workspaces, err := h.workspaces.ListForUser(c, userID)
+	if err != nil {
+		log.Ctx(c).Error().Err(err).Msg("Service return error on ListForUser")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+
+	resp := workspaceListResponse{Workspaces: make([]workspaceResponse, 0, len(workspaces))}
+	for _, ws := range workspaces {
+		resp.Workspaces = append(resp.Workspaces, newWorkspaceResponse(ws))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Get returns a workspace
+// @Summary Get workspace
+// @Description Returns a workspace the authenticated user is a member of.
+// @Tags Workspaces
+// @Produce json
+// @Param workspace path string true "Workspace ID"
+// @Success 200 {object} workspaceResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Workspace not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/workspaces/{workspace} [get]
+func (h *workspaceHandler) Get(c *gin.Context) {
+	ws, err := h.workspaces.Get(c, c.Param("workspace"))
+	if errors.Is(err, service.ErrWorkspaceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "workspace not found"})
+		return
+	}
+	if err != nil {
+		log.Ctx(c).Error().Err(err).Msg("Service return error on Get workspace")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, newWorkspaceResponse(ws))
+}
+
+// Members returns the members of a workspace
+// @Summary List workspace members
+// @Description Lists the members of a workspace and their role, sorted by user ID.
+// @Tags Workspaces
+// @Produce json
+// @Param workspace path string true "Workspace ID"
+// @Success 200 {object} workspaceMembersResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Workspace not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/workspaces/{workspace}/members [get]
+func (h *workspaceHandler) Members(c *gin.Context) {
+	members, err := h.workspaces.Members(c, c.Param("workspace"))
+	if err != nil {
+		log.Ctx(c).Error().Err(err).Msg("Service return error on Members")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+
+	resp := workspaceMembersResponse{Members: make([]workspaceMemberResponse, 0, len(members))}
+	for _, m := range members {
+		resp.Members = append(resp.Members, workspaceMemberResponse{UserID: m.UserID, Role: m.Role})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetMember adds a member to a workspace or changes their role
+// @Summary Set workspace member
+// @Description Adds a user to a workspace with a role (owner, admin, member or viewer), or changes their role. Requires admin, and nobody grants a role above their own or changes the role of a member above them.
+// @Tags Workspaces
+// @Accept json
+// @Param workspace path string true "Workspace ID"
+// @Param user path string true "User ID"
+// @Param setMemberRequest body setMemberRequest true "Role of the member"
+// @Success 204
+// @Failure 400 {object} map[string]string "Bad Request - invalid role"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace not found"
+// @Failure 409 {object} map[string]string "Conflict - the workspace would be left without an owner"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/workspaces/{workspace}/members/{user} [put]
+func (h *workspaceHandler) SetMember(c *gin.Context) {
+	var req setMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request"})
+		return
+	}
+
+	err := h.workspaces.SetMember(c, c.Param("workspace"), c.GetString(middleware.WorkspaceRoleKey), c.Param("user"), req.Role)
+	h.memberResponse(c, err)
+}
+
+// RemoveMember removes a member from a workspace
+// @Summary Remove workspace member
+// @Description Removes a user from a workspace. Requires admin, and only owners remove owners.
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID"
+// @Param user path string true "User ID"
+// @Success 204
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden - insufficient role"
+// @Failure 404 {object} map[string]string "Workspace or member not found"
+// @Failure 409 {object} map[string]string "Conflict - the workspace would be left without an owner"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /v1/workspaces/{workspace}/members/{user} [delete]
+func (h *workspaceHandler) RemoveMember(c *gin.Context) {
+	err := h.workspaces.RemoveMember(c, c.Param("workspace"), c.GetString(middleware.WorkspaceRoleKey), c.Param("user"))
+	h.memberResponse(c, err)
+}
+
+// memberResponse writes the response of a change of the members of a workspace, err is the error of the service.
+func (h *workspaceHandler) memberResponse(c *gin.Context, err error) {
+	switch {
+	case err == nil:
+		c.Status(http.StatusNoContent)
+	case errors.Is(err, service.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid role"})
+	case errors.Is(err, service.ErrInsufficientRole):
+		c.JSON(http.StatusForbidden, gin.H{"message": "insufficient role"})
+	case errors.Is(err, service.ErrNotWorkspaceMember):
+		c.JSON(http.StatusNotFound, gin.H{"message": "member not found"})
+	case errors.Is(err, service.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"message": "a workspace must keep an owner"})
+	default:
+		log.Ctx(c).Error().Err(err).Msg("Service return error on workspace members")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+	}
+}
+
+func newWorkspaceResponse(ws model.Workspace) workspaceResponse {
+	return workspaceResponse{ID: ws.ID, Name: ws.Name, CreatedAt: ws.CreatedAt}
+}
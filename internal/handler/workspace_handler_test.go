@@ -0,0 +1,423 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/middleware"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkspaceHandler_Create(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		userID       string
+		body         string
+		setupMockSvc func(t *testing.T) *mocks.Workspace
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "success -> 201",
+
+			userID: "alice",
+			body:   `{"name":"Team A"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("Create", mock.Anything, "Team A", "alice").
+					Return(model.Workspace{ID: "ws1", Name: "Team A", CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusCreated,
+			expectedResponseBody: `{"id":"ws1","name":"Team A","createdAt":"2025-01-02T03:04:05Z"}`,
+		},
+		{
+			name: "anonymous -> 401",
+
+			body: `{"name":"Team A"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				return mocks.NewWorkspace(t)
+			},
+
+			expectedResponseCode: http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"authentication required"}`,
+		},
+		{
+			name: "invalid name -> 400",
+
+			userID: "alice",
+			body:   `{"name":"   "}`,
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("Create", mock.Anything, "   ", "alice").Return(model.Workspace{}, service.ErrInvalidWorkspaceName).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseBody: `{"message":"Invalid request"}`,
+		},
+		{
+			name: "service error -> 500",
+
+			userID: "alice",
+			body:   `{"name":"Team A"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("Create", mock.Anything, "Team A", "alice").Return(model.Workspace{}, errors.New("redis down")).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodPost, "/v1/workspaces", strings.NewReader(tc.body))
+			if tc.userID != "" {
+				gc.Set(middleware.UserIDKey, tc.userID)
+			}
+
+			testHandler := NewWorkspaceHandler(tc.setupMockSvc(t))
+			testHandler.Create(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
+
+func TestWorkspaceHandler_List(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		userID       string
+		setupMockSvc func(t *testing.T) *mocks.Workspace
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "no workspace",
+
+			userID: "alice",
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("ListForUser", mock.Anything, "alice").Return([]model.Workspace{}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"workspaces":[]}`,
+		},
+		{
+			name: "workspaces",
+
+			userID: "alice",
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("ListForUser", mock.Anything, "alice").
+					Return([]model.Workspace{{ID: "ws1", Name: "Team A", CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"workspaces":[{"id":"ws1","name":"Team A","createdAt":"2025-01-02T03:04:05Z"}]}`,
+		},
+		{
+			name: "anonymous -> 401",
+
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				return mocks.NewWorkspace(t)
+			},
+
+			expectedResponseCode: http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"authentication required"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodGet, "/v1/workspaces", nil)
+			if tc.userID != "" {
+				gc.Set(middleware.UserIDKey, tc.userID)
+			}
+
+			testHandler := NewWorkspaceHandler(tc.setupMockSvc(t))
+			testHandler.List(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
+
+func TestWorkspaceHandler_Get(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		setupMockSvc func(t *testing.T) *mocks.Workspace
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "success",
+
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("Get", mock.Anything, "ws1").
+					Return(model.Workspace{ID: "ws1", Name: "Team A", CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}, nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: `{"id":"ws1","name":"Team A","createdAt":"2025-01-02T03:04:05Z"}`,
+		},
+		{
+			name: "not found -> 404",
+
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("Get", mock.Anything, "ws1").Return(model.Workspace{}, service.ErrWorkspaceNotFound).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponseBody: `{"message":"workspace not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodGet, "/v1/workspaces/ws1", nil)
+			gc.Params = gin.Params{{Key: "workspace", Value: "ws1"}}
+
+			testHandler := NewWorkspaceHandler(tc.setupMockSvc(t))
+			testHandler.Get(gc)
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
+
+func TestWorkspaceHandler_Members(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	gc, _ := gin.CreateTestContext(rec)
+	gc.Request = httptest.NewRequest(http.MethodGet, "/v1/workspaces/ws1/members", nil)
+	gc.Params = gin.Params{{Key: "workspace", Value: "ws1"}}
+
+	svc := mocks.NewWorkspace(t)
+	svc.On("Members", mock.Anything, "ws1").Return([]model.WorkspaceMember{
+		{UserID: "alice", Role: model.RoleOwner},
+		{UserID: "bob", Role: model.RoleViewer},
+	}, nil).Once()
+
+	NewWorkspaceHandler(svc).Members(gc)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"members":[{"userId":"alice","role":"owner"},{"userId":"bob","role":"viewer"}]}`, rec.Body.String())
+}
+
+func TestWorkspaceHandler_SetMember(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		body         string
+		setupMockSvc func(t *testing.T) *mocks.Workspace
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "success -> 204",
+
+			body: `{"role":"member"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("SetMember", mock.Anything, "ws1", model.RoleAdmin, "bob", model.RoleMember).Return(nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusNoContent,
+		},
+		{
+			name: "missing role -> 400",
+
+			body: `{}`,
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				return mocks.NewWorkspace(t)
+			},
+
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseBody: `{"message":"Invalid request"}`,
+		},
+		{
+			name: "invalid role -> 400",
+
+			body: `{"role":"superuser"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("SetMember", mock.Anything, "ws1", model.RoleAdmin, "bob", "superuser").Return(service.ErrInvalidRole).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid role"}`,
+		},
+		{
+			name: "insufficient role -> 403",
+
+			body: `{"role":"owner"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("SetMember", mock.Anything, "ws1", model.RoleAdmin, "bob", model.RoleOwner).Return(service.ErrInsufficientRole).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusForbidden,
+			expectedResponseBody: `{"message":"insufficient role"}`,
+		},
+		{
+			name: "last owner -> 409",
+
+			body: `{"role":"viewer"}`,
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("SetMember", mock.Anything, "ws1", model.RoleAdmin, "bob", model.RoleViewer).Return(service.ErrLastOwner).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusConflict,
+			expectedResponseBody: `{"message":"a workspace must keep an owner"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodPut, "/v1/workspaces/ws1/members/bob", strings.NewReader(tc.body))
+			gc.Params = gin.Params{{Key: "workspace", Value: "ws1"}, {Key: "user", Value: "bob"}}
+			gc.Set(middleware.WorkspaceRoleKey, model.RoleAdmin)
+
+			testHandler := NewWorkspaceHandler(tc.setupMockSvc(t))
+			testHandler.SetMember(gc)
+			gc.Writer.WriteHeaderNow()
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
+
+func TestWorkspaceHandler_RemoveMember(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		setupMockSvc func(t *testing.T) *mocks.Workspace
+
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "success -> 204",
+
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("RemoveMember", mock.Anything, "ws1", model.RoleAdmin, "bob").Return(nil).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusNoContent,
+		},
+		{
+			name: "not a member -> 404",
+
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("RemoveMember", mock.Anything, "ws1", model.RoleAdmin, "bob").Return(service.ErrNotWorkspaceMember).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusNotFound,
+			expectedResponseBody: `{"message":"member not found"}`,
+		},
+		{
+			name: "service error -> 500",
+
+			setupMockSvc: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("RemoveMember", mock.Anything, "ws1", model.RoleAdmin, "bob").Return(errors.New("redis down")).Once()
+				return svc
+			},
+
+			expectedResponseCode: http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			gc, _ := gin.CreateTestContext(rec)
+			gc.Request = httptest.NewRequest(http.MethodDelete, "/v1/workspaces/ws1/members/bob", nil)
+			gc.Params = gin.Params{{Key: "workspace", Value: "ws1"}, {Key: "user", Value: "bob"}}
+			gc.Set(middleware.WorkspaceRoleKey, model.RoleAdmin)
+
+			testHandler := NewWorkspaceHandler(tc.setupMockSvc(t))
+			testHandler.RemoveMember(gc)
+			gc.Writer.WriteHeaderNow()
+
+			assert.Equal(t, tc.expectedResponseCode, rec.Code)
+			assert.Equal(t, tc.expectedResponseBody, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	namespace = "bookmark"
+
+	RedirectFound    = "found"
+	RedirectPreview  = "preview"
+	RedirectNotFound = "not_found"
+	RedirectInvalid  = "invalid"
+	RedirectError    = "error"
+
+	ShortenFailureInvalidRequest = "invalid_request"
+	ShortenFailureCollisions     = "collisions"
+	ShortenFailureAliasTaken     = "alias_taken"
+	ShortenFailureInternal       = "internal"
+
+	unmatchedRoute = "unmatched"
+)
+
+// KeyspaceSource provides the keyspace statistics exported as metrics.
+type KeyspaceSource interface {
+	CodeLength() int
+	Stats() []model.KeyspaceStats
+}
+
+// Metrics holds the Prometheus collectors of the service on its own registry.
+// Every Observe method is safe to call on a nil *Metrics, so metrics are optional for handlers and tests.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	redirects       *prometheus.CounterVec
+	shortenFailures *prometheus.CounterVec
+	redisDuration   *prometheus.HistogramVec
+	healthChecks    *prometheus.CounterVec
+	healthy         prometheus.Gauge
+}
+
+// New returns a new instance of the Metrics with every collector registered, including the Go runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		redirects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "redirects_total",
+			Help:      "Number of redirect lookups by result.",
+		}, []string{"result"}),
+		shortenFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "shorten_failures_total",
+			Help:      "Number of failed shorten requests by reason.",
+		}, []string{"reason"}),
+		redisDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "redis_command_duration_seconds",
+			Help:      "Redis command latency by command and status.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"command", "status"}),
+		healthChecks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "health_checks_total",
+			Help:      "Number of health checks by result.",
+		}, []string{"result"}),
+		healthy: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "health_check_up",
+			Help:      "1 if the last health check succeeded, 0 otherwise.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.redirects,
+		m.shortenFailures,
+		m.redisDuration,
+		m.healthChecks,
+		m.healthy,
+	)
+	return m
+}
+
+// Handler returns the http.Handler serving the metrics in the Prometheus text exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware returns a gin middleware counting requests and observing their latency.
+// Requests are labeled with the route template rather than the path, so codes do not blow up the label cardinality.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.requests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.requestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterKeyspace exports the statistics of source as gauges, read at every scrape.
+func (m *Metrics) RegisterKeyspace(source KeyspaceSource) {
+	m.registry.MustRegister(&keyspaceCollector{source: source})
+}
+
+// ObserveRedirect counts a redirect lookup, result is one of the Redirect* constants.
+func (m *Metrics) ObserveRedirect(result string) {
+	if m == nil {
+		return
+	}
+	m.redirects.WithLabelValues(result).Inc()
+}
+
+// ObserveShortenFailure counts a failed shorten request, reason is one of the ShortenFailure* constants.
+func (m *Metrics) ObserveShortenFailure(reason string) {
+	if m == nil {
+		return
+	}
+	m.shortenFailures.WithLabelValues(reason).Inc()
+}
+
+// ObserveHealthCheck counts a health check and records its result.
+func (m *Metrics) ObserveHealthCheck(healthy bool) {
+	if m == nil {
+		return
+	}
+
+	if healthy {
+		m.healthChecks.WithLabelValues("ok").Inc()
+		m.healthy.Set(1)
+		return
+	}
+	m.healthChecks.WithLabelValues("error").Inc()
+	m.healthy.Set(0)
+}
+
+// RedisHook returns a redis.Hook observing the latency of every command, pipelines are observed as a whole.
+func (m *Metrics) RedisHook() redis.Hook {
+	return &redisHook{duration: m.redisDuration}
+}
+
+type redisHook struct {
+	duration *prometheus.HistogramVec
+}
+
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.observe(strings.ToLower(cmd.Name()), start, err)
+		return err
+	}
+}
+
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.observe("pipeline", start, err)
+		return err
+	}
+}
+
+func (h *redisHook) observe(command string, start time.Time, err error) {
+	status := "ok"
+	if err != nil && !errors.Is(err, redis.Nil) {
+		status = "error"
+	}
+	h.duration.WithLabelValues(command, status).Observe(time.Since(start).Seconds())
+}
+
+var (
+	keyspaceCodeLengthDesc = prometheus.NewDesc(namespace+"_keyspace_code_length", "Length of newly generated codes.", nil, nil)
+	keyspaceAttemptsDesc   = prometheus.NewDesc(namespace+"_keyspace_attempts_total", "Number of attempts to store a generated code by code length.", []string{"length"}, nil)
+	keyspaceCollisionsDesc = prometheus.NewDesc(namespace+"_keyspace_collisions_total", "Number of generated codes that were already taken by code length.", []string{"length"}, nil)
+	keyspaceOccupancyDesc  = prometheus.NewDesc(namespace+"_keyspace_occupancy_ratio", "Estimated fraction of the keyspace in use by code length.", []string{"length"}, nil)
+)
+
+type keyspaceCollector struct {
+	source KeyspaceSource
+}
+
+func (c *keyspaceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- keyspaceCodeLengthDesc
+	ch <- keyspaceAttemptsDesc
+	ch <- keyspaceCollisionsDesc
+	ch <- keyspaceOccupancyDesc
+}
+
+func (c *keyspaceCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(keyspaceCodeLengthDesc, prometheus.GaugeValue, float64(c.source.CodeLength()))
+	for _, s := range c.source.Stats() {
+		length := strconv.Itoa(s.Length)
+		ch <- prometheus.MustNewConstMetric(keyspaceAttemptsDesc, prometheus.CounterValue, float64(s.Attempts), length)
+		ch <- prometheus.MustNewConstMetric(keyspaceCollisionsDesc, prometheus.CounterValue, float64(s.Collisions), length)
+		ch <- prometheus.MustNewConstMetric(keyspaceOccupancyDesc, prometheus.GaugeValue, s.EstimatedOccupancy, length)
+	}
+}
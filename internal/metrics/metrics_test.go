@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testKeyspace struct{}
+
+func (testKeyspace) CodeLength() int { return 8 }
+
+func (testKeyspace) Stats() []model.KeyspaceStats {
+	return []model.KeyspaceStats{{Length: 7, Attempts: 120, Collisions: 30, EstimatedOccupancy: 0.25}}
+}
+
+func TestMetrics_Middleware(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		path string
+
+		expectedRoute  string
+		expectedStatus string
+	}{
+		{
+			name: "matched route uses the template",
+
+			path: "/v1/links/redirect/abc1234",
+
+			expectedRoute:  "/v1/links/redirect/:code",
+			expectedStatus: "302",
+		},
+		{
+			name: "unmatched route",
+
+			path: "/nope",
+
+			expectedRoute:  unmatchedRoute,
+			expectedStatus: "404",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := New()
+			app := gin.New()
+			app.Use(m.Middleware())
+			app.GET("/v1/links/redirect/:code", func(c *gin.Context) {
+				c.Redirect(http.StatusFound, "https://google.com")
+			})
+
+			app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, tc.expectedRoute, tc.expectedStatus)))
+			assert.Equal(t, 1, testutil.CollectAndCount(m.requestDuration))
+		})
+	}
+}
+
+func TestMetrics_Observe(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		observe func(m *Metrics)
+
+		expectedMetrics []string
+	}{
+		{
+			name: "redirects and shorten failures",
+
+			observe: func(m *Metrics) {
+				m.ObserveRedirect(RedirectFound)
+				m.ObserveRedirect(RedirectNotFound)
+				m.ObserveShortenFailure(ShortenFailureCollisions)
+			},
+
+			expectedMetrics: []string{
+				`bookmark_redirects_total{result="found"} 1`,
+				`bookmark_redirects_total{result="not_found"} 1`,
+				`bookmark_shorten_failures_total{reason="collisions"} 1`,
+			},
+		},
+		{
+			name: "health check",
+
+			observe: func(m *Metrics) {
+				m.ObserveHealthCheck(true)
+				m.ObserveHealthCheck(false)
+			},
+
+			expectedMetrics: []string{
+				`bookmark_health_checks_total{result="ok"} 1`,
+				`bookmark_health_checks_total{result="error"} 1`,
+				`bookmark_health_check_up 0`,
+			},
+		},
+		{
+			name: "redis commands",
+
+			observe: func(m *Metrics) {
+				client := redisPkg.InitMockRedis(t)
+				client.AddHook(m.RedisHook())
+				ctx := context.Background()
+				require.NoError(t, client.Set(ctx, "k", "v", 0).Err())
+				_ = client.Get(ctx, "missing").Err()
+				_, err := client.Pipelined(ctx, func(p redis.Pipeliner) error {
+					p.Get(ctx, "k")
+					return nil
+				})
+				require.NoError(t, err)
+			},
+
+			expectedMetrics: []string{
+				`bookmark_redis_command_duration_seconds_count{command="set",status="ok"} 1`,
+				`bookmark_redis_command_duration_seconds_count{command="get",status="ok"} 1`,
+				`bookmark_redis_command_duration_seconds_count{command="pipeline",status="ok"} 1`,
+			},
+		},
+		{
+			name: "keyspace",
+
+			observe: func(m *Metrics) {
+				m.RegisterKeyspace(testKeyspace{})
+			},
+
+			expectedMetrics: []string{
+				`bookmark_keyspace_code_length 8`,
+				`bookmark_keyspace_attempts_total{length="7"} 120`,
+				`bookmark_keyspace_collisions_total{length="7"} 30`,
+				`bookmark_keyspace_occupancy_ratio{length="7"} 0.25`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := New()
+			tc.observe(m)
+
+			rec := httptest.NewRecorder()
+			m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			for _, expected := range tc.expectedMetrics {
+				assert.True(t, strings.Contains(rec.Body.String(), expected), expected)
+			}
+		})
+	}
+}
+
+func TestMetrics_NilIsNoop(t *testing.T) {
+	t.Parallel()
+
+	var m *Metrics
+	assert.NotPanics(t, func() {
+		m.ObserveRedirect(RedirectFound)
+		m.ObserveShortenFailure(ShortenFailureInternal)
+		m.ObserveHealthCheck(true)
+	})
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+// AccessLog returns a gin middleware writing one log line per request once it has been served.
+// The line is logged through the request logger, so it carries the request ID when RequestID runs first.
+// Server errors are logged at error level, client errors at warn level and everything else at info level.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		logger := log.Ctx(c.Request.Context())
+
+		var event *zerolog.Event
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = logger.Error()
+		case status >= http.StatusBadRequest:
+			event = logger.Warn()
+		default:
+			event = logger.Info()
+		}
+
+		event.
+			Str("method", c.Request.Method).
+			Str("route", c.FullPath()).
+			Str("path", c.Request.URL.Path).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Str("client_ip", c.ClientIP()).
+			Int("bytes", c.Writer.Size()).
+			Str("user_agent", c.Request.UserAgent()).
+			Msg("Request served")
+	}
+}
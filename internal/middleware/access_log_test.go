@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		path   string
+		status int
+
+		expectedLevel string
+		expectedRoute string
+	}{
+		{
+			name: "success -> info",
+
+			path:   "/v1/links/redirect/abc1234",
+			status: http.StatusFound,
+
+			expectedLevel: "info",
+			expectedRoute: "/v1/links/redirect/:code",
+		},
+		{
+			name: "client error -> warn",
+
+			path:   "/v1/links/redirect/notfound",
+			status: http.StatusNotFound,
+
+			expectedLevel: "warn",
+			expectedRoute: "/v1/links/redirect/:code",
+		},
+		{
+			name: "server error -> error",
+
+			path:   "/v1/links/redirect/boom",
+			status: http.StatusInternalServerError,
+
+			expectedLevel: "error",
+			expectedRoute: "/v1/links/redirect/:code",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			logger := zerolog.New(&buf).With().Str("request_id", "req-1").Logger()
+
+			app := gin.New()
+			app.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
+			}, AccessLog())
+			app.GET("/v1/links/redirect/:code", func(c *gin.Context) {
+				c.Status(tc.status)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.RemoteAddr = "203.0.113.7:1234"
+			app.ServeHTTP(httptest.NewRecorder(), req)
+
+			var line map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+			assert.Equal(t, tc.expectedLevel, line["level"])
+			assert.Equal(t, "req-1", line["request_id"])
+			assert.Equal(t, http.MethodGet, line["method"])
+			assert.Equal(t, tc.expectedRoute, line["route"])
+			assert.Equal(t, tc.path, line["path"])
+			assert.Equal(t, float64(tc.status), line["status"])
+			assert.Equal(t, "203.0.113.7", line["client_ip"])
+			assert.Contains(t, line, "latency")
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// CustomDomain returns a gin middleware resolving the Host of the request to the workspace of a verified custom domain.
+// The workspace is added as the :workspace path parameter and stored under WorkspaceIDKey, so the handlers after it
+// work within the workspace as on the /v1/workspaces/:workspace routes.
+// Requests to any other host are let through, without a workspace, when otherHosts is true and get a 404 otherwise.
+func CustomDomain(domains service.Domain, otherHosts bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspace, err := domains.Resolve(c.Request.Context(), c.Request.Host)
+		if errors.Is(err, service.ErrDomainNotFound) && otherHosts {
+			c.Next()
+			return
+		}
+		if errors.Is(err, service.ErrDomainNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "url not found"})
+			return
+		}
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Str("host", c.Request.Host).Msg("Failed to resolve custom domain")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+			return
+		}
+
+		c.Params = append(c.Params, gin.Param{Key: "workspace", Value: workspace})
+		c.Set(WorkspaceIDKey, workspace)
+		c.Next()
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCustomDomain(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		otherHosts bool
+		setupMock  func(t *testing.T) *mocks.Domain
+
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "verified custom domain",
+
+			setupMock: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Resolve", mock.Anything, "go.team.io").Return("ws1", nil).Once()
+				return svc
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ws1 ws1 abc",
+		},
+		{
+			name: "other host -> 404",
+
+			setupMock: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Resolve", mock.Anything, "go.team.io").Return("", service.ErrDomainNotFound).Once()
+				return svc
+			},
+
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"url not found"}`,
+		},
+		{
+			name: "other host let through",
+
+			otherHosts: true,
+			setupMock: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Resolve", mock.Anything, "go.team.io").Return("", service.ErrDomainNotFound).Once()
+				return svc
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   "  abc",
+		},
+		{
+			name: "service error -> 500",
+
+			setupMock: func(t *testing.T) *mocks.Domain {
+				svc := mocks.NewDomain(t)
+				svc.On("Resolve", mock.Anything, "go.team.io").Return("", errors.New("redis down")).Once()
+				return svc
+			},
+
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := gin.New()
+			app.GET("/:code", CustomDomain(tc.setupMock(t), tc.otherHosts), func(c *gin.Context) {
+				c.String(http.StatusOK, c.Param("workspace")+" "+c.GetString(WorkspaceIDKey)+" "+c.Param("code"))
+			})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+			req.Host = "go.team.io"
+			app.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
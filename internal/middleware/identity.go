@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net"
+)
+
+const maxUserIDLength = 128
+
+// Identity returns a gin middleware storing the user ID carried by the header under UserIDKey.
+// The header is set by the authenticating gateway in front of the service, it is only trusted on requests whose peer
+// address is one of trustedProxies, IPs or CIDRs, and ignored on any other request, which is then anonymous.
+// A malformed user ID is ignored and the request is anonymous.
+// An empty header name disables the middleware, every request is then anonymous.
+func Identity(header string, trustedProxies []string) gin.HandlerFunc {
+	gateways := parseNetworks(trustedProxies)
+	return func(c *gin.Context) {
+		if header == "" || !fromNetworks(c.RemoteIP(), gateways) {
+			c.Next()
+			return
+		}
+
+		if id := c.GetHeader(header); validID(id, maxUserIDLength) {
+			c.Set(UserIDKey, id)
+		}
+		c.Next()
+	}
+}
+
+// parseNetworks returns the networks of entries, a bare IP is a network of its own. Invalid entries are skipped.
+func parseNetworks(entries []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return networks
+}
+
+// fromNetworks returns whether the address addr is in one of networks.
+func fromNetworks(addr string, networks []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
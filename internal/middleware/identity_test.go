@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		header         string
+		trustedProxies []string
+		userID         string
+
+		expectedUserID string
+	}{
+		{
+			name: "trusted header",
+
+			header:         "X-User-ID",
+			trustedProxies: []string{"192.0.2.0/24"},
+			userID:         "alice",
+
+			expectedUserID: "alice",
+		},
+		{
+			name: "missing header -> anonymous",
+
+			header:         "X-User-ID",
+			trustedProxies: []string{"192.0.2.0/24"},
+
+			expectedUserID: "",
+		},
+		{
+			name: "malformed user ID -> anonymous",
+
+			header:         "X-User-ID",
+			trustedProxies: []string{"192.0.2.0/24"},
+			userID:         "alice}{bob",
+
+			expectedUserID: "",
+		},
+		{
+			name: "too long user ID -> anonymous",
+
+			header:         "X-User-ID",
+			trustedProxies: []string{"192.0.2.0/24"},
+			userID:         strings.Repeat("a", maxUserIDLength+1),
+
+			expectedUserID: "",
+		},
+		{
+			name: "trusted proxy IP",
+
+			header:         "X-User-ID",
+			trustedProxies: []string{"192.0.2.1"},
+			userID:         "alice",
+
+			expectedUserID: "alice",
+		},
+		{
+			name: "untrusted peer -> anonymous",
+
+			header:         "X-User-ID",
+			trustedProxies: []string{"10.0.0.0/8"},
+			userID:         "alice",
+
+			expectedUserID: "",
+		},
+		{
+			name: "no trusted proxies -> anonymous",
+
+			header: "X-User-ID",
+			userID: "alice",
+
+			expectedUserID: "",
+		},
+		{
+			name: "disabled",
+
+			trustedProxies: []string{"192.0.2.0/24"},
+			userID:         "alice",
+
+			expectedUserID: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var userID string
+			app := gin.New()
+			app.Use(Identity(tc.header, tc.trustedProxies))
+			app.GET("/", func(c *gin.Context) {
+				userID = c.GetString(UserIDKey)
+			})
+
+			// httptest requests come from 192.0.2.1.
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.userID != "" {
+				req.Header.Set("X-User-ID", tc.userID)
+			}
+			app.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tc.expectedUserID, userID)
+		})
+	}
+}
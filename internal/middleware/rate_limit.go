@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/rs/zerolog/log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// UserIDKey is the gin context key holding the ID of the authenticated user or API key, set by authentication.
+	UserIDKey = "userID"
+
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// RateLimit returns a gin middleware counting every request against the rate limit policy of route.
+// Clients are identified by the user ID under UserIDKey when authenticated, and by their IP address otherwise.
+// Limited responses carry the RateLimit-* headers, and a client over its limit gets a 429 with Retry-After.
+// If the limiter fails the request is let through, so an outage of Redis does not take the endpoints down.
+func RateLimit(limiter service.RateLimiter, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := limiter.Allow(c.Request.Context(), route, c.ClientIP(), c.GetString(UserIDKey))
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Str("route", route).Msg("Rate limiter failed, letting request through")
+			c.Next()
+			return
+		}
+		if res.Limit == 0 {
+			c.Next()
+			return
+		}
+
+		c.Header(RateLimitLimitHeader, strconv.Itoa(res.Limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(res.Remaining))
+		c.Header(RateLimitResetHeader, strconv.Itoa(ceilSeconds(res.Reset)))
+		c.Header(RateLimitPolicyHeader, rateLimitPolicy(res))
+
+		if !res.Allowed {
+			c.Header(RetryAfterHeader, strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"message": "too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func rateLimitPolicy(res model.RateLimitResult) string {
+	return fmt.Sprintf("%d;w=%d", res.Limit, ceilSeconds(res.Window))
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		userID    string
+		setupMock func(t *testing.T) *mocks.RateLimiter
+
+		expectedStatus  int
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			name: "allowed",
+
+			setupMock: func(t *testing.T) *mocks.RateLimiter {
+				limiter := mocks.NewRateLimiter(t)
+				limiter.On("Allow", mock.Anything, "shorten", "192.0.2.1", "").
+					Return(model.RateLimitResult{Allowed: true, Limit: 10, Remaining: 9, Window: time.Minute, Reset: 6 * time.Second}, nil).Once()
+				return limiter
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ok",
+			expectedHeaders: map[string]string{
+				RateLimitLimitHeader:     "10",
+				RateLimitRemainingHeader: "9",
+				RateLimitResetHeader:     "6",
+				RateLimitPolicyHeader:    "10;w=60",
+				RetryAfterHeader:         "",
+			},
+		},
+		{
+			name: "over the limit -> 429",
+
+			userID: "user-1",
+			setupMock: func(t *testing.T) *mocks.RateLimiter {
+				limiter := mocks.NewRateLimiter(t)
+				limiter.On("Allow", mock.Anything, "shorten", "192.0.2.1", "user-1").
+					Return(model.RateLimitResult{Limit: 10, Window: time.Minute, RetryAfter: 1500 * time.Millisecond, Reset: time.Minute}, nil).Once()
+				return limiter
+			},
+
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   `{"message":"too many requests"}`,
+			expectedHeaders: map[string]string{
+				RateLimitLimitHeader:     "10",
+				RateLimitRemainingHeader: "0",
+				RateLimitResetHeader:     "60",
+				RetryAfterHeader:         "2",
+			},
+		},
+		{
+			name: "no limit applies",
+
+			setupMock: func(t *testing.T) *mocks.RateLimiter {
+				limiter := mocks.NewRateLimiter(t)
+				limiter.On("Allow", mock.Anything, "shorten", "192.0.2.1", "").
+					Return(model.RateLimitResult{Allowed: true}, nil).Once()
+				return limiter
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ok",
+			expectedHeaders: map[string]string{
+				RateLimitLimitHeader: "",
+			},
+		},
+		{
+			name: "limiter error lets the request through",
+
+			setupMock: func(t *testing.T) *mocks.RateLimiter {
+				limiter := mocks.NewRateLimiter(t)
+				limiter.On("Allow", mock.Anything, "shorten", "192.0.2.1", "").
+					Return(model.RateLimitResult{}, errors.New("redis down")).Once()
+				return limiter
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ok",
+			expectedHeaders: map[string]string{
+				RateLimitLimitHeader: "",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := gin.New()
+			app.Use(func(c *gin.Context) {
+				if tc.userID != "" {
+					c.Set(UserIDKey, tc.userID)
+				}
+			})
+			app.POST("/v1/links/shorten", RateLimit(tc.setupMock(t), "shorten"), func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/links/shorten", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			app.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+			for header, expected := range tc.expectedHeaders {
+				assert.Equal(t, expected, rec.Header().Get(header), header)
+			}
+		})
+	}
+}
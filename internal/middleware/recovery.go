@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recovery returns a gin middleware turning a panic in a later handler into a JSON 500 response.
+// The panic value and stack trace are logged through the request logger.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Ctx(c.Request.Context()).Error().
+					Str("panic", fmt.Sprint(r)).
+					Str("stack", string(debug.Stack())).
+					Msg("Recovered from panic")
+
+				if c.Writer.Written() {
+					c.Abort()
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+			}
+		}()
+
+		c.Next()
+	}
+}
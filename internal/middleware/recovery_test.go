@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecovery(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		handler gin.HandlerFunc
+
+		expectedStatus int
+		expectedBody   string
+		expectedLog    string
+	}{
+		{
+			name: "no panic",
+
+			handler: func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ok",
+		},
+		{
+			name: "panic -> json 500",
+
+			handler: func(c *gin.Context) {
+				panic("boom")
+			},
+
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"internal server error"}`,
+			expectedLog:    `"panic":"boom"`,
+		},
+		{
+			name: "panic after response started keeps response",
+
+			handler: func(c *gin.Context) {
+				c.String(http.StatusOK, "partial")
+				panic("late boom")
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   "partial",
+			expectedLog:    `"panic":"late boom"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			logger := zerolog.New(&buf)
+
+			app := gin.New()
+			app.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
+			}, Recovery())
+			app.GET("/", tc.handler)
+
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+			if tc.expectedLog != "" {
+				assert.Contains(t, buf.String(), tc.expectedLog)
+			} else {
+				assert.Empty(t, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the gin context key holding the request ID.
+	RequestIDKey = "requestID"
+
+	maxRequestIDLength = 128
+)
+
+// RequestID returns a gin middleware assigning every request an ID.
+// A well-formed X-Request-ID header from the client or a proxy is kept, otherwise a UUID is generated.
+// The ID is echoed in the response header, stored under RequestIDKey and attached to a zerolog logger in the request context,
+// so log.Ctx(c) in handlers tags every line with it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validID(id, maxRequestIDLength) {
+			id = uuid.NewString()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		logger := log.With().Str("request_id", id).Logger()
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
+
+		c.Next()
+	}
+}
+
+// validID reports whether id is a non-empty header value of at most maxLength letters, digits, '-', '_', '.' or ':'.
+func validID(id string, maxLength int) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
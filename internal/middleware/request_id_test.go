@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		requestID string
+
+		expectedID        string
+		expectedGenerated bool
+	}{
+		{
+			name: "propagated",
+
+			requestID: "abc-123_x.y:z",
+
+			expectedID: "abc-123_x.y:z",
+		},
+		{
+			name: "missing -> generated",
+
+			expectedGenerated: true,
+		},
+		{
+			name: "malformed -> generated",
+
+			requestID: "bad id\r\nInjected: header",
+
+			expectedGenerated: true,
+		},
+		{
+			name: "too long -> generated",
+
+			requestID: strings.Repeat("a", maxRequestIDLength+1),
+
+			expectedGenerated: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ctxID string
+			var logLine bytes.Buffer
+
+			app := gin.New()
+			app.Use(RequestID())
+			app.GET("/", func(c *gin.Context) {
+				ctxID = c.GetString(RequestIDKey)
+				logger := log.Ctx(c.Request.Context()).Output(&logLine)
+				logger.Info().Msg("handled")
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.requestID != "" {
+				req.Header.Set(RequestIDHeader, tc.requestID)
+			}
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if tc.expectedGenerated {
+				assert.Regexp(t, uuidRegex, id)
+			} else {
+				assert.Equal(t, tc.expectedID, id)
+			}
+			assert.Equal(t, id, ctxID)
+			assert.Contains(t, logLine.String(), `"request_id":"`+id+`"`)
+		})
+	}
+}
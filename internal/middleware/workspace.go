@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+const (
+	// WorkspaceIDKey is the gin context key holding the ID of the workspace of the request, set by RequireWorkspaceRole.
+	WorkspaceIDKey = "workspaceID"
+	// WorkspaceRoleKey is the gin context key holding the role of the user in the workspace of the request, set by RequireWorkspaceRole.
+	WorkspaceRoleKey = "workspaceRole"
+)
+
+// RequireWorkspaceRole returns a gin middleware letting through the members of the workspace in the :workspace path parameter
+// whose role is minRole or above, and storing the workspace and the role under WorkspaceIDKey and WorkspaceRoleKey.
+// Anonymous requests get a 401 and members below minRole a 403.
+// Non-members get a 404, as if the workspace did not exist, so workspace IDs cannot be probed.
+func RequireWorkspaceRole(workspaces service.Workspace, minRole string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString(UserIDKey)
+		if userID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "authentication required"})
+			return
+		}
+
+		id := c.Param("workspace")
+		role, err := workspaces.Role(c.Request.Context(), id, userID)
+		if errors.Is(err, service.ErrNotWorkspaceMember) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "workspace not found"})
+			return
+		}
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Str("workspace", id).Msg("Failed to get workspace role")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+			return
+		}
+		if model.RoleRank(role) < model.RoleRank(minRole) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "insufficient role"})
+			return
+		}
+
+		c.Set(WorkspaceIDKey, id)
+		c.Set(WorkspaceRoleKey, role)
+		c.Next()
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireWorkspaceRole(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+
+		userID    string
+		setupMock func(t *testing.T) *mocks.Workspace
+
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "role above the minimum",
+
+			userID: "alice",
+			setupMock: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("Role", mock.Anything, "ws1", "alice").Return(model.RoleAdmin, nil).Once()
+				return svc
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ws1 admin",
+		},
+		{
+			name: "anonymous -> 401",
+
+			setupMock: func(t *testing.T) *mocks.Workspace {
+				return mocks.NewWorkspace(t)
+			},
+
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"message":"authentication required"}`,
+		},
+		{
+			name: "not a member -> 404",
+
+			userID: "alice",
+			setupMock: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("Role", mock.Anything, "ws1", "alice").Return("", service.ErrNotWorkspaceMember).Once()
+				return svc
+			},
+
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"workspace not found"}`,
+		},
+		{
+			name: "role below the minimum -> 403",
+
+			userID: "alice",
+			setupMock: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("Role", mock.Anything, "ws1", "alice").Return(model.RoleViewer, nil).Once()
+				return svc
+			},
+
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"message":"insufficient role"}`,
+		},
+		{
+			name: "service error -> 500",
+
+			userID: "alice",
+			setupMock: func(t *testing.T) *mocks.Workspace {
+				svc := mocks.NewWorkspace(t)
+				svc.On("Role", mock.Anything, "ws1", "alice").Return("", errors.New("redis down")).Once()
+				return svc
+			},
+
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := gin.New()
+			app.Use(func(c *gin.Context) {
+				if tc.userID != "" {
+					c.Set(UserIDKey, tc.userID)
+				}
+			})
+			app.GET("/v1/workspaces/:workspace", RequireWorkspaceRole(tc.setupMock(t), model.RoleMember), func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString(WorkspaceIDKey)+" "+c.GetString(WorkspaceRoleKey))
+			})
+
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/workspaces/ws1", nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Domain is a custom short domain of a workspace, its codes resolve within the workspace once the domain is verified.
+// Token is the value the workspace publishes in a DNS TXT record to prove it controls the domain.
+type Domain struct {
+	Host       string
+	Workspace  string
+	Token      string
+	Verified   bool
+	CreatedAt  time.Time
+	VerifiedAt time.Time
+}
@@ -0,0 +1,20 @@
+package model
+
+const (
+	KeyspaceStatusOK        = "ok"
+	KeyspaceStatusWarning   = "warning"
+	KeyspaceStatusSaturated = "saturated"
+)
+
+// KeyspaceStats describes how full the keyspace of one code length is.
+// For uniformly random codes the probability that a new code collides equals the fraction of the keyspace in use,
+// so EstimatedOccupancy is the recent collision rate and EstimatedCodes the matching number of stored codes.
+type KeyspaceStats struct {
+	Length             int
+	Capacity           float64
+	Attempts           uint64
+	Collisions         uint64
+	EstimatedOccupancy float64
+	EstimatedCodes     float64
+	Status             string
+}
@@ -0,0 +1,200 @@
+package model
+
+import (
+	"net/http"
+	"net/netip"
+	"time"
+)
+
+// Link is the destination of a code and the options of its redirect.
+// RedirectStatus is one of 301, 302, 307 or 308, 0 stands for 302. Title is an optional label shown on the preview page.
+// Params are query parameters merged into the URL at redirect time, and PassQuery passes the query string of the request
+// to the code through to the destination. Rules are evaluated in order on every redirect, the first one matching the visitor
+// replaces URL, which is the fallback when none does. When no rule matches and the link has Variants, the visitor is
+// assigned one of them instead of URL. Owner is the ID of the user who created the link, empty for anonymous links,
+// and Tags are labels the links of a workspace or an owner can be listed by. ExpiresAt is the time the link expires at
+// when it was read, zero when it does not expire.
+type Link struct {
+	URL            string
+	Owner          string
+	Tags           []string
+	RedirectStatus int
+	Title          string
+	Params         map[string]string
+	PassQuery      bool
+	Rules          []Rule
+	Variants       []Variant
+	ExpiresAt      time.Time
+}
+
+// StatusCode returns the HTTP status of the redirect to the link.
+func (l Link) StatusCode() int {
+	if l.RedirectStatus == 0 {
+		return http.StatusFound
+	}
+	return l.RedirectStatus
+}
+
+// ValidRedirectStatus reports whether status can be used as the redirect status of a link, 0 included.
+func ValidRedirectStatus(status int) bool {
+	switch status {
+	case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// Platforms a rule can match, detected from the User-Agent header of the visitor.
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWindows = "windows"
+	PlatformMacOS   = "macos"
+	PlatformLinux   = "linux"
+)
+
+// Rule sends the visitors matching every one of its conditions to URL, an empty condition matches every visitor.
+// Platforms are Platform* constants, Languages are language tags matched against the preferred language of the visitor,
+// a primary tag like "en" also matching its regions like "en-US", and Countries are ISO 3166-1 alpha-2 codes.
+type Rule struct {
+	URL       string      `json:"url"`
+	Platforms []string    `json:"platforms,omitempty"`
+	Languages []string    `json:"languages,omitempty"`
+	Countries []string    `json:"countries,omitempty"`
+	Time      *TimeWindow `json:"time,omitempty"`
+}
+
+// TimeWindow is a daily time range in the IANA time zone Timezone, UTC when empty.
+// From and To are "HH:MM" times, From is included and To is not, and a window with To before From spans midnight.
+type TimeWindow struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Variant is a destination of an A/B split of a link, it receives Weight shares of the visitors.
+// Name identifies the variant in the click analytics of the link.
+type Variant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// ClickStats counts the redirects of a link, in total and per variant served.
+type ClickStats struct {
+	Total    int64
+	Variants map[string]int64
+}
+
+// Visitor is the client following a link, as seen by the rules and the variants of the link.
+// ID identifies the visitor across requests, so they keep being assigned the same variant.
+type Visitor struct {
+	ID             string
+	UserAgent      string
+	AcceptLanguage string
+	IP             netip.Addr
+}
+
+// ShortenRequest is a link to shorten.
+// Workspace is the namespace of the code, empty for the global one, and Alias is the code wanted instead of a generated one.
+type ShortenRequest struct {
+	Workspace string
+	Alias     string
+	Exp       int
+	Link
+}
+
+// LinkSafety is the outcome of the static checks run on the destination of a link, Warnings is empty when none failed.
+type LinkSafety struct {
+	Warnings []string
+}
+
+// Safe reports whether every check passed.
+func (s LinkSafety) Safe() bool {
+	return len(s.Warnings) == 0
+}
+
+// LinkExpiry is the time a link expires at. Owner is the ID of the user who created the link, so the events of global
+// links can still reach the webhooks of their owner once the link is gone.
+type LinkExpiry struct {
+	Workspace string
+	Owner     string
+	Code      string
+	ExpiresAt time.Time
+}
+
+// Sort orders of the listed links.
+const (
+	LinkSortCreated = "created"
+	LinkSortExpiry  = "expiry"
+	LinkSortClicks  = "clicks"
+)
+
+// Statuses of the listed links, revoked links are the deleted ones kept in their trash.
+const (
+	LinkStatusActive  = "active"
+	LinkStatusExpired = "expired"
+	LinkStatusRevoked = "revoked"
+)
+
+// LinkSummary is a link as listed to its workspace or owner, Host is the lowercase host name of URL.
+// Revoked is set on the links listed from the trash.
+type LinkSummary struct {
+	Workspace string
+	Code      string
+	URL       string
+	Host      string
+	Tags      []string
+	Clicks    int64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Revoked   bool
+}
+
+// Status returns LinkStatusRevoked if the link is revoked, LinkStatusExpired if it expired by now, LinkStatusActive
+// otherwise.
+func (l LinkSummary) Status(now time.Time) string {
+	if l.Revoked {
+		return LinkStatusRevoked
+	}
+	if l.ExpiresAt.After(now) {
+		return LinkStatusActive
+	}
+	return LinkStatusExpired
+}
+
+// LinkQuery selects the links listed to a workspace or an owner, an empty filter matches every link.
+// Sort is a LinkSort* constant, LinkSortCreated when empty: newest first, soonest to expire first or most clicked first.
+// ExpiringWithin keeps the active links expiring within that duration from now, and Cursor resumes after the last link
+// of the previous page. Limit bounds the links of the page.
+type LinkQuery struct {
+	Sort           string
+	Host           string
+	Status         string
+	Tag            string
+	ExpiringWithin time.Duration
+	Cursor         string
+	Limit          int
+}
+
+// LinkPage is a page of listed links, Next is the cursor of the next page, empty on the last one.
+type LinkPage struct {
+	Links []LinkSummary
+	Next  string
+}
+
+// LinkCursor is a position in the links of a workspace or an owner sorted by Sort, right after the link Code whose
+// sort key was Score.
+type LinkCursor struct {
+	Sort  string
+	Score float64
+	Code  string
+}
+
+// TrashedLink is a deleted link kept in the trash of its workspace or owner until it is restored or purged.
+// Owner is the ID of the user who created the link.
+type TrashedLink struct {
+	LinkSummary
+	Owner     string
+	DeletedAt time.Time
+}
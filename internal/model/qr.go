@@ -0,0 +1,24 @@
+package model
+
+// QR code output formats.
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+)
+
+// QR code error correction levels, from the least to the most redundant: about 7%, 15%, 25% and 30% of the code can be
+// damaged and still be read.
+const (
+	QRECCLow      = "L"
+	QRECCMedium   = "M"
+	QRECCQuartile = "Q"
+	QRECCHigh     = "H"
+)
+
+// QRCodeRequest is a QR code to render. Size is the width and height of the image in pixels, quiet zone included.
+type QRCodeRequest struct {
+	Content string
+	Format  string
+	Size    int
+	ECC     string
+}
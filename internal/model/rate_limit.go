@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit allows Limit requests per Period, a zero Limit disables the limit.
+// It decodes from "<limit>/<period>", e.g. "60/1m", so it can be used directly in envconfig structs.
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+}
+
+// RateLimitResult is the outcome of counting one request against a RateLimit.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Window is the period Limit applies to.
+	Window time.Duration
+	// RetryAfter is how long a rejected client has to wait before its next request is allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the client is back to its full quota.
+	Reset time.Duration
+}
+
+// Enabled reports whether the limit is enforced.
+func (r RateLimit) Enabled() bool {
+	return r.Limit > 0
+}
+
+// Decode parses value in the "<limit>/<period>" format, an empty value or "0" disables the limit.
+func (r *RateLimit) Decode(value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		*r = RateLimit{}
+		return nil
+	}
+
+	limitStr, periodStr, ok := strings.Cut(value, "/")
+	if !ok {
+		return fmt.Errorf("invalid rate limit %q, expected <limit>/<period>", value)
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 0 {
+		return fmt.Errorf("invalid rate limit %q, limit must be a non-negative integer", value)
+	}
+	period, err := time.ParseDuration(periodStr)
+	if err != nil || period <= 0 {
+		return fmt.Errorf("invalid rate limit %q, period must be a positive duration", value)
+	}
+
+	*r = RateLimit{Limit: limit, Period: period}
+	return nil
+}
+
+// UnmarshalText is Decode for config files.
+func (r *RateLimit) UnmarshalText(text []byte) error {
+	return r.Decode(string(text))
+}
+
+// MarshalText formats the limit like String.
+func (r RateLimit) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// String formats the limit in the format accepted by Decode.
+func (r RateLimit) String() string {
+	if !r.Enabled() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", r.Limit, r.Period)
+}
+
+// RateLimitPolicy holds the limits of one route.
+// Authenticated clients are counted against User by their ID, anonymous clients against IP by their address.
+type RateLimitPolicy struct {
+	IP   RateLimit
+	User RateLimit
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRateLimit_Decode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		value string
+
+		expected  RateLimit
+		expectErr bool
+	}{
+		{
+			name: "normal case",
+
+			value: "60/1m",
+
+			expected: RateLimit{Limit: 60, Period: time.Minute},
+		},
+		{
+			name: "empty disables",
+
+			value: "",
+
+			expected: RateLimit{},
+		},
+		{
+			name: "zero disables",
+
+			value: "0",
+
+			expected: RateLimit{},
+		},
+		{
+			name: "missing period",
+
+			value:     "60",
+			expectErr: true,
+		},
+		{
+			name: "negative limit",
+
+			value:     "-1/1m",
+			expectErr: true,
+		},
+		{
+			name: "invalid period",
+
+			value:     "60/0s",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var limit RateLimit
+			err := limit.Decode(tc.value)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, limit)
+			assert.Equal(t, tc.expected.Enabled(), limit.Enabled())
+		})
+	}
+}
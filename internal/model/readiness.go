@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+const (
+	CheckStatusOK   = "ok"
+	CheckStatusFail = "fail"
+)
+
+// CheckResult is the outcome of one named readiness checker.
+type CheckResult struct {
+	Name    string
+	Status  string
+	Latency time.Duration
+	Error   string
+}
+
+// ReadinessReport is the outcome of every readiness checker, Status is CheckStatusFail if any of them failed.
+type ReadinessReport struct {
+	Status    string
+	CheckedAt time.Time
+	Checks    []CheckResult
+}
@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+// Events a webhook can subscribe to.
+const (
+	EventLinkCreated  = "link.created"
+	EventLinkClicked  = "link.clicked"
+	EventLinkExpiring = "link.expiring"
+	EventLinkExpired  = "link.expired"
+	EventLinkDeleted  = "link.deleted"
+)
+
+// ValidEvent reports whether event is one of the Event* constants.
+func ValidEvent(event string) bool {
+	switch event {
+	case EventLinkCreated, EventLinkClicked, EventLinkExpiring, EventLinkExpired, EventLinkDeleted:
+		return true
+	}
+	return false
+}
+
+// Webhook is a subscription of an HTTP endpoint to the events of the links of a workspace, or of the global links
+// created by a user when Workspace is empty. Secret is the key the payloads are signed with.
+type Webhook struct {
+	ID        string
+	Workspace string
+	Owner     string
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+}
+
+// Event is something that happened to a link, delivered to the webhooks subscribed to its Type.
+// Variant is the name of the variant served, for click events of links splitting their traffic.
+// ExpiresAt is the time the link expires at, for expiry events.
+// Events are delivered as their JSON encoding.
+type Event struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	OccurredAt time.Time  `json:"occurredAt"`
+	Workspace  string     `json:"workspace,omitempty"`
+	Owner      string     `json:"owner,omitempty"`
+	Code       string     `json:"code"`
+	URL        string     `json:"url,omitempty"`
+	Variant    string     `json:"variant,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Webhook delivery states. A pending delivery is waiting for its next attempt, a dead one exhausted its attempts.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookDelivery is an event queued for a webhook and the outcome of its attempts so far.
+// Payload is the signed request body. LastStatus is the HTTP status of the last attempt, 0 when it got no response.
+type WebhookDelivery struct {
+	ID            string
+	WebhookID     string
+	EventID       string
+	EventType     string
+	Payload       string
+	Status        string
+	Attempts      int
+	LastStatus    int
+	LastError     string
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Workspace roles, from the most to the least privileged.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleViewer = "viewer"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleMember: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// RoleRank returns the privilege level of role, higher is more privileged, and 0 for an unknown role.
+func RoleRank(role string) int {
+	return roleRanks[role]
+}
+
+// ValidRole reports whether role is one of the Role* constants.
+func ValidRole(role string) bool {
+	return RoleRank(role) > 0
+}
+
+// Workspace is a tenant of the service, its links are stored under its own namespace.
+type Workspace struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
+
+// WorkspaceMember is a user of a workspace and their role in it.
+type WorkspaceMember struct {
+	UserID string
+	Role   string
+}
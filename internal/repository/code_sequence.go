@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	codeSequenceKey = "url:seq"
+)
+
+//go:generate mockery --name=CodeSequence --filename code_sequence.go
+type CodeSequence interface {
+	Next(ctx context.Context) (uint64, error)
+}
+
+type codeSequence struct {
+	c redis.UniversalClient
+}
+
+// NewCodeSequence returns a new instance of the codeSequence, which implements the CodeSequence interface.
+// The sequence lives in Redis, so numbers are unique across every instance sharing the same Redis.
+func NewCodeSequence(c redis.UniversalClient) CodeSequence {
+	return &codeSequence{c: c}
+}
+
+// Next increments the sequence and returns the new value, the first value is 1.
+// It returns an error if there is an issue incrementing the sequence.
+func (s *codeSequence) Next(ctx context.Context) (uint64, error) {
+	n, err := s.c.Incr(ctx, codeSequenceKey).Uint64()
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
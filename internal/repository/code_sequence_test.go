@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCodeSequence_Next(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func() *redis.Client
+
+		expectedValues []uint64
+		expectErr      error
+	}{
+		{
+			name: "normal case",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+
+			expectedValues: []uint64{1, 2, 3},
+		},
+		{
+			name: "continues existing sequence",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				require.NoError(t, mock.Set(context.Background(), codeSequenceKey, 41, 0).Err())
+				return mock
+			},
+
+			expectedValues: []uint64{42},
+		},
+		{
+			name: "redis connection error",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				_ = mock.Close()
+				return mock
+			},
+
+			expectedValues: []uint64{0},
+			expectErr:      redis.ErrClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			testRepo := NewCodeSequence(tc.setupMock())
+
+			for _, expected := range tc.expectedValues {
+				n, err := testRepo.Next(ctx)
+				assert.Equal(t, tc.expectErr, err)
+				assert.Equal(t, expected, n)
+			}
+		})
+	}
+}
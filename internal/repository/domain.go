@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	domainKeyPrefix = "domain:"
+
+	domainWorkspaceField  = "workspace"
+	domainTokenField      = "token"
+	domainCreatedAtField  = "created_at"
+	domainVerifiedAtField = "verified_at"
+)
+
+// domainKey returns the hash holding the custom domain host.
+func domainKey(host string) string {
+	return domainKeyPrefix + "{" + host + "}"
+}
+
+// domainClaimsKey returns the hash of the pending claims on the custom domain host by the workspaces not holding it,
+// each workspace ID mapped to "<created at>:<token>".
+func domainClaimsKey(host string) string {
+	return domainKey(host) + ":claims"
+}
+
+// workspaceDomainsKey returns the set of the custom domains of the workspace id.
+func workspaceDomainsKey(id string) string {
+	return workspaceKey(id) + ":domains"
+}
+
+// DomainStorage stores the custom domains of the workspaces.
+//
+//go:generate mockery --name=DomainStorage --filename domain.go
+type DomainStorage interface {
+	Create(ctx context.Context, d model.Domain) (bool, error)
+	Get(ctx context.Context, host string) (model.Domain, error)
+	ListForWorkspace(ctx context.Context, workspace string) ([]string, error)
+	Claim(ctx context.Context, d model.Domain) (bool, error)
+	GetClaim(ctx context.Context, host, workspace string) (model.Domain, error)
+	Verify(ctx context.Context, d model.Domain) (bool, error)
+	Delete(ctx context.Context, workspace, host string) error
+}
+
+// verifyDomainScript makes the workspace ARGV[1] the verified holder of the domain KEYS[1], with the token ARGV[2], created
+// at ARGV[3] and verified at ARGV[4], if the domain is not verified yet and the workspace still holds it with that token,
+// or still claims it in KEYS[2]. It returns the previous holder, "" for none, and nil otherwise.
+var verifyDomainScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], 'verified_at') == 1 then
+	return false
+end
+local holder = redis.call('HGET', KEYS[1], 'workspace')
+if holder == ARGV[1] then
+	if redis.call('HGET', KEYS[1], 'token') ~= ARGV[2] then
+		return false
+	end
+elseif redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[3] .. ':' .. ARGV[2] then
+	return false
+end
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[1], 'workspace', ARGV[1], 'token', ARGV[2], 'created_at', ARGV[3], 'verified_at', ARGV[4])
+redis.call('HDEL', KEYS[2], ARGV[1])
+return holder or ''
+`)
+
+// deleteDomainScript removes the domain KEYS[1] if the workspace ARGV[1] holds it, and its claim in KEYS[2].
+var deleteDomainScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'workspace') == ARGV[1] then
+	redis.call('DEL', KEYS[1])
+end
+redis.call('HDEL', KEYS[2], ARGV[1])
+return 1
+`)
+
+type domainStorage struct {
+	c redis.UniversalClient
+}
+
+// NewDomainStorage returns a new instance of the domainStorage, which implements the DomainStorage interface.
+func NewDomainStorage(c redis.UniversalClient) DomainStorage {
+	return &domainStorage{c: c}
+}
+
+// Create stores d unless its host is already registered, by any workspace, in which case it returns false.
+func (s *domainStorage) Create(ctx context.Context, d model.Domain) (bool, error) {
+	ok, err := s.c.HSetNX(ctx, domainKey(d.Host), domainWorkspaceField, d.Workspace).Result()
+	if err != nil || !ok {
+		return false, err
+	}
+
+	_, err = s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, domainKey(d.Host),
+			domainTokenField, d.Token,
+			domainCreatedAtField, d.CreatedAt.Unix(),
+		)
+		p.HDel(ctx, domainClaimsKey(d.Host), d.Workspace)
+		p.SAdd(ctx, workspaceDomainsKey(d.Workspace), d.Host)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Get returns the custom domain host, and redis.Nil if it is not registered.
+func (s *domainStorage) Get(ctx context.Context, host string) (model.Domain, error) {
+	fields, err := s.c.HGetAll(ctx, domainKey(host)).Result()
+	if err != nil {
+		return model.Domain{}, err
+	}
+	if len(fields) == 0 {
+		return model.Domain{}, redis.Nil
+	}
+
+	d := model.Domain{
+		Host:      host,
+		Workspace: fields[domainWorkspaceField],
+		Token:     fields[domainTokenField],
+		CreatedAt: unixField(fields, domainCreatedAtField),
+	}
+	if _, ok := fields[domainVerifiedAtField]; ok {
+		d.Verified = true
+		d.VerifiedAt = unixField(fields, domainVerifiedAtField)
+	}
+	return d, nil
+}
+
+// ListForWorkspace returns the custom domains of workspace, in no particular order.
+func (s *domainStorage) ListForWorkspace(ctx context.Context, workspace string) ([]string, error) {
+	return s.c.SMembers(ctx, workspaceDomainsKey(workspace)).Result()
+}
+
+// Claim stores the pending claim d on a host held by another workspace, unless d.Workspace already claims it,
+// in which case it returns false. The claim takes the host over once it is verified, see Verify.
+func (s *domainStorage) Claim(ctx context.Context, d model.Domain) (bool, error) {
+	claim := strconv.FormatInt(d.CreatedAt.Unix(), 10) + ":" + d.Token
+	ok, err := s.c.HSetNX(ctx, domainClaimsKey(d.Host), d.Workspace, claim).Result()
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := s.c.SAdd(ctx, workspaceDomainsKey(d.Workspace), d.Host).Err(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetClaim returns the pending claim of workspace on host, and redis.Nil if it has none.
+func (s *domainStorage) GetClaim(ctx context.Context, host, workspace string) (model.Domain, error) {
+	claim, err := s.c.HGet(ctx, domainClaimsKey(host), workspace).Result()
+	if err != nil {
+		return model.Domain{}, err
+	}
+	createdAt, token, _ := strings.Cut(claim, ":")
+	return model.Domain{
+		Host:      host,
+		Workspace: workspace,
+		Token:     token,
+		CreatedAt: unixTime(createdAt),
+	}, nil
+}
+
+// Verify makes d, held or claimed by d.Workspace, the verified holder of its host, verified at d.VerifiedAt, and drops
+// the host from the domains of the workspace holding it until then.
+// It returns false if the host was verified by another workspace first, or if d is no longer held or claimed.
+func (s *domainStorage) Verify(ctx context.Context, d model.Domain) (bool, error) {
+	previous, err := verifyDomainScript.Run(ctx, s.c, []string{domainKey(d.Host), domainClaimsKey(d.Host)},
+		d.Workspace, d.Token, d.CreatedAt.Unix(), d.VerifiedAt.Unix()).Text()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if previous != "" && previous != d.Workspace {
+		if err := s.c.SRem(ctx, workspaceDomainsKey(previous), d.Host).Err(); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// Delete removes the custom domain host of workspace, whether workspace holds it or only claims it.
+func (s *domainStorage) Delete(ctx context.Context, workspace, host string) error {
+	if err := deleteDomainScript.Run(ctx, s.c, []string{domainKey(host), domainClaimsKey(host)}, workspace).Err(); err != nil {
+		return err
+	}
+	return s.c.SRem(ctx, workspaceDomainsKey(workspace), host).Err()
+}
+
+// unixField returns the time of the Unix timestamp in fields[name], the zero time if it is missing.
+func unixField(fields map[string]string, name string) time.Time {
+	return unixTime(fields[name])
+}
+
+// unixTime returns the time of the Unix timestamp s, the zero time if it is not one.
+func unixTime(s string) time.Time {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0).UTC()
+}
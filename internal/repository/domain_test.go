@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDomainStorage_Create(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func() *redis.Client
+
+		expectOK  bool
+		expectErr error
+	}{
+		{
+			name: "normal case",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+
+			expectOK: true,
+		},
+		{
+			name: "host registered by another workspace",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				require.NoError(t, mock.HSet(context.Background(), "domain:{go.team.io}", "workspace", "ws2").Err())
+				return mock
+			},
+
+			expectOK: false,
+		},
+		{
+			name: "redis connection error",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				_ = mock.Close()
+				return mock
+			},
+
+			expectErr: redis.ErrClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			testRepo := NewDomainStorage(tc.setupMock())
+
+			ok, err := testRepo.Create(ctx, model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok", CreatedAt: time.Unix(1700000000, 0)})
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectOK, ok)
+		})
+	}
+}
+
+func TestDomainStorage_Get(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setup func(ctx context.Context, r DomainStorage)
+
+		expected  model.Domain
+		expectErr error
+	}{
+		{
+			name: "pending verification",
+
+			setup: func(ctx context.Context, r DomainStorage) {
+				_, err := r.Create(ctx, model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok", CreatedAt: time.Unix(1700000000, 0)})
+				require.NoError(t, err)
+			},
+
+			expected: model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok", CreatedAt: time.Unix(1700000000, 0).UTC()},
+		},
+		{
+			name: "verified",
+
+			setup: func(ctx context.Context, r DomainStorage) {
+				_, err := r.Create(ctx, model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok", CreatedAt: time.Unix(1700000000, 0)})
+				require.NoError(t, err)
+				ok, err := r.Verify(ctx, model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok", CreatedAt: time.Unix(1700000000, 0), VerifiedAt: time.Unix(1700000100, 0)})
+				require.NoError(t, err)
+				require.True(t, ok)
+			},
+
+			expected: model.Domain{
+				Host:       "go.team.io",
+				Workspace:  "ws1",
+				Token:      "tok",
+				Verified:   true,
+				CreatedAt:  time.Unix(1700000000, 0).UTC(),
+				VerifiedAt: time.Unix(1700000100, 0).UTC(),
+			},
+		},
+		{
+			name: "deleted",
+
+			setup: func(ctx context.Context, r DomainStorage) {
+				_, err := r.Create(ctx, model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok"})
+				require.NoError(t, err)
+				require.NoError(t, r.Delete(ctx, "ws1", "go.team.io"))
+
+				hosts, err := r.ListForWorkspace(ctx, "ws1")
+				require.NoError(t, err)
+				assert.Empty(t, hosts)
+			},
+
+			expectErr: redis.Nil,
+		},
+		{
+			name: "not registered",
+
+			setup: func(ctx context.Context, r DomainStorage) {},
+
+			expectErr: redis.Nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			testRepo := NewDomainStorage(redisPkg.InitMockRedis(t))
+			tc.setup(ctx, testRepo)
+
+			d, err := testRepo.Get(ctx, "go.team.io")
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expected, d)
+		})
+	}
+}
+
+func TestDomainStorage_Verify(t *testing.T) {
+	t.Parallel()
+
+	created := time.Unix(1700000000, 0)
+	verified := time.Unix(1700000100, 0)
+	squat := model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok1", CreatedAt: created}
+	claim := model.Domain{Host: "go.team.io", Workspace: "ws2", Token: "tok2", CreatedAt: created.Add(time.Hour)}
+
+	testCases := []struct {
+		name string
+
+		setup  func(ctx context.Context, r DomainStorage)
+		verify model.Domain
+
+		expectOK    bool
+		expected    model.Domain
+		expectedWS1 []string
+		expectedWS2 []string
+	}{
+		{
+			name: "holder",
+
+			setup:  func(ctx context.Context, r DomainStorage) {},
+			verify: squat,
+
+			expectOK:    true,
+			expected:    model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok1", Verified: true, CreatedAt: created.UTC(), VerifiedAt: verified.UTC()},
+			expectedWS1: []string{"go.team.io"},
+			expectedWS2: []string{"go.team.io"},
+		},
+		{
+			name: "claim takes the host over",
+
+			setup:  func(ctx context.Context, r DomainStorage) {},
+			verify: claim,
+
+			expectOK:    true,
+			expected:    model.Domain{Host: "go.team.io", Workspace: "ws2", Token: "tok2", Verified: true, CreatedAt: created.Add(time.Hour).UTC(), VerifiedAt: verified.UTC()},
+			expectedWS1: []string{},
+			expectedWS2: []string{"go.team.io"},
+		},
+		{
+			name: "host verified by another workspace first",
+
+			setup: func(ctx context.Context, r DomainStorage) {
+				ok, err := r.Verify(ctx, model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok1", CreatedAt: created, VerifiedAt: created})
+				require.NoError(t, err)
+				require.True(t, ok)
+			},
+			verify: claim,
+
+			expected:    model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok1", Verified: true, CreatedAt: created.UTC(), VerifiedAt: created.UTC()},
+			expectedWS1: []string{"go.team.io"},
+			expectedWS2: []string{"go.team.io"},
+		},
+		{
+			name: "claim deleted",
+
+			setup: func(ctx context.Context, r DomainStorage) {
+				require.NoError(t, r.Delete(ctx, "ws2", "go.team.io"))
+			},
+			verify: claim,
+
+			expected:    model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok1", CreatedAt: created.UTC()},
+			expectedWS1: []string{"go.team.io"},
+			expectedWS2: []string{},
+		},
+		{
+			name: "holder deleted, claim still verifies",
+
+			setup: func(ctx context.Context, r DomainStorage) {
+				require.NoError(t, r.Delete(ctx, "ws1", "go.team.io"))
+			},
+			verify: claim,
+
+			expectOK:    true,
+			expected:    model.Domain{Host: "go.team.io", Workspace: "ws2", Token: "tok2", Verified: true, CreatedAt: created.Add(time.Hour).UTC(), VerifiedAt: verified.UTC()},
+			expectedWS1: []string{},
+			expectedWS2: []string{"go.team.io"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			testRepo := NewDomainStorage(redisPkg.InitMockRedis(t))
+			ok, err := testRepo.Create(ctx, squat)
+			require.NoError(t, err)
+			require.True(t, ok)
+			ok, err = testRepo.Claim(ctx, claim)
+			require.NoError(t, err)
+			require.True(t, ok)
+			tc.setup(ctx, testRepo)
+
+			tc.verify.VerifiedAt = verified
+			ok, err = testRepo.Verify(ctx, tc.verify)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectOK, ok)
+
+			d, err := testRepo.Get(ctx, "go.team.io")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, d)
+			for workspace, expected := range map[string][]string{"ws1": tc.expectedWS1, "ws2": tc.expectedWS2} {
+				hosts, err := testRepo.ListForWorkspace(ctx, workspace)
+				require.NoError(t, err)
+				assert.Equal(t, expected, hosts, workspace)
+			}
+			if tc.expectOK && tc.verify.Workspace == "ws2" {
+				_, err = testRepo.GetClaim(ctx, "go.team.io", "ws2")
+				assert.Equal(t, redis.Nil, err, "the claim is consumed")
+			}
+		})
+	}
+}
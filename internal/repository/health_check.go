@@ -3,23 +3,30 @@ package repository
 import (
 	"context"
 	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+const (
+	healthProbeKeyPrefix = "health:probe:"
+	healthProbeTTL       = 10 * time.Second
 )
 
 //go:generate mockery --name=HealthCheck --filename health_check.go
 type HealthCheck interface {
 	Ping(ctx context.Context) error
+	WriteProbe(ctx context.Context, instanceID string) error
 }
 
 type healthCheck struct {
-	redis *redis.Client
+	redis redis.UniversalClient
 }
 
 // NewHealthCheck returns a new instance of the healthCheck, which implements the HealthCheck interface.
-// It takes a single parameter, redis, which is a pointer to a redis.Client.
+// It takes a single parameter, redis, which is a redis.UniversalClient.
 // The returned healthCheck is used to ping the redis server to check if it is reachable.
 // If an error occurs while generating the healthCheck, the error is returned immediately and the generated healthCheck is an empty string.
 // The character set used for generating the healthCheck response is constant and does not change across different implementations of the interface. The length of the generated healthCheck response is constant and does not change across different implementations of the interface.
-func NewHealthCheck(redis *redis.Client) HealthCheck {
+func NewHealthCheck(redis redis.UniversalClient) HealthCheck {
 	return &healthCheck{redis: redis}
 }
 
@@ -32,3 +39,9 @@ func NewHealthCheck(redis *redis.Client) HealthCheck {
 func (r *healthCheck) Ping(ctx context.Context) error {
 	return r.redis.Ping(ctx).Err()
 }
+
+// WriteProbe writes a short-lived key owned by instanceID, to check that Redis accepts writes.
+// A replica or a server out of memory answers Ping but rejects the write.
+func (r *healthCheck) WriteProbe(ctx context.Context, instanceID string) error {
+	return r.redis.Set(ctx, healthProbeKeyPrefix+instanceID, time.Now().Unix(), healthProbeTTL).Err()
+}
@@ -53,3 +53,54 @@ func TestHealthCheck_Ping(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthCheck_WriteProbe(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func() *redis.Client
+
+		expectErr error
+	}{
+		{
+			name: "normal case",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+		},
+		{
+			name: "redis connection error",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				_ = mock.Close()
+				return mock
+			},
+
+			expectErr: redis.ErrClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			redisMock := tc.setupMock()
+			testRepo := NewHealthCheck(redisMock)
+
+			err := testRepo.WriteProbe(ctx, "2025")
+			assert.Equal(t, tc.expectErr, err)
+			if err != nil {
+				return
+			}
+
+			ttl, err := redisMock.TTL(ctx, healthProbeKeyPrefix+"2025").Result()
+			assert.NoError(t, err)
+			assert.Equal(t, healthProbeTTL, ttl)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyspaceLengthKey = "keyspace:length"
+)
+
+// raiseKeyspaceLengthScript stores ARGV[1] in KEYS[1] unless a greater length is stored, and returns the stored length.
+var raiseKeyspaceLengthScript = redis.NewScript(`
+local length = tonumber(ARGV[1])
+local current = tonumber(redis.call('GET', KEYS[1]) or 0)
+if current >= length then
+	return current
+end
+redis.call('SET', KEYS[1], ARGV[1])
+return length
+`)
+
+//go:generate mockery --name=KeyspaceLength --filename keyspace_length.go
+type KeyspaceLength interface {
+	Get(ctx context.Context) (int, error)
+	Raise(ctx context.Context, length int) (int, error)
+}
+
+type keyspaceLength struct {
+	c redis.UniversalClient
+}
+
+// NewKeyspaceLength returns a new instance of the keyspaceLength, which implements the KeyspaceLength interface.
+// The length lives in Redis without expiry, so it survives restarts and is shared by every instance.
+func NewKeyspaceLength(c redis.UniversalClient) KeyspaceLength {
+	return &keyspaceLength{c: c}
+}
+
+// Get returns the stored length of new codes, 0 if none was stored yet.
+func (s *keyspaceLength) Get(ctx context.Context) (int, error) {
+	length, err := s.c.Get(ctx, keyspaceLengthKey).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return length, err
+}
+
+// Raise stores length unless a greater length is already stored, the length never shrinks.
+// It returns the stored length.
+func (s *keyspaceLength) Raise(ctx context.Context, length int) (int, error) {
+	return raiseKeyspaceLengthScript.Run(ctx, s.c, []string{keyspaceLengthKey}, length).Int()
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestKeyspaceLength_Raise(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func() *redis.Client
+		raiseTo   []int
+
+		expectedLengths []int
+		expectedStored  int
+		expectErr       error
+	}{
+		{
+			name: "nothing stored",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+
+			expectedStored: 0,
+		},
+		{
+			name: "length only grows",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+			raiseTo: []int{8, 9, 8},
+
+			expectedLengths: []int{8, 9, 9},
+			expectedStored:  9,
+		},
+		{
+			name: "length stored by another instance",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				require.NoError(t, mock.Set(context.Background(), keyspaceLengthKey, 10, 0).Err())
+				return mock
+			},
+			raiseTo: []int{8},
+
+			expectedLengths: []int{10},
+			expectedStored:  10,
+		},
+		{
+			name: "redis connection error",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				_ = mock.Close()
+				return mock
+			},
+
+			expectErr: redis.ErrClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			testRepo := NewKeyspaceLength(tc.setupMock())
+
+			for i, length := range tc.raiseTo {
+				stored, err := testRepo.Raise(ctx, length)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedLengths[i], stored)
+			}
+
+			stored, err := testRepo.Get(ctx)
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectedStored, stored)
+		})
+	}
+}
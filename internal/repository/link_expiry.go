@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+const (
+	// linkExpiryKey schedules the expiry of every link with a workspace or an owner, linkExpiryDueKey the ones whose
+	// expiry notice was popped. The shared hash tag lets a script move links from one to the other.
+	linkExpiryKey    = "links:{expiry}"
+	linkExpiryDueKey = "links:{expiry}:due"
+)
+
+// popLinkExpiryScript pops the links expiring by a time.
+// KEYS[1] is the sorted set to pop from and KEYS[2], if any, the one the popped links are moved to with their score.
+// ARGV[1] is the time in Unix milliseconds and ARGV[2] the maximum number of links to pop.
+// It returns the popped members and scores, interleaved.
+var popLinkExpiryScript = redis.NewScript(`
+local items = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'WITHSCORES', 'LIMIT', 0, ARGV[2])
+for i = 1, #items, 2 do
+	redis.call('ZREM', KEYS[1], items[i])
+	if KEYS[2] then
+		redis.call('ZADD', KEYS[2], items[i + 1], items[i])
+	end
+end
+return items
+`)
+
+// linkExpiryMember is the member identifying a link in the schedules shared by the instances, of its expiry or purge.
+type linkExpiryMember struct {
+	Workspace string `json:"w,omitempty"`
+	Owner     string `json:"o,omitempty"`
+	Code      string `json:"c"`
+}
+
+// LinkExpiryIndex schedules the expiry events of the links, since Redis does not notify expiries reliably.
+//
+//go:generate mockery --name=LinkExpiryIndex --filename link_expiry.go
+type LinkExpiryIndex interface {
+	Add(ctx context.Context, expiry model.LinkExpiry) error
+	Remove(ctx context.Context, expiry model.LinkExpiry) error
+	PopExpiring(ctx context.Context, until time.Time, limit int) ([]model.LinkExpiry, error)
+	PopExpired(ctx context.Context, now time.Time, limit int) ([]model.LinkExpiry, error)
+}
+
+type linkExpiryIndex struct {
+	c redis.UniversalClient
+}
+
+// NewLinkExpiryIndex returns a new instance of the linkExpiryIndex, which implements the LinkExpiryIndex interface.
+// Links are scheduled in a sorted set shared by every instance popping their expiry notices then their expiries.
+// Anonymous global links are not scheduled.
+func NewLinkExpiryIndex(c redis.UniversalClient) LinkExpiryIndex {
+	return &linkExpiryIndex{c: c}
+}
+
+// Add schedules the expiry of a link, a link with no workspace and no owner is skipped.
+func (s *linkExpiryIndex) Add(ctx context.Context, expiry model.LinkExpiry) error {
+	if expiry.Workspace == "" && expiry.Owner == "" {
+		return nil
+	}
+	member, err := json.Marshal(linkExpiryMember{Workspace: expiry.Workspace, Owner: expiry.Owner, Code: expiry.Code})
+	if err != nil {
+		return err
+	}
+	return s.c.ZAdd(ctx, linkExpiryKey, redis.Z{Score: float64(expiry.ExpiresAt.UnixMilli()), Member: member}).Err()
+}
+
+// Remove cancels the expiry events of a link, its ExpiresAt is ignored.
+func (s *linkExpiryIndex) Remove(ctx context.Context, expiry model.LinkExpiry) error {
+	member, err := json.Marshal(linkExpiryMember{Workspace: expiry.Workspace, Owner: expiry.Owner, Code: expiry.Code})
+	if err != nil {
+		return err
+	}
+	_, err = s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZRem(ctx, linkExpiryKey, member)
+		p.ZRem(ctx, linkExpiryDueKey, member)
+		return nil
+	})
+	return err
+}
+
+// PopExpiring pops up to limit links expiring by until from the schedule, soonest first, and keeps them for PopExpired.
+// Every link is popped once across the instances.
+func (s *linkExpiryIndex) PopExpiring(ctx context.Context, until time.Time, limit int) ([]model.LinkExpiry, error) {
+	return s.pop(ctx, []string{linkExpiryKey, linkExpiryDueKey}, until, limit)
+}
+
+// PopExpired pops up to limit links expired by now whose expiry notice was popped, soonest first.
+// Every link is popped once across the instances.
+func (s *linkExpiryIndex) PopExpired(ctx context.Context, now time.Time, limit int) ([]model.LinkExpiry, error) {
+	return s.pop(ctx, []string{linkExpiryDueKey}, now, limit)
+}
+
+// pop runs popLinkExpiryScript on keys and decodes the popped links.
+func (s *linkExpiryIndex) pop(ctx context.Context, keys []string, until time.Time, limit int) ([]model.LinkExpiry, error) {
+	items, err := popLinkExpiryScript.Run(ctx, s.c, keys, until.UnixMilli(), limit).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	expiries := make([]model.LinkExpiry, 0, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		var member linkExpiryMember
+		if err := json.Unmarshal([]byte(items[i]), &member); err != nil {
+			return nil, fmt.Errorf("decode link expiry %q: %w", items[i], err)
+		}
+		ms, err := strconv.ParseFloat(items[i+1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("decode link expiry score %q: %w", items[i+1], err)
+		}
+		expiries = append(expiries, model.LinkExpiry{
+			Workspace: member.Workspace,
+			Owner:     member.Owner,
+			Code:      member.Code,
+			ExpiresAt: time.UnixMilli(int64(ms)).UTC(),
+		})
+	}
+	return expiries, nil
+}
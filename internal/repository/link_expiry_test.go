@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/lhducc/bookmark-management/internal/model"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLinkExpiryIndex_Pop(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	now := time.UnixMilli(1700000000000).UTC()
+	testRepo := NewLinkExpiryIndex(redisPkg.InitMockRedis(t))
+
+	soon := model.LinkExpiry{Workspace: "ws1", Owner: "u1", Code: "soon", ExpiresAt: now.Add(time.Hour)}
+	later := model.LinkExpiry{Owner: "u1", Code: "later", ExpiresAt: now.Add(72 * time.Hour)}
+	require.NoError(t, testRepo.Add(ctx, later))
+	require.NoError(t, testRepo.Add(ctx, soon))
+
+	popped, err := testRepo.PopExpiring(ctx, now.Add(24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.LinkExpiry{soon}, popped)
+	popped, err = testRepo.PopExpiring(ctx, now.Add(24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, popped, "a link is popped once")
+
+	popped, err = testRepo.PopExpired(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, popped)
+	popped, err = testRepo.PopExpired(ctx, soon.ExpiresAt, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.LinkExpiry{soon}, popped)
+}
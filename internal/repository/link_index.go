@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+const (
+	linkIndexSuffix = ":links"
+	// linkPruneBatch bounds the links removed from an index at once by Prune.
+	linkPruneBatch = 1000
+)
+
+// ErrUnknownLinkSort is returned when links are scanned in an order that is not a model.LinkSort* constant.
+var ErrUnknownLinkSort = errors.New("unknown link sort")
+
+// pruneLinkIndexScript removes the links expired by a time from an index.
+// KEYS[1] is the hash of the links, KEYS[2] the sorted set scoring them by expiry and the rest the other sorted sets.
+// ARGV[1] is the time in Unix milliseconds and ARGV[2] the maximum number of links to remove.
+// It returns the number of links removed.
+var pruneLinkIndexScript = redis.NewScript(`
+local codes = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, code in ipairs(codes) do
+	for i = 1, #KEYS do
+		if i == 1 then
+			redis.call('HDEL', KEYS[i], code)
+		else
+			redis.call('ZREM', KEYS[i], code)
+		end
+	end
+end
+return #codes
+`)
+
+// scanSortedScript reads the entries of a sorted set following a cursor, with their scores.
+// KEYS[1] is the sorted set, ARGV[1] the order, asc or desc, ARGV[2] the score and ARGV[3] the member of the cursor, an
+// empty member starting from the first entry, and ARGV[4] the maximum number of entries to read.
+// Entries tied on a score are ordered by member in the same direction, so the first one past the cursor is found by a
+// binary search over the ranks of the ties, whether the member of the cursor is still there or not.
+var scanSortedScript = redis.NewScript(`
+local desc = ARGV[1] == 'desc'
+local range = 'ZRANGE'
+if desc then
+	range = 'ZREVRANGE'
+end
+
+local start = 0
+if ARGV[3] ~= '' then
+	local lo, hi
+	if desc then
+		lo = redis.call('ZCOUNT', KEYS[1], '(' .. ARGV[2], '+inf')
+		hi = redis.call('ZCOUNT', KEYS[1], ARGV[2], '+inf')
+	else
+		lo = redis.call('ZCOUNT', KEYS[1], '-inf', '(' .. ARGV[2])
+		hi = redis.call('ZCOUNT', KEYS[1], '-inf', ARGV[2])
+	end
+	while lo < hi do
+		local mid = math.floor((lo + hi) / 2)
+		local member = redis.call(range, KEYS[1], mid, mid)[1]
+		if (desc and member < ARGV[3]) or (not desc and member > ARGV[3]) then
+			hi = mid
+		else
+			lo = mid + 1
+		end
+	end
+	start = lo
+end
+return redis.call(range, KEYS[1], start, start + tonumber(ARGV[4]) - 1, 'WITHSCORES')
+`)
+
+// linkRecord is a link as stored in the hash of an index, times are in Unix milliseconds and 0 when unknown.
+// Trashed links also keep their owner, their clicks and the time they were deleted at.
+type linkRecord struct {
+	URL       string   `json:"u"`
+	Host      string   `json:"h"`
+	Tags      []string `json:"t,omitempty"`
+	CreatedAt int64    `json:"c"`
+	ExpiresAt int64    `json:"e"`
+	Owner     string   `json:"o,omitempty"`
+	Clicks    int64    `json:"n,omitempty"`
+	DeletedAt int64    `json:"d,omitempty"`
+}
+
+// newLinkRecord returns the record of link.
+func newLinkRecord(link model.LinkSummary) linkRecord {
+	return linkRecord{
+		URL:       link.URL,
+		Host:      link.Host,
+		Tags:      link.Tags,
+		CreatedAt: link.CreatedAt.UnixMilli(),
+		ExpiresAt: link.ExpiresAt.UnixMilli(),
+	}
+}
+
+// summary returns the link of record, stored under code in workspace.
+func (r linkRecord) summary(workspace, code string) model.LinkSummary {
+	return model.LinkSummary{
+		Workspace: workspace,
+		Code:      code,
+		URL:       r.URL,
+		Host:      r.Host,
+		Tags:      r.Tags,
+		Clicks:    r.Clicks,
+		CreatedAt: recordTime(r.CreatedAt),
+		ExpiresAt: recordTime(r.ExpiresAt),
+	}
+}
+
+// recordTime returns the time of ms, Unix milliseconds of a linkRecord, and the zero time for 0.
+func recordTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// linkScopeKey returns the prefix of the keys of workspace, or of the global links of owner when workspace is empty.
+// It ends with a hash tag, so every key of the scope lands in the same slot and scripts can use them together.
+func linkScopeKey(workspace, owner string) string {
+	if workspace != "" {
+		return workspaceKey(workspace)
+	}
+	return userWorkspacesKeyPrefix + "{" + owner + "}"
+}
+
+// linkIndexKey returns the hash of the links of workspace, or of the global links of owner when workspace is empty,
+// by code. The sorted sets ordering them share its prefix.
+func linkIndexKey(workspace, owner string) string {
+	return linkScopeKey(workspace, owner) + linkIndexSuffix
+}
+
+// linkSortKey returns the sorted set of the codes of workspace, or of the global codes of owner when workspace is empty,
+// scored by sort.
+func linkSortKey(workspace, owner, sort string) string {
+	return linkIndexKey(workspace, owner) + ":" + sort
+}
+
+// LinkIndex indexes the links of every workspace and owner, so they can be listed in several orders.
+//
+//go:generate mockery --name=LinkIndex --filename link_index.go
+type LinkIndex interface {
+	Add(ctx context.Context, workspace, owner string, link model.LinkSummary) error
+	IncrClicks(ctx context.Context, workspace, owner, code string) error
+	Scan(ctx context.Context, workspace, owner string, after model.LinkCursor, limit int) ([]model.LinkSummary, error)
+	Prune(ctx context.Context, workspace, owner string, expiredBy time.Time) error
+}
+
+type linkIndex struct {
+	c redis.UniversalClient
+}
+
+// NewLinkIndex returns a new instance of the linkIndex, which implements the LinkIndex interface.
+// Every workspace and owner has a hash of its links and a sorted set per model.LinkSort* order, all in the same slot.
+// Anonymous global links are not indexed.
+func NewLinkIndex(c redis.UniversalClient) LinkIndex {
+	return &linkIndex{c: c}
+}
+
+// Add indexes link in workspace, or among the global links of owner when workspace is empty, with no clicks.
+// A link with no workspace and no owner is skipped.
+func (s *linkIndex) Add(ctx context.Context, workspace, owner string, link model.LinkSummary) error {
+	if workspace == "" && owner == "" {
+		return nil
+	}
+	_, err := s.c.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		return addIndexedLink(ctx, p, workspace, owner, link.Code, newLinkRecord(link))
+	})
+	return err
+}
+
+// addIndexedLink queues the commands indexing the link code with record on p, the clicks of record go to their
+// sorted set only.
+func addIndexedLink(ctx context.Context, p redis.Pipeliner, workspace, owner, code string, record linkRecord) error {
+	raw, err := json.Marshal(linkRecord{
+		URL:       record.URL,
+		Host:      record.Host,
+		Tags:      record.Tags,
+		CreatedAt: record.CreatedAt,
+		ExpiresAt: record.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	p.HSet(ctx, linkIndexKey(workspace, owner), code, raw)
+	p.ZAdd(ctx, linkSortKey(workspace, owner, model.LinkSortCreated), redis.Z{Score: float64(record.CreatedAt), Member: code})
+	p.ZAdd(ctx, linkSortKey(workspace, owner, model.LinkSortExpiry), redis.Z{Score: float64(record.ExpiresAt), Member: code})
+	p.ZAdd(ctx, linkSortKey(workspace, owner, model.LinkSortClicks), redis.Z{Score: float64(record.Clicks), Member: code})
+	return nil
+}
+
+// removeIndexedLink queues the commands removing the link code from the index on p.
+func removeIndexedLink(ctx context.Context, p redis.Pipeliner, workspace, owner, code string) {
+	p.HDel(ctx, linkIndexKey(workspace, owner), code)
+	for _, sort := range []string{model.LinkSortCreated, model.LinkSortExpiry, model.LinkSortClicks} {
+		p.ZRem(ctx, linkSortKey(workspace, owner, sort), code)
+	}
+}
+
+// IncrClicks counts a redirect of code in workspace, or among the global links of owner when workspace is empty.
+// Codes missing from the index are skipped.
+func (s *linkIndex) IncrClicks(ctx context.Context, workspace, owner, code string) error {
+	if workspace == "" && owner == "" {
+		return nil
+	}
+	err := s.c.ZAddArgsIncr(ctx, linkSortKey(workspace, owner, model.LinkSortClicks), redis.ZAddArgs{
+		XX:      true,
+		Members: []redis.Z{{Score: 1, Member: code}},
+	}).Err()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// Scan returns up to limit links of workspace, or global links of owner when workspace is empty, following after in the
+// order after.Sort: newest first, soonest to expire first or most clicked first, ties by code in the same direction.
+// An after without code starts from the first link.
+// It returns ErrUnknownLinkSort if after.Sort is not a model.LinkSort* constant.
+func (s *linkIndex) Scan(ctx context.Context, workspace, owner string, after model.LinkCursor, limit int) ([]model.LinkSummary, error) {
+	var desc bool
+	switch after.Sort {
+	case model.LinkSortCreated, model.LinkSortClicks:
+		desc = true
+	case model.LinkSortExpiry:
+	default:
+		return nil, ErrUnknownLinkSort
+	}
+
+	entries, err := scanSorted(ctx, s.c, linkSortKey(workspace, owner, after.Sort), after, desc, limit)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return s.load(ctx, workspace, owner, after.Sort, entries)
+}
+
+// scanSorted returns up to limit entries of the sorted set key following after, in ascending or descending order,
+// in a single read. Entries tied with after come in the order of their code, ascending or descending as well.
+func scanSorted(ctx context.Context, c redis.UniversalClient, key string, after model.LinkCursor, desc bool, limit int) ([]redis.Z, error) {
+	order := "asc"
+	if desc {
+		order = "desc"
+	}
+	items, err := scanSortedScript.Run(ctx, c, []string{key}, order, strconv.FormatFloat(after.Score, 'f', -1, 64), after.Code, limit).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]redis.Z, 0, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		score, err := strconv.ParseFloat(items[i+1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("decode score %q of %s: %w", items[i+1], items[i], err)
+		}
+		entries = append(entries, redis.Z{Score: score, Member: items[i]})
+	}
+	return entries, nil
+}
+
+// load returns the links of entries, entries of the sorted set scoring them by sort, along with their clicks.
+// Links removed since they were scanned are skipped.
+func (s *linkIndex) load(ctx context.Context, workspace, owner, sort string, entries []redis.Z) ([]model.LinkSummary, error) {
+	codes := make([]string, 0, len(entries))
+	for _, z := range entries {
+		codes = append(codes, z.Member.(string))
+	}
+
+	var records *redis.SliceCmd
+	clicks := make([]*redis.FloatCmd, 0, len(codes))
+	_, err := s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		records = p.HMGet(ctx, linkIndexKey(workspace, owner), codes...)
+		if sort != model.LinkSortClicks {
+			for _, code := range codes {
+				clicks = append(clicks, p.ZScore(ctx, linkSortKey(workspace, owner, model.LinkSortClicks), code))
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	links := make([]model.LinkSummary, 0, len(entries))
+	for i, value := range records.Val() {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var record linkRecord
+		if err := json.Unmarshal([]byte(raw), &record); err != nil {
+			return nil, fmt.Errorf("decode indexed link %s: %w", codes[i], err)
+		}
+		link := record.summary(workspace, codes[i])
+		if sort == model.LinkSortClicks {
+			link.Clicks = int64(entries[i].Score)
+		} else {
+			link.Clicks = int64(clicks[i].Val())
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// Prune removes the links of workspace, or the global links of owner when workspace is empty, expired by expiredBy
+// from the index.
+func (s *linkIndex) Prune(ctx context.Context, workspace, owner string, expiredBy time.Time) error {
+	keys := []string{
+		linkIndexKey(workspace, owner),
+		linkSortKey(workspace, owner, model.LinkSortExpiry),
+		linkSortKey(workspace, owner, model.LinkSortCreated),
+		linkSortKey(workspace, owner, model.LinkSortClicks),
+	}
+	for {
+		removed, err := pruneLinkIndexScript.Run(ctx, s.c, keys, expiredBy.UnixMilli(), linkPruneBatch).Int()
+		if err != nil || removed < linkPruneBatch {
+			return err
+		}
+	}
+}
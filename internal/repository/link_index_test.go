@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/lhducc/bookmark-management/internal/model"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLinkIndex_Scan(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMilli(1700000000000).UTC()
+
+	testCases := []struct {
+		name string
+
+		workspace string
+		owner     string
+		after     model.LinkCursor
+		limit     int
+
+		expectCodes []string
+		expectErr   error
+	}{
+		{
+			name: "newest first",
+
+			owner: "u1",
+			after: model.LinkCursor{Sort: model.LinkSortCreated},
+			limit: 10,
+
+			expectCodes: []string{"c", "b", "a", "d"},
+		},
+		{
+			name: "newest first, ties by code after the cursor",
+
+			owner: "u1",
+			after: model.LinkCursor{Sort: model.LinkSortCreated, Score: float64(now.Add(-time.Hour).UnixMilli()), Code: "b"},
+			limit: 10,
+
+			expectCodes: []string{"a", "d"},
+		},
+		{
+			name: "soonest to expire first",
+
+			owner: "u1",
+			after: model.LinkCursor{Sort: model.LinkSortExpiry},
+			limit: 2,
+
+			expectCodes: []string{"d", "a"},
+		},
+		{
+			name: "soonest to expire first after the cursor",
+
+			owner: "u1",
+			after: model.LinkCursor{Sort: model.LinkSortExpiry, Score: float64(now.Add(time.Hour).UnixMilli()), Code: "a"},
+			limit: 10,
+
+			expectCodes: []string{"b", "c"},
+		},
+		{
+			name: "most clicked first",
+
+			owner: "u1",
+			after: model.LinkCursor{Sort: model.LinkSortClicks},
+			limit: 10,
+
+			expectCodes: []string{"b", "d", "a", "c"},
+		},
+		{
+			name: "soonest to expire first, ties by code after the cursor",
+
+			owner: "u1",
+			after: model.LinkCursor{Sort: model.LinkSortExpiry, Score: float64(now.Add(2 * time.Hour).UnixMilli()), Code: "b"},
+			limit: 10,
+
+			expectCodes: []string{"c"},
+		},
+		{
+			name: "most clicked first after a cursor whose link left the ties",
+
+			owner: "u1",
+			after: model.LinkCursor{Sort: model.LinkSortClicks, Score: 1, Code: "c"},
+			limit: 10,
+
+			expectCodes: []string{"a", "c"},
+		},
+		{
+			name: "workspace links",
+
+			workspace: "ws1",
+			owner:     "u1",
+			after:     model.LinkCursor{Sort: model.LinkSortCreated},
+			limit:     10,
+
+			expectCodes: []string{"team"},
+		},
+		{
+			name: "unknown sort",
+
+			owner: "u1",
+			after: model.LinkCursor{Sort: "name"},
+			limit: 10,
+
+			expectErr: ErrUnknownLinkSort,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			testRepo := NewLinkIndex(redisPkg.InitMockRedis(t))
+			for _, link := range []model.LinkSummary{
+				{Code: "a", URL: "https://example.com/a", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+				{Code: "b", URL: "https://example.com/b", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(2 * time.Hour)},
+				{Code: "c", URL: "https://example.com/c", CreatedAt: now, ExpiresAt: now.Add(2 * time.Hour)},
+				{Code: "d", URL: "https://example.com/d", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Minute)},
+			} {
+				require.NoError(t, testRepo.Add(ctx, "", "u1", link))
+			}
+			require.NoError(t, testRepo.Add(ctx, "ws1", "u1", model.LinkSummary{Code: "team", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+			require.NoError(t, testRepo.Add(ctx, "", "", model.LinkSummary{Code: "anonymous", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+			for _, code := range []string{"b", "b", "a", "d"} {
+				require.NoError(t, testRepo.IncrClicks(ctx, "", "u1", code))
+			}
+
+			links, err := testRepo.Scan(ctx, tc.workspace, tc.owner, tc.after, tc.limit)
+
+			assert.Equal(t, tc.expectErr, err)
+			var codes []string
+			for _, link := range links {
+				codes = append(codes, link.Code)
+			}
+			assert.Equal(t, tc.expectCodes, codes)
+		})
+	}
+}
+
+func TestLinkIndex_ScanTies(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	now := time.UnixMilli(1700000000000).UTC()
+	testRepo := NewLinkIndex(redisPkg.InitMockRedis(t))
+	var expected []string
+	for i := range 250 {
+		code := fmt.Sprintf("c%03d", i)
+		require.NoError(t, testRepo.Add(ctx, "", "u1", model.LinkSummary{Code: code, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+		expected = append(expected, code)
+	}
+	slices.Reverse(expected)
+
+	// Links that were never clicked all tie, every page still starts right after the previous one.
+	var codes []string
+	after := model.LinkCursor{Sort: model.LinkSortClicks}
+	for {
+		links, err := testRepo.Scan(ctx, "", "u1", after, 100)
+		require.NoError(t, err)
+		for _, link := range links {
+			codes = append(codes, link.Code)
+			after = model.LinkCursor{Sort: model.LinkSortClicks, Score: float64(link.Clicks), Code: link.Code}
+		}
+		if len(links) < 100 {
+			break
+		}
+	}
+	assert.Equal(t, expected, codes)
+}
+
+func TestLinkIndex_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	now := time.UnixMilli(1700000000000).UTC()
+	testRepo := NewLinkIndex(redisPkg.InitMockRedis(t))
+
+	link := model.LinkSummary{
+		Code:      "launch",
+		URL:       "https://example.com/launch",
+		Host:      "example.com",
+		Tags:      []string{"promo"},
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+	require.NoError(t, testRepo.Add(ctx, "", "u1", link))
+	require.NoError(t, testRepo.IncrClicks(ctx, "", "u1", "launch"))
+	require.NoError(t, testRepo.IncrClicks(ctx, "", "u1", "unknown"), "codes missing from the index are skipped")
+
+	link.Clicks = 1
+	for _, sort := range []string{model.LinkSortCreated, model.LinkSortExpiry, model.LinkSortClicks} {
+		links, err := testRepo.Scan(ctx, "", "u1", model.LinkCursor{Sort: sort}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []model.LinkSummary{link}, links, sort)
+	}
+}
+
+func TestLinkIndex_Prune(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	now := time.UnixMilli(1700000000000).UTC()
+	client := redisPkg.InitMockRedis(t)
+	testRepo := NewLinkIndex(client)
+
+	require.NoError(t, testRepo.Add(ctx, "", "u1", model.LinkSummary{Code: "old", CreatedAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(-24 * time.Hour)}))
+	require.NoError(t, testRepo.Add(ctx, "", "u1", model.LinkSummary{Code: "recent", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}))
+
+	require.NoError(t, testRepo.Prune(ctx, "", "u1", now.Add(-12*time.Hour)))
+
+	links, err := testRepo.Scan(ctx, "", "u1", model.LinkCursor{Sort: model.LinkSortCreated}, 10)
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, "recent", links[0].Code)
+	for _, key := range []string{"user:{u1}:links:created", "user:{u1}:links:expiry", "user:{u1}:links:clicks"} {
+		assert.Equal(t, int64(1), client.ZCard(ctx, key).Val(), key)
+	}
+	assert.Equal(t, int64(1), client.HLen(ctx, "user:{u1}:links").Val())
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+const (
+	linkTrashSuffix        = ":trash"
+	linkTrashDeletedSuffix = ":trash:deleted"
+	// linkTrashKey schedules the purge of every trashed link, scored by the time it was deleted at.
+	linkTrashKey = "links:{trash}"
+)
+
+// linkTrashKeys returns the hash of the trashed links of workspace, or of the global links of owner when workspace is
+// empty, by code, and the sorted set scoring them by the time they were deleted at.
+func linkTrashKeys(workspace, owner string) (string, string) {
+	scope := linkScopeKey(workspace, owner)
+	return scope + linkTrashSuffix, scope + linkTrashDeletedSuffix
+}
+
+// LinkTrash keeps the deleted links of every workspace and owner until they are restored or purged.
+//
+//go:generate mockery --name=LinkTrash --filename link_trash.go
+type LinkTrash interface {
+	Add(ctx context.Context, workspace, owner string, link model.LinkSummary, deletedAt time.Time) error
+	Get(ctx context.Context, workspace, owner, code string) (model.TrashedLink, error)
+	List(ctx context.Context, workspace, owner string, limit int) ([]model.TrashedLink, error)
+	Restore(ctx context.Context, workspace, owner, code string) (model.TrashedLink, error)
+	Scan(ctx context.Context, workspace, owner string, after model.LinkCursor, limit int) ([]model.TrashedLink, error)
+	Remove(ctx context.Context, workspace, owner, code string) (bool, error)
+	Reschedule(ctx context.Context, link model.TrashedLink) error
+	PopDeleted(ctx context.Context, deletedBy time.Time, limit int) ([]model.TrashedLink, error)
+}
+
+type linkTrash struct {
+	c redis.UniversalClient
+}
+
+// NewLinkTrash returns a new instance of the linkTrash, which implements the LinkTrash interface.
+// A trashed link leaves the LinkIndex of its workspace or owner for a trash next to it, with its clicks, and is scheduled
+// for purge in a sorted set shared by every instance.
+func NewLinkTrash(c redis.UniversalClient) LinkTrash {
+	return &linkTrash{c: c}
+}
+
+// Add moves the link code of link from the LinkIndex of workspace, or of the global links of owner when workspace is
+// empty, to its trash and schedules its purge. A link missing from the index, stored before links were indexed, is
+// trashed with the URL and tags of link only and is not indexed when restored.
+// The purge is scheduled first, so a trashed link always has one: a purge left scheduled for a link that did not reach
+// the trash purges nothing.
+func (s *linkTrash) Add(ctx context.Context, workspace, owner string, link model.LinkSummary, deletedAt time.Time) error {
+	var indexed *redis.StringCmd
+	var clicks *redis.FloatCmd
+	_, err := s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		indexed = p.HGet(ctx, linkIndexKey(workspace, owner), link.Code)
+		clicks = p.ZScore(ctx, linkSortKey(workspace, owner, model.LinkSortClicks), link.Code)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	record := linkRecord{URL: link.URL, Host: linkHost(link.URL), Tags: link.Tags}
+	if indexed.Err() == nil {
+		if err := json.Unmarshal([]byte(indexed.Val()), &record); err != nil {
+			return fmt.Errorf("decode indexed link %s: %w", link.Code, err)
+		}
+	}
+	record.Owner, record.Clicks, record.DeletedAt = owner, int64(clicks.Val()), deletedAt.UnixMilli()
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	member, err := linkTrashMember(workspace, owner, link.Code)
+	if err != nil {
+		return err
+	}
+
+	if err := s.c.ZAdd(ctx, linkTrashKey, redis.Z{Score: float64(record.DeletedAt), Member: member}).Err(); err != nil {
+		return err
+	}
+	trashKey, deletedKey := linkTrashKeys(workspace, owner)
+	_, err = s.c.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		removeIndexedLink(ctx, p, workspace, owner, link.Code)
+		p.HSet(ctx, trashKey, link.Code, raw)
+		p.ZAdd(ctx, deletedKey, redis.Z{Score: float64(record.DeletedAt), Member: link.Code})
+		return nil
+	})
+	return err
+}
+
+// Get returns the trashed link code of workspace, or of the global links of owner when workspace is empty,
+// and redis.Nil if there is none.
+func (s *linkTrash) Get(ctx context.Context, workspace, owner, code string) (model.TrashedLink, error) {
+	trashKey, _ := linkTrashKeys(workspace, owner)
+	raw, err := s.c.HGet(ctx, trashKey, code).Result()
+	if err != nil {
+		return model.TrashedLink{}, err
+	}
+	return decodeTrashedLink(workspace, code, raw)
+}
+
+// List returns up to limit trashed links of workspace, or of the global links of owner when workspace is empty,
+// the latest deleted first.
+func (s *linkTrash) List(ctx context.Context, workspace, owner string, limit int) ([]model.TrashedLink, error) {
+	trashKey, deletedKey := linkTrashKeys(workspace, owner)
+	codes, err := s.c.ZRevRange(ctx, deletedKey, 0, int64(limit)-1).Result()
+	if err != nil || len(codes) == 0 {
+		return nil, err
+	}
+	return s.load(ctx, workspace, trashKey, codes)
+}
+
+// Scan returns up to limit trashed links of workspace, or of the global links of owner when workspace is empty,
+// following after, the latest deleted first and ties by code in the same direction. The score of after is the time its
+// link was deleted at in Unix milliseconds, and an after without code starts from the latest deleted link.
+func (s *linkTrash) Scan(ctx context.Context, workspace, owner string, after model.LinkCursor, limit int) ([]model.TrashedLink, error) {
+	trashKey, deletedKey := linkTrashKeys(workspace, owner)
+	entries, err := scanSorted(ctx, s.c, deletedKey, after, true, limit)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	codes := make([]string, 0, len(entries))
+	for _, z := range entries {
+		codes = append(codes, z.Member.(string))
+	}
+	return s.load(ctx, workspace, trashKey, codes)
+}
+
+// load returns the trashed links codes of workspace from trashKey in order, the ones removed meanwhile are skipped.
+func (s *linkTrash) load(ctx context.Context, workspace, trashKey string, codes []string) ([]model.TrashedLink, error) {
+	values, err := s.c.HMGet(ctx, trashKey, codes...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]model.TrashedLink, 0, len(codes))
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		link, err := decodeTrashedLink(workspace, codes[i], raw)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// Restore moves the trashed link code of workspace, or of the global links of owner when workspace is empty, back to
+// the LinkIndex with its clicks, and cancels its purge. It returns the link, or redis.Nil if it is not in the trash.
+func (s *linkTrash) Restore(ctx context.Context, workspace, owner, code string) (model.TrashedLink, error) {
+	link, err := s.Get(ctx, workspace, owner, code)
+	if err != nil {
+		return model.TrashedLink{}, err
+	}
+
+	trashKey, deletedKey := linkTrashKeys(workspace, owner)
+	_, err = s.c.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HDel(ctx, trashKey, code)
+		p.ZRem(ctx, deletedKey, code)
+		if link.CreatedAt.IsZero() {
+			return nil
+		}
+		record := newLinkRecord(link.LinkSummary)
+		record.Clicks = link.Clicks
+		return addIndexedLink(ctx, p, workspace, owner, code, record)
+	})
+	if err != nil {
+		return model.TrashedLink{}, err
+	}
+	return link, s.unschedule(ctx, workspace, owner, code)
+}
+
+// Remove drops the trashed link code of workspace, or of the global links of owner when workspace is empty, and
+// cancels its purge. It returns false if the link is not in the trash.
+func (s *linkTrash) Remove(ctx context.Context, workspace, owner, code string) (bool, error) {
+	trashKey, deletedKey := linkTrashKeys(workspace, owner)
+	var removed *redis.IntCmd
+	_, err := s.c.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		removed = p.HDel(ctx, trashKey, code)
+		p.ZRem(ctx, deletedKey, code)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return removed.Val() > 0, s.unschedule(ctx, workspace, owner, code)
+}
+
+// Reschedule schedules the purge of link again, popped by PopDeleted but not purged, at the time it was deleted at.
+func (s *linkTrash) Reschedule(ctx context.Context, link model.TrashedLink) error {
+	member, err := linkTrashMember(link.Workspace, link.Owner, link.Code)
+	if err != nil {
+		return err
+	}
+	return s.c.ZAdd(ctx, linkTrashKey, redis.Z{Score: float64(link.DeletedAt.UnixMilli()), Member: member}).Err()
+}
+
+// PopDeleted pops up to limit links deleted by deletedBy from the purge schedule, the earliest deleted first.
+// Every link is popped once across the instances, until it is rescheduled, the returned links only have their workspace, code and deletion
+// time, and their owner for global links.
+func (s *linkTrash) PopDeleted(ctx context.Context, deletedBy time.Time, limit int) ([]model.TrashedLink, error) {
+	items, err := popLinkExpiryScript.Run(ctx, s.c, []string{linkTrashKey}, deletedBy.UnixMilli(), limit).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]model.TrashedLink, 0, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		var member linkExpiryMember
+		if err := json.Unmarshal([]byte(items[i]), &member); err != nil {
+			return nil, fmt.Errorf("decode trashed link %q: %w", items[i], err)
+		}
+		ms, err := strconv.ParseFloat(items[i+1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("decode trashed link score %q: %w", items[i+1], err)
+		}
+		links = append(links, model.TrashedLink{
+			LinkSummary: model.LinkSummary{Workspace: member.Workspace, Code: member.Code},
+			Owner:       member.Owner,
+			DeletedAt:   time.UnixMilli(int64(ms)).UTC(),
+		})
+	}
+	return links, nil
+}
+
+// unschedule cancels the purge of the trashed link code of workspace or owner.
+func (s *linkTrash) unschedule(ctx context.Context, workspace, owner, code string) error {
+	member, err := linkTrashMember(workspace, owner, code)
+	if err != nil {
+		return err
+	}
+	return s.c.ZRem(ctx, linkTrashKey, member).Err()
+}
+
+// linkTrashMember returns the member identifying the link code of workspace or owner in the purge schedule.
+// The owner of a workspace link is left out, anyone allowed in the trash of the workspace can unschedule it.
+func linkTrashMember(workspace, owner, code string) ([]byte, error) {
+	if workspace != "" {
+		owner = ""
+	}
+	return json.Marshal(linkExpiryMember{Workspace: workspace, Owner: owner, Code: code})
+}
+
+// decodeTrashedLink decodes raw, the record of the trashed link code of workspace.
+func decodeTrashedLink(workspace, code, raw string) (model.TrashedLink, error) {
+	var record linkRecord
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		return model.TrashedLink{}, fmt.Errorf("decode trashed link %s: %w", code, err)
+	}
+	return model.TrashedLink{
+		LinkSummary: record.summary(workspace, code),
+		Owner:       record.Owner,
+		DeletedAt:   time.UnixMilli(record.DeletedAt).UTC(),
+	}, nil
+}
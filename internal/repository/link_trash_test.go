@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"github.com/lhducc/bookmark-management/internal/model"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLinkTrash_AddRestore(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	now := time.UnixMilli(1700000000000).UTC()
+	redisMock := redisPkg.InitMockRedis(t)
+	index := NewLinkIndex(redisMock)
+	testRepo := NewLinkTrash(redisMock)
+
+	launch := model.LinkSummary{Code: "launch", URL: "https://example.com", Host: "example.com", Tags: []string{"promo"}, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, index.Add(ctx, "ws1", "u1", launch))
+	require.NoError(t, index.IncrClicks(ctx, "ws1", "u1", "launch"))
+	require.NoError(t, testRepo.Add(ctx, "ws1", "u1", launch, now.Add(time.Minute)))
+	require.NoError(t, testRepo.Add(ctx, "ws1", "u2", model.LinkSummary{Code: "legacy", URL: "https://Legacy.example.com/a"}, now.Add(2*time.Minute)))
+
+	indexed, err := index.Scan(ctx, "ws1", "", model.LinkCursor{Sort: model.LinkSortCreated}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, indexed, "trashed links leave the index")
+
+	trashed := model.TrashedLink{LinkSummary: launch, Owner: "u1", DeletedAt: now.Add(time.Minute)}
+	trashed.Workspace, trashed.Clicks = "ws1", 1
+	legacy := model.TrashedLink{
+		LinkSummary: model.LinkSummary{Workspace: "ws1", Code: "legacy", URL: "https://Legacy.example.com/a", Host: "legacy.example.com"},
+		Owner:       "u2",
+		DeletedAt:   now.Add(2 * time.Minute),
+	}
+	links, err := testRepo.List(ctx, "ws1", "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TrashedLink{legacy, trashed}, links)
+	links, err = testRepo.Scan(ctx, "ws1", "", model.LinkCursor{Score: float64(legacy.DeletedAt.UnixMilli()), Code: "legacy"}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TrashedLink{trashed}, links, "the trash is scanned after the cursor, the latest deleted first")
+
+	popped, err := testRepo.PopDeleted(ctx, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TrashedLink{{LinkSummary: model.LinkSummary{Workspace: "ws1", Code: "launch"}, DeletedAt: trashed.DeletedAt}}, popped)
+
+	restored, err := testRepo.Restore(ctx, "ws1", "", "launch")
+	require.NoError(t, err)
+	assert.Equal(t, trashed, restored)
+	indexed, err = index.Scan(ctx, "ws1", "", model.LinkCursor{Sort: model.LinkSortClicks}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.LinkSummary{trashed.LinkSummary}, indexed, "restored links keep their clicks")
+
+	_, err = testRepo.Restore(ctx, "ws1", "", "legacy")
+	require.NoError(t, err)
+	indexed, err = index.Scan(ctx, "ws1", "", model.LinkCursor{Sort: model.LinkSortCreated}, 10)
+	require.NoError(t, err)
+	assert.Len(t, indexed, 1, "links deleted before they were indexed are not indexed")
+
+	_, err = testRepo.Get(ctx, "ws1", "", "launch")
+	assert.ErrorIs(t, err, redis.Nil)
+	popped, err = testRepo.PopDeleted(ctx, now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, popped, "restored links are not purged")
+}
+
+func TestLinkTrash_Reschedule(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	now := time.UnixMilli(1700000000000).UTC()
+	testRepo := NewLinkTrash(redisPkg.InitMockRedis(t))
+	require.NoError(t, testRepo.Add(ctx, "ws1", "u1", model.LinkSummary{Code: "launch", URL: "https://example.com"}, now))
+
+	popped, err := testRepo.PopDeleted(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, popped, 1)
+	popped, err = testRepo.PopDeleted(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, popped)
+
+	require.NoError(t, testRepo.Reschedule(ctx, model.TrashedLink{LinkSummary: model.LinkSummary{Workspace: "ws1", Code: "launch"}, DeletedAt: now}))
+	popped, err = testRepo.PopDeleted(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TrashedLink{{LinkSummary: model.LinkSummary{Workspace: "ws1", Code: "launch"}, DeletedAt: now}}, popped)
+}
+
+func TestLinkTrash_Remove(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	now := time.UnixMilli(1700000000000).UTC()
+	testRepo := NewLinkTrash(redisPkg.InitMockRedis(t))
+	require.NoError(t, testRepo.Add(ctx, "", "u1", model.LinkSummary{Code: "launch", URL: "https://example.com"}, now))
+
+	link, err := testRepo.Get(ctx, "", "u1", "launch")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", link.Owner)
+	_, err = testRepo.Get(ctx, "", "u2", "launch")
+	assert.ErrorIs(t, err, redis.Nil, "the trash of an owner is their own")
+
+	removed, err := testRepo.Remove(ctx, "", "u1", "launch")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = testRepo.Remove(ctx, "", "u1", "launch")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	links, err := testRepo.List(ctx, "", "u1", 10)
+	require.NoError(t, err)
+	assert.Empty(t, links)
+	popped, err := testRepo.PopDeleted(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, popped, "removed links are not purged")
+}
@@ -31,6 +31,24 @@ func (_m *HealthCheck) Ping(ctx context.Context) error {
 	return r0
 }
 
+// WriteProbe provides a mock function with given fields: ctx, instanceID
+func (_m *HealthCheck) WriteProbe(ctx context.Context, instanceID string) error {
+	ret := _m.Called(ctx, instanceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteProbe")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, instanceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewHealthCheck creates a new instance of HealthCheck. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewHealthCheck(t interface {
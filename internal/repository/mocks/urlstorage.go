@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 
+	model "github.com/lhducc/bookmark-management/internal/model"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -13,27 +14,27 @@ type UrlStorage struct {
 	mock.Mock
 }
 
-// GetURL provides a mock function with given fields: ctx, code
-func (_m *UrlStorage) GetURL(ctx context.Context, code string) (string, error) {
-	ret := _m.Called(ctx, code)
+// GetClicks provides a mock function with given fields: ctx, workspace, code
+func (_m *UrlStorage) GetClicks(ctx context.Context, workspace string, code string) (model.ClickStats, error) {
+	ret := _m.Called(ctx, workspace, code)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetURL")
+		panic("no return value specified for GetClicks")
 	}
 
-	var r0 string
+	var r0 model.ClickStats
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return rf(ctx, code)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (model.ClickStats, error)); ok {
+		return rf(ctx, workspace, code)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = rf(ctx, code)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) model.ClickStats); ok {
+		r0 = rf(ctx, workspace, code)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(model.ClickStats)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, code)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, workspace, code)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -41,17 +42,137 @@ func (_m *UrlStorage) GetURL(ctx context.Context, code string) (string, error) {
 	return r0, r1
 }
 
-// StoreURL provides a mock function with given fields: ctx, code, url
-func (_m *UrlStorage) StoreURL(ctx context.Context, code string, url string) error {
-	ret := _m.Called(ctx, code, url)
+// GetLink provides a mock function with given fields: ctx, workspace, code
+func (_m *UrlStorage) GetLink(ctx context.Context, workspace string, code string) (model.Link, error) {
+	ret := _m.Called(ctx, workspace, code)
 
 	if len(ret) == 0 {
-		panic("no return value specified for StoreURL")
+		panic("no return value specified for GetLink")
+	}
+
+	var r0 model.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (model.Link, error)); ok {
+		return rf(ctx, workspace, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) model.Link); ok {
+		r0 = rf(ctx, workspace, code)
+	} else {
+		r0 = ret.Get(0).(model.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, workspace, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrClicks provides a mock function with given fields: ctx, workspace, owner, code, variant
+func (_m *UrlStorage) IncrClicks(ctx context.Context, workspace string, owner string, code string, variant string) error {
+	ret := _m.Called(ctx, workspace, owner, code, variant)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrClicks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
+		r0 = rf(ctx, workspace, owner, code, variant)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PurgeLink provides a mock function with given fields: ctx, workspace, code
+func (_m *UrlStorage) PurgeLink(ctx context.Context, workspace string, code string) error {
+	ret := _m.Called(ctx, workspace, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeLink")
 	}
 
 	var r0 error
 	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(ctx, code, url)
+		r0 = rf(ctx, workspace, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreLink provides a mock function with given fields: ctx, workspace, code
+func (_m *UrlStorage) RestoreLink(ctx context.Context, workspace string, code string) (bool, error) {
+	ret := _m.Called(ctx, workspace, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreLink")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, workspace, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, workspace, code)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, workspace, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StoreLinkIfNotExists provides a mock function with given fields: ctx, workspace, code, link, exp
+func (_m *UrlStorage) StoreLinkIfNotExists(ctx context.Context, workspace string, code string, link model.Link, exp int) (bool, error) {
+	ret := _m.Called(ctx, workspace, code, link, exp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StoreLinkIfNotExists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.Link, int) (bool, error)); ok {
+		return rf(ctx, workspace, code, link, exp)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.Link, int) bool); ok {
+		r0 = rf(ctx, workspace, code, link, exp)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, model.Link, int) error); ok {
+		r1 = rf(ctx, workspace, code, link, exp)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StoreURL provides a mock function with given fields: ctx, workspace, code, url
+func (_m *UrlStorage) StoreURL(ctx context.Context, workspace string, code string, url string) error {
+	ret := _m.Called(ctx, workspace, code, url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StoreURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, workspace, code, url)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -59,27 +180,27 @@ func (_m *UrlStorage) StoreURL(ctx context.Context, code string, url string) err
 	return r0
 }
 
-// StoreURLIfNotExists provides a mock function with given fields: ctx, code, url, exp
-func (_m *UrlStorage) StoreURLIfNotExists(ctx context.Context, code string, url string, exp int) (bool, error) {
-	ret := _m.Called(ctx, code, url, exp)
+// TrashLink provides a mock function with given fields: ctx, workspace, code
+func (_m *UrlStorage) TrashLink(ctx context.Context, workspace string, code string) (bool, error) {
+	ret := _m.Called(ctx, workspace, code)
 
 	if len(ret) == 0 {
-		panic("no return value specified for StoreURLIfNotExists")
+		panic("no return value specified for TrashLink")
 	}
 
 	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) (bool, error)); ok {
-		return rf(ctx, code, url, exp)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, workspace, code)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) bool); ok {
-		r0 = rf(ctx, code, url, exp)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, workspace, code)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
-		r1 = rf(ctx, code, url, exp)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, workspace, code)
 	} else {
 		r1 = ret.Error(1)
 	}
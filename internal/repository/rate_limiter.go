@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+const (
+	rateLimitKeyPrefix = "ratelimit:"
+)
+
+// gcraScript implements the generic cell rate algorithm, a token bucket stored as a single timestamp.
+// KEYS[1] holds the theoretical arrival time (TAT) in microseconds of Redis TIME.
+// ARGV[1] is the emission interval (period / limit) and ARGV[2] the period, both in microseconds.
+// It returns {allowed, remaining, retry after, reset}, durations in microseconds.
+var gcraScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local interval = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local newTat = tat + interval
+local allowAt = newTat - period
+if allowAt > now then
+	return {0, 0, allowAt - now, tat - now}
+end
+
+redis.call('SET', KEYS[1], string.format('%d', newTat), 'PX', string.format('%d', math.ceil((newTat - now) / 1000)))
+return {1, math.floor((now - allowAt) / interval), 0, newTat - now}
+`)
+
+//go:generate mockery --name=RateLimiter --filename rate_limiter.go
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit model.RateLimit) (model.RateLimitResult, error)
+}
+
+type rateLimiter struct {
+	c redis.UniversalClient
+}
+
+// NewRateLimiter returns a new instance of the rateLimiter, which implements the RateLimiter interface.
+// The counters live in Redis, so a limit is shared by every instance.
+func NewRateLimiter(c redis.UniversalClient) RateLimiter {
+	return &rateLimiter{c: c}
+}
+
+// Allow counts one request of key against limit and reports whether it is allowed.
+// The limit behaves like a token bucket holding limit.Limit tokens and refilled at limit.Limit per limit.Period,
+// so a client can burst its whole quota and then continues at the sustained rate.
+// The clock of Redis is used, so instances with skewed clocks still agree.
+func (r *rateLimiter) Allow(ctx context.Context, key string, limit model.RateLimit) (model.RateLimitResult, error) {
+	interval := limit.Period.Microseconds() / int64(limit.Limit)
+	res, err := gcraScript.Run(ctx, r.c, []string{rateLimitKeyPrefix + key}, max(interval, 1), limit.Period.Microseconds()).Int64Slice()
+	if err != nil {
+		return model.RateLimitResult{}, err
+	}
+
+	return model.RateLimitResult{
+		Allowed:    res[0] == 1,
+		Limit:      limit.Limit,
+		Remaining:  int(res[1]),
+		Window:     limit.Period,
+		RetryAfter: time.Duration(res[2]) * time.Microsecond,
+		Reset:      time.Duration(res[3]) * time.Microsecond,
+	}, nil
+}
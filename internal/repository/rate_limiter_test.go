@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"github.com/lhducc/bookmark-management/internal/model"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func() *redis.Client
+		limit     model.RateLimit
+		requests  int
+
+		expectedAllowed   []bool
+		expectedRemaining []int
+		expectErr         error
+	}{
+		{
+			name: "burst up to the limit then reject",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+			limit:    model.RateLimit{Limit: 3, Period: time.Hour},
+			requests: 4,
+
+			expectedAllowed:   []bool{true, true, true, false},
+			expectedRemaining: []int{2, 1, 0, 0},
+		},
+		{
+			name: "single request per period",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+			limit:    model.RateLimit{Limit: 1, Period: time.Minute},
+			requests: 2,
+
+			expectedAllowed:   []bool{true, false},
+			expectedRemaining: []int{0, 0},
+		},
+		{
+			name: "redis connection error",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				_ = mock.Close()
+				return mock
+			},
+			limit:    model.RateLimit{Limit: 1, Period: time.Minute},
+			requests: 1,
+
+			expectedAllowed:   []bool{false},
+			expectedRemaining: []int{0},
+			expectErr:         redis.ErrClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			testRepo := NewRateLimiter(tc.setupMock())
+
+			for i := 0; i < tc.requests; i++ {
+				res, err := testRepo.Allow(ctx, "shorten:ip:1.2.3.4", tc.limit)
+				assert.Equal(t, tc.expectErr, err)
+				assert.Equal(t, tc.expectedAllowed[i], res.Allowed, "request %d", i)
+				assert.Equal(t, tc.expectedRemaining[i], res.Remaining, "request %d", i)
+				if err != nil {
+					continue
+				}
+
+				assert.Equal(t, tc.limit.Limit, res.Limit)
+				assert.Equal(t, tc.limit.Period, res.Window)
+				assert.LessOrEqual(t, res.Reset, tc.limit.Period)
+				if res.Allowed {
+					assert.Zero(t, res.RetryAfter)
+				} else {
+					require.Positive(t, res.RetryAfter)
+					assert.LessOrEqual(t, res.RetryAfter, tc.limit.Period/time.Duration(tc.limit.Limit))
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter_AllowSeparateKeys(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	testRepo := NewRateLimiter(redisPkg.InitMockRedis(t))
+	limit := model.RateLimit{Limit: 1, Period: time.Minute}
+
+	res, err := testRepo.Allow(ctx, "redirect:ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = testRepo.Allow(ctx, "redirect:ip:5.6.7.8", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	urlInvalidationChannel = "url:invalidate"
+)
+
+//go:generate mockery --name=UrlInvalidation --filename url_invalidation.go
+type UrlInvalidation interface {
+	Publish(ctx context.Context, code string) error
+	Subscribe(ctx context.Context) (<-chan string, error)
+}
+
+type urlInvalidation struct {
+	c redis.UniversalClient
+}
+
+// NewUrlInvalidation returns a new instance of the urlInvalidation, which implements the UrlInvalidation interface.
+// It broadcasts URL codes whose cached value became stale over a Redis pub/sub channel shared by every instance.
+func NewUrlInvalidation(c redis.UniversalClient) UrlInvalidation {
+	return &urlInvalidation{c: c}
+}
+
+// Publish announces that the URL stored under code was updated or deleted.
+// It returns an error if the message could not be published.
+func (r *urlInvalidation) Publish(ctx context.Context, code string) error {
+	return r.c.Publish(ctx, urlInvalidationChannel, code).Err()
+}
+
+// Subscribe starts listening for invalidated URL codes.
+// It returns once the subscription is confirmed by Redis, the returned channel receives every published code
+// and is closed when ctx is done.
+func (r *urlInvalidation) Subscribe(ctx context.Context) (<-chan string, error) {
+	pubSub := r.c.Subscribe(ctx, urlInvalidationChannel)
+	if _, err := pubSub.Receive(ctx); err != nil {
+		_ = pubSub.Close()
+		return nil, err
+	}
+
+	codes := make(chan string)
+	go func() {
+		defer close(codes)
+		defer pubSub.Close()
+
+		messages := pubSub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case codes <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return codes, nil
+}
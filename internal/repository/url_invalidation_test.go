@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestUrlInvalidation_PublishSubscribe(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func() *redis.Client
+
+		expectErr     error
+		expectedCodes []string
+	}{
+		{
+			name: "normal case",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+
+			expectedCodes: []string{"abc1234", "xyz9876"},
+		},
+		{
+			name: "redis connection error",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				_ = mock.Close()
+				return mock
+			},
+
+			expectErr: redis.ErrClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			redisMock := tc.setupMock()
+			testRepo := NewUrlInvalidation(redisMock)
+
+			codes, err := testRepo.Subscribe(ctx)
+			assert.Equal(t, tc.expectErr, err)
+			if err != nil {
+				return
+			}
+
+			for _, code := range tc.expectedCodes {
+				require.NoError(t, testRepo.Publish(ctx, code))
+			}
+
+			for _, expected := range tc.expectedCodes {
+				select {
+				case code := <-codes:
+					assert.Equal(t, expected, code)
+				case <-time.After(time.Second):
+					t.Fatalf("timed out waiting for %q", expected)
+				}
+			}
+
+			cancel()
+			assert.Eventually(t, func() bool {
+				_, ok := <-codes
+				return !ok
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
@@ -2,51 +2,404 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lhducc/bookmark-management/internal/model"
 	"github.com/redis/go-redis/v9"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const (
 	urlExpTime = 24 * time.Hour
+
+	urlKeyPrefix          = "url:"
+	workspaceURLKeyPrefix = "ws:"
+	linkMetaKeySuffix     = ":meta"
+	linkClicksKeySuffix   = ":clicks"
+	trashKeyPrefix        = "trash:"
+
+	linkFieldRedirectStatus = "redirect_status"
+	linkFieldTitle          = "title"
+	linkFieldParams         = "params"
+	linkFieldPassQuery      = "pass_query"
+	linkFieldRules          = "rules"
+	linkFieldVariants       = "variants"
+	linkFieldOwner          = "owner"
+	linkFieldTags           = "tags"
+
+	clicksFieldTotal    = "total"
+	clicksVariantPrefix = "variant:"
 )
 
+// storeLinkScript stores a link unless its code is taken, or trashed.
+// KEYS[1] is the URL key, KEYS[2] the options hash of the link and KEYS[3] the URL key of the link in the trash, in the same slot.
+// ARGV[1] is the URL, ARGV[2] the TTL in milliseconds and the rest the option fields and values, if any.
+// It returns 1 if the link was stored and 0 if the code is taken.
+var storeLinkScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	return 0
+end
+if not redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 0
+end
+redis.call('DEL', KEYS[2])
+if #ARGV > 2 then
+	redis.call('HSET', KEYS[2], unpack(ARGV, 3))
+	redis.call('PEXPIRE', KEYS[2], ARGV[2])
+end
+return 1
+`)
+
+// incrClicksScript counts a redirect of a link.
+// KEYS[1] is the URL key and KEYS[2] the clicks hash of the link, ARGV[1] is the variant served, empty for none.
+// The clicks hash gets the remaining TTL of the link when it is created, so both expire together.
+var incrClicksScript = redis.NewScript(`
+redis.call('HINCRBY', KEYS[2], 'total', 1)
+if ARGV[1] ~= '' then
+	redis.call('HINCRBY', KEYS[2], 'variant:' .. ARGV[1], 1)
+end
+if redis.call('PTTL', KEYS[2]) == -1 then
+	local ttl = redis.call('PTTL', KEYS[1])
+	if ttl > 0 then
+		redis.call('PEXPIRE', KEYS[2], ttl)
+	end
+end
+return 1
+`)
+
+// moveLinkScript moves the keys of a link, keeping their TTL.
+// KEYS[1..n] are the keys of the link, its URL key first, and KEYS[n+1..2n] the keys they are moved to, in the same slot.
+// It returns 1 if the keys were moved, 0 if there is no URL key to move or the target URL key exists.
+var moveLinkScript = redis.NewScript(`
+local n = #KEYS / 2
+if redis.call('EXISTS', KEYS[1]) == 0 or redis.call('EXISTS', KEYS[n + 1]) == 1 then
+	return 0
+end
+for i = 1, n do
+	if redis.call('EXISTS', KEYS[i]) == 1 then
+		redis.call('RENAME', KEYS[i], KEYS[n + i])
+	end
+end
+return 1
+`)
+
+// urlKey returns the key holding the URL of code in workspace, an empty workspace is the global namespace.
+// The code is a hash tag, so every key of a code lands in the same Redis Cluster slot and scripts can use them together.
+func urlKey(workspace, code string) string {
+	if workspace == "" {
+		return urlKeyPrefix + "{" + code + "}"
+	}
+	return workspaceURLKeyPrefix + workspace + ":" + urlKeyPrefix + "{" + code + "}"
+}
+
+// linkMetaKey returns the key of the hash holding the redirect options of code in workspace, next to its URL key.
+func linkMetaKey(workspace, code string) string {
+	return urlKey(workspace, code) + linkMetaKeySuffix
+}
+
+// linkClicksKey returns the key of the hash counting the redirects of code in workspace, next to its URL key.
+func linkClicksKey(workspace, code string) string {
+	return urlKey(workspace, code) + linkClicksKeySuffix
+}
+
+// linkKeys returns the URL key, the options hash and the clicks hash of code in workspace.
+func linkKeys(workspace, code string) []string {
+	return []string{urlKey(workspace, code), linkMetaKey(workspace, code), linkClicksKey(workspace, code)}
+}
+
+// trashedLinkKeys returns the keys of code in workspace once trashed, in the order of linkKeys.
+// They keep the hash tag of the code, so a link moves to the trash and back within its slot.
+func trashedLinkKeys(workspace, code string) []string {
+	keys := linkKeys(workspace, code)
+	for i, key := range keys {
+		keys[i] = trashKeyPrefix + key
+	}
+	return keys
+}
+
 //go:generate mockery --name=UrlStorage --filename urlstorage.go
 type UrlStorage interface {
-	StoreURL(ctx context.Context, code, url string) error
-	GetURL(ctx context.Context, code string) (string, error)
-	StoreURLIfNotExists(ctx context.Context, code, url string, exp int) (bool, error)
+	StoreURL(ctx context.Context, workspace, code, url string) error
+	GetLink(ctx context.Context, workspace, code string) (model.Link, error)
+	StoreLinkIfNotExists(ctx context.Context, workspace, code string, link model.Link, exp int) (bool, error)
+	IncrClicks(ctx context.Context, workspace, owner, code, variant string) error
+	GetClicks(ctx context.Context, workspace, code string) (model.ClickStats, error)
+	TrashLink(ctx context.Context, workspace, code string) (bool, error)
+	RestoreLink(ctx context.Context, workspace, code string) (bool, error)
+	PurgeLink(ctx context.Context, workspace, code string) error
 }
 type urlStorage struct {
-	c *redis.Client
+	c      redis.UniversalClient
+	index  LinkIndex
+	expiry LinkExpiryIndex
 }
 
-func NewUrlStorage(c *redis.Client) UrlStorage {
-	return &urlStorage{c: c}
+// NewUrlStorage returns a new instance of the urlStorage, which implements the UrlStorage interface.
+// Every method takes the workspace the code belongs to, codes of different workspaces never collide and the empty workspace is the global namespace.
+// Global URLs stored before the url:{code} key layout under the bare code are still read, and their codes are never reused.
+// Stored links are added to the LinkIndex of their workspace or owner and to the LinkExpiryIndex.
+func NewUrlStorage(c redis.UniversalClient) UrlStorage {
+	return &urlStorage{c: c, index: NewLinkIndex(c), expiry: NewLinkExpiryIndex(c)}
 }
 
 // StoreURL stores a URL in the repository with a given code and expiration time.
-// The method takes a context, a code, and a URL as input parameters.
+// The method takes a context, a workspace, a code, and a URL as input parameters.
 // It stores the URL in the repository with the given code and expiration time, and returns an error if there is an issue storing the URL.
-func (s *urlStorage) StoreURL(ctx context.Context, code, url string) error {
-	return s.c.Set(ctx, code, url, urlExpTime).Err()
+func (s *urlStorage) StoreURL(ctx context.Context, workspace, code, url string) error {
+	return s.c.Set(ctx, urlKey(workspace, code), url, urlExpTime).Err()
+}
+
+// GetLink retrieves a link from the repository using a given code.
+// The method takes a context, a workspace and a code as input parameters.
+// It returns the link associated with the given code, and redis.Nil if there is none.
+// The URL, the expiry and the options of the link are read in a single round trip.
+// A global code missing under its key is looked up under the legacy bare code key, such links have no options.
+func (s *urlStorage) GetLink(ctx context.Context, workspace, code string) (model.Link, error) {
+	var url *redis.StringCmd
+	var ttl *redis.DurationCmd
+	var meta *redis.MapStringStringCmd
+	_, err := s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		url = p.Get(ctx, urlKey(workspace, code))
+		ttl = p.PTTL(ctx, urlKey(workspace, code))
+		meta = p.HGetAll(ctx, linkMetaKey(workspace, code))
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return model.Link{}, err
+	}
+
+	if errors.Is(url.Err(), redis.Nil) {
+		if workspace != "" {
+			return model.Link{}, redis.Nil
+		}
+		return s.getLegacyLink(ctx, code)
+	}
+
+	link, err := linkFromMeta(url.Val(), meta.Val())
+	if err != nil {
+		return model.Link{}, err
+	}
+	link.ExpiresAt = expiresAt(ttl.Val())
+	return link, nil
 }
 
-// GetURL retrieves a URL from the repository using a given code.
-// The method takes a context and a code as input parameters.
-// It returns the URL associated with the given code, and an error if there is an issue retrieving the URL.
-func (s *urlStorage) GetURL(ctx context.Context, code string) (string, error) {
-	return s.c.Get(ctx, code).Result()
+// getLegacyLink returns the link stored under the legacy bare code key, and redis.Nil if there is none.
+func (s *urlStorage) getLegacyLink(ctx context.Context, code string) (model.Link, error) {
+	var url *redis.StringCmd
+	var ttl *redis.DurationCmd
+	_, err := s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		url = p.Get(ctx, code)
+		ttl = p.PTTL(ctx, code)
+		return nil
+	})
+	if err != nil {
+		return model.Link{}, err
+	}
+	return model.Link{URL: url.Val(), ExpiresAt: expiresAt(ttl.Val())}, nil
 }
 
-func (s *urlStorage) StoreURLIfNotExists(ctx context.Context, code, url string, exp int) (bool, error) {
+// expiresAt returns the time a key with the remaining ttl expires at, zero for the negative TTLs of keys without expiry.
+func expiresAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// StoreLinkIfNotExists stores link under code in workspace for exp seconds, or urlExpTime if exp is not positive, unless code is already taken.
+// It returns false if code is taken in workspace, under its key or, for the global namespace, the legacy bare code key,
+// or if the link of code is in the trash.
+// The URL and the options of the link are written atomically and expire together, the link is then indexed,
+// and deleted again if it cannot be, so a failed store leaves code free.
+func (s *urlStorage) StoreLinkIfNotExists(ctx context.Context, workspace, code string, link model.Link, exp int) (bool, error) {
 	expDuration := urlExpTime
 	if exp > 0 {
 		expDuration = time.Duration(exp) * time.Second
 	}
 
-	ok, err := s.c.SetNX(ctx, code, url, expDuration).Result()
+	if workspace == "" {
+		legacy, err := s.c.Exists(ctx, code).Result()
+		if err != nil {
+			return false, err
+		}
+		if legacy > 0 {
+			return false, nil
+		}
+	}
+
+	fields, err := linkMetaFields(link)
 	if err != nil {
 		return false, err
 	}
-	return ok, nil
+	args := append([]any{link.URL, expDuration.Milliseconds()}, fields...)
+	keys := []string{urlKey(workspace, code), linkMetaKey(workspace, code), trashKeyPrefix + urlKey(workspace, code)}
+	stored, err := storeLinkScript.Run(ctx, s.c, keys, args...).Int()
+	if err != nil || stored == 0 {
+		return false, err
+	}
+	now := time.Now()
+	summary := model.LinkSummary{
+		Code:      code,
+		URL:       link.URL,
+		Host:      linkHost(link.URL),
+		Tags:      link.Tags,
+		CreatedAt: now,
+		ExpiresAt: now.Add(expDuration),
+	}
+	expiry := model.LinkExpiry{Workspace: workspace, Owner: link.Owner, Code: code, ExpiresAt: summary.ExpiresAt}
+	if err := s.expiry.Add(ctx, expiry); err != nil {
+		return false, s.unstoreLink(ctx, workspace, code, fmt.Errorf("index expiry of %s: %w", code, err))
+	}
+	if err := s.index.Add(ctx, workspace, link.Owner, summary); err != nil {
+		err = fmt.Errorf("index %s: %w", code, err)
+		if removeErr := s.expiry.Remove(ctx, expiry); removeErr != nil {
+			err = errors.Join(err, fmt.Errorf("remove expiry of %s: %w", code, removeErr))
+		}
+		return false, s.unstoreLink(ctx, workspace, code, err)
+	}
+	return true, nil
+}
+
+// unstoreLink deletes the link of code in workspace stored by StoreLinkIfNotExists before it could be indexed, so the
+// code is not left taken by a link the caller was told is not stored, and returns err along with the error deleting it.
+func (s *urlStorage) unstoreLink(ctx context.Context, workspace, code string, err error) error {
+	if delErr := s.c.Del(ctx, linkKeys(workspace, code)...).Err(); delErr != nil {
+		return errors.Join(err, fmt.Errorf("delete %s: %w", code, delErr))
+	}
+	return err
+}
+
+// IncrClicks counts a redirect of code in workspace, owner is the owner of the link and variant the name of the variant
+// served, empty for none. The counters expire along with the link, the redirects of legacy bare code links are counted
+// without expiry. The redirect is then counted in the LinkIndex.
+func (s *urlStorage) IncrClicks(ctx context.Context, workspace, owner, code, variant string) error {
+	if err := incrClicksScript.Run(ctx, s.c, []string{urlKey(workspace, code), linkClicksKey(workspace, code)}, variant).Err(); err != nil {
+		return err
+	}
+	return s.index.IncrClicks(ctx, workspace, owner, code)
+}
+
+// GetClicks returns the redirects counted for code in workspace, all zero if there are none.
+func (s *urlStorage) GetClicks(ctx context.Context, workspace, code string) (model.ClickStats, error) {
+	fields, err := s.c.HGetAll(ctx, linkClicksKey(workspace, code)).Result()
+	if err != nil {
+		return model.ClickStats{}, err
+	}
+
+	stats := model.ClickStats{Variants: map[string]int64{}}
+	for field, value := range fields {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return model.ClickStats{}, fmt.Errorf("decode clicks of %s: %w", code, err)
+		}
+		if field == clicksFieldTotal {
+			stats.Total = count
+		} else if name, ok := strings.CutPrefix(field, clicksVariantPrefix); ok {
+			stats.Variants[name] = count
+		}
+	}
+	return stats, nil
+}
+
+// TrashLink moves the URL, the options and the clicks of code in workspace to the trash, where they keep expiring.
+// It returns false if no link is stored under code, legacy bare code links included.
+func (s *urlStorage) TrashLink(ctx context.Context, workspace, code string) (bool, error) {
+	return moveLinkScript.Run(ctx, s.c, append(linkKeys(workspace, code), trashedLinkKeys(workspace, code)...)).Bool()
+}
+
+// RestoreLink moves the link of code in workspace back from the trash.
+// It returns false if the link is not in the trash, or expired there.
+func (s *urlStorage) RestoreLink(ctx context.Context, workspace, code string) (bool, error) {
+	return moveLinkScript.Run(ctx, s.c, append(trashedLinkKeys(workspace, code), linkKeys(workspace, code)...)).Bool()
+}
+
+// PurgeLink deletes the link of code in workspace from the trash, its code can then be handed out again.
+func (s *urlStorage) PurgeLink(ctx context.Context, workspace, code string) error {
+	return s.c.Del(ctx, trashedLinkKeys(workspace, code)...).Err()
+}
+
+// linkMetaFields returns the fields and values of the options hash of link, options left to their default are omitted.
+func linkMetaFields(link model.Link) ([]any, error) {
+	var fields []any
+	if link.RedirectStatus != 0 {
+		fields = append(fields, linkFieldRedirectStatus, link.RedirectStatus)
+	}
+	if link.Title != "" {
+		fields = append(fields, linkFieldTitle, link.Title)
+	}
+	if link.Owner != "" {
+		fields = append(fields, linkFieldOwner, link.Owner)
+	}
+	if len(link.Tags) > 0 {
+		tags, err := json.Marshal(link.Tags)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, linkFieldTags, tags)
+	}
+	if len(link.Params) > 0 {
+		params, err := json.Marshal(link.Params)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, linkFieldParams, params)
+	}
+	if link.PassQuery {
+		fields = append(fields, linkFieldPassQuery, 1)
+	}
+	if len(link.Rules) > 0 {
+		rules, err := json.Marshal(link.Rules)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, linkFieldRules, rules)
+	}
+	if len(link.Variants) > 0 {
+		variants, err := json.Marshal(link.Variants)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, linkFieldVariants, variants)
+	}
+	return fields, nil
+}
+
+// linkFromMeta returns the link to url with the options read from the fields of its options hash.
+func linkFromMeta(url string, fields map[string]string) (model.Link, error) {
+	link := model.Link{URL: url, Owner: fields[linkFieldOwner], Title: fields[linkFieldTitle], PassQuery: fields[linkFieldPassQuery] == "1"}
+	link.RedirectStatus, _ = strconv.Atoi(fields[linkFieldRedirectStatus])
+	if tags := fields[linkFieldTags]; tags != "" {
+		if err := json.Unmarshal([]byte(tags), &link.Tags); err != nil {
+			return model.Link{}, fmt.Errorf("decode tags of %s: %w", url, err)
+		}
+	}
+	if params := fields[linkFieldParams]; params != "" {
+		if err := json.Unmarshal([]byte(params), &link.Params); err != nil {
+			return model.Link{}, fmt.Errorf("decode params of %s: %w", url, err)
+		}
+	}
+	if rules := fields[linkFieldRules]; rules != "" {
+		if err := json.Unmarshal([]byte(rules), &link.Rules); err != nil {
+			return model.Link{}, fmt.Errorf("decode rules of %s: %w", url, err)
+		}
+	}
+	if variants := fields[linkFieldVariants]; variants != "" {
+		if err := json.Unmarshal([]byte(variants), &link.Variants); err != nil {
+			return model.Link{}, fmt.Errorf("decode variants of %s: %w", url, err)
+		}
+	}
+	return link, nil
+}
+
+// linkHost returns the lowercase host name of rawURL, empty if it cannot be parsed.
+func linkHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
 }
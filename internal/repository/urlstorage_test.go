@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
 	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +33,7 @@ func TestUrlStorage_StoreURL(t *testing.T) {
 
 			expectErr: nil,
 			verifyFunc: func(ctx context.Context, r *redis.Client) {
-				url, err := r.Get(ctx, "123").Result()
+				url, err := r.Get(ctx, "url:{123}").Result()
 				assert.Nil(t, err)
 				assert.Equal(t, url, "https://google.com")
 			},
@@ -46,7 +48,7 @@ func TestUrlStorage_StoreURL(t *testing.T) {
 			redisMock := tc.setupMock()
 			testRepo := NewUrlStorage(redisMock)
 
-			err := testRepo.StoreURL(ctx, "123", "https://google.com")
+			err := testRepo.StoreURL(ctx, "", "123", "https://google.com")
 			assert.Equal(t, tc.expectErr, err)
 			if err == nil {
 				tc.verifyFunc(ctx, redisMock)
@@ -55,20 +57,22 @@ func TestUrlStorage_StoreURL(t *testing.T) {
 	}
 }
 
-func TestUrlStorage_StoreURLIfNotExists(t *testing.T) {
+func TestUrlStorage_StoreLinkIfNotExists(t *testing.T) {
 	t.Parallel()
 
 	testCases := []struct {
 		name string
 
-		code string
-		url  string
-		exp  int
+		workspace string
+		code      string
+		link      model.Link
+		exp       int
 
 		setupMock func() *redis.Client
 
-		expectOK  bool
-		expectErr error
+		expectOK   bool
+		expectErr  error
+		verifyFunc func(ctx context.Context, r *redis.Client)
 	}{
 		{
 			name: "normal case",
@@ -79,15 +83,112 @@ func TestUrlStorage_StoreURLIfNotExists(t *testing.T) {
 			},
 
 			code: "123",
-			url:  "https://google.com",
+			link: model.Link{URL: "https://google.com"},
+			exp:  10,
+
+			expectOK:  true,
+			expectErr: nil,
+		},
+		{
+			name: "with options",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.HSet(context.Background(), "url:{123}:meta", "title", "stale").Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			code: "123",
+			link: model.Link{
+				URL:            "https://google.com",
+				RedirectStatus: 301,
+				Title:          "Launch",
+				Params:         map[string]string{"utm_source": "news"},
+				PassQuery:      true,
+				Rules:          []model.Rule{{URL: "https://apps.apple.com/app/id1", Platforms: []string{"ios"}}},
+			},
+			exp: 10,
+
+			expectOK:  true,
+			expectErr: nil,
+			verifyFunc: func(ctx context.Context, r *redis.Client) {
+				meta, err := r.HGetAll(ctx, "url:{123}:meta").Result()
+				require.NoError(t, err)
+				assert.Equal(t, map[string]string{
+					"redirect_status": "301",
+					"title":           "Launch",
+					"params":          `{"utm_source":"news"}`,
+					"pass_query":      "1",
+					"rules":           `[{"url":"https://apps.apple.com/app/id1","platforms":["ios"]}]`,
+				}, meta)
+				assert.Equal(t, 10*time.Second, r.TTL(ctx, "url:{123}:meta").Val())
+			},
+		},
+		{
+			name: "owned link is indexed",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+
+			workspace: "ws1",
+			code:      "launch",
+			link:      model.Link{URL: "https://Example.com:8443/a", Owner: "u1", Tags: []string{"q3", "promo"}},
+			exp:       3600,
+
+			expectOK: true,
+			verifyFunc: func(ctx context.Context, r *redis.Client) {
+				assert.Equal(t, `["q3","promo"]`, r.HGet(ctx, "ws:ws1:url:{launch}:meta", "tags").Val())
+				links, err := NewLinkIndex(r).Scan(ctx, "ws1", "", model.LinkCursor{Sort: model.LinkSortCreated}, 10)
+				require.NoError(t, err)
+				require.Len(t, links, 1)
+				assert.Equal(t, "example.com", links[0].Host)
+				assert.Equal(t, []string{"q3", "promo"}, links[0].Tags)
+				assert.Equal(t, time.Hour, links[0].ExpiresAt.Sub(links[0].CreatedAt))
+				assert.Equal(t, int64(1), r.ZCard(ctx, "links:{expiry}").Val())
+			},
+		},
+		{
+			name: "stale options are dropped",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.HSet(context.Background(), "url:{123}:meta", "title", "stale").Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			code: "123",
+			link: model.Link{URL: "https://google.com"},
 			exp:  10,
 
 			expectOK:  true,
 			expectErr: nil,
+			verifyFunc: func(ctx context.Context, r *redis.Client) {
+				assert.Zero(t, r.Exists(ctx, "url:{123}:meta").Val())
+			},
 		},
 		{
 			name: "key already exists",
 
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.Set(context.Background(), "url:{123}", "old-url", time.Hour).Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			code: "123",
+			link: model.Link{URL: "https://google.com"},
+			exp:  10,
+
+			expectOK:  false,
+			expectErr: nil,
+		},
+		{
+			name: "legacy key already exists",
+
 			setupMock: func() *redis.Client {
 				mock := redisPkg.InitMockRedis(t)
 				err := mock.Set(context.Background(), "123", "old-url", time.Hour).Err()
@@ -96,12 +197,50 @@ func TestUrlStorage_StoreURLIfNotExists(t *testing.T) {
 			},
 
 			code: "123",
-			url:  "https://google.com",
+			link: model.Link{URL: "https://google.com"},
 			exp:  10,
 
 			expectOK:  false,
 			expectErr: nil,
 		},
+		{
+			name: "code taken in another namespace",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.Set(context.Background(), "url:{123}", "old-url", time.Hour).Err()
+				require.NoError(t, err)
+				err = mock.Set(context.Background(), "123", "old-url", time.Hour).Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			workspace: "team-a",
+			code:      "123",
+			link:      model.Link{URL: "https://google.com"},
+			exp:       10,
+
+			expectOK:  true,
+			expectErr: nil,
+		},
+		{
+			name: "key already exists in workspace",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.Set(context.Background(), "ws:team-a:url:{123}", "old-url", time.Hour).Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			workspace: "team-a",
+			code:      "123",
+			link:      model.Link{URL: "https://google.com"},
+			exp:       10,
+
+			expectOK:  false,
+			expectErr: nil,
+		},
 		{
 			name: "redis connection error",
 
@@ -123,23 +262,100 @@ func TestUrlStorage_StoreURLIfNotExists(t *testing.T) {
 			redisMock := tc.setupMock()
 			testRepo := NewUrlStorage(redisMock)
 
-			ok, err := testRepo.StoreURLIfNotExists(ctx, tc.code, tc.url, tc.exp)
+			ok, err := testRepo.StoreLinkIfNotExists(ctx, tc.workspace, tc.code, tc.link, tc.exp)
 
 			assert.Equal(t, tc.expectErr, err)
 			assert.Equal(t, tc.expectOK, ok)
+			if tc.verifyFunc != nil {
+				tc.verifyFunc(ctx, redisMock)
+			}
+
+		})
+	}
+}
+
+// failingLinkIndex is a LinkIndex failing to add links with err.
+type failingLinkIndex struct {
+	LinkIndex
+	err error
+}
+
+func (f failingLinkIndex) Add(context.Context, string, string, model.LinkSummary) error {
+	return f.err
+}
+
+// failingLinkExpiryIndex is a LinkExpiryIndex failing to add links with err.
+type failingLinkExpiryIndex struct {
+	LinkExpiryIndex
+	err error
+}
+
+func (f failingLinkExpiryIndex) Add(context.Context, model.LinkExpiry) error {
+	return f.err
+}
+
+func TestUrlStorage_StoreLinkIfNotExists_indexError(t *testing.T) {
+	t.Parallel()
+
+	errIndex := errors.New("index down")
+
+	testCases := []struct {
+		name string
+
+		setupRepo func(c *redis.Client) *urlStorage
+	}{
+		{
+			name: "link index error",
+
+			setupRepo: func(c *redis.Client) *urlStorage {
+				return &urlStorage{c: c, index: failingLinkIndex{err: errIndex}, expiry: NewLinkExpiryIndex(c)}
+			},
+		},
+		{
+			name: "expiry index error",
+
+			setupRepo: func(c *redis.Client) *urlStorage {
+				return &urlStorage{c: c, index: NewLinkIndex(c), expiry: failingLinkExpiryIndex{err: errIndex}}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			redisMock := redisPkg.InitMockRedis(t)
+			testRepo := tc.setupRepo(redisMock)
+
+			ok, err := testRepo.StoreLinkIfNotExists(ctx, "", "123", model.Link{URL: "https://google.com", Owner: "u1", Title: "Google"}, 10)
+			assert.ErrorIs(t, err, errIndex)
+			assert.False(t, ok)
+
+			n, err := redisMock.Exists(ctx, "url:{123}", "url:{123}:meta").Result()
+			require.NoError(t, err)
+			assert.Zero(t, n, "the code is left free")
+			n, err = redisMock.ZCard(ctx, linkExpiryKey).Result()
+			require.NoError(t, err)
+			assert.Zero(t, n, "the expiry is not scheduled")
 
+			ok, err = NewUrlStorage(redisMock).StoreLinkIfNotExists(ctx, "", "123", model.Link{URL: "https://google.com"}, 10)
+			require.NoError(t, err)
+			assert.True(t, ok, "the store can be retried")
 		})
 	}
 }
 
-func TestUrlStorage_GetURL(t *testing.T) {
+func TestUrlStorage_GetLink(t *testing.T) {
 	t.Parallel()
 
 	testCases := []struct {
 		name string
 
-		code string
-		url  string
+		workspace string
+		code      string
+		link      model.Link
+		expiresIn time.Duration
 
 		setupMock func() *redis.Client
 
@@ -148,8 +364,63 @@ func TestUrlStorage_GetURL(t *testing.T) {
 		{
 			name: "normal case",
 
+			code:      "ABC1234",
+			link:      model.Link{URL: "https://google.com"},
+			expiresIn: time.Hour,
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.Set(context.Background(), "url:{ABC1234}", "https://google.com", time.Hour).Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			expectedErr: nil,
+		},
+		{
+			name: "with options",
+
 			code: "ABC1234",
-			url:  "https://google.com",
+			link: model.Link{
+				URL:            "https://google.com",
+				RedirectStatus: 308,
+				Title:          "Launch",
+				Params:         map[string]string{"utm_source": "news", "utm_medium": "email"},
+				PassQuery:      true,
+				Rules: []model.Rule{
+					{URL: "https://example.fr", Countries: []string{"FR"}},
+					{URL: "https://example.com/night", Time: &model.TimeWindow{From: "22:00", To: "06:00", Timezone: "Europe/Paris"}},
+				},
+				Variants: []model.Variant{{Name: "a", URL: "https://example.com/a", Weight: 70}, {Name: "b", URL: "https://example.com/b", Weight: 30}},
+				Tags:     []string{"promo"},
+			},
+			expiresIn: time.Hour,
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.Set(context.Background(), "url:{ABC1234}", "https://google.com", time.Hour).Err()
+				require.NoError(t, err)
+				err = mock.HSet(context.Background(), "url:{ABC1234}:meta",
+					"redirect_status", "308",
+					"title", "Launch",
+					"params", `{"utm_source":"news","utm_medium":"email"}`,
+					"pass_query", "1",
+					"rules", `[{"url":"https://example.fr","countries":["FR"]},{"url":"https://example.com/night","time":{"from":"22:00","to":"06:00","timezone":"Europe/Paris"}}]`,
+					"variants", `[{"name":"a","url":"https://example.com/a","weight":70},{"name":"b","url":"https://example.com/b","weight":30}]`,
+					"tags", `["promo"]`,
+				).Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			expectedErr: nil,
+		},
+		{
+			name: "legacy key",
+
+			code:      "ABC1234",
+			link:      model.Link{URL: "https://google.com"},
+			expiresIn: time.Hour,
 
 			setupMock: func() *redis.Client {
 				mock := redisPkg.InitMockRedis(t)
@@ -160,6 +431,53 @@ func TestUrlStorage_GetURL(t *testing.T) {
 
 			expectedErr: nil,
 		},
+		{
+			name: "workspace key",
+
+			workspace: "team-a",
+			code:      "ABC1234",
+			link:      model.Link{URL: "https://google.com"},
+			expiresIn: time.Hour,
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.Set(context.Background(), "ws:team-a:url:{ABC1234}", "https://google.com", time.Hour).Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			expectedErr: nil,
+		},
+		{
+			name: "key without expiry",
+
+			code: "ABC1234",
+			link: model.Link{URL: "https://google.com"},
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.Set(context.Background(), "url:{ABC1234}", "https://google.com", 0).Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			expectedErr: nil,
+		},
+		{
+			name: "legacy key is not read in a workspace",
+
+			workspace: "team-a",
+			code:      "ABC1234",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.Set(context.Background(), "ABC1234", "https://google.com", time.Hour).Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			expectedErr: redis.Nil,
+		},
 		{
 			name: "key not found",
 
@@ -169,7 +487,6 @@ func TestUrlStorage_GetURL(t *testing.T) {
 			},
 
 			code:        "404",
-			url:         "",
 			expectedErr: redis.Nil,
 		},
 		{
@@ -182,7 +499,6 @@ func TestUrlStorage_GetURL(t *testing.T) {
 			},
 
 			code:        "123",
-			url:         "",
 			expectedErr: redis.ErrClosed,
 		},
 	}
@@ -195,10 +511,144 @@ func TestUrlStorage_GetURL(t *testing.T) {
 			redisMock := tc.setupMock()
 			testRepo := NewUrlStorage(redisMock)
 
-			url, err := testRepo.GetURL(ctx, tc.code)
+			link, err := testRepo.GetLink(ctx, tc.workspace, tc.code)
+
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expiresIn > 0 {
+				assert.WithinDuration(t, time.Now().Add(tc.expiresIn), link.ExpiresAt, time.Second)
+			} else {
+				assert.True(t, link.ExpiresAt.IsZero())
+			}
+			link.ExpiresAt = time.Time{}
+			assert.Equal(t, tc.link, link)
+		})
+	}
+}
+
+func TestUrlStorage_IncrClicks(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	redisMock := redisPkg.InitMockRedis(t)
+	testRepo := NewUrlStorage(redisMock)
+	ok, err := testRepo.StoreLinkIfNotExists(ctx, "ws1", "launch", model.Link{URL: "https://example.com", Owner: "u1"}, 3600)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, testRepo.IncrClicks(ctx, "ws1", "u1", "launch", ""))
+	require.NoError(t, testRepo.IncrClicks(ctx, "ws1", "u1", "launch", "b"))
+	require.NoError(t, testRepo.IncrClicks(ctx, "ws1", "u1", "launch", "b"))
+
+	clicks, err := redisMock.HGetAll(ctx, "ws:ws1:url:{launch}:clicks").Result()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"total": "3", "variant:b": "2"}, clicks)
+	assert.Equal(t, time.Hour, redisMock.TTL(ctx, "ws:ws1:url:{launch}:clicks").Val())
+	assert.Equal(t, float64(3), redisMock.ZScore(ctx, "workspace:{ws1}:links:clicks", "launch").Val())
+}
+
+func TestUrlStorage_TrashLink(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	redisMock := redisPkg.InitMockRedis(t)
+	testRepo := NewUrlStorage(redisMock)
+	ok, err := testRepo.StoreLinkIfNotExists(ctx, "ws1", "launch", model.Link{URL: "https://example.com", Owner: "u1", Title: "Launch"}, 3600)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, testRepo.IncrClicks(ctx, "ws1", "u1", "launch", ""))
+
+	moved, err := testRepo.TrashLink(ctx, "ws1", "launch")
+	require.NoError(t, err)
+	assert.True(t, moved)
+	_, err = testRepo.GetLink(ctx, "ws1", "launch")
+	assert.ErrorIs(t, err, redis.Nil)
+	assert.Equal(t, time.Hour, redisMock.TTL(ctx, "trash:ws:ws1:url:{launch}").Val(), "trashed links keep expiring")
+	ok, err = testRepo.StoreLinkIfNotExists(ctx, "ws1", "launch", model.Link{URL: "https://example.org"}, 3600)
+	require.NoError(t, err)
+	assert.False(t, ok, "the code of a trashed link stays taken")
+	moved, err = testRepo.TrashLink(ctx, "ws1", "launch")
+	require.NoError(t, err)
+	assert.False(t, moved)
+
+	restored, err := testRepo.RestoreLink(ctx, "ws1", "launch")
+	require.NoError(t, err)
+	assert.True(t, restored)
+	link, err := testRepo.GetLink(ctx, "ws1", "launch")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), link.ExpiresAt, time.Second, "restored links keep their expiry")
+	link.ExpiresAt = time.Time{}
+	assert.Equal(t, model.Link{URL: "https://example.com", Owner: "u1", Title: "Launch"}, link)
+	stats, err := testRepo.GetClicks(ctx, "ws1", "launch")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Total)
+
+	moved, err = testRepo.TrashLink(ctx, "ws1", "launch")
+	require.NoError(t, err)
+	require.True(t, moved)
+	require.NoError(t, testRepo.PurgeLink(ctx, "ws1", "launch"))
+	restored, err = testRepo.RestoreLink(ctx, "ws1", "launch")
+	require.NoError(t, err)
+	assert.False(t, restored)
+	ok, err = testRepo.StoreLinkIfNotExists(ctx, "ws1", "launch", model.Link{URL: "https://example.org"}, 3600)
+	require.NoError(t, err)
+	assert.True(t, ok, "the code of a purged link is free")
+}
+
+func TestUrlStorage_GetClicks(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func() *redis.Client
+
+		expectedStats model.ClickStats
+		expectedErr   error
+	}{
+		{
+			name: "no clicks",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+
+			expectedStats: model.ClickStats{Variants: map[string]int64{}},
+		},
+		{
+			name: "clicks per variant",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				err := mock.HSet(context.Background(), "url:{abc1234}:clicks", "total", 10, "variant:a", 7, "variant:b", 3).Err()
+				require.NoError(t, err)
+				return mock
+			},
+
+			expectedStats: model.ClickStats{Total: 10, Variants: map[string]int64{"a": 7, "b": 3}},
+		},
+		{
+			name: "redis connection error",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				_ = mock.Close()
+				return mock
+			},
+
+			expectedErr: redis.ErrClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			testRepo := NewUrlStorage(tc.setupMock())
+
+			stats, err := testRepo.GetClicks(t.Context(), "", "abc1234")
 
 			assert.Equal(t, tc.expectedErr, err)
-			assert.Equal(t, tc.url, url)
+			assert.Equal(t, tc.expectedStats, stats)
 		})
 	}
 }
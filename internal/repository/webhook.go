@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/redis/go-redis/v9"
+	"strings"
+)
+
+const (
+	webhookKeyPrefix = "webhook:"
+
+	webhookWorkspaceField = "workspace"
+	webhookOwnerField     = "owner"
+	webhookURLField       = "url"
+	webhookSecretField    = "secret"
+	webhookEventsField    = "events"
+	webhookCreatedAtField = "created_at"
+)
+
+// webhookKey returns the hash holding the webhook id.
+// The id is a hash tag, so the webhook and its delivery log land in the same Redis Cluster slot.
+func webhookKey(id string) string {
+	return webhookKeyPrefix + "{" + id + "}"
+}
+
+// webhooksKey returns the set of the IDs of the webhooks of workspace, or of owner when workspace is empty.
+func webhooksKey(workspace, owner string) string {
+	if workspace != "" {
+		return workspaceKey(workspace) + ":webhooks"
+	}
+	return userWorkspacesKeyPrefix + "{" + owner + "}:webhooks"
+}
+
+// WebhookStorage stores the webhook subscriptions of the workspaces and the users.
+//
+//go:generate mockery --name=WebhookStorage --filename webhook.go
+type WebhookStorage interface {
+	Create(ctx context.Context, hook model.Webhook) error
+	Get(ctx context.Context, id string) (model.Webhook, error)
+	List(ctx context.Context, workspace, owner string) ([]model.Webhook, error)
+	Delete(ctx context.Context, hook model.Webhook) error
+}
+
+type webhookStorage struct {
+	c redis.UniversalClient
+}
+
+// NewWebhookStorage returns a new instance of the webhookStorage, which implements the WebhookStorage interface.
+// Webhooks are listed per workspace, and per owner for the webhooks of the global links, which have no workspace.
+func NewWebhookStorage(c redis.UniversalClient) WebhookStorage {
+	return &webhookStorage{c: c}
+}
+
+// Create stores hook and adds it to the webhooks of its workspace or owner.
+func (s *webhookStorage) Create(ctx context.Context, hook model.Webhook) error {
+	_, err := s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, webhookKey(hook.ID),
+			webhookWorkspaceField, hook.Workspace,
+			webhookOwnerField, hook.Owner,
+			webhookURLField, hook.URL,
+			webhookSecretField, hook.Secret,
+			webhookEventsField, strings.Join(hook.Events, ","),
+			webhookCreatedAtField, hook.CreatedAt.Unix(),
+		)
+		p.SAdd(ctx, webhooksKey(hook.Workspace, hook.Owner), hook.ID)
+		return nil
+	})
+	return err
+}
+
+// Get returns the webhook id, and redis.Nil if it does not exist.
+func (s *webhookStorage) Get(ctx context.Context, id string) (model.Webhook, error) {
+	fields, err := s.c.HGetAll(ctx, webhookKey(id)).Result()
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	if len(fields) == 0 {
+		return model.Webhook{}, redis.Nil
+	}
+	return webhookFromFields(id, fields), nil
+}
+
+// List returns the webhooks of workspace, or of owner when workspace is empty, in no particular order.
+func (s *webhookStorage) List(ctx context.Context, workspace, owner string) ([]model.Webhook, error) {
+	ids, err := s.c.SMembers(ctx, webhooksKey(workspace, owner)).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	_, err = s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = p.HGetAll(ctx, webhookKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := make([]model.Webhook, 0, len(ids))
+	for i, cmd := range cmds {
+		if len(cmd.Val()) == 0 {
+			continue
+		}
+		hooks = append(hooks, webhookFromFields(ids[i], cmd.Val()))
+	}
+	return hooks, nil
+}
+
+// Delete removes hook and its delivery log, queued deliveries of hook are dropped when they are next attempted.
+func (s *webhookStorage) Delete(ctx context.Context, hook model.Webhook) error {
+	_, err := s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, webhookKey(hook.ID), webhookDeliveriesKey(hook.ID))
+		p.SRem(ctx, webhooksKey(hook.Workspace, hook.Owner), hook.ID)
+		return nil
+	})
+	return err
+}
+
+// webhookFromFields returns the webhook id read from the fields of its hash.
+func webhookFromFields(id string, fields map[string]string) model.Webhook {
+	hook := model.Webhook{
+		ID:        id,
+		Workspace: fields[webhookWorkspaceField],
+		Owner:     fields[webhookOwnerField],
+		URL:       fields[webhookURLField],
+		Secret:    fields[webhookSecretField],
+		CreatedAt: unixField(fields, webhookCreatedAtField),
+	}
+	if events := fields[webhookEventsField]; events != "" {
+		hook.Events = strings.Split(events, ",")
+	}
+	return hook
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+const (
+	webhookQueueKey          = "webhook:queue"
+	webhookDeliveryKeyPrefix = "webhook:delivery:"
+
+	// webhookDeliveryRetention is how long a delivery is kept after its last attempt, for the delivery log.
+	webhookDeliveryRetention = 7 * 24 * time.Hour
+	// maxWebhookDeliveriesLog bounds the delivery log of a webhook.
+	maxWebhookDeliveriesLog = 100
+
+	deliveryWebhookField       = "webhook"
+	deliveryEventField         = "event"
+	deliveryEventTypeField     = "event_type"
+	deliveryPayloadField       = "payload"
+	deliveryStatusField        = "status"
+	deliveryAttemptsField      = "attempts"
+	deliveryLastStatusField    = "last_status"
+	deliveryLastErrorField     = "last_error"
+	deliveryCreatedAtField     = "created_at"
+	deliveryNextAttemptAtField = "next_attempt_at"
+)
+
+// claimDeliveriesScript leases the deliveries due for an attempt.
+// KEYS[1] is the queue, ARGV[1] the current time and ARGV[2] the end of the lease in Unix milliseconds, ARGV[3] the
+// maximum number of deliveries to claim. Claimed deliveries are pushed back to the end of the lease, so they are
+// attempted again if the claiming instance stops before saving their outcome.
+var claimDeliveriesScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+for _, id in ipairs(ids) do
+	redis.call('ZADD', KEYS[1], ARGV[2], id)
+end
+return ids
+`)
+
+// webhookDeliveryKey returns the hash holding the delivery id.
+func webhookDeliveryKey(id string) string {
+	return webhookDeliveryKeyPrefix + "{" + id + "}"
+}
+
+// webhookDeliveriesKey returns the list of the IDs of the latest deliveries of the webhook id, newest first.
+func webhookDeliveriesKey(id string) string {
+	return webhookKey(id) + ":deliveries"
+}
+
+// WebhookQueue is the durable queue of the webhook deliveries, shared by every instance.
+//
+//go:generate mockery --name=WebhookQueue --filename webhook_queue.go
+type WebhookQueue interface {
+	Enqueue(ctx context.Context, d model.WebhookDelivery) error
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error)
+	Save(ctx context.Context, d model.WebhookDelivery) error
+	Log(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error)
+}
+
+type webhookQueue struct {
+	c redis.UniversalClient
+}
+
+// NewWebhookQueue returns a new instance of the webhookQueue, which implements the WebhookQueue interface.
+// Pending deliveries are scheduled in a sorted set by the time of their next attempt, and every delivery, dead ones
+// included, is kept for webhookDeliveryRetention after its last attempt in the delivery log of its webhook.
+func NewWebhookQueue(c redis.UniversalClient) WebhookQueue {
+	return &webhookQueue{c: c}
+}
+
+// Enqueue stores the new delivery d, schedules it and adds it to the delivery log of its webhook.
+func (q *webhookQueue) Enqueue(ctx context.Context, d model.WebhookDelivery) error {
+	if err := q.Save(ctx, d); err != nil {
+		return err
+	}
+	_, err := q.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.LPush(ctx, webhookDeliveriesKey(d.WebhookID), d.ID)
+		p.LTrim(ctx, webhookDeliveriesKey(d.WebhookID), 0, maxWebhookDeliveriesLog-1)
+		return nil
+	})
+	return err
+}
+
+// Claim leases up to limit deliveries whose next attempt is due at now for lease, and returns them.
+// Deliveries whose hash expired are dropped from the queue.
+func (q *webhookQueue) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error) {
+	ids, err := claimDeliveriesScript.Run(ctx, q.c, []string{webhookQueueKey}, now.UnixMilli(), now.Add(lease).UnixMilli(), limit).StringSlice()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	deliveries, missing, err := q.get(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		if err := q.c.ZRem(ctx, webhookQueueKey, missing).Err(); err != nil {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+// Save stores the state of d. A pending delivery is scheduled at its NextAttemptAt, others leave the queue.
+func (q *webhookQueue) Save(ctx context.Context, d model.WebhookDelivery) error {
+	_, err := q.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		key := webhookDeliveryKey(d.ID)
+		p.HSet(ctx, key,
+			deliveryWebhookField, d.WebhookID,
+			deliveryEventField, d.EventID,
+			deliveryEventTypeField, d.EventType,
+			deliveryPayloadField, d.Payload,
+			deliveryStatusField, d.Status,
+			deliveryAttemptsField, d.Attempts,
+			deliveryLastStatusField, d.LastStatus,
+			deliveryLastErrorField, d.LastError,
+			deliveryCreatedAtField, d.CreatedAt.Unix(),
+			deliveryNextAttemptAtField, d.NextAttemptAt.UnixMilli(),
+		)
+		p.Expire(ctx, key, webhookDeliveryRetention)
+
+		if d.Status == model.DeliveryPending {
+			p.ZAdd(ctx, webhookQueueKey, redis.Z{Score: float64(d.NextAttemptAt.UnixMilli()), Member: d.ID})
+		} else {
+			p.ZRem(ctx, webhookQueueKey, d.ID)
+		}
+		return nil
+	})
+	return err
+}
+
+// Log returns up to limit of the latest deliveries of the webhook webhookID, newest first.
+func (q *webhookQueue) Log(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	ids, err := q.c.LRange(ctx, webhookDeliveriesKey(webhookID), 0, int64(limit)-1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	deliveries, _, err := q.get(ctx, ids)
+	return deliveries, err
+}
+
+// get returns the deliveries ids in order, and the IDs of the ones that do not exist anymore.
+func (q *webhookQueue) get(ctx context.Context, ids []string) ([]model.WebhookDelivery, []string, error) {
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	_, err := q.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = p.HGetAll(ctx, webhookDeliveryKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deliveries := make([]model.WebhookDelivery, 0, len(ids))
+	var missing []string
+	for i, cmd := range cmds {
+		if len(cmd.Val()) == 0 {
+			missing = append(missing, ids[i])
+			continue
+		}
+		deliveries = append(deliveries, deliveryFromFields(ids[i], cmd.Val()))
+	}
+	return deliveries, missing, nil
+}
+
+// deliveryFromFields returns the delivery id read from the fields of its hash.
+func deliveryFromFields(id string, fields map[string]string) model.WebhookDelivery {
+	d := model.WebhookDelivery{
+		ID:        id,
+		WebhookID: fields[deliveryWebhookField],
+		EventID:   fields[deliveryEventField],
+		EventType: fields[deliveryEventTypeField],
+		Payload:   fields[deliveryPayloadField],
+		Status:    fields[deliveryStatusField],
+		LastError: fields[deliveryLastErrorField],
+		CreatedAt: unixField(fields, deliveryCreatedAtField),
+	}
+	d.Attempts, _ = strconv.Atoi(fields[deliveryAttemptsField])
+	d.LastStatus, _ = strconv.Atoi(fields[deliveryLastStatusField])
+	if ms, err := strconv.ParseInt(fields[deliveryNextAttemptAtField], 10, 64); err == nil {
+		d.NextAttemptAt = time.UnixMilli(ms).UTC()
+	}
+	return d
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWebhookQueue_Claim(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMilli(1700000000000).UTC()
+	pending := func(id string, next time.Time) model.WebhookDelivery {
+		return model.WebhookDelivery{
+			ID:            id,
+			WebhookID:     "wh1",
+			EventID:       "ev-" + id,
+			EventType:     model.EventLinkClicked,
+			Payload:       `{"code":"abc"}`,
+			Status:        model.DeliveryPending,
+			CreatedAt:     time.Unix(1700000000, 0).UTC(),
+			NextAttemptAt: next,
+		}
+	}
+
+	testCases := []struct {
+		name string
+
+		setup func(ctx context.Context, q WebhookQueue, c *redis.Client)
+
+		expectIDs []string
+	}{
+		{
+			name: "due deliveries only",
+
+			setup: func(ctx context.Context, q WebhookQueue, _ *redis.Client) {
+				require.NoError(t, q.Enqueue(ctx, pending("d1", now.Add(-time.Second))))
+				require.NoError(t, q.Enqueue(ctx, pending("d2", now)))
+				require.NoError(t, q.Enqueue(ctx, pending("d3", now.Add(time.Second))))
+			},
+
+			expectIDs: []string{"d1", "d2"},
+		},
+		{
+			name: "leased deliveries are hidden",
+
+			setup: func(ctx context.Context, q WebhookQueue, _ *redis.Client) {
+				require.NoError(t, q.Enqueue(ctx, pending("d1", now)))
+				claimed, err := q.Claim(ctx, now, time.Minute, 10)
+				require.NoError(t, err)
+				require.Len(t, claimed, 1)
+			},
+		},
+		{
+			name: "delivered and dead deliveries leave the queue",
+
+			setup: func(ctx context.Context, q WebhookQueue, _ *redis.Client) {
+				delivered, dead := pending("d1", now), pending("d2", now)
+				require.NoError(t, q.Enqueue(ctx, delivered))
+				require.NoError(t, q.Enqueue(ctx, dead))
+				delivered.Status, dead.Status = model.DeliveryDelivered, model.DeliveryDead
+				require.NoError(t, q.Save(ctx, delivered))
+				require.NoError(t, q.Save(ctx, dead))
+			},
+		},
+		{
+			name: "expired deliveries are dropped",
+
+			setup: func(ctx context.Context, q WebhookQueue, c *redis.Client) {
+				require.NoError(t, q.Enqueue(ctx, pending("d1", now)))
+				require.NoError(t, q.Enqueue(ctx, pending("d2", now)))
+				require.NoError(t, c.Del(ctx, webhookDeliveryKey("d1")).Err())
+			},
+
+			expectIDs: []string{"d2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			client := redisPkg.InitMockRedis(t)
+			testRepo := NewWebhookQueue(client)
+			tc.setup(ctx, testRepo, client)
+
+			claimed, err := testRepo.Claim(ctx, now, time.Minute, 10)
+			require.NoError(t, err)
+			ids := make([]string, 0, len(claimed))
+			for _, d := range claimed {
+				ids = append(ids, d.ID)
+			}
+			assert.ElementsMatch(t, tc.expectIDs, ids)
+		})
+	}
+}
+
+func TestWebhookQueue_SaveAndLog(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	client := redisPkg.InitMockRedis(t)
+	testRepo := NewWebhookQueue(client)
+
+	first := model.WebhookDelivery{
+		ID:            "d1",
+		WebhookID:     "wh1",
+		EventID:       "ev1",
+		EventType:     model.EventLinkCreated,
+		Payload:       `{"code":"abc"}`,
+		Status:        model.DeliveryPending,
+		CreatedAt:     time.Unix(1700000000, 0).UTC(),
+		NextAttemptAt: time.UnixMilli(1700000000000).UTC(),
+	}
+	second := first
+	second.ID, second.EventID = "d2", "ev2"
+	require.NoError(t, testRepo.Enqueue(ctx, first))
+	require.NoError(t, testRepo.Enqueue(ctx, second))
+
+	first.Status, first.Attempts, first.LastStatus, first.LastError = model.DeliveryDead, 3, 500, "unexpected status 500"
+	require.NoError(t, testRepo.Save(ctx, first))
+
+	log, err := testRepo.Log(ctx, "wh1", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.WebhookDelivery{second, first}, log)
+
+	assert.Equal(t, []string{"d2"}, client.ZRange(ctx, webhookQueueKey, 0, -1).Val(), "a dead delivery leaves the queue")
+	assert.Positive(t, client.TTL(ctx, webhookDeliveryKey("d1")).Val())
+}
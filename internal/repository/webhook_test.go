@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWebhookStorage_Get(t *testing.T) {
+	t.Parallel()
+
+	hook := model.Webhook{
+		ID:        "wh1",
+		Workspace: "ws1",
+		Owner:     "u1",
+		URL:       "https://hooks.example.com/in",
+		Secret:    "s3cret",
+		Events:    []string{model.EventLinkClicked, model.EventLinkCreated},
+		CreatedAt: time.Unix(1700000000, 0).UTC(),
+	}
+
+	testCases := []struct {
+		name string
+
+		setup func(ctx context.Context, r WebhookStorage)
+
+		expected  model.Webhook
+		expectErr error
+	}{
+		{
+			name: "normal case",
+
+			setup: func(ctx context.Context, r WebhookStorage) {
+				require.NoError(t, r.Create(ctx, hook))
+			},
+
+			expected: hook,
+		},
+		{
+			name: "deleted",
+
+			setup: func(ctx context.Context, r WebhookStorage) {
+				require.NoError(t, r.Create(ctx, hook))
+				require.NoError(t, r.Delete(ctx, hook))
+			},
+
+			expectErr: redis.Nil,
+		},
+		{
+			name: "not created",
+
+			setup: func(ctx context.Context, r WebhookStorage) {},
+
+			expectErr: redis.Nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			testRepo := NewWebhookStorage(redisPkg.InitMockRedis(t))
+			tc.setup(ctx, testRepo)
+
+			got, err := testRepo.Get(ctx, "wh1")
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestWebhookStorage_List(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		workspace string
+		owner     string
+
+		expectIDs []string
+	}{
+		{
+			name: "workspace webhooks",
+
+			workspace: "ws1",
+			owner:     "u1",
+
+			expectIDs: []string{"wh-ws1"},
+		},
+		{
+			name: "user webhooks",
+
+			owner: "u1",
+
+			expectIDs: []string{"wh-u1"},
+		},
+		{
+			name: "none",
+
+			owner: "u2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			testRepo := NewWebhookStorage(redisPkg.InitMockRedis(t))
+			require.NoError(t, testRepo.Create(ctx, model.Webhook{ID: "wh-ws1", Workspace: "ws1", Owner: "u1", Events: []string{model.EventLinkCreated}}))
+			require.NoError(t, testRepo.Create(ctx, model.Webhook{ID: "wh-u1", Owner: "u1", Events: []string{model.EventLinkCreated}}))
+
+			hooks, err := testRepo.List(ctx, tc.workspace, tc.owner)
+			require.NoError(t, err)
+			ids := make([]string, 0, len(hooks))
+			for _, h := range hooks {
+				ids = append(ids, h.ID)
+			}
+			assert.ElementsMatch(t, tc.expectIDs, ids)
+		})
+	}
+}
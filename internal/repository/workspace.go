@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	workspaceKeyPrefix      = "workspace:"
+	userWorkspacesKeyPrefix = "user:"
+
+	workspaceNameField      = "name"
+	workspaceCreatedAtField = "created_at"
+)
+
+// workspaceKey returns the hash holding the attributes of the workspace id.
+// The id is a hash tag, so the workspace and its members land in the same Redis Cluster slot.
+func workspaceKey(id string) string {
+	return workspaceKeyPrefix + "{" + id + "}"
+}
+
+// workspaceMembersKey returns the hash mapping the user IDs of the members of the workspace id to their role.
+func workspaceMembersKey(id string) string {
+	return workspaceKey(id) + ":members"
+}
+
+// userWorkspacesKey returns the set of the IDs of the workspaces userID is a member of.
+func userWorkspacesKey(userID string) string {
+	return userWorkspacesKeyPrefix + "{" + userID + "}:workspaces"
+}
+
+// WorkspaceStorage stores workspaces and the roles of their members.
+//
+//go:generate mockery --name=WorkspaceStorage --filename workspace.go
+type WorkspaceStorage interface {
+	Create(ctx context.Context, ws model.Workspace, ownerID string) error
+	Get(ctx context.Context, id string) (model.Workspace, error)
+	ListForUser(ctx context.Context, userID string) ([]string, error)
+	Role(ctx context.Context, id, userID string) (string, error)
+	Members(ctx context.Context, id string) (map[string]string, error)
+	SetMember(ctx context.Context, id, userID, role string) error
+	RemoveMember(ctx context.Context, id, userID string) error
+}
+
+type workspaceStorage struct {
+	c redis.UniversalClient
+}
+
+// NewWorkspaceStorage returns a new instance of the workspaceStorage, which implements the WorkspaceStorage interface.
+func NewWorkspaceStorage(c redis.UniversalClient) WorkspaceStorage {
+	return &workspaceStorage{c: c}
+}
+
+// Create stores ws with ownerID as its owner.
+func (s *workspaceStorage) Create(ctx context.Context, ws model.Workspace, ownerID string) error {
+	_, err := s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, workspaceKey(ws.ID),
+			workspaceNameField, ws.Name,
+			workspaceCreatedAtField, ws.CreatedAt.Unix(),
+		)
+		p.HSet(ctx, workspaceMembersKey(ws.ID), ownerID, model.RoleOwner)
+		p.SAdd(ctx, userWorkspacesKey(ownerID), ws.ID)
+		return nil
+	})
+	return err
+}
+
+// Get returns the workspace id, and redis.Nil if it does not exist.
+func (s *workspaceStorage) Get(ctx context.Context, id string) (model.Workspace, error) {
+	fields, err := s.c.HGetAll(ctx, workspaceKey(id)).Result()
+	if err != nil {
+		return model.Workspace{}, err
+	}
+	if len(fields) == 0 {
+		return model.Workspace{}, redis.Nil
+	}
+
+	return model.Workspace{
+		ID:        id,
+		Name:      fields[workspaceNameField],
+		CreatedAt: unixField(fields, workspaceCreatedAtField),
+	}, nil
+}
+
+// ListForUser returns the IDs of the workspaces userID is a member of, in no particular order.
+func (s *workspaceStorage) ListForUser(ctx context.Context, userID string) ([]string, error) {
+	return s.c.SMembers(ctx, userWorkspacesKey(userID)).Result()
+}
+
+// Role returns the role of userID in the workspace id, and redis.Nil if they are not a member.
+func (s *workspaceStorage) Role(ctx context.Context, id, userID string) (string, error) {
+	return s.c.HGet(ctx, workspaceMembersKey(id), userID).Result()
+}
+
+// Members returns the role of every member of the workspace id by user ID.
+func (s *workspaceStorage) Members(ctx context.Context, id string) (map[string]string, error) {
+	return s.c.HGetAll(ctx, workspaceMembersKey(id)).Result()
+}
+
+// SetMember adds userID to the workspace id with role, or changes their role if they are already a member.
+func (s *workspaceStorage) SetMember(ctx context.Context, id, userID, role string) error {
+	_, err := s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, workspaceMembersKey(id), userID, role)
+		p.SAdd(ctx, userWorkspacesKey(userID), id)
+		return nil
+	})
+	return err
+}
+
+// RemoveMember removes userID from the workspace id.
+func (s *workspaceStorage) RemoveMember(ctx context.Context, id, userID string) error {
+	_, err := s.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HDel(ctx, workspaceMembersKey(id), userID)
+		p.SRem(ctx, userWorkspacesKey(userID), id)
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWorkspaceStorage_Create(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func() *redis.Client
+
+		expectErr  error
+		verifyFunc func(ctx context.Context, r *redis.Client)
+	}{
+		{
+			name: "normal case",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+
+			verifyFunc: func(ctx context.Context, r *redis.Client) {
+				fields, err := r.HGetAll(ctx, "workspace:{ws1}").Result()
+				require.NoError(t, err)
+				assert.Equal(t, map[string]string{"name": "Team A", "created_at": "1700000000"}, fields)
+
+				role, err := r.HGet(ctx, "workspace:{ws1}:members", "alice").Result()
+				require.NoError(t, err)
+				assert.Equal(t, model.RoleOwner, role)
+
+				ids, err := r.SMembers(ctx, "user:{alice}:workspaces").Result()
+				require.NoError(t, err)
+				assert.Equal(t, []string{"ws1"}, ids)
+			},
+		},
+		{
+			name: "redis connection error",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				_ = mock.Close()
+				return mock
+			},
+
+			expectErr: redis.ErrClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			redisMock := tc.setupMock()
+			testRepo := NewWorkspaceStorage(redisMock)
+
+			err := testRepo.Create(ctx, model.Workspace{ID: "ws1", Name: "Team A", CreatedAt: time.Unix(1700000000, 0)}, "alice")
+			assert.Equal(t, tc.expectErr, err)
+			if err == nil {
+				tc.verifyFunc(ctx, redisMock)
+			}
+		})
+	}
+}
+
+func TestWorkspaceStorage_Get(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func() *redis.Client
+
+		expected  model.Workspace
+		expectErr error
+	}{
+		{
+			name: "normal case",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				require.NoError(t, mock.HSet(context.Background(), "workspace:{ws1}", "name", "Team A", "created_at", 1700000000).Err())
+				return mock
+			},
+
+			expected: model.Workspace{ID: "ws1", Name: "Team A", CreatedAt: time.Unix(1700000000, 0).UTC()},
+		},
+		{
+			name: "not found",
+
+			setupMock: func() *redis.Client {
+				return redisPkg.InitMockRedis(t)
+			},
+
+			expectErr: redis.Nil,
+		},
+		{
+			name: "redis connection error",
+
+			setupMock: func() *redis.Client {
+				mock := redisPkg.InitMockRedis(t)
+				_ = mock.Close()
+				return mock
+			},
+
+			expectErr: redis.ErrClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			testRepo := NewWorkspaceStorage(tc.setupMock())
+
+			ws, err := testRepo.Get(t.Context(), "ws1")
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expected, ws)
+		})
+	}
+}
+
+func TestWorkspaceStorage_Members(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		update func(ctx context.Context, r WorkspaceStorage) error
+
+		expectedMembers map[string]string
+		expectedBobWs   []string
+		expectedRoleErr error
+	}{
+		{
+			name: "add member",
+
+			update: func(ctx context.Context, r WorkspaceStorage) error {
+				return r.SetMember(ctx, "ws1", "bob", model.RoleViewer)
+			},
+
+			expectedMembers: map[string]string{"alice": model.RoleOwner, "bob": model.RoleViewer},
+			expectedBobWs:   []string{"ws1"},
+		},
+		{
+			name: "change role",
+
+			update: func(ctx context.Context, r WorkspaceStorage) error {
+				if err := r.SetMember(ctx, "ws1", "bob", model.RoleViewer); err != nil {
+					return err
+				}
+				return r.SetMember(ctx, "ws1", "bob", model.RoleAdmin)
+			},
+
+			expectedMembers: map[string]string{"alice": model.RoleOwner, "bob": model.RoleAdmin},
+			expectedBobWs:   []string{"ws1"},
+		},
+		{
+			name: "remove member",
+
+			update: func(ctx context.Context, r WorkspaceStorage) error {
+				if err := r.SetMember(ctx, "ws1", "bob", model.RoleMember); err != nil {
+					return err
+				}
+				return r.RemoveMember(ctx, "ws1", "bob")
+			},
+
+			expectedMembers: map[string]string{"alice": model.RoleOwner},
+			expectedBobWs:   []string{},
+			expectedRoleErr: redis.Nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			testRepo := NewWorkspaceStorage(redisPkg.InitMockRedis(t))
+			require.NoError(t, testRepo.Create(ctx, model.Workspace{ID: "ws1", Name: "Team A"}, "alice"))
+
+			require.NoError(t, tc.update(ctx, testRepo))
+
+			members, err := testRepo.Members(ctx, "ws1")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMembers, members)
+
+			ids, err := testRepo.ListForUser(ctx, "bob")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedBobWs, ids)
+
+			role, err := testRepo.Role(ctx, "ws1", "bob")
+			assert.Equal(t, tc.expectedRoleErr, err)
+			assert.Equal(t, tc.expectedMembers["bob"], role)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// ClickRecorder counts the redirects and publishes their link.clicked events in the background, so a redirect never
+// waits for Redis.
+//
+//go:generate mockery --name ClickRecorder --filename click_recorder.go
+type ClickRecorder interface {
+	Record(ctx context.Context, click model.Event)
+	Run(ctx context.Context, beat func()) error
+}
+
+// queuedClick is a click waiting in the queue of the clickRecorder, with the values of the context of its request.
+type queuedClick struct {
+	ctx   context.Context
+	event model.Event
+}
+
+type clickRecorder struct {
+	links    ShortenUrl
+	webhooks Webhook
+	queue    chan queuedClick
+	timeout  time.Duration
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewClickRecorder returns a new instance of the clickRecorder, which implements the ClickRecorder interface.
+// Up to size clicks wait in its queue, each is recorded within timeout, and Run beats every interval while idle.
+// The webhooks are optional, when it is nil no link.clicked events are published.
+func NewClickRecorder(links ShortenUrl, webhooks Webhook, size int, timeout, interval time.Duration) ClickRecorder {
+	return &clickRecorder{
+		links:    links,
+		webhooks: webhooks,
+		queue:    make(chan queuedClick, size),
+		timeout:  timeout,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Record queues click, a link.clicked event, without blocking. The click is dropped and logged when the queue is full.
+// The values of ctx, such as its logger and span, are kept past its cancellation for the recording.
+func (r *clickRecorder) Record(ctx context.Context, click model.Event) {
+	if click.OccurredAt.IsZero() {
+		click.OccurredAt = r.now().UTC().Truncate(time.Second)
+	}
+	select {
+	case r.queue <- queuedClick{ctx: context.WithoutCancel(ctx), event: click}:
+	default:
+		log.Ctx(ctx).Warn().Str("code", click.Code).Msg("Click queue full, dropping the click")
+	}
+}
+
+// Run records the queued clicks until ctx is canceled, then records the clicks left in the queue and returns.
+// beat is called on every interval and after every click, so a stuck recorder can be told apart.
+func (r *clickRecorder) Run(ctx context.Context, beat func()) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		beat()
+		select {
+		case <-ctx.Done():
+			r.drain()
+			return ctx.Err()
+		case click := <-r.queue:
+			r.record(click)
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain records the clicks left in the queue.
+func (r *clickRecorder) drain() {
+	for {
+		select {
+		case click := <-r.queue:
+			r.record(click)
+		default:
+			return
+		}
+	}
+}
+
+// record counts click and publishes its event, errors are logged.
+func (r *clickRecorder) record(click queuedClick) {
+	ctx, cancel := context.WithTimeout(click.ctx, r.timeout)
+	defer cancel()
+
+	event := click.event
+	if err := r.links.RecordClick(ctx, event.Workspace, event.Owner, event.Code, event.Variant); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("code", event.Code).Msg("Cannot record the click")
+	}
+	if r.webhooks == nil {
+		return
+	}
+	if err := r.webhooks.Publish(ctx, event); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("code", event.Code).Str("event", event.Type).Msg("Cannot publish the webhook event")
+	}
+}
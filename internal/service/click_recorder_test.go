@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	serviceMocks "github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestClickRecorder_Run(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	click := model.Event{Type: model.EventLinkClicked, Workspace: "ws1", Owner: "u1", Code: "launch", URL: "https://example.com/b", Variant: "b"}
+	dropped := model.Event{Type: model.EventLinkClicked, Code: "dropped"}
+
+	testCases := []struct {
+		name string
+
+		setupMock func(t *testing.T) (*serviceMocks.ShortenUrl, *serviceMocks.Webhook)
+	}{
+		{
+			name: "clicks are recorded and published, the ones past the queue dropped",
+
+			setupMock: func(t *testing.T) (*serviceMocks.ShortenUrl, *serviceMocks.Webhook) {
+				links, webhooks := serviceMocks.NewShortenUrl(t), serviceMocks.NewWebhook(t)
+				published := click
+				published.OccurredAt = now
+				links.On("RecordClick", mock.Anything, "ws1", "u1", "launch", "b").Return(nil).Twice()
+				webhooks.On("Publish", mock.Anything, published).Return(nil).Twice()
+				return links, webhooks
+			},
+		},
+		{
+			name: "errors are logged",
+
+			setupMock: func(t *testing.T) (*serviceMocks.ShortenUrl, *serviceMocks.Webhook) {
+				links, webhooks := serviceMocks.NewShortenUrl(t), serviceMocks.NewWebhook(t)
+				links.On("RecordClick", mock.Anything, "ws1", "u1", "launch", "b").Return(testError).Twice()
+				webhooks.On("Publish", mock.Anything, mock.Anything).Return(testError).Twice()
+				return links, webhooks
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			links, webhooks := tc.setupMock(t)
+			recorder := NewClickRecorder(links, webhooks, 2, time.Second, time.Hour)
+			recorder.(*clickRecorder).now = func() time.Time { return now }
+
+			// The request of a click may be over by the time it is recorded.
+			ctx, cancel := context.WithCancel(context.Background())
+			recorder.Record(ctx, click)
+			recorder.Record(ctx, click)
+			recorder.Record(ctx, dropped)
+			cancel()
+
+			// The queued clicks are still recorded once the recorder is stopped.
+			runCtx, stop := context.WithCancel(context.Background())
+			stop()
+			assert.Equal(t, context.Canceled, recorder.Run(runCtx, func() {}))
+		})
+	}
+}
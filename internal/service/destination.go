@@ -0,0 +1,113 @@
+package service
+
+import (
+	"github.com/lhducc/bookmark-management/internal/model"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	maxLinkParams       = 20
+	maxLinkParamsLength = 2048
+	// PreviewQueryParam asks for the preview page of a link, it is never passed through to the destination.
+	PreviewQueryParam = "preview"
+)
+
+// queryParam is a parameter of a raw query string, key is decoded and raw is the parameter as written.
+type queryParam struct {
+	key string
+	raw string
+}
+
+// Destination returns the URL the link redirects to, requestQuery being the raw query string of the request to the code.
+// The query string of the destination is built in three layers, a parameter of a layer replaces every parameter of the same name of the layers below:
+//  1. the query string of the URL of the link
+//  2. the params of the link
+//  3. the query string of the request, when the link passes it through, except the preview parameter
+//
+// Parameters that are not replaced are kept as written, in their order, so destinations with unusual encodings are left untouched.
+// The fragment of the URL of the link is kept. A URL that cannot be parsed is returned as is.
+func Destination(link model.Link, requestQuery string) string {
+	passed := []queryParam(nil)
+	if link.PassQuery {
+		passed = slices.DeleteFunc(splitQuery(requestQuery), func(p queryParam) bool { return p.key == PreviewQueryParam })
+	}
+	if len(link.Params) == 0 && len(passed) == 0 {
+		return link.URL
+	}
+
+	u, err := url.Parse(link.URL)
+	if err != nil {
+		return link.URL
+	}
+
+	query := overrideQuery(splitQuery(u.RawQuery), linkParams(link.Params))
+	query = overrideQuery(query, passed)
+
+	raw := make([]string, 0, len(query))
+	for _, p := range query {
+		raw = append(raw, p.raw)
+	}
+	u.RawQuery, u.ForceQuery = strings.Join(raw, "&"), false
+	return u.String()
+}
+
+// validLinkParams reports whether params can be stored with a link: at most maxLinkParams non-empty names
+// and maxLinkParamsLength bytes of names and values.
+func validLinkParams(params map[string]string) bool {
+	if len(params) > maxLinkParams {
+		return false
+	}
+	size := 0
+	for k, v := range params {
+		if k == "" {
+			return false
+		}
+		size += len(k) + len(v)
+	}
+	return size <= maxLinkParamsLength
+}
+
+// splitQuery splits the raw query string rawQuery into its parameters, empty ones are dropped.
+// A key that is not valid percent-encoding is kept as written.
+func splitQuery(rawQuery string) []queryParam {
+	var params []queryParam
+	for _, raw := range strings.Split(rawQuery, "&") {
+		if raw == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(raw, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		params = append(params, queryParam{key: key, raw: raw})
+	}
+	return params
+}
+
+// linkParams returns the params of a link as query parameters sorted by name.
+func linkParams(params map[string]string) []queryParam {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	query := make([]queryParam, 0, len(keys))
+	for _, k := range keys {
+		query = append(query, queryParam{key: k, raw: url.QueryEscape(k) + "=" + url.QueryEscape(params[k])})
+	}
+	return query
+}
+
+// overrideQuery returns the parameters of base that top has no parameter of the same name of, followed by top.
+func overrideQuery(base, top []queryParam) []queryParam {
+	if len(top) == 0 {
+		return base
+	}
+	merged := slices.DeleteFunc(slices.Clone(base), func(p queryParam) bool {
+		return slices.ContainsFunc(top, func(t queryParam) bool { return t.key == p.key })
+	})
+	return append(merged, top...)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDestination(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		link         model.Link
+		requestQuery string
+
+		expected string
+	}{
+		{
+			name: "no params",
+
+			link:         model.Link{URL: "https://example.com/a?b=1"},
+			requestQuery: "utm_source=x",
+
+			expected: "https://example.com/a?b=1",
+		},
+		{
+			name: "params are appended sorted by name",
+
+			link: model.Link{URL: "https://example.com/a", Params: map[string]string{"utm_source": "news", "utm_medium": "email"}},
+
+			expected: "https://example.com/a?utm_medium=email&utm_source=news",
+		},
+		{
+			name: "params replace the parameters of the url",
+
+			link: model.Link{URL: "https://example.com/a?utm_source=old&id=7&utm_source=older", Params: map[string]string{"utm_source": "news"}},
+
+			expected: "https://example.com/a?id=7&utm_source=news",
+		},
+		{
+			name: "request query replaces params and url parameters",
+
+			link: model.Link{
+				URL:       "https://example.com/a?id=7&ref=site",
+				Params:    map[string]string{"utm_source": "news", "utm_campaign": "spring"},
+				PassQuery: true,
+			},
+			requestQuery: "utm_source=ads&ref=partner&ref=mobile",
+
+			expected: "https://example.com/a?id=7&utm_campaign=spring&utm_source=ads&ref=partner&ref=mobile",
+		},
+		{
+			name: "request query is dropped unless passed through",
+
+			link:         model.Link{URL: "https://example.com/a", Params: map[string]string{"utm_source": "news"}},
+			requestQuery: "utm_source=ads",
+
+			expected: "https://example.com/a?utm_source=news",
+		},
+		{
+			name: "preview parameter is not passed through",
+
+			link:         model.Link{URL: "https://example.com/a", PassQuery: true},
+			requestQuery: "preview=1&x=1",
+
+			expected: "https://example.com/a?x=1",
+		},
+		{
+			name: "only the preview parameter",
+
+			link:         model.Link{URL: "https://example.com/a?b=1", PassQuery: true},
+			requestQuery: "preview=1",
+
+			expected: "https://example.com/a?b=1",
+		},
+		{
+			name: "special characters in params are encoded",
+
+			link: model.Link{URL: "https://example.com/a", Params: map[string]string{"q": "a b&c=d/é", "x y": "+"}},
+
+			expected: "https://example.com/a?q=a+b%26c%3Dd%2F%C3%A9&x+y=%2B",
+		},
+		{
+			name: "encoded names match decoded ones",
+
+			link:         model.Link{URL: "https://example.com/a?utm%5Fsource=old&a+b=1", Params: map[string]string{"utm_source": "news"}, PassQuery: true},
+			requestQuery: "a%20b=2",
+
+			expected: "https://example.com/a?utm_source=news&a%20b=2",
+		},
+		{
+			name: "untouched parameters are kept as written",
+
+			link: model.Link{URL: "https://example.com/a?path=%2Fx%2Fy&flag&list=a,b;c&bad=%zz", Params: map[string]string{"utm_source": "news"}},
+
+			expected: "https://example.com/a?path=%2Fx%2Fy&flag&list=a,b;c&bad=%zz&utm_source=news",
+		},
+		{
+			name: "fragment and escaped path are kept",
+
+			link: model.Link{URL: "https://example.com/a%2Fb/c?x=1#section-2", Params: map[string]string{"utm_source": "news"}},
+
+			expected: "https://example.com/a%2Fb/c?x=1&utm_source=news#section-2",
+		},
+		{
+			name: "empty parameters are dropped",
+
+			link:         model.Link{URL: "https://example.com/a?&x=1&&", PassQuery: true},
+			requestQuery: "&y=2&",
+
+			expected: "https://example.com/a?x=1&y=2",
+		},
+		{
+			name: "empty param value",
+
+			link: model.Link{URL: "https://example.com/a", Params: map[string]string{"ref": ""}},
+
+			expected: "https://example.com/a?ref=",
+		},
+		{
+			name: "unparsable url is returned as is",
+
+			link: model.Link{URL: "https://exa mple.com/%zz", Params: map[string]string{"utm_source": "news"}},
+
+			expected: "https://exa mple.com/%zz",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, Destination(tc.link, tc.requestQuery))
+		})
+	}
+}
+
+func TestValidLinkParams(t *testing.T) {
+	t.Parallel()
+
+	tooMany := map[string]string{}
+	for _, k := range strings.Split("a b c d e f g h i j k l m n o p q r s t u", " ") {
+		tooMany[k] = "1"
+	}
+
+	testCases := []struct {
+		name string
+
+		params map[string]string
+
+		expected bool
+	}{
+		{
+			name: "none",
+
+			expected: true,
+		},
+		{
+			name: "utm params",
+
+			params: map[string]string{"utm_source": "news", "utm_medium": "email", "utm_campaign": "spring"},
+
+			expected: true,
+		},
+		{
+			name: "empty name",
+
+			params: map[string]string{"": "x"},
+
+			expected: false,
+		},
+		{
+			name: "too many",
+
+			params: tooMany,
+
+			expected: false,
+		},
+		{
+			name: "too large",
+
+			params: map[string]string{"q": strings.Repeat("x", maxLinkParamsLength)},
+
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, validLinkParams(tc.params))
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository"
+	"github.com/lhducc/bookmark-management/pkg/lrucache"
+	"github.com/lhducc/bookmark-management/pkg/stringutils"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DomainVerificationLabel prefixes the custom domain in the name of its verification TXT record.
+	DomainVerificationLabel = "_bookmark-verify."
+	// DomainVerificationValuePrefix prefixes the token in the value of the verification TXT record.
+	DomainVerificationValuePrefix = "bookmark-verify="
+
+	domainTokenLength = 32
+	domainCacheSize   = 1024
+	maxHostLength     = 253
+)
+
+var (
+	ErrInvalidDomain     = errors.New("invalid domain")
+	ErrDomainTaken       = errors.New("domain already registered")
+	ErrDomainNotFound    = errors.New("domain not found")
+	ErrDomainNotVerified = errors.New("domain verification record not found")
+)
+
+var hostLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// TXTResolver looks up DNS TXT records, *net.Resolver implements it.
+//
+//go:generate mockery --name TXTResolver --filename txt_resolver.go
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Domain manages the custom short domains of the workspaces.
+//
+//go:generate mockery --name Domain --filename domain.go
+type Domain interface {
+	Register(ctx context.Context, workspace, host string) (model.Domain, error)
+	Verify(ctx context.Context, workspace, host string) (model.Domain, error)
+	List(ctx context.Context, workspace string) ([]model.Domain, error)
+	Delete(ctx context.Context, workspace, host string) error
+	Resolve(ctx context.Context, host string) (string, error)
+}
+
+type domain struct {
+	repo     repository.DomainStorage
+	resolver TXTResolver
+	cache    *lrucache.Cache[string, string]
+	now      func() time.Time
+}
+
+// NewDomain returns a new instance of the domain, which implements the Domain interface.
+// Resolved hosts are cached for cacheTTL, 0 disables the cache. Verify and Delete evict the host on this instance only,
+// so a deleted domain may still resolve on other instances for cacheTTL.
+func NewDomain(repo repository.DomainStorage, resolver TXTResolver, cacheTTL time.Duration) Domain {
+	d := &domain{repo: repo, resolver: resolver, now: time.Now}
+	if cacheTTL > 0 {
+		d.cache = lrucache.New[string, string](domainCacheSize, cacheTTL)
+	}
+	return d
+}
+
+// DomainVerificationRecord returns the name and the value of the TXT record proving the ownership of d.
+func DomainVerificationRecord(d model.Domain) (name, value string) {
+	return DomainVerificationLabel + d.Host, DomainVerificationValuePrefix + d.Token
+}
+
+// Register registers host as a custom domain of workspace, pending verification.
+// A host registered but not verified by another workspace is claimed instead, the first workspace to verify it gets it,
+// so registering a domain it does not control does not lock its owner out.
+// It returns ErrInvalidDomain if host is not a valid DNS name with at least two labels,
+// and ErrDomainTaken if workspace already registered it or another workspace verified it.
+func (s *domain) Register(ctx context.Context, workspace, host string) (_ model.Domain, err error) {
+	ctx, span := tracer.Start(ctx, "Domain.Register", trace.WithAttributes(attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrInvalidDomain, ErrDomainTaken) }()
+
+	host = normalizeHost(host)
+	if !validHost(host) {
+		return model.Domain{}, ErrInvalidDomain
+	}
+
+	token, err := stringutils.GenerateCode(domainTokenLength)
+	if err != nil {
+		return model.Domain{}, err
+	}
+	d := model.Domain{Host: host, Workspace: workspace, Token: token, CreatedAt: s.now().UTC().Truncate(time.Second)}
+
+	ok, err := s.repo.Create(ctx, d)
+	if err != nil {
+		return model.Domain{}, err
+	}
+	if ok {
+		return d, nil
+	}
+
+	holder, err := s.repo.Get(ctx, host)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return model.Domain{}, err
+	}
+	if err != nil || holder.Workspace == workspace || holder.Verified {
+		return model.Domain{}, ErrDomainTaken
+	}
+	if ok, err = s.repo.Claim(ctx, d); err != nil {
+		return model.Domain{}, err
+	}
+	if !ok {
+		return model.Domain{}, ErrDomainTaken
+	}
+	return d, nil
+}
+
+// Verify looks up the verification TXT record of the custom domain host of workspace and marks the domain verified
+// if one of the record values is the token of the domain, taking it over from the workspace that registered it first.
+// Verifying a verified domain is a no-op.
+// It returns ErrDomainNotFound if host is not a domain of workspace, ErrDomainNotVerified if no record matches,
+// and ErrDomainTaken if another workspace verified it first.
+func (s *domain) Verify(ctx context.Context, workspace, host string) (_ model.Domain, err error) {
+	ctx, span := tracer.Start(ctx, "Domain.Verify", trace.WithAttributes(attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrDomainNotFound, ErrDomainNotVerified, ErrDomainTaken) }()
+
+	d, err := s.get(ctx, workspace, host)
+	if err != nil || d.Verified {
+		return d, err
+	}
+
+	name, value := DomainVerificationRecord(d)
+	records, err := s.resolver.LookupTXT(ctx, name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return model.Domain{}, ErrDomainNotVerified
+	}
+	if err != nil {
+		return model.Domain{}, fmt.Errorf("lookup %s: %w", name, err)
+	}
+	if !slices.ContainsFunc(records, func(r string) bool { return strings.TrimSpace(r) == value }) {
+		return model.Domain{}, ErrDomainNotVerified
+	}
+
+	d.Verified, d.VerifiedAt = true, s.now().UTC().Truncate(time.Second)
+	ok, err := s.repo.Verify(ctx, d)
+	if err != nil && !ok {
+		return model.Domain{}, err
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("host", d.Host).Msg("Cannot remove the verified domain from its previous workspace")
+	}
+	if !ok {
+		return model.Domain{}, ErrDomainTaken
+	}
+	if s.cache != nil {
+		s.cache.Delete(d.Host)
+	}
+	return d, nil
+}
+
+// List returns the custom domains of workspace sorted by host.
+func (s *domain) List(ctx context.Context, workspace string) (_ []model.Domain, err error) {
+	ctx, span := tracer.Start(ctx, "Domain.List", trace.WithAttributes(attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err) }()
+
+	hosts, err := s.repo.ListForWorkspace(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(hosts)
+
+	domains := make([]model.Domain, 0, len(hosts))
+	for _, host := range hosts {
+		d, err := s.get(ctx, workspace, host)
+		if errors.Is(err, ErrDomainNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
+
+// Delete removes the custom domain host of workspace, and returns ErrDomainNotFound if host is not a domain of workspace.
+func (s *domain) Delete(ctx context.Context, workspace, host string) (err error) {
+	ctx, span := tracer.Start(ctx, "Domain.Delete", trace.WithAttributes(attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrDomainNotFound) }()
+
+	d, err := s.get(ctx, workspace, host)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, workspace, d.Host); err != nil {
+		return err
+	}
+	if s.cache != nil {
+		s.cache.Delete(d.Host)
+	}
+	return nil
+}
+
+// Resolve returns the workspace of the verified custom domain host, and ErrDomainNotFound if host is not one.
+// host may carry a port, as in the Host header of a request.
+func (s *domain) Resolve(ctx context.Context, host string) (_ string, err error) {
+	host = normalizeHost(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if s.cache != nil {
+		if workspace, ok := s.cache.Get(host); ok {
+			if workspace == "" {
+				return "", ErrDomainNotFound
+			}
+			return workspace, nil
+		}
+	}
+
+	ctx, span := tracer.Start(ctx, "Domain.Resolve")
+	defer func() { endSpan(span, err, ErrDomainNotFound) }()
+
+	d, err := s.repo.Get(ctx, host)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+	// Unknown and unverified hosts are cached too, so requests to the service host do not each hit Redis.
+	if !d.Verified {
+		d.Workspace = ""
+	}
+	if s.cache != nil {
+		s.cache.Set(host, d.Workspace)
+	}
+	if d.Workspace == "" {
+		return "", ErrDomainNotFound
+	}
+	return d.Workspace, nil
+}
+
+// get returns the custom domain host of workspace, held or only claimed by it, and ErrDomainNotFound if host is not a
+// domain of workspace.
+func (s *domain) get(ctx context.Context, workspace, host string) (model.Domain, error) {
+	host = normalizeHost(host)
+	d, err := s.repo.Get(ctx, host)
+	switch {
+	case err != nil && !errors.Is(err, redis.Nil):
+		return model.Domain{}, err
+	case err == nil && d.Workspace == workspace:
+		return d, nil
+	case err == nil && d.Verified:
+		return model.Domain{}, ErrDomainNotFound
+	}
+
+	d, err = s.repo.GetClaim(ctx, host, workspace)
+	if errors.Is(err, redis.Nil) {
+		return model.Domain{}, ErrDomainNotFound
+	}
+	return d, err
+}
+
+// normalizeHost lowercases host and strips the trailing dot of a fully qualified name.
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// validHost reports whether the normalized host is a DNS name of at least two labels.
+func validHost(host string) bool {
+	if len(host) > maxHostLength {
+		return false
+	}
+	labels := strings.Split(host, ".")
+	return len(labels) >= 2 && !slices.ContainsFunc(labels, func(l string) bool { return !hostLabelPattern.MatchString(l) })
+}
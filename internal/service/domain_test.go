@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	serviceMocks "github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDomain_Register(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		host      string
+		setupMock func(t *testing.T) *mocks.DomainStorage
+
+		expectedHost string
+		expectErr    error
+	}{
+		{
+			name: "normalized host",
+
+			host: "Go.Team.IO.",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Create", mock.Anything, mock.MatchedBy(func(d model.Domain) bool {
+					return d.Host == "go.team.io" && d.Workspace == "ws1" && len(d.Token) == domainTokenLength && !d.Verified
+				})).Return(true, nil).Once()
+				return repo
+			},
+
+			expectedHost: "go.team.io",
+		},
+		{
+			name: "single label",
+
+			host: "localhost",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				return mocks.NewDomainStorage(t)
+			},
+
+			expectErr: ErrInvalidDomain,
+		},
+		{
+			name: "invalid label",
+
+			host: "go.-team.io",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				return mocks.NewDomainStorage(t)
+			},
+
+			expectErr: ErrInvalidDomain,
+		},
+		{
+			name: "verified by another workspace",
+
+			host: "go.team.io",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Create", mock.Anything, mock.Anything).Return(false, nil).Once()
+				repo.On("Get", mock.Anything, "go.team.io").Return(model.Domain{Host: "go.team.io", Workspace: "ws2", Verified: true}, nil).Once()
+				return repo
+			},
+
+			expectErr: ErrDomainTaken,
+		},
+		{
+			name: "already registered by the workspace",
+
+			host: "go.team.io",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Create", mock.Anything, mock.Anything).Return(false, nil).Once()
+				repo.On("Get", mock.Anything, "go.team.io").Return(model.Domain{Host: "go.team.io", Workspace: "ws1"}, nil).Once()
+				return repo
+			},
+
+			expectErr: ErrDomainTaken,
+		},
+		{
+			name: "pending for another workspace -> claimed",
+
+			host: "go.team.io",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Create", mock.Anything, mock.Anything).Return(false, nil).Once()
+				repo.On("Get", mock.Anything, "go.team.io").Return(model.Domain{Host: "go.team.io", Workspace: "ws2"}, nil).Once()
+				repo.On("Claim", mock.Anything, mock.MatchedBy(func(d model.Domain) bool {
+					return d.Host == "go.team.io" && d.Workspace == "ws1" && len(d.Token) == domainTokenLength
+				})).Return(true, nil).Once()
+				return repo
+			},
+
+			expectedHost: "go.team.io",
+		},
+		{
+			name: "already claimed by the workspace",
+
+			host: "go.team.io",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Create", mock.Anything, mock.Anything).Return(false, nil).Once()
+				repo.On("Get", mock.Anything, "go.team.io").Return(model.Domain{Host: "go.team.io", Workspace: "ws2"}, nil).Once()
+				repo.On("Claim", mock.Anything, mock.Anything).Return(false, nil).Once()
+				return repo
+			},
+
+			expectErr: ErrDomainTaken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewDomain(tc.setupMock(t), nil, 0)
+
+			d, err := svc.Register(context.Background(), "ws1", tc.host)
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectedHost, d.Host)
+		})
+	}
+}
+
+func TestDomain_Verify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	pending := model.Domain{Host: "go.team.io", Workspace: "ws1", Token: "tok"}
+
+	testCases := []struct {
+		name string
+
+		workspace     string
+		setupMock     func(t *testing.T) *mocks.DomainStorage
+		setupResolver func(t *testing.T) *serviceMocks.TXTResolver
+
+		expectedVerified bool
+		expectErr        error
+	}{
+		{
+			name: "matching record",
+
+			workspace: "ws1",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Get", mock.Anything, "go.team.io").Return(pending, nil).Once()
+				verified := pending
+				verified.Verified, verified.VerifiedAt = true, now
+				repo.On("Verify", mock.Anything, verified).Return(true, nil).Once()
+				return repo
+			},
+			setupResolver: func(t *testing.T) *serviceMocks.TXTResolver {
+				resolver := serviceMocks.NewTXTResolver(t)
+				resolver.On("LookupTXT", mock.Anything, "_bookmark-verify.go.team.io").
+					Return([]string{"v=spf1 -all", " bookmark-verify=tok "}, nil).Once()
+				return resolver
+			},
+
+			expectedVerified: true,
+		},
+		{
+			name: "no matching record",
+
+			workspace: "ws1",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Get", mock.Anything, "go.team.io").Return(pending, nil).Once()
+				return repo
+			},
+			setupResolver: func(t *testing.T) *serviceMocks.TXTResolver {
+				resolver := serviceMocks.NewTXTResolver(t)
+				resolver.On("LookupTXT", mock.Anything, "_bookmark-verify.go.team.io").
+					Return([]string{"bookmark-verify=other"}, nil).Once()
+				return resolver
+			},
+
+			expectErr: ErrDomainNotVerified,
+		},
+		{
+			name: "no record at all",
+
+			workspace: "ws1",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Get", mock.Anything, "go.team.io").Return(pending, nil).Once()
+				return repo
+			},
+			setupResolver: func(t *testing.T) *serviceMocks.TXTResolver {
+				resolver := serviceMocks.NewTXTResolver(t)
+				resolver.On("LookupTXT", mock.Anything, "_bookmark-verify.go.team.io").
+					Return(nil, &net.DNSError{Err: "no such host", IsNotFound: true}).Once()
+				return resolver
+			},
+
+			expectErr: ErrDomainNotVerified,
+		},
+		{
+			name: "claim takes the domain over",
+
+			workspace: "ws2",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				claim := model.Domain{Host: "go.team.io", Workspace: "ws2", Token: "tok2"}
+				repo.On("Get", mock.Anything, "go.team.io").Return(pending, nil).Once()
+				repo.On("GetClaim", mock.Anything, "go.team.io", "ws2").Return(claim, nil).Once()
+				claim.Verified, claim.VerifiedAt = true, now
+				repo.On("Verify", mock.Anything, claim).Return(true, nil).Once()
+				return repo
+			},
+			setupResolver: func(t *testing.T) *serviceMocks.TXTResolver {
+				resolver := serviceMocks.NewTXTResolver(t)
+				resolver.On("LookupTXT", mock.Anything, "_bookmark-verify.go.team.io").
+					Return([]string{"bookmark-verify=tok2"}, nil).Once()
+				return resolver
+			},
+
+			expectedVerified: true,
+		},
+		{
+			name: "verified by another workspace first",
+
+			workspace: "ws2",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				claim := model.Domain{Host: "go.team.io", Workspace: "ws2", Token: "tok2"}
+				repo.On("Get", mock.Anything, "go.team.io").Return(pending, nil).Once()
+				repo.On("GetClaim", mock.Anything, "go.team.io", "ws2").Return(claim, nil).Once()
+				repo.On("Verify", mock.Anything, mock.Anything).Return(false, nil).Once()
+				return repo
+			},
+			setupResolver: func(t *testing.T) *serviceMocks.TXTResolver {
+				resolver := serviceMocks.NewTXTResolver(t)
+				resolver.On("LookupTXT", mock.Anything, "_bookmark-verify.go.team.io").
+					Return([]string{"bookmark-verify=tok2"}, nil).Once()
+				return resolver
+			},
+
+			expectErr: ErrDomainTaken,
+		},
+		{
+			name: "domain of another workspace",
+
+			workspace: "ws2",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Get", mock.Anything, "go.team.io").Return(pending, nil).Once()
+				repo.On("GetClaim", mock.Anything, "go.team.io", "ws2").Return(model.Domain{}, redis.Nil).Once()
+				return repo
+			},
+			setupResolver: func(t *testing.T) *serviceMocks.TXTResolver {
+				return serviceMocks.NewTXTResolver(t)
+			},
+
+			expectErr: ErrDomainNotFound,
+		},
+		{
+			name: "unknown domain",
+
+			workspace: "ws1",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Get", mock.Anything, "go.team.io").Return(model.Domain{}, redis.Nil).Once()
+				repo.On("GetClaim", mock.Anything, "go.team.io", "ws1").Return(model.Domain{}, redis.Nil).Once()
+				return repo
+			},
+			setupResolver: func(t *testing.T) *serviceMocks.TXTResolver {
+				return serviceMocks.NewTXTResolver(t)
+			},
+
+			expectErr: ErrDomainNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &domain{repo: tc.setupMock(t), resolver: tc.setupResolver(t), now: func() time.Time { return now }}
+
+			d, err := svc.Verify(context.Background(), tc.workspace, "go.team.io")
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectedVerified, d.Verified)
+		})
+	}
+}
+
+func TestDomain_Resolve(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		host      string
+		setupMock func(t *testing.T) *mocks.DomainStorage
+
+		expectedWorkspace string
+		expectErr         error
+	}{
+		{
+			name: "verified domain, cached",
+
+			host: "GO.team.io:8080",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Get", mock.Anything, "go.team.io").
+					Return(model.Domain{Host: "go.team.io", Workspace: "ws1", Verified: true}, nil).Once()
+				return repo
+			},
+
+			expectedWorkspace: "ws1",
+		},
+		{
+			name: "unverified domain",
+
+			host: "go.team.io",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Get", mock.Anything, "go.team.io").
+					Return(model.Domain{Host: "go.team.io", Workspace: "ws1"}, nil).Once()
+				return repo
+			},
+
+			expectErr: ErrDomainNotFound,
+		},
+		{
+			name: "unknown host, cached",
+
+			host: "localhost:8080",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Get", mock.Anything, "localhost").Return(model.Domain{}, redis.Nil).Once()
+				return repo
+			},
+
+			expectErr: ErrDomainNotFound,
+		},
+		{
+			name: "repository error",
+
+			host: "go.team.io",
+			setupMock: func(t *testing.T) *mocks.DomainStorage {
+				repo := mocks.NewDomainStorage(t)
+				repo.On("Get", mock.Anything, "go.team.io").Return(model.Domain{}, testError).Twice()
+				return repo
+			},
+
+			expectErr: testError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewDomain(tc.setupMock(t), nil, time.Minute)
+
+			// The second call is served from the cache unless the first one failed.
+			for i := 0; i < 2; i++ {
+				workspace, err := svc.Resolve(context.Background(), tc.host)
+				assert.Equal(t, tc.expectErr, err)
+				assert.Equal(t, tc.expectedWorkspace, workspace)
+			}
+		})
+	}
+}
@@ -2,18 +2,25 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/lhducc/bookmark-management/internal/repository"
+	"sync/atomic"
 )
 
+// ErrDraining is returned by HealthCheck.Check once the service is shutting down.
+var ErrDraining = errors.New("service is draining")
+
 //go:generate mockery --name=HealthCheck --filename health_check_service.go
 type HealthCheck interface {
 	Check(ctx context.Context) (string, string, string, error)
+	Drain()
 }
 
 type healthCheckService struct {
 	serviceName string
 	instanceID  string
 	healthCheck repository.HealthCheck
+	draining    atomic.Bool
 }
 
 // NewHealthCheck returns a new instance of the healthCheckService, which implements the HealthCheck interface.
@@ -35,7 +42,14 @@ func NewHealthCheck(serviceName, instanceID string, healthCheck repository.Healt
 // and instanceID is the ID of the service instance.
 // If an error occurs while generating the health check response, the error is returned immediately and the generated health check response is an empty string.
 // The character set used for generating the health check response is constant and does not change across different implementations of the interface. The length of the generated health check response is constant and does not change across different implementations of the interface.
-func (s *healthCheckService) Check(ctx context.Context) (string, string, string, error) {
+func (s *healthCheckService) Check(ctx context.Context) (_ string, _ string, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "HealthCheck.Check")
+	defer func() { endSpan(span, err) }()
+
+	if s.draining.Load() {
+		return "DRAINING", s.serviceName, s.instanceID, ErrDraining
+	}
+
 	if s.healthCheck != nil {
 		if err := s.healthCheck.Ping(ctx); err != nil {
 			return "NOT OK", s.serviceName, s.instanceID, err
@@ -44,3 +58,8 @@ func (s *healthCheckService) Check(ctx context.Context) (string, string, string,
 
 	return "OK", s.serviceName, s.instanceID, nil
 }
+
+// Drain marks the service as shutting down, every later Check returns ErrDraining so load balancers stop sending traffic.
+func (s *healthCheckService) Drain() {
+	s.draining.Store(true)
+}
@@ -27,6 +27,7 @@ func TestHealthCheckService_Check(t *testing.T) {
 		inputServiceName string
 		inputInstanceID  string
 		setupMock        func(t *testing.T) repository.HealthCheck
+		drain            bool
 
 		expectedMessage     string
 		expectedServiceName string
@@ -65,6 +66,21 @@ func TestHealthCheckService_Check(t *testing.T) {
 			expectedInstanceID:  "2025",
 			expectedError:       testConnectError,
 		},
+		{
+			name: "draining case",
+
+			inputServiceName: "bookmark-manager",
+			inputInstanceID:  "2025",
+			setupMock: func(t *testing.T) repository.HealthCheck {
+				return mocks.NewHealthCheck(t)
+			},
+			drain: true,
+
+			expectedMessage:     "DRAINING",
+			expectedServiceName: "bookmark-manager",
+			expectedInstanceID:  "2025",
+			expectedError:       ErrDraining,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -75,6 +91,9 @@ func TestHealthCheckService_Check(t *testing.T) {
 			healthCheckRepoMock := tc.setupMock(t)
 
 			testSvc := NewHealthCheck(tc.inputServiceName, tc.inputInstanceID, healthCheckRepoMock)
+			if tc.drain {
+				testSvc.Drain()
+			}
 
 			message, serviceName, instanceID, err := testSvc.Check(ctx)
 
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Heartbeat tracks the background workers, so readiness fails once one of them has stopped or is stuck.
+//
+//go:generate mockery --name Heartbeat --filename heartbeat.go
+type Heartbeat interface {
+	Start(worker string, maxAge time.Duration)
+	Beat(worker string)
+	Fail(worker string, err error)
+	Stop(worker string, err error)
+	Check(ctx context.Context) error
+}
+
+type workerState struct {
+	maxAge   time.Duration
+	lastBeat time.Time
+	failing  error
+	stopped  bool
+	err      error
+}
+
+type heartbeat struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	workers map[string]workerState
+}
+
+// NewHeartbeat returns a new instance of the heartbeat, which implements the Heartbeat interface.
+func NewHeartbeat() Heartbeat {
+	return &heartbeat{
+		now:     time.Now,
+		workers: make(map[string]workerState),
+	}
+}
+
+// Start records that worker is running, it is considered stuck once it has not beaten for maxAge.
+// A maxAge of 0 only checks that the worker is still running, for workers that block until they are stopped.
+func (h *heartbeat) Start(worker string, maxAge time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.workers[worker] = workerState{maxAge: maxAge, lastBeat: h.now()}
+}
+
+// Beat records that worker is alive, and no longer failing.
+func (h *heartbeat) Beat(worker string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	state := h.workers[worker]
+	state.lastBeat = h.now()
+	state.failing = nil
+	h.workers[worker] = state
+}
+
+// Fail records that worker is still running but failing with err, such as a worker reconnecting, until it beats again.
+func (h *heartbeat) Fail(worker string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	state := h.workers[worker]
+	state.failing = err
+	h.workers[worker] = state
+}
+
+// Stop records that worker has returned, with the error it returned.
+func (h *heartbeat) Stop(worker string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	state := h.workers[worker]
+	state.stopped = true
+	state.err = err
+	h.workers[worker] = state
+}
+
+// Check returns an error naming the first worker, in name order, that has stopped, is failing or missed its heartbeat.
+func (h *heartbeat) Check(_ context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	names := make([]string, 0, len(h.workers))
+	for name := range h.workers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		state := h.workers[name]
+		switch {
+		case state.stopped && state.err != nil:
+			return fmt.Errorf("worker %q stopped: %w", name, state.err)
+		case state.stopped:
+			return fmt.Errorf("worker %q stopped", name)
+		case state.failing != nil:
+			return fmt.Errorf("worker %q failing: %w", name, state.failing)
+		case state.maxAge > 0 && h.now().Sub(state.lastBeat) > state.maxAge:
+			return fmt.Errorf("worker %q missed its heartbeat, last beat %s ago", name, h.now().Sub(state.lastBeat).Round(time.Second))
+		}
+	}
+	return nil
+}
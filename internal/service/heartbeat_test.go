@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat_Check(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		run func(h Heartbeat, clock *time.Time)
+
+		expectedErr string
+	}{
+		{
+			name: "no workers",
+
+			run: func(h Heartbeat, clock *time.Time) {},
+		},
+		{
+			name: "running worker",
+
+			run: func(h Heartbeat, clock *time.Time) {
+				h.Start("url cache invalidation", 0)
+				*clock = clock.Add(time.Hour)
+			},
+		},
+		{
+			name: "stopped worker",
+
+			run: func(h Heartbeat, clock *time.Time) {
+				h.Start("url cache invalidation", 0)
+				h.Stop("url cache invalidation", testError)
+			},
+
+			expectedErr: `worker "url cache invalidation" stopped: ` + testError.Error(),
+		},
+		{
+			name: "failing worker",
+
+			run: func(h Heartbeat, clock *time.Time) {
+				h.Start("url cache invalidation", 0)
+				h.Fail("url cache invalidation", testError)
+			},
+
+			expectedErr: `worker "url cache invalidation" failing: ` + testError.Error(),
+		},
+		{
+			name: "worker recovered",
+
+			run: func(h Heartbeat, clock *time.Time) {
+				h.Start("url cache invalidation", 0)
+				h.Fail("url cache invalidation", testError)
+				h.Beat("url cache invalidation")
+			},
+		},
+		{
+			name: "fresh heartbeat",
+
+			run: func(h Heartbeat, clock *time.Time) {
+				h.Start("sweeper", time.Minute)
+				*clock = clock.Add(time.Minute)
+			},
+		},
+		{
+			name: "beats keep the worker fresh",
+
+			run: func(h Heartbeat, clock *time.Time) {
+				h.Start("sweeper", time.Minute)
+				*clock = clock.Add(50 * time.Second)
+				h.Beat("sweeper")
+				*clock = clock.Add(50 * time.Second)
+			},
+		},
+		{
+			name: "missed heartbeat",
+
+			run: func(h Heartbeat, clock *time.Time) {
+				h.Start("sweeper", time.Minute)
+				*clock = clock.Add(2 * time.Minute)
+			},
+
+			expectedErr: `worker "sweeper" missed its heartbeat, last beat 2m0s ago`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := time.Unix(0, 0)
+			h := NewHeartbeat()
+			h.(*heartbeat).now = func() time.Time { return clock }
+
+			tc.run(h, &clock)
+
+			err := h.Check(context.Background())
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
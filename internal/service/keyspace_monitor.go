@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository"
+	"github.com/rs/zerolog/log"
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	// keyspaceEWMAAlpha weights the latest attempt in the collision rate, it remembers roughly the last 50 attempts.
+	keyspaceEWMAAlpha = 0.02
+	// keyspaceMinSamples is the number of attempts at a length before its collision rate is trusted.
+	keyspaceMinSamples = 100
+)
+
+// KeyspaceMonitor tracks code collisions per code length and picks the length of new codes,
+// moving to a longer length once the current one is too crowded.
+// The collision statistics are kept per instance, the length of new codes is shared through Refresh.
+//
+//go:generate mockery --name KeyspaceMonitor --filename keyspace_monitor.go
+type KeyspaceMonitor interface {
+	CodeLength() int
+	Refresh(ctx context.Context) error
+	Record(ctx context.Context, length int, collided bool)
+	Stats() []model.KeyspaceStats
+}
+
+type keyspaceLength struct {
+	attempts   uint64
+	collisions uint64
+	rate       float64
+	warned     bool
+}
+
+type keyspaceMonitor struct {
+	mu           sync.Mutex
+	store        repository.KeyspaceLength
+	alphabetSize int
+	length       int
+	warnAt       float64
+	growAt       float64
+	lengths      map[int]*keyspaceLength
+}
+
+// NewKeyspaceMonitor returns a new instance of the keyspaceMonitor, which implements the KeyspaceMonitor interface.
+// Codes start at length characters drawn from an alphabet of alphabetSize characters.
+// A warning is logged once the estimated occupancy of a length reaches warnAt,
+// and new codes get one more character once the occupancy of the current length reaches growAt.
+// A warnAt or growAt less than or equal to zero disables the warning or the lengthening.
+// The store is optional, when it is set a lengthening is persisted in it, so it survives restarts and reaches every instance.
+func NewKeyspaceMonitor(store repository.KeyspaceLength, alphabetSize, length int, warnAt, growAt float64) KeyspaceMonitor {
+	return &keyspaceMonitor{
+		store:        store,
+		alphabetSize: alphabetSize,
+		length:       length,
+		warnAt:       warnAt,
+		growAt:       growAt,
+		lengths:      make(map[int]*keyspaceLength),
+	}
+}
+
+// CodeLength returns the length new codes should be generated with.
+func (m *keyspaceMonitor) CodeLength() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.length
+}
+
+// Refresh adopts the length stored by any instance when it is longer than the current one.
+// It is called at startup and before every batch of codes is generated.
+func (m *keyspaceMonitor) Refresh(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+	length, err := m.store.Get(ctx)
+	if err != nil {
+		return err
+	}
+	m.adopt(length)
+	return nil
+}
+
+// Record accounts one attempt to store a code of the given length, collided tells whether the code was already taken.
+// A lengthening is persisted in the store, a failure to do so is logged and the next Refresh tries again to share it.
+func (m *keyspaceMonitor) Record(ctx context.Context, length int, collided bool) {
+	grownTo := m.record(length, collided)
+	if grownTo == 0 || m.store == nil {
+		return
+	}
+	stored, err := m.store.Raise(ctx, grownTo)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int("length", grownTo).Msg("Cannot persist the code length")
+		return
+	}
+	m.adopt(stored)
+}
+
+// record accounts one attempt like Record and returns the new length of codes when the attempt lengthened them, 0 otherwise.
+func (m *keyspaceMonitor) record(length int, collided bool) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.lengths[length]
+	if !ok {
+		l = &keyspaceLength{}
+		m.lengths[length] = l
+	}
+
+	l.attempts++
+	sample := 0.0
+	if collided {
+		l.collisions++
+		sample = 1
+	}
+	l.rate += keyspaceEWMAAlpha * (sample - l.rate)
+
+	if l.attempts < keyspaceMinSamples {
+		return 0
+	}
+
+	if m.warnAt > 0 && !l.warned && l.rate >= m.warnAt {
+		l.warned = true
+		log.Warn().Int("length", length).Float64("occupancy", l.rate).Msg("Keyspace is filling up")
+	}
+
+	if m.growAt > 0 && length == m.length && l.rate >= m.growAt {
+		m.length++
+		log.Warn().Int("length", length).Float64("occupancy", l.rate).Int("newLength", m.length).Msg("Keyspace saturated, lengthening codes")
+		return m.length
+	}
+	return 0
+}
+
+// adopt moves new codes to length when it is longer than the current length.
+func (m *keyspaceMonitor) adopt(length int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if length > m.length {
+		m.length = length
+	}
+}
+
+// Stats returns the keyspace statistics of every code length seen so far, ordered by length.
+func (m *keyspaceMonitor) Stats() []model.KeyspaceStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]model.KeyspaceStats, 0, len(m.lengths))
+	for length, l := range m.lengths {
+		capacity := math.Pow(float64(m.alphabetSize), float64(length))
+		status := model.KeyspaceStatusOK
+		switch {
+		case l.attempts < keyspaceMinSamples:
+		case m.growAt > 0 && l.rate >= m.growAt:
+			status = model.KeyspaceStatusSaturated
+		case m.warnAt > 0 && l.rate >= m.warnAt:
+			status = model.KeyspaceStatusWarning
+		}
+
+		stats = append(stats, model.KeyspaceStats{
+			Length:             length,
+			Capacity:           capacity,
+			Attempts:           l.attempts,
+			Collisions:         l.collisions,
+			EstimatedOccupancy: l.rate,
+			EstimatedCodes:     l.rate * capacity,
+			Status:             status,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Length < stats[j].Length })
+	return stats
+}
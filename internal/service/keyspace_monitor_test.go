@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestKeyspaceMonitor_Record(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		record func(ctx context.Context, m KeyspaceMonitor)
+
+		expectedLength int
+		expectedStats  []model.KeyspaceStats
+	}{
+		{
+			name: "no attempts",
+
+			record: func(ctx context.Context, m KeyspaceMonitor) {},
+
+			expectedLength: 2,
+			expectedStats:  []model.KeyspaceStats{},
+		},
+		{
+			name: "too few samples to judge",
+
+			record: func(ctx context.Context, m KeyspaceMonitor) {
+				for i := 0; i < keyspaceMinSamples-1; i++ {
+					m.Record(ctx, 2, true)
+				}
+			},
+
+			expectedLength: 2,
+			expectedStats: []model.KeyspaceStats{
+				{Length: 2, Capacity: 100, Attempts: keyspaceMinSamples - 1, Collisions: keyspaceMinSamples - 1, Status: model.KeyspaceStatusOK},
+			},
+		},
+		{
+			name: "no collisions",
+
+			record: func(ctx context.Context, m KeyspaceMonitor) {
+				for i := 0; i < 2*keyspaceMinSamples; i++ {
+					m.Record(ctx, 2, false)
+				}
+			},
+
+			expectedLength: 2,
+			expectedStats: []model.KeyspaceStats{
+				{Length: 2, Capacity: 100, Attempts: 2 * keyspaceMinSamples, Status: model.KeyspaceStatusOK},
+			},
+		},
+		{
+			name: "crowded length is lengthened",
+
+			record: func(ctx context.Context, m KeyspaceMonitor) {
+				for i := 0; i < keyspaceMinSamples; i++ {
+					m.Record(ctx, 2, i%2 == 0)
+				}
+				m.Record(ctx, 3, false)
+			},
+
+			expectedLength: 3,
+			expectedStats: []model.KeyspaceStats{
+				{Length: 2, Capacity: 100, Attempts: keyspaceMinSamples, Collisions: keyspaceMinSamples / 2, Status: model.KeyspaceStatusSaturated},
+				{Length: 3, Capacity: 1000, Attempts: 1, Status: model.KeyspaceStatusOK},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewKeyspaceMonitor(nil, 10, 2, 0.05, 0.2)
+			tc.record(t.Context(), m)
+
+			assert.Equal(t, tc.expectedLength, m.CodeLength())
+
+			stats := m.Stats()
+			assert.Len(t, stats, len(tc.expectedStats))
+			for i, expected := range tc.expectedStats {
+				assert.Equal(t, expected.Length, stats[i].Length)
+				assert.Equal(t, expected.Capacity, stats[i].Capacity)
+				assert.Equal(t, expected.Attempts, stats[i].Attempts)
+				assert.Equal(t, expected.Collisions, stats[i].Collisions)
+				assert.Equal(t, expected.Status, stats[i].Status)
+				assert.InDelta(t, stats[i].EstimatedOccupancy*stats[i].Capacity, stats[i].EstimatedCodes, 1e-9)
+			}
+		})
+	}
+}
+
+func TestKeyspaceMonitor_Refresh(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupStore func(t *testing.T) *mocks.KeyspaceLength
+
+		expectedLength int
+		expectErr      error
+	}{
+		{
+			name: "nothing stored keeps the initial length",
+
+			setupStore: func(t *testing.T) *mocks.KeyspaceLength {
+				store := mocks.NewKeyspaceLength(t)
+				store.On("Get", mock.Anything).Return(0, nil).Once()
+				return store
+			},
+
+			expectedLength: 2,
+		},
+		{
+			name: "longer stored length is adopted",
+
+			setupStore: func(t *testing.T) *mocks.KeyspaceLength {
+				store := mocks.NewKeyspaceLength(t)
+				store.On("Get", mock.Anything).Return(4, nil).Once()
+				return store
+			},
+
+			expectedLength: 4,
+		},
+		{
+			name: "store error",
+
+			setupStore: func(t *testing.T) *mocks.KeyspaceLength {
+				store := mocks.NewKeyspaceLength(t)
+				store.On("Get", mock.Anything).Return(0, testError).Once()
+				return store
+			},
+
+			expectedLength: 2,
+			expectErr:      testError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewKeyspaceMonitor(tc.setupStore(t), 10, 2, 0.05, 0.2)
+
+			err := m.Refresh(t.Context())
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectedLength, m.CodeLength())
+		})
+	}
+}
+
+func TestKeyspaceMonitor_Record_persistsLengthening(t *testing.T) {
+	t.Parallel()
+
+	store := mocks.NewKeyspaceLength(t)
+	// Another instance lengthened the codes further in the meantime.
+	store.On("Raise", mock.Anything, 3).Return(5, nil).Once()
+	m := NewKeyspaceMonitor(store, 10, 2, 0.05, 0.2)
+
+	for i := 0; i < keyspaceMinSamples; i++ {
+		m.Record(t.Context(), 2, i%2 == 0)
+	}
+
+	assert.Equal(t, 5, m.CodeLength())
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// linkExpiryBatch bounds the links popped at once by the expiry notifier.
+const linkExpiryBatch = 100
+
+// ExpiryNotifier publishes the expiry events of the links.
+//
+//go:generate mockery --name ExpiryNotifier --filename expiry_notifier.go
+type ExpiryNotifier interface {
+	Run(ctx context.Context, beat func()) error
+}
+
+type expiryNotifier struct {
+	index    repository.LinkExpiryIndex
+	links    repository.UrlStorage
+	webhooks Webhook
+	notice   time.Duration
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewExpiryNotifier returns a new instance of the expiryNotifier, which implements the ExpiryNotifier interface.
+// Every interval it publishes link.expiring for the links expiring within notice, 0 disables it, and link.expired
+// for the links that expired.
+func NewExpiryNotifier(index repository.LinkExpiryIndex, links repository.UrlStorage, webhooks Webhook, notice, interval time.Duration) ExpiryNotifier {
+	return &expiryNotifier{index: index, links: links, webhooks: webhooks, notice: notice, interval: interval, now: time.Now}
+}
+
+// Run publishes the due expiry events every interval until ctx is canceled, it only returns then.
+// Every instance runs it, each link is popped by a single instance, so its events are published at most once.
+// Links created with less than the notice left get their link.expiring event on the next run.
+// beat is called on every tick and after every batch of links, so a stuck notifier can be told apart.
+func (n *expiryNotifier) Run(ctx context.Context, beat func()) error {
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for {
+		beat()
+		n.notify(ctx, beat)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// notify publishes the events of the links expiring within the notice, then of the links that expired.
+// Errors are logged, a link whose event could not be published is not retried.
+func (n *expiryNotifier) notify(ctx context.Context, beat func()) {
+	now := n.now()
+	n.drain(ctx, beat, func() ([]model.LinkExpiry, error) {
+		return n.index.PopExpiring(ctx, now.Add(n.notice), linkExpiryBatch)
+	}, func(e model.LinkExpiry) {
+		if n.notice > 0 && e.ExpiresAt.After(now) {
+			n.publishExpiring(ctx, e)
+		}
+	})
+	n.drain(ctx, beat, func() ([]model.LinkExpiry, error) {
+		return n.index.PopExpired(ctx, now, linkExpiryBatch)
+	}, func(e model.LinkExpiry) {
+		n.publish(ctx, model.Event{Type: model.EventLinkExpired, OccurredAt: e.ExpiresAt}, e)
+	})
+}
+
+// drain calls handle on every link returned by pop until it returns less than a batch, and beat after every batch.
+func (n *expiryNotifier) drain(ctx context.Context, beat func(), pop func() ([]model.LinkExpiry, error), handle func(model.LinkExpiry)) {
+	for ctx.Err() == nil {
+		expiries, err := pop()
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Cannot pop the link expiries")
+			return
+		}
+		for _, e := range expiries {
+			handle(e)
+		}
+		beat()
+		if len(expiries) < linkExpiryBatch {
+			return
+		}
+	}
+}
+
+// publishExpiring publishes link.expiring for e with the URL of the link, a link deleted since it was indexed is skipped.
+func (n *expiryNotifier) publishExpiring(ctx context.Context, e model.LinkExpiry) {
+	link, err := n.links.GetLink(ctx, e.Workspace, e.Code)
+	if errors.Is(err, redis.Nil) {
+		return
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("code", e.Code).Msg("Cannot get the expiring link")
+		return
+	}
+	n.publish(ctx, model.Event{Type: model.EventLinkExpiring, URL: link.URL}, e)
+}
+
+// publish completes event with e and publishes it.
+func (n *expiryNotifier) publish(ctx context.Context, event model.Event, e model.LinkExpiry) {
+	event.Workspace, event.Owner, event.Code = e.Workspace, e.Owner, e.Code
+	expiresAt := e.ExpiresAt
+	event.ExpiresAt = &expiresAt
+	if err := n.webhooks.Publish(ctx, event); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("code", e.Code).Str("event", event.Type).Msg("Cannot publish the expiry event")
+	}
+}
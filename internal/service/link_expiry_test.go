@@ -0,0 +1,49 @@
+package service
+
+import (
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	serviceMocks "github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestExpiryNotifier_Notify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	notice := 24 * time.Hour
+	expiring := model.LinkExpiry{Workspace: "ws1", Owner: "u1", Code: "soon", ExpiresAt: now.Add(time.Hour)}
+	gone := model.LinkExpiry{Owner: "u1", Code: "gone", ExpiresAt: now.Add(2 * time.Hour)}
+	expired := model.LinkExpiry{Owner: "u1", Code: "old", ExpiresAt: now.Add(-time.Minute)}
+
+	index := mocks.NewLinkExpiryIndex(t)
+	index.On("PopExpiring", mock.Anything, now.Add(notice), linkExpiryBatch).Return([]model.LinkExpiry{expiring, gone, expired}, nil).Once()
+	index.On("PopExpired", mock.Anything, now, linkExpiryBatch).Return([]model.LinkExpiry{expired}, nil).Once()
+
+	links := mocks.NewUrlStorage(t)
+	links.On("GetLink", mock.Anything, "ws1", "soon").Return(model.Link{URL: "https://example.com"}, nil).Once()
+	links.On("GetLink", mock.Anything, "", "gone").Return(model.Link{}, redis.Nil).Once()
+
+	webhooks := serviceMocks.NewWebhook(t)
+	webhooks.On("Publish", mock.Anything, model.Event{
+		Type:      model.EventLinkExpiring,
+		Workspace: "ws1",
+		Owner:     "u1",
+		Code:      "soon",
+		URL:       "https://example.com",
+		ExpiresAt: &expiring.ExpiresAt,
+	}).Return(nil).Once()
+	webhooks.On("Publish", mock.Anything, model.Event{
+		Type:       model.EventLinkExpired,
+		OccurredAt: expired.ExpiresAt,
+		Owner:      "u1",
+		Code:       "old",
+		ExpiresAt:  &expired.ExpiresAt,
+	}).Return(nil).Once()
+
+	testSvc := &expiryNotifier{index: index, links: links, webhooks: webhooks, notice: notice, now: func() time.Time { return now }}
+	testSvc.notify(t.Context(), func() {})
+}
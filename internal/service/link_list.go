@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLinkPageSize is the number of links of a page when the query sets no limit.
+	DefaultLinkPageSize = 20
+	// MaxLinkPageSize bounds the links of a page.
+	MaxLinkPageSize = 100
+	// ExpiredLinkRetention is how long expired links are still listed.
+	ExpiredLinkRetention = 30 * 24 * time.Hour
+
+	maxLinkTags = 10
+	// linkScanBatch is the number of links read from the index at once while filling a page.
+	linkScanBatch = 100
+	// linkScanLimit bounds the links read from the index for a page, so a filter matching few links stays cheap.
+	linkScanLimit = 10 * linkScanBatch
+	// linkSortDeleted is the order of the cursors of revoked links, the latest deleted first.
+	linkSortDeleted = "deleted"
+)
+
+// ErrInvalidLinkQuery is returned when the sort, status, limit, window or cursor of a link query is invalid.
+var ErrInvalidLinkQuery = errors.New("invalid link query")
+
+var linkTagPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// linkCursor is the JSON form of a model.LinkCursor in the cursors handed out to the clients.
+type linkCursor struct {
+	Sort  string  `json:"s"`
+	Score float64 `json:"v"`
+	Code  string  `json:"c"`
+}
+
+// LinkList lists the links of the workspaces and of the owners of global links.
+//
+//go:generate mockery --name LinkList --filename link_list.go
+type LinkList interface {
+	List(ctx context.Context, workspace, owner string, query model.LinkQuery) (model.LinkPage, error)
+}
+
+type linkList struct {
+	index repository.LinkIndex
+	trash repository.LinkTrash
+	now   func() time.Time
+}
+
+// NewLinkList returns a new instance of the linkList, which implements the LinkList interface.
+// Only the links stored since the links are indexed are listed, anonymous global links never are. Revoked links are
+// listed from trash.
+func NewLinkList(index repository.LinkIndex, trash repository.LinkTrash) LinkList {
+	return &linkList{index: index, trash: trash, now: time.Now}
+}
+
+// List returns a page of the links of workspace, or of the global links of owner when workspace is empty, matching query.
+// Links expired for more than ExpiredLinkRetention are dropped from the index first. Revoked links, the deleted ones
+// kept in the trash, are listed the latest deleted first and only in the default sort.
+// A page holds up to query.Limit links, or DefaultLinkPageSize, and has a next cursor when it is full or when
+// linkScanLimit links were read without filling it, so a page may hold fewer links than its limit, or none, and still
+// have one. It returns ErrInvalidLinkQuery if query is invalid.
+func (s *linkList) List(ctx context.Context, workspace, owner string, query model.LinkQuery) (_ model.LinkPage, err error) {
+	ctx, span := tracer.Start(ctx, "LinkList.List", trace.WithAttributes(attribute.String("workspace.id", workspace), attribute.String("link.sort", query.Sort)))
+	defer func() { endSpan(span, err, ErrInvalidLinkQuery) }()
+
+	query, after, err := normalizeLinkQuery(query)
+	if err != nil {
+		return model.LinkPage{}, err
+	}
+	page := model.LinkPage{Links: []model.LinkSummary{}}
+
+	now := s.now()
+	if err := s.index.Prune(ctx, workspace, owner, now.Add(-ExpiredLinkRetention)); err != nil {
+		return model.LinkPage{}, err
+	}
+
+	scan := s.scanIndex
+	if query.Status == model.LinkStatusRevoked {
+		scan = s.scanTrash
+	}
+	for scanned := 0; ; {
+		links, cursors, err := scan(ctx, workspace, owner, after)
+		if err != nil {
+			return model.LinkPage{}, err
+		}
+		for i, link := range links {
+			if query.Sort == model.LinkSortExpiry && query.ExpiringWithin > 0 && link.ExpiresAt.After(now.Add(query.ExpiringWithin)) {
+				return page, nil
+			}
+			after = cursors[i]
+			if !linkMatches(link, query, now) {
+				continue
+			}
+			page.Links = append(page.Links, link)
+			if len(page.Links) == query.Limit {
+				page.Next = encodeLinkCursor(after)
+				return page, nil
+			}
+		}
+		if len(links) < linkScanBatch {
+			return page, nil
+		}
+		if scanned += len(links); scanned >= linkScanLimit {
+			page.Next = encodeLinkCursor(after)
+			return page, nil
+		}
+	}
+}
+
+// scanIndex reads the links of the index following after, along with the cursor of each.
+func (s *linkList) scanIndex(ctx context.Context, workspace, owner string, after model.LinkCursor) ([]model.LinkSummary, []model.LinkCursor, error) {
+	links, err := s.index.Scan(ctx, workspace, owner, after, linkScanBatch)
+	if err != nil {
+		return nil, nil, err
+	}
+	cursors := make([]model.LinkCursor, 0, len(links))
+	for _, link := range links {
+		cursors = append(cursors, model.LinkCursor{Sort: after.Sort, Score: linkScore(link, after.Sort), Code: link.Code})
+	}
+	return links, cursors, nil
+}
+
+// scanTrash reads the revoked links of the trash following after, along with the cursor of each.
+func (s *linkList) scanTrash(ctx context.Context, workspace, owner string, after model.LinkCursor) ([]model.LinkSummary, []model.LinkCursor, error) {
+	trashed, err := s.trash.Scan(ctx, workspace, owner, after, linkScanBatch)
+	if err != nil {
+		return nil, nil, err
+	}
+	links := make([]model.LinkSummary, 0, len(trashed))
+	cursors := make([]model.LinkCursor, 0, len(trashed))
+	for _, link := range trashed {
+		link.Revoked = true
+		links = append(links, link.LinkSummary)
+		cursors = append(cursors, model.LinkCursor{Sort: after.Sort, Score: float64(link.DeletedAt.UnixMilli()), Code: link.Code})
+	}
+	return links, cursors, nil
+}
+
+// normalizeLinkQuery returns query with its defaults applied and its filters normalized, along with its decoded cursor.
+// The cursors of revoked links are in the order linkSortDeleted, so they only resume a listing of revoked links.
+func normalizeLinkQuery(query model.LinkQuery) (model.LinkQuery, model.LinkCursor, error) {
+	if query.Sort == "" {
+		query.Sort = model.LinkSortCreated
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultLinkPageSize
+	}
+	query.Host = strings.ToLower(query.Host)
+
+	switch {
+	case query.Sort != model.LinkSortCreated && query.Sort != model.LinkSortExpiry && query.Sort != model.LinkSortClicks,
+		query.Status != "" && query.Status != model.LinkStatusActive && query.Status != model.LinkStatusExpired && query.Status != model.LinkStatusRevoked,
+		query.Status == model.LinkStatusRevoked && (query.Sort != model.LinkSortCreated || query.ExpiringWithin != 0),
+		query.Limit < 1 || query.Limit > MaxLinkPageSize,
+		query.ExpiringWithin < 0:
+		return model.LinkQuery{}, model.LinkCursor{}, ErrInvalidLinkQuery
+	}
+
+	after := model.LinkCursor{Sort: query.Sort}
+	if query.Status == model.LinkStatusRevoked {
+		after.Sort = linkSortDeleted
+	}
+	if query.Cursor != "" {
+		cursor, ok := decodeLinkCursor(query.Cursor)
+		if !ok || cursor.Sort != after.Sort {
+			return model.LinkQuery{}, model.LinkCursor{}, ErrInvalidLinkQuery
+		}
+		after = cursor
+	}
+	return query, after, nil
+}
+
+// linkMatches reports whether link matches the filters of query at now.
+func linkMatches(link model.LinkSummary, query model.LinkQuery, now time.Time) bool {
+	if query.Host != "" && link.Host != query.Host {
+		return false
+	}
+	if query.Tag != "" && !slices.Contains(link.Tags, query.Tag) {
+		return false
+	}
+	if query.Status != "" && link.Status(now) != query.Status {
+		return false
+	}
+	if query.ExpiringWithin > 0 && (!link.ExpiresAt.After(now) || link.ExpiresAt.After(now.Add(query.ExpiringWithin))) {
+		return false
+	}
+	return true
+}
+
+// linkScore returns the sort key of link in the order sort.
+func linkScore(link model.LinkSummary, sort string) float64 {
+	switch sort {
+	case model.LinkSortExpiry:
+		return float64(link.ExpiresAt.UnixMilli())
+	case model.LinkSortClicks:
+		return float64(link.Clicks)
+	default:
+		return float64(link.CreatedAt.UnixMilli())
+	}
+}
+
+// encodeLinkCursor returns the opaque form of cursor handed out to the clients.
+func encodeLinkCursor(cursor model.LinkCursor) string {
+	raw, _ := json.Marshal(linkCursor{Sort: cursor.Sort, Score: cursor.Score, Code: cursor.Code})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeLinkCursor decodes a cursor returned by encodeLinkCursor, it returns false if raw is not one.
+func decodeLinkCursor(raw string) (model.LinkCursor, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return model.LinkCursor{}, false
+	}
+	var cursor linkCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil || cursor.Code == "" {
+		return model.LinkCursor{}, false
+	}
+	return model.LinkCursor{Sort: cursor.Sort, Score: cursor.Score, Code: cursor.Code}, true
+}
+
+// validLinkTags reports whether tags can be stored with a link: up to maxLinkTags distinct tags of 1 to 32 lowercase
+// letters, digits, '-' or '_'.
+func validLinkTags(tags []string) bool {
+	if len(tags) > maxLinkTags {
+		return false
+	}
+	for i, tag := range tags {
+		if !linkTagPattern.MatchString(tag) || slices.Contains(tags[:i], tag) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkList_List(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	active := model.LinkSummary{Code: "active", Host: "example.com", Tags: []string{"promo"}, Clicks: 5, CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}
+	other := model.LinkSummary{Code: "other", Host: "other.com", Clicks: 3, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(48 * time.Hour)}
+	expired := model.LinkSummary{Code: "expired", Host: "example.com", Tags: []string{"promo"}, Clicks: 1, CreatedAt: now.Add(-3 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+	batch := make([]model.LinkSummary, linkScanBatch)
+	for i := range batch {
+		batch[i] = other
+	}
+	batch[linkScanBatch-1] = active
+
+	created := func(link model.LinkSummary) model.LinkCursor {
+		return model.LinkCursor{Sort: model.LinkSortCreated, Score: float64(link.CreatedAt.UnixMilli()), Code: link.Code}
+	}
+	deleted := func(link model.LinkSummary) model.TrashedLink {
+		return model.TrashedLink{LinkSummary: link, Owner: "u1", DeletedAt: now}
+	}
+	revoked := func(link model.LinkSummary) model.LinkSummary {
+		link.Revoked = true
+		return link
+	}
+
+	testCases := []struct {
+		name string
+
+		query      model.LinkQuery
+		setupIndex func(t *testing.T) *mocks.LinkIndex
+		setupTrash func(t *testing.T) *mocks.LinkTrash
+
+		expectedPage model.LinkPage
+		expectErr    error
+	}{
+		{
+			name: "every link, newest first",
+
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				index := mocks.NewLinkIndex(t)
+				index.On("Prune", mock.Anything, "ws1", "u1", now.Add(-ExpiredLinkRetention)).Return(nil).Once()
+				index.On("Scan", mock.Anything, "ws1", "u1", model.LinkCursor{Sort: model.LinkSortCreated}, linkScanBatch).Return([]model.LinkSummary{active, other, expired}, nil).Once()
+				return index
+			},
+
+			expectedPage: model.LinkPage{Links: []model.LinkSummary{active, other, expired}},
+		},
+		{
+			name: "full page has a next cursor",
+
+			query: model.LinkQuery{Limit: 2},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				index := mocks.NewLinkIndex(t)
+				index.On("Prune", mock.Anything, "ws1", "u1", now.Add(-ExpiredLinkRetention)).Return(nil).Once()
+				index.On("Scan", mock.Anything, "ws1", "u1", model.LinkCursor{Sort: model.LinkSortCreated}, linkScanBatch).Return([]model.LinkSummary{active, other, expired}, nil).Once()
+				return index
+			},
+
+			expectedPage: model.LinkPage{Links: []model.LinkSummary{active, other}, Next: encodeLinkCursor(created(other))},
+		},
+		{
+			name: "filters, resumed from a cursor",
+
+			query: model.LinkQuery{Host: "Example.com", Tag: "promo", Status: model.LinkStatusActive, Cursor: encodeLinkCursor(created(active))},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				index := mocks.NewLinkIndex(t)
+				index.On("Prune", mock.Anything, "ws1", "u1", now.Add(-ExpiredLinkRetention)).Return(nil).Once()
+				index.On("Scan", mock.Anything, "ws1", "u1", created(active), linkScanBatch).Return([]model.LinkSummary{other, expired, active}, nil).Once()
+				return index
+			},
+
+			expectedPage: model.LinkPage{Links: []model.LinkSummary{active}},
+		},
+		{
+			name: "filtered links span several scans",
+
+			query: model.LinkQuery{Tag: "promo"},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				index := mocks.NewLinkIndex(t)
+				index.On("Prune", mock.Anything, "ws1", "u1", now.Add(-ExpiredLinkRetention)).Return(nil).Once()
+				index.On("Scan", mock.Anything, "ws1", "u1", model.LinkCursor{Sort: model.LinkSortCreated}, linkScanBatch).Return(batch, nil).Once()
+				index.On("Scan", mock.Anything, "ws1", "u1", created(active), linkScanBatch).Return([]model.LinkSummary{expired}, nil).Once()
+				return index
+			},
+
+			expectedPage: model.LinkPage{Links: []model.LinkSummary{active, expired}},
+		},
+		{
+			name: "filter matching none stops at the scan limit",
+
+			query: model.LinkQuery{Tag: "q3"},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				index := mocks.NewLinkIndex(t)
+				index.On("Prune", mock.Anything, "ws1", "u1", now.Add(-ExpiredLinkRetention)).Return(nil).Once()
+				index.On("Scan", mock.Anything, "ws1", "u1", mock.Anything, linkScanBatch).Return(batch, nil).Times(linkScanLimit / linkScanBatch)
+				return index
+			},
+
+			expectedPage: model.LinkPage{Links: []model.LinkSummary{}, Next: encodeLinkCursor(created(active))},
+		},
+		{
+			name: "expiring within a window, soonest first",
+
+			query: model.LinkQuery{Sort: model.LinkSortExpiry, ExpiringWithin: 24 * time.Hour},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				index := mocks.NewLinkIndex(t)
+				index.On("Prune", mock.Anything, "ws1", "u1", now.Add(-ExpiredLinkRetention)).Return(nil).Once()
+				index.On("Scan", mock.Anything, "ws1", "u1", model.LinkCursor{Sort: model.LinkSortExpiry}, linkScanBatch).Return([]model.LinkSummary{expired, active, other}, nil).Once()
+				return index
+			},
+
+			expectedPage: model.LinkPage{Links: []model.LinkSummary{active}},
+		},
+		{
+			name: "revoked links from the trash, latest deleted first",
+
+			query: model.LinkQuery{Status: model.LinkStatusRevoked, Host: "example.com", Limit: 1},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				index := mocks.NewLinkIndex(t)
+				index.On("Prune", mock.Anything, "ws1", "u1", now.Add(-ExpiredLinkRetention)).Return(nil).Once()
+				return index
+			},
+			setupTrash: func(t *testing.T) *mocks.LinkTrash {
+				trash := mocks.NewLinkTrash(t)
+				trash.On("Scan", mock.Anything, "ws1", "u1", model.LinkCursor{Sort: linkSortDeleted}, linkScanBatch).Return([]model.TrashedLink{deleted(other), deleted(active)}, nil).Once()
+				return trash
+			},
+
+			expectedPage: model.LinkPage{
+				Links: []model.LinkSummary{revoked(active)},
+				Next:  encodeLinkCursor(model.LinkCursor{Sort: linkSortDeleted, Score: float64(now.UnixMilli()), Code: "active"}),
+			},
+		},
+		{
+			name: "revoked links in another sort",
+
+			query: model.LinkQuery{Status: model.LinkStatusRevoked, Sort: model.LinkSortClicks},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				return mocks.NewLinkIndex(t)
+			},
+
+			expectErr: ErrInvalidLinkQuery,
+		},
+		{
+			name: "cursor of revoked links",
+
+			query: model.LinkQuery{Cursor: encodeLinkCursor(model.LinkCursor{Sort: linkSortDeleted, Score: 1, Code: "active"})},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				return mocks.NewLinkIndex(t)
+			},
+
+			expectErr: ErrInvalidLinkQuery,
+		},
+		{
+			name: "unknown status",
+
+			query: model.LinkQuery{Status: "paused"},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				return mocks.NewLinkIndex(t)
+			},
+
+			expectErr: ErrInvalidLinkQuery,
+		},
+		{
+			name: "unknown sort",
+
+			query: model.LinkQuery{Sort: "name"},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				return mocks.NewLinkIndex(t)
+			},
+
+			expectErr: ErrInvalidLinkQuery,
+		},
+		{
+			name: "limit too large",
+
+			query: model.LinkQuery{Limit: MaxLinkPageSize + 1},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				return mocks.NewLinkIndex(t)
+			},
+
+			expectErr: ErrInvalidLinkQuery,
+		},
+		{
+			name: "cursor of another sort",
+
+			query: model.LinkQuery{Sort: model.LinkSortClicks, Cursor: encodeLinkCursor(created(active))},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				return mocks.NewLinkIndex(t)
+			},
+
+			expectErr: ErrInvalidLinkQuery,
+		},
+		{
+			name: "malformed cursor",
+
+			query: model.LinkQuery{Cursor: "not a cursor"},
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				return mocks.NewLinkIndex(t)
+			},
+
+			expectErr: ErrInvalidLinkQuery,
+		},
+		{
+			name: "index error",
+
+			setupIndex: func(t *testing.T) *mocks.LinkIndex {
+				index := mocks.NewLinkIndex(t)
+				index.On("Prune", mock.Anything, "ws1", "u1", now.Add(-ExpiredLinkRetention)).Return(nil).Once()
+				index.On("Scan", mock.Anything, "ws1", "u1", model.LinkCursor{Sort: model.LinkSortCreated}, linkScanBatch).Return(nil, errors.New("redis down")).Once()
+				return index
+			},
+
+			expectErr: errors.New("redis down"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			trash := mocks.NewLinkTrash(t)
+			if tc.setupTrash != nil {
+				trash = tc.setupTrash(t)
+			}
+			testSvc := &linkList{index: tc.setupIndex(t), trash: trash, now: func() time.Time { return now }}
+			page, err := testSvc.List(t.Context(), "ws1", "u1", tc.query)
+
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectedPage, page)
+		})
+	}
+}
+
+func TestValidLinkTags(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		tags []string
+
+		expected bool
+	}{
+		{
+			name: "none",
+
+			expected: true,
+		},
+		{
+			name: "distinct tags",
+
+			tags: []string{"q3", "promo", "launch_2025", "a-b"},
+
+			expected: true,
+		},
+		{
+			name: "uppercase",
+
+			tags: []string{"Promo"},
+
+			expected: false,
+		},
+		{
+			name: "too long",
+
+			tags: []string{strings.Repeat("a", 33)},
+
+			expected: false,
+		},
+		{
+			name: "duplicate",
+
+			tags: []string{"promo", "q3", "promo"},
+
+			expected: false,
+		},
+		{
+			name: "too many",
+
+			tags: strings.Split("a,b,c,d,e,f,g,h,i,j,k", ","),
+
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, validLinkTags(tc.tags))
+		})
+	}
+}
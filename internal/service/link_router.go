@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/rs/zerolog/log"
+	"hash/fnv"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxLinkRules     = 20
+	maxLinkVariants  = 10
+	maxVariantWeight = 1000
+	// timeOfDayLayout is the layout of the From and To times of a model.TimeWindow.
+	timeOfDayLayout = "15:04"
+)
+
+// CountryResolver returns the ISO 3166-1 alpha-2 country code of an IP address, and an empty string if it is unknown.
+// *geoip.Reader implements it.
+//
+//go:generate mockery --name CountryResolver --filename country_resolver.go
+type CountryResolver interface {
+	Country(ip netip.Addr) (string, error)
+}
+
+var variantNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// LinkRouter picks the destination of a link for a visitor.
+//
+//go:generate mockery --name LinkRouter --filename link_router.go
+type LinkRouter interface {
+	Route(ctx context.Context, code string, link model.Link, visitor model.Visitor) (url, variant string)
+}
+
+type linkRouter struct {
+	countries CountryResolver
+	now       func() time.Time
+	locations sync.Map
+}
+
+// NewLinkRouter returns a new instance of the linkRouter, which implements the LinkRouter interface.
+// The country resolver is optional, when it is nil rules with countries never match.
+func NewLinkRouter(countries CountryResolver) LinkRouter {
+	return &linkRouter{countries: countries, now: time.Now}
+}
+
+// Route returns the URL of the first rule of link matching visitor. When none does, it returns the URL and the name of the
+// variant of link assigned to visitor, or the URL of link if it has no variants.
+// The country of the visitor is only resolved when a rule needs it, a lookup error is logged and leaves the country unknown.
+// Variants are assigned by hashing the ID of the visitor with code, the same visitor keeps the same variant as long as
+// the variants of the link are unchanged, and visitors spread over the variants in proportion to their weights.
+func (r *linkRouter) Route(ctx context.Context, code string, link model.Link, visitor model.Visitor) (string, string) {
+	if url, ok := r.matchRule(ctx, link.Rules, visitor); ok {
+		return url, ""
+	}
+	if len(link.Variants) > 0 {
+		variant := assignVariant(link.Variants, visitor.ID+"\x00"+code)
+		return variant.URL, variant.Name
+	}
+	return link.URL, ""
+}
+
+// matchRule returns the URL of the first of rules matching visitor, and false if none does.
+func (r *linkRouter) matchRule(ctx context.Context, rules []model.Rule, visitor model.Visitor) (string, bool) {
+	if len(rules) == 0 {
+		return "", false
+	}
+
+	platform := detectPlatform(visitor.UserAgent)
+	language := preferredLanguage(visitor.AcceptLanguage)
+	country, countryResolved := "", false
+	for _, rule := range rules {
+		if len(rule.Platforms) > 0 && !slices.Contains(rule.Platforms, platform) {
+			continue
+		}
+		if len(rule.Languages) > 0 && !slices.ContainsFunc(rule.Languages, func(l string) bool { return matchLanguage(l, language) }) {
+			continue
+		}
+		if len(rule.Countries) > 0 {
+			if !countryResolved {
+				country, countryResolved = r.country(ctx, visitor), true
+			}
+			if !slices.ContainsFunc(rule.Countries, func(c string) bool { return country != "" && strings.EqualFold(c, country) }) {
+				continue
+			}
+		}
+		if rule.Time != nil && !r.inTimeWindow(*rule.Time) {
+			continue
+		}
+		return rule.URL, true
+	}
+	return "", false
+}
+
+// assignVariant returns the variant of variants the hash of key falls in, each variant covering a range of the hash
+// as wide as its weight.
+func assignVariant(variants []model.Variant, key string) model.Variant {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	point := int(h.Sum64() % uint64(total))
+	for _, v := range variants {
+		if point < v.Weight {
+			return v
+		}
+		point -= v.Weight
+	}
+	return variants[len(variants)-1]
+}
+
+// country returns the country of visitor, or an empty string if it is unknown.
+func (r *linkRouter) country(ctx context.Context, visitor model.Visitor) string {
+	if r.countries == nil || !visitor.IP.IsValid() {
+		return ""
+	}
+	country, err := r.countries.Country(visitor.IP)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("ip", visitor.IP.String()).Msg("Cannot resolve the country of the visitor")
+		return ""
+	}
+	return country
+}
+
+// inTimeWindow reports whether the current time falls in window, a window that cannot be parsed never matches.
+func (r *linkRouter) inTimeWindow(window model.TimeWindow) bool {
+	from, to, loc, err := r.parseTimeWindow(window)
+	if err != nil {
+		return false
+	}
+	now := r.now().In(loc)
+	minute := now.Hour()*60 + now.Minute()
+	if from < to {
+		return from <= minute && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// parseTimeWindow returns the minutes of the day From and To of window stand for and its location.
+// Locations are loaded once, the time zone database is read from disk.
+func (r *linkRouter) parseTimeWindow(window model.TimeWindow) (from, to int, loc *time.Location, err error) {
+	if from, err = minuteOfDay(window.From); err != nil {
+		return 0, 0, nil, err
+	}
+	if to, err = minuteOfDay(window.To); err != nil {
+		return 0, 0, nil, err
+	}
+	if cached, ok := r.locations.Load(window.Timezone); ok {
+		return from, to, cached.(*time.Location), nil
+	}
+	if loc, err = time.LoadLocation(window.Timezone); err != nil {
+		return 0, 0, nil, err
+	}
+	r.locations.Store(window.Timezone, loc)
+	return from, to, loc, nil
+}
+
+// minuteOfDay returns the minute of the day of the "HH:MM" time hhmm.
+func minuteOfDay(hhmm string) (int, error) {
+	t, err := time.Parse(timeOfDayLayout, hhmm)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// detectPlatform returns the model.Platform* constant of the operating system named in userAgent, or an empty string.
+// Android and iOS are checked first, their user agents also name Linux and Mac OS X.
+func detectPlatform(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "iPod"):
+		return model.PlatformIOS
+	case strings.Contains(userAgent, "Android"):
+		return model.PlatformAndroid
+	case strings.Contains(userAgent, "Windows"):
+		return model.PlatformWindows
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		return model.PlatformMacOS
+	case strings.Contains(userAgent, "Linux"):
+		return model.PlatformLinux
+	}
+	return ""
+}
+
+// preferredLanguage returns the language tag of acceptLanguage with the highest quality, the first one on a tie,
+// in lower case. It returns an empty string when the header names no language.
+func preferredLanguage(acceptLanguage string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = strings.ToLower(tag), q
+		}
+	}
+	return best
+}
+
+// matchLanguage reports whether the language tag of a rule matches language, the preferred language of a visitor.
+// A tag matches itself and its subtags, "en" matches "en-us" but "en-gb" does not.
+func matchLanguage(tag, language string) bool {
+	if language == "" {
+		return false
+	}
+	tag = strings.ToLower(tag)
+	return language == tag || strings.HasPrefix(language, tag+"-")
+}
+
+// validLinkRules reports whether rules can be stored with a link: at most maxLinkRules rules, each with an absolute
+// http or https URL, at least one condition, known platforms, two-letter countries and a valid time window.
+func validLinkRules(rules []model.Rule) bool {
+	if len(rules) > maxLinkRules {
+		return false
+	}
+	for _, rule := range rules {
+		if !validAbsoluteURL(rule.URL) {
+			return false
+		}
+		if len(rule.Platforms) == 0 && len(rule.Languages) == 0 && len(rule.Countries) == 0 && rule.Time == nil {
+			return false
+		}
+		for _, platform := range rule.Platforms {
+			switch platform {
+			case model.PlatformIOS, model.PlatformAndroid, model.PlatformWindows, model.PlatformMacOS, model.PlatformLinux:
+			default:
+				return false
+			}
+		}
+		if slices.Contains(rule.Languages, "") {
+			return false
+		}
+		for _, country := range rule.Countries {
+			if len(country) != 2 || strings.ContainsFunc(country, func(r rune) bool { return (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') }) {
+				return false
+			}
+		}
+		if rule.Time != nil && !validTimeWindow(*rule.Time) {
+			return false
+		}
+	}
+	return true
+}
+
+// validLinkVariants reports whether variants can be stored with a link: none, or 2 to maxLinkVariants variants with
+// distinct names of 1 to 32 letters, digits, '-' or '_', absolute http or https URLs and weights from 1 to maxVariantWeight.
+func validLinkVariants(variants []model.Variant) bool {
+	if len(variants) == 0 {
+		return true
+	}
+	if len(variants) < 2 || len(variants) > maxLinkVariants {
+		return false
+	}
+	names := make(map[string]struct{}, len(variants))
+	for _, v := range variants {
+		if !variantNamePattern.MatchString(v.Name) || !validAbsoluteURL(v.URL) || v.Weight < 1 || v.Weight > maxVariantWeight {
+			return false
+		}
+		if _, ok := names[v.Name]; ok {
+			return false
+		}
+		names[v.Name] = struct{}{}
+	}
+	return true
+}
+
+// validAbsoluteURL reports whether rawURL is an absolute http or https URL.
+func validAbsoluteURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validTimeWindow reports whether window has two distinct "HH:MM" times and a known time zone.
+func validTimeWindow(window model.TimeWindow) bool {
+	from, err := minuteOfDay(window.From)
+	if err != nil {
+		return false
+	}
+	to, err := minuteOfDay(window.To)
+	if err != nil || from == to {
+		return false
+	}
+	_, err = time.LoadLocation(window.Timezone)
+	return err == nil
+}
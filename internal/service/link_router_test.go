@@ -0,0 +1,486 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	iPhoneUserAgent  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+	androidUserAgent = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+	windowsUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+)
+
+func TestLinkRouter_Route(t *testing.T) {
+	t.Parallel()
+
+	// 2025-01-02 23:30 in Paris.
+	now := time.Date(2025, 1, 2, 22, 30, 0, 0, time.UTC)
+	appLink := model.Link{
+		URL: "https://example.com",
+		Rules: []model.Rule{
+			{URL: "https://apps.apple.com/app/id1", Platforms: []string{model.PlatformIOS}},
+			{URL: "https://play.google.com/store/apps/details?id=app", Platforms: []string{model.PlatformAndroid}},
+		},
+	}
+
+	testCases := []struct {
+		name string
+
+		link    model.Link
+		visitor model.Visitor
+
+		setupResolver func(t *testing.T) CountryResolver
+
+		expected        string
+		expectedVariant string
+	}{
+		{
+			name: "no rules",
+
+			link:    model.Link{URL: "https://example.com"},
+			visitor: model.Visitor{UserAgent: iPhoneUserAgent},
+
+			expected: "https://example.com",
+		},
+		{
+			name: "ios",
+
+			link:    appLink,
+			visitor: model.Visitor{UserAgent: iPhoneUserAgent},
+
+			expected: "https://apps.apple.com/app/id1",
+		},
+		{
+			name: "android",
+
+			link:    appLink,
+			visitor: model.Visitor{UserAgent: androidUserAgent},
+
+			expected: "https://play.google.com/store/apps/details?id=app",
+		},
+		{
+			name: "fallback",
+
+			link:    appLink,
+			visitor: model.Visitor{UserAgent: windowsUserAgent},
+
+			expected: "https://example.com",
+		},
+		{
+			name: "first matching rule wins",
+
+			link: model.Link{
+				URL: "https://example.com",
+				Rules: []model.Rule{
+					{URL: "https://example.com/fr", Languages: []string{"fr"}},
+					{URL: "https://example.com/ios", Platforms: []string{model.PlatformIOS}},
+				},
+			},
+			visitor: model.Visitor{UserAgent: iPhoneUserAgent, AcceptLanguage: "fr-CA,en;q=0.8"},
+
+			expected: "https://example.com/fr",
+		},
+		{
+			name: "every condition must match",
+
+			link: model.Link{
+				URL:   "https://example.com",
+				Rules: []model.Rule{{URL: "https://example.com/ios-fr", Platforms: []string{model.PlatformIOS}, Languages: []string{"fr"}}},
+			},
+			visitor: model.Visitor{UserAgent: iPhoneUserAgent, AcceptLanguage: "en-US"},
+
+			expected: "https://example.com",
+		},
+		{
+			name: "country",
+
+			link: model.Link{
+				URL: "https://example.com",
+				Rules: []model.Rule{
+					{URL: "https://example.com/de", Countries: []string{"DE", "AT"}},
+					{URL: "https://example.com/vn", Countries: []string{"vn"}},
+				},
+			},
+			visitor: model.Visitor{IP: netip.MustParseAddr("203.0.113.7")},
+
+			setupResolver: func(t *testing.T) CountryResolver {
+				resolver := mocks.NewCountryResolver(t)
+				resolver.On("Country", netip.MustParseAddr("203.0.113.7")).Return("VN", nil).Once()
+				return resolver
+			},
+
+			expected: "https://example.com/vn",
+		},
+		{
+			name: "country not resolved when an earlier rule matches",
+
+			link: model.Link{
+				URL: "https://example.com",
+				Rules: []model.Rule{
+					{URL: "https://example.com/ios", Platforms: []string{model.PlatformIOS}},
+					{URL: "https://example.com/vn", Countries: []string{"VN"}},
+				},
+			},
+			visitor: model.Visitor{UserAgent: iPhoneUserAgent, IP: netip.MustParseAddr("203.0.113.7")},
+
+			setupResolver: func(t *testing.T) CountryResolver {
+				return mocks.NewCountryResolver(t)
+			},
+
+			expected: "https://example.com/ios",
+		},
+		{
+			name: "country lookup error",
+
+			link: model.Link{
+				URL:   "https://example.com",
+				Rules: []model.Rule{{URL: "https://example.com/vn", Countries: []string{"VN"}}},
+			},
+			visitor: model.Visitor{IP: netip.MustParseAddr("203.0.113.7")},
+
+			setupResolver: func(t *testing.T) CountryResolver {
+				resolver := mocks.NewCountryResolver(t)
+				resolver.On("Country", netip.MustParseAddr("203.0.113.7")).Return("", errors.New("corrupt database")).Once()
+				return resolver
+			},
+
+			expected: "https://example.com",
+		},
+		{
+			name: "country without resolver",
+
+			link: model.Link{
+				URL:   "https://example.com",
+				Rules: []model.Rule{{URL: "https://example.com/vn", Countries: []string{"VN"}}},
+			},
+			visitor: model.Visitor{IP: netip.MustParseAddr("203.0.113.7")},
+
+			expected: "https://example.com",
+		},
+		{
+			name: "time window in time zone",
+
+			link: model.Link{
+				URL:   "https://example.com",
+				Rules: []model.Rule{{URL: "https://example.com/night", Time: &model.TimeWindow{From: "22:00", To: "06:00", Timezone: "Europe/Paris"}}},
+			},
+
+			expected: "https://example.com/night",
+		},
+		{
+			name: "time window in utc",
+
+			link: model.Link{
+				URL:   "https://example.com",
+				Rules: []model.Rule{{URL: "https://example.com/evening", Time: &model.TimeWindow{From: "18:00", To: "22:30"}}},
+			},
+
+			expected: "https://example.com",
+		},
+		{
+			name: "variant when no rule matches",
+
+			link: model.Link{
+				URL:      "https://example.com",
+				Rules:    []model.Rule{{URL: "https://apps.apple.com/app/id1", Platforms: []string{model.PlatformIOS}}},
+				Variants: []model.Variant{{Name: "b", URL: "https://example.com/b", Weight: 1}, {Name: "c", URL: "https://example.com/c", Weight: 0}},
+			},
+			visitor: model.Visitor{ID: "visitor-1", UserAgent: windowsUserAgent},
+
+			expected:        "https://example.com/b",
+			expectedVariant: "b",
+		},
+		{
+			name: "matching rule wins over variants",
+
+			link: model.Link{
+				URL:      "https://example.com",
+				Rules:    []model.Rule{{URL: "https://apps.apple.com/app/id1", Platforms: []string{model.PlatformIOS}}},
+				Variants: []model.Variant{{Name: "b", URL: "https://example.com/b", Weight: 1}, {Name: "c", URL: "https://example.com/c", Weight: 1}},
+			},
+			visitor: model.Visitor{ID: "visitor-1", UserAgent: iPhoneUserAgent},
+
+			expected: "https://apps.apple.com/app/id1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var resolver CountryResolver
+			if tc.setupResolver != nil {
+				resolver = tc.setupResolver(t)
+			}
+			router := &linkRouter{countries: resolver, now: func() time.Time { return now }}
+
+			url, variant := router.Route(context.Background(), "abc1234", tc.link, tc.visitor)
+			assert.Equal(t, tc.expected, url)
+			assert.Equal(t, tc.expectedVariant, variant)
+		})
+	}
+}
+
+func TestAssignVariant(t *testing.T) {
+	t.Parallel()
+
+	variants := []model.Variant{
+		{Name: "a", URL: "https://example.com/a", Weight: 70},
+		{Name: "b", URL: "https://example.com/b", Weight: 30},
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		key := strconv.Itoa(i) + "\x00abc1234"
+		variant := assignVariant(variants, key)
+		assert.Equal(t, variant, assignVariant(variants, key), "assignment must be sticky")
+		counts[variant.Name]++
+	}
+	assert.InDelta(t, 7000, counts["a"], 300)
+	assert.InDelta(t, 3000, counts["b"], 300)
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		acceptLanguage string
+
+		expected string
+	}{
+		{
+			name: "single",
+
+			acceptLanguage: "en-US",
+
+			expected: "en-us",
+		},
+		{
+			name: "highest quality",
+
+			acceptLanguage: "en;q=0.5, vi-VN;q=0.9, fr;q=0.7",
+
+			expected: "vi-vn",
+		},
+		{
+			name: "first on a tie",
+
+			acceptLanguage: "de-DE,de,en",
+
+			expected: "de-de",
+		},
+		{
+			name: "wildcard and refused languages are skipped",
+
+			acceptLanguage: "*, ja;q=0",
+
+			expected: "",
+		},
+		{
+			name: "empty",
+
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, preferredLanguage(tc.acceptLanguage))
+		})
+	}
+}
+
+func TestValidLinkRules(t *testing.T) {
+	t.Parallel()
+
+	tooMany := make([]model.Rule, maxLinkRules+1)
+	for i := range tooMany {
+		tooMany[i] = model.Rule{URL: "https://example.com", Platforms: []string{model.PlatformIOS}}
+	}
+
+	testCases := []struct {
+		name string
+
+		rules []model.Rule
+
+		expected bool
+	}{
+		{
+			name: "none",
+
+			expected: true,
+		},
+		{
+			name: "every condition",
+
+			rules: []model.Rule{{
+				URL:       "https://example.com/fr",
+				Platforms: []string{model.PlatformAndroid, model.PlatformLinux},
+				Languages: []string{"fr", "fr-CA"},
+				Countries: []string{"FR", "ca"},
+				Time:      &model.TimeWindow{From: "09:00", To: "17:30", Timezone: "America/Toronto"},
+			}},
+
+			expected: true,
+		},
+		{
+			name: "too many",
+
+			rules: tooMany,
+
+			expected: false,
+		},
+		{
+			name: "relative url",
+
+			rules: []model.Rule{{URL: "/fr", Languages: []string{"fr"}}},
+
+			expected: false,
+		},
+		{
+			name: "no condition",
+
+			rules: []model.Rule{{URL: "https://example.com"}},
+
+			expected: false,
+		},
+		{
+			name: "unknown platform",
+
+			rules: []model.Rule{{URL: "https://example.com", Platforms: []string{"symbian"}}},
+
+			expected: false,
+		},
+		{
+			name: "three-letter country",
+
+			rules: []model.Rule{{URL: "https://example.com", Countries: []string{"FRA"}}},
+
+			expected: false,
+		},
+		{
+			name: "malformed time",
+
+			rules: []model.Rule{{URL: "https://example.com", Time: &model.TimeWindow{From: "9am", To: "17:00"}}},
+
+			expected: false,
+		},
+		{
+			name: "empty time window",
+
+			rules: []model.Rule{{URL: "https://example.com", Time: &model.TimeWindow{From: "09:00", To: "09:00"}}},
+
+			expected: false,
+		},
+		{
+			name: "unknown time zone",
+
+			rules: []model.Rule{{URL: "https://example.com", Time: &model.TimeWindow{From: "09:00", To: "17:00", Timezone: "Mars/Olympus"}}},
+
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, validLinkRules(tc.rules))
+		})
+	}
+}
+
+func TestValidLinkVariants(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		variants []model.Variant
+
+		expected bool
+	}{
+		{
+			name: "none",
+
+			expected: true,
+		},
+		{
+			name: "70/30",
+
+			variants: []model.Variant{{Name: "control", URL: "https://example.com/a", Weight: 70}, {Name: "new-page", URL: "https://example.com/b", Weight: 30}},
+
+			expected: true,
+		},
+		{
+			name: "single variant",
+
+			variants: []model.Variant{{Name: "a", URL: "https://example.com/a", Weight: 1}},
+
+			expected: false,
+		},
+		{
+			name: "duplicate name",
+
+			variants: []model.Variant{{Name: "a", URL: "https://example.com/a", Weight: 1}, {Name: "a", URL: "https://example.com/b", Weight: 1}},
+
+			expected: false,
+		},
+		{
+			name: "invalid name",
+
+			variants: []model.Variant{{Name: "a b", URL: "https://example.com/a", Weight: 1}, {Name: "c", URL: "https://example.com/b", Weight: 1}},
+
+			expected: false,
+		},
+		{
+			name: "zero weight",
+
+			variants: []model.Variant{{Name: "a", URL: "https://example.com/a", Weight: 0}, {Name: "b", URL: "https://example.com/b", Weight: 1}},
+
+			expected: false,
+		},
+		{
+			name: "relative url",
+
+			variants: []model.Variant{{Name: "a", URL: "/a", Weight: 1}, {Name: "b", URL: "https://example.com/b", Weight: 1}},
+
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, validLinkVariants(tc.variants))
+		})
+	}
+}
+
+func TestDetectPlatform(t *testing.T) {
+	t.Parallel()
+
+	for userAgent, expected := range map[string]string{
+		iPhoneUserAgent:  model.PlatformIOS,
+		androidUserAgent: model.PlatformAndroid,
+		windowsUserAgent: model.PlatformWindows,
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Safari/605.1.15": model.PlatformMacOS,
+		"Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0":            model.PlatformLinux,
+		"curl/8.4.0": "",
+	} {
+		assert.Equal(t, expected, detectPlatform(userAgent), userAgent)
+	}
+}
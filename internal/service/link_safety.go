@@ -0,0 +1,41 @@
+package service
+
+import (
+	"github.com/lhducc/bookmark-management/internal/model"
+	"net"
+	"net/url"
+	"strings"
+)
+
+const (
+	SafetyWarningUnparsable  = "The destination is not a valid URL."
+	SafetyWarningNotHTTPS    = "The destination is not served over HTTPS, the connection is not encrypted."
+	SafetyWarningCredentials = "The destination carries a user name or password before its host, which can disguise the real host."
+	SafetyWarningIPHost      = "The destination host is an IP address rather than a domain name."
+	SafetyWarningPunycode    = "The destination host contains internationalized characters that may imitate another domain."
+)
+
+// CheckLinkSafety runs static checks on the destination rawURL of a link, for the preview page.
+// The checks only look at the URL itself, they do not fetch the destination nor query any reputation service.
+func CheckLinkSafety(rawURL string) model.LinkSafety {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return model.LinkSafety{Warnings: []string{SafetyWarningUnparsable}}
+	}
+
+	var warnings []string
+	if !strings.EqualFold(u.Scheme, "https") {
+		warnings = append(warnings, SafetyWarningNotHTTPS)
+	}
+	if u.User != nil {
+		warnings = append(warnings, SafetyWarningCredentials)
+	}
+	host := u.Hostname()
+	if net.ParseIP(host) != nil {
+		warnings = append(warnings, SafetyWarningIPHost)
+	}
+	if strings.Contains(strings.ToLower(host), "xn--") || strings.ContainsFunc(host, func(r rune) bool { return r > 127 }) {
+		warnings = append(warnings, SafetyWarningPunycode)
+	}
+	return model.LinkSafety{Warnings: warnings}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckLinkSafety(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		url string
+
+		expectedWarnings []string
+	}{
+		{
+			name: "https domain",
+
+			url: "https://example.com/path?q=1",
+		},
+		{
+			name: "plain http",
+
+			url: "http://example.com",
+
+			expectedWarnings: []string{SafetyWarningNotHTTPS},
+		},
+		{
+			name: "credentials before the host",
+
+			url: "https://example.com@evil.io/login",
+
+			expectedWarnings: []string{SafetyWarningCredentials},
+		},
+		{
+			name: "ip host",
+
+			url: "https://[2001:db8::1]:8443/",
+
+			expectedWarnings: []string{SafetyWarningIPHost},
+		},
+		{
+			name: "punycode host over http",
+
+			url: "http://xn--pple-43d.com",
+
+			expectedWarnings: []string{SafetyWarningNotHTTPS, SafetyWarningPunycode},
+		},
+		{
+			name: "unicode host",
+
+			url: "https://аpple.com",
+
+			expectedWarnings: []string{SafetyWarningPunycode},
+		},
+		{
+			name: "not a url",
+
+			url: "not a url",
+
+			expectedWarnings: []string{SafetyWarningUnparsable},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			safety := CheckLinkSafety(tc.url)
+			assert.Equal(t, tc.expectedWarnings, safety.Warnings)
+			assert.Equal(t, len(tc.expectedWarnings) == 0, safety.Safe())
+		})
+	}
+}
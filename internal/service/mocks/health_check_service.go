@@ -55,6 +55,11 @@ func (_m *HealthCheck) Check(ctx context.Context) (string, string, string, error
 	return r0, r1, r2, r3
 }
 
+// Drain provides a mock function with no fields
+func (_m *HealthCheck) Drain() {
+	_m.Called()
+}
+
 // NewHealthCheck creates a new instance of HealthCheck. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewHealthCheck(t interface {
@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 
+	model "github.com/lhducc/bookmark-management/internal/model"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -13,27 +14,27 @@ type ShortenUrl struct {
 	mock.Mock
 }
 
-// GetUrl provides a mock function with given fields: cxt, urlCode
-func (_m *ShortenUrl) GetUrl(cxt context.Context, urlCode string) (string, error) {
-	ret := _m.Called(cxt, urlCode)
+// GetClicks provides a mock function with given fields: ctx, workspace, user, urlCode
+func (_m *ShortenUrl) GetClicks(ctx context.Context, workspace string, user string, urlCode string) (model.ClickStats, error) {
+	ret := _m.Called(ctx, workspace, user, urlCode)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUrl")
+		panic("no return value specified for GetClicks")
 	}
 
-	var r0 string
+	var r0 model.ClickStats
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return rf(cxt, urlCode)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (model.ClickStats, error)); ok {
+		return rf(ctx, workspace, user, urlCode)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = rf(cxt, urlCode)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) model.ClickStats); ok {
+		r0 = rf(ctx, workspace, user, urlCode)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(model.ClickStats)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(cxt, urlCode)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, workspace, user, urlCode)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -41,9 +42,55 @@ func (_m *ShortenUrl) GetUrl(cxt context.Context, urlCode string) (string, error
 	return r0, r1
 }
 
-// ShortenUrl provides a mock function with given fields: ctx, url, exp
-func (_m *ShortenUrl) ShortenUrl(ctx context.Context, url string, exp int) (string, error) {
-	ret := _m.Called(ctx, url, exp)
+// GetLink provides a mock function with given fields: ctx, workspace, urlCode
+func (_m *ShortenUrl) GetLink(ctx context.Context, workspace string, urlCode string) (model.Link, error) {
+	ret := _m.Called(ctx, workspace, urlCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLink")
+	}
+
+	var r0 model.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (model.Link, error)); ok {
+		return rf(ctx, workspace, urlCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) model.Link); ok {
+		r0 = rf(ctx, workspace, urlCode)
+	} else {
+		r0 = ret.Get(0).(model.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, workspace, urlCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordClick provides a mock function with given fields: ctx, workspace, owner, urlCode, variant
+func (_m *ShortenUrl) RecordClick(ctx context.Context, workspace string, owner string, urlCode string, variant string) error {
+	ret := _m.Called(ctx, workspace, owner, urlCode, variant)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordClick")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
+		r0 = rf(ctx, workspace, owner, urlCode, variant)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ShortenUrl provides a mock function with given fields: ctx, req
+func (_m *ShortenUrl) ShortenUrl(ctx context.Context, req model.ShortenRequest) (string, error) {
+	ret := _m.Called(ctx, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ShortenUrl")
@@ -51,17 +98,17 @@ func (_m *ShortenUrl) ShortenUrl(ctx context.Context, url string, exp int) (stri
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, int) (string, error)); ok {
-		return rf(ctx, url, exp)
+	if rf, ok := ret.Get(0).(func(context.Context, model.ShortenRequest) (string, error)); ok {
+		return rf(ctx, req)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, int) string); ok {
-		r0 = rf(ctx, url, exp)
+	if rf, ok := ret.Get(0).(func(context.Context, model.ShortenRequest) string); ok {
+		r0 = rf(ctx, req)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
-		r1 = rf(ctx, url, exp)
+	if rf, ok := ret.Get(1).(func(context.Context, model.ShortenRequest) error); ok {
+		r1 = rf(ctx, req)
 	} else {
 		r1 = ret.Error(1)
 	}
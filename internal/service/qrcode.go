@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/skip2/go-qrcode"
+	"strings"
+)
+
+const (
+	// QRCodeMinSize and QRCodeMaxSize bound the size of the rendered QR codes, in pixels.
+	QRCodeMinSize = 64
+	QRCodeMaxSize = 2048
+)
+
+// ErrInvalidQRCode is returned when the format, the size or the error correction level of a QR code is not supported.
+var ErrInvalidQRCode = errors.New("invalid QR code options")
+
+var qrRecoveryLevels = map[string]qrcode.RecoveryLevel{
+	model.QRECCLow:      qrcode.Low,
+	model.QRECCMedium:   qrcode.Medium,
+	model.QRECCQuartile: qrcode.High,
+	model.QRECCHigh:     qrcode.Highest,
+}
+
+// QRCode renders QR codes, in process without any network call.
+//
+//go:generate mockery --name QRCode --filename qrcode.go
+type QRCode interface {
+	Render(req model.QRCodeRequest) ([]byte, error)
+}
+
+type qrCode struct{}
+
+// NewQRCode returns a new instance of the qrCode, which implements the QRCode interface.
+func NewQRCode() QRCode {
+	return &qrCode{}
+}
+
+// Render returns the QR code of req as a PNG image or an SVG document.
+// The output only depends on req, the same request always renders the same bytes.
+// It returns ErrInvalidQRCode if the format is not a model.QRFormat* constant, the size is not between QRCodeMinSize
+// and QRCodeMaxSize or the error correction level is not a model.QRECC* constant.
+func (s *qrCode) Render(req model.QRCodeRequest) ([]byte, error) {
+	level, ok := qrRecoveryLevels[req.ECC]
+	if !ok || req.Size < QRCodeMinSize || req.Size > QRCodeMaxSize {
+		return nil, ErrInvalidQRCode
+	}
+
+	code, err := qrcode.New(req.Content, level)
+	if err != nil {
+		return nil, fmt.Errorf("encode QR code: %w", err)
+	}
+
+	switch req.Format {
+	case model.QRFormatPNG:
+		return code.PNG(req.Size)
+	case model.QRFormatSVG:
+		return qrSVG(code.Bitmap(), req.Size), nil
+	default:
+		return nil, ErrInvalidQRCode
+	}
+}
+
+// qrSVG returns an SVG document of size pixels drawing the dark modules of bitmap, quiet zone included.
+// Each row of dark modules is a single path segment per run, the document scales without blurring.
+func qrSVG(bitmap [][]bool, size int) []byte {
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, len(bitmap), len(bitmap))
+	svg.WriteString(`<rect width="100%" height="100%" fill="#fff"/>`)
+	fmt.Fprintf(&svg, `<path fill="#000" d="%s"/></svg>`, path.String())
+	return []byte(svg.String())
+}
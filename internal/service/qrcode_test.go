@@ -0,0 +1,88 @@
+package service
+
+import (
+	"bytes"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestQRCode_Render(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		req model.QRCodeRequest
+
+		expectErr    error
+		verifyOutput func(t *testing.T, output []byte)
+	}{
+		{
+			name: "png",
+
+			req: model.QRCodeRequest{Content: "https://sho.rt/abc1234", Format: model.QRFormatPNG, Size: 256, ECC: model.QRECCMedium},
+
+			verifyOutput: func(t *testing.T, output []byte) {
+				img, err := png.Decode(bytes.NewReader(output))
+				require.NoError(t, err)
+				assert.Equal(t, 256, img.Bounds().Dx())
+				assert.Equal(t, 256, img.Bounds().Dy())
+			},
+		},
+		{
+			name: "svg",
+
+			req: model.QRCodeRequest{Content: "https://sho.rt/abc1234", Format: model.QRFormatSVG, Size: 512, ECC: model.QRECCHigh},
+
+			verifyOutput: func(t *testing.T, output []byte) {
+				svg := string(output)
+				assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="512" height="512" viewBox="0 0 `))
+				assert.Contains(t, svg, `<path fill="#000" d="M`)
+				assert.True(t, strings.HasSuffix(svg, `"/></svg>`))
+			},
+		},
+		{
+			name: "unknown format",
+
+			req: model.QRCodeRequest{Content: "https://sho.rt/abc1234", Format: "gif", Size: 256, ECC: model.QRECCMedium},
+
+			expectErr: ErrInvalidQRCode,
+		},
+		{
+			name: "size too small",
+
+			req: model.QRCodeRequest{Content: "https://sho.rt/abc1234", Format: model.QRFormatPNG, Size: 16, ECC: model.QRECCMedium},
+
+			expectErr: ErrInvalidQRCode,
+		},
+		{
+			name: "unknown error correction level",
+
+			req: model.QRCodeRequest{Content: "https://sho.rt/abc1234", Format: model.QRFormatPNG, Size: 256, ECC: "X"},
+
+			expectErr: ErrInvalidQRCode,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			output, err := NewQRCode().Render(tc.req)
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			tc.verifyOutput(t, output)
+
+			again, err := NewQRCode().Render(tc.req)
+			require.NoError(t, err)
+			assert.Equal(t, output, again, "the same request must render the same bytes")
+		})
+	}
+}
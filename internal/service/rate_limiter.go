@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository"
+)
+
+// RateLimiter counts requests against the rate limit policy of their route.
+//
+//go:generate mockery --name RateLimiter --filename rate_limiter.go
+type RateLimiter interface {
+	Allow(ctx context.Context, route, ip, userID string) (model.RateLimitResult, error)
+}
+
+type rateLimiter struct {
+	repo     repository.RateLimiter
+	policies map[string]model.RateLimitPolicy
+}
+
+// NewRateLimiter returns a new instance of the rateLimiter, which implements the RateLimiter interface.
+// policies maps a route name to its limits, routes without a policy are not limited.
+func NewRateLimiter(repo repository.RateLimiter, policies map[string]model.RateLimitPolicy) RateLimiter {
+	return &rateLimiter{
+		repo:     repo,
+		policies: policies,
+	}
+}
+
+// Allow counts one request to route and reports whether it is allowed.
+// A request with a userID is counted against the user limit of the route, otherwise against the IP limit.
+// The returned result has a zero Limit if no limit applies to the request, in which case it is always allowed.
+func (r *rateLimiter) Allow(ctx context.Context, route, ip, userID string) (_ model.RateLimitResult, err error) {
+	policy := r.policies[route]
+
+	limit, key := policy.IP, route+":ip:"+ip
+	if userID != "" {
+		limit, key = policy.User, route+":user:"+userID
+	}
+	if !limit.Enabled() {
+		return model.RateLimitResult{Allowed: true}, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "RateLimiter.Allow")
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.Allow(ctx, key, limit)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	policies := map[string]model.RateLimitPolicy{
+		"shorten": {
+			IP:   model.RateLimit{Limit: 10, Period: time.Minute},
+			User: model.RateLimit{Limit: 100, Period: time.Minute},
+		},
+		"redirect": {
+			IP: model.RateLimit{Limit: 600, Period: time.Minute},
+		},
+	}
+
+	testCases := []struct {
+		name string
+
+		route     string
+		userID    string
+		setupMock func(t *testing.T) *mocks.RateLimiter
+
+		expectedResult model.RateLimitResult
+		expectErr      error
+	}{
+		{
+			name: "anonymous client is limited by IP",
+
+			route: "shorten",
+			setupMock: func(t *testing.T) *mocks.RateLimiter {
+				repo := mocks.NewRateLimiter(t)
+				repo.On("Allow", mock.Anything, "shorten:ip:1.2.3.4", policies["shorten"].IP).
+					Return(model.RateLimitResult{Allowed: true, Limit: 10, Remaining: 9}, nil).Once()
+				return repo
+			},
+
+			expectedResult: model.RateLimitResult{Allowed: true, Limit: 10, Remaining: 9},
+		},
+		{
+			name: "authenticated client is limited by user",
+
+			route:  "shorten",
+			userID: "user-1",
+			setupMock: func(t *testing.T) *mocks.RateLimiter {
+				repo := mocks.NewRateLimiter(t)
+				repo.On("Allow", mock.Anything, "shorten:user:user-1", policies["shorten"].User).
+					Return(model.RateLimitResult{Allowed: false, Limit: 100, RetryAfter: time.Second}, nil).Once()
+				return repo
+			},
+
+			expectedResult: model.RateLimitResult{Allowed: false, Limit: 100, RetryAfter: time.Second},
+		},
+		{
+			name: "disabled user limit",
+
+			route:  "redirect",
+			userID: "user-1",
+			setupMock: func(t *testing.T) *mocks.RateLimiter {
+				return mocks.NewRateLimiter(t)
+			},
+
+			expectedResult: model.RateLimitResult{Allowed: true},
+		},
+		{
+			name: "route without policy",
+
+			route: "health",
+			setupMock: func(t *testing.T) *mocks.RateLimiter {
+				return mocks.NewRateLimiter(t)
+			},
+
+			expectedResult: model.RateLimitResult{Allowed: true},
+		},
+		{
+			name: "repository error",
+
+			route: "redirect",
+			setupMock: func(t *testing.T) *mocks.RateLimiter {
+				repo := mocks.NewRateLimiter(t)
+				repo.On("Allow", mock.Anything, "redirect:ip:1.2.3.4", policies["redirect"].IP).
+					Return(model.RateLimitResult{}, testError).Once()
+				return repo
+			},
+
+			expectErr: testError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			testSvc := NewRateLimiter(tc.setupMock(t), policies)
+
+			res, err := testSvc.Allow(context.Background(), tc.route, "1.2.3.4", tc.userID)
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectedResult, res)
+		})
+	}
+}
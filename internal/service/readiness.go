@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const drainingCheckName = "shutdown"
+
+// Readiness is a registry of named checkers deciding whether the instance should receive traffic.
+//
+//go:generate mockery --name Readiness --filename readiness.go
+type Readiness interface {
+	Register(name string, timeout time.Duration, check func(ctx context.Context) error)
+	Check(ctx context.Context) model.ReadinessReport
+	Drain()
+}
+
+type checker struct {
+	name    string
+	timeout time.Duration
+	check   func(ctx context.Context) error
+}
+
+type readiness struct {
+	cacheTTL time.Duration
+	now      func() time.Time
+	draining atomic.Bool
+
+	mu       sync.Mutex
+	checkers []checker
+	report   model.ReadinessReport
+}
+
+// NewReadiness returns a new instance of the readiness, which implements the Readiness interface.
+// A report is reused for cacheTTL, so frequent probes from several load balancers do not each hit the dependencies.
+func NewReadiness(cacheTTL time.Duration) Readiness {
+	return &readiness{
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+	}
+}
+
+// Register adds a checker, check fails the readiness when it returns an error or does not return within timeout.
+func (r *readiness) Register(name string, timeout time.Duration, check func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkers = append(r.checkers, checker{name: name, timeout: timeout, check: check})
+	r.report = model.ReadinessReport{}
+}
+
+// Check runs every checker concurrently and returns their results in registration order.
+// The checkers are not canceled with ctx, since their results are cached for later callers.
+// Once Drain has been called the report always fails, with a "shutdown" check on top of the cached results.
+func (r *readiness) Check(ctx context.Context) model.ReadinessReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.report.CheckedAt.IsZero() || r.now().Sub(r.report.CheckedAt) >= r.cacheTTL {
+		r.report = r.run(context.WithoutCancel(ctx))
+	}
+
+	report := r.report
+	report.Checks = append([]model.CheckResult(nil), r.report.Checks...)
+	if r.draining.Load() {
+		report.Status = model.CheckStatusFail
+		report.Checks = append(report.Checks, model.CheckResult{
+			Name:   drainingCheckName,
+			Status: model.CheckStatusFail,
+			Error:  ErrDraining.Error(),
+		})
+	}
+	return report
+}
+
+// Drain fails every later Check, so load balancers stop sending traffic before the instance shuts down.
+func (r *readiness) Drain() {
+	r.draining.Store(true)
+}
+
+func (r *readiness) run(ctx context.Context) model.ReadinessReport {
+	ctx, span := tracer.Start(ctx, "Readiness.Check")
+	defer span.End()
+
+	report := model.ReadinessReport{
+		Status:    model.CheckStatusOK,
+		CheckedAt: r.now(),
+		Checks:    make([]model.CheckResult, len(r.checkers)),
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range r.checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = runChecker(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for _, res := range report.Checks {
+		if res.Status != model.CheckStatusOK {
+			report.Status = model.CheckStatusFail
+		}
+	}
+	return report
+}
+
+func runChecker(ctx context.Context, c checker) model.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.check(ctx)
+	res := model.CheckResult{
+		Name:    c.name,
+		Status:  model.CheckStatusOK,
+		Latency: time.Since(start),
+	}
+	if err != nil {
+		res.Status = model.CheckStatusFail
+		res.Error = err.Error()
+	}
+	return res
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReadiness_Check(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		run func(r *readiness, clock *time.Time) model.ReadinessReport
+
+		expectedStatus string
+		expectedChecks []model.CheckResult
+		expectedCalls  int
+	}{
+		{
+			name: "all checks pass",
+
+			run: func(r *readiness, clock *time.Time) model.ReadinessReport {
+				return r.Check(context.Background())
+			},
+
+			expectedStatus: model.CheckStatusOK,
+			expectedChecks: []model.CheckResult{
+				{Name: "redis", Status: model.CheckStatusOK},
+				{Name: "slow", Status: model.CheckStatusOK},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name: "result is cached",
+
+			run: func(r *readiness, clock *time.Time) model.ReadinessReport {
+				r.Check(context.Background())
+				*clock = clock.Add(500 * time.Millisecond)
+				return r.Check(context.Background())
+			},
+
+			expectedStatus: model.CheckStatusOK,
+			expectedChecks: []model.CheckResult{
+				{Name: "redis", Status: model.CheckStatusOK},
+				{Name: "slow", Status: model.CheckStatusOK},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name: "expired cache runs the checks again",
+
+			run: func(r *readiness, clock *time.Time) model.ReadinessReport {
+				r.Check(context.Background())
+				*clock = clock.Add(time.Second)
+				return r.Check(context.Background())
+			},
+
+			expectedStatus: model.CheckStatusOK,
+			expectedChecks: []model.CheckResult{
+				{Name: "redis", Status: model.CheckStatusOK},
+				{Name: "slow", Status: model.CheckStatusOK},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name: "checker timeout fails",
+
+			run: func(r *readiness, clock *time.Time) model.ReadinessReport {
+				r.Register("stuck", 10*time.Millisecond, func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+				return r.Check(context.Background())
+			},
+
+			expectedStatus: model.CheckStatusFail,
+			expectedChecks: []model.CheckResult{
+				{Name: "redis", Status: model.CheckStatusOK},
+				{Name: "slow", Status: model.CheckStatusOK},
+				{Name: "stuck", Status: model.CheckStatusFail, Error: context.DeadlineExceeded.Error()},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name: "canceled caller does not fail the checks",
+
+			run: func(r *readiness, clock *time.Time) model.ReadinessReport {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return r.Check(ctx)
+			},
+
+			expectedStatus: model.CheckStatusOK,
+			expectedChecks: []model.CheckResult{
+				{Name: "redis", Status: model.CheckStatusOK},
+				{Name: "slow", Status: model.CheckStatusOK},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name: "draining",
+
+			run: func(r *readiness, clock *time.Time) model.ReadinessReport {
+				r.Check(context.Background())
+				r.Drain()
+				return r.Check(context.Background())
+			},
+
+			expectedStatus: model.CheckStatusFail,
+			expectedChecks: []model.CheckResult{
+				{Name: "redis", Status: model.CheckStatusOK},
+				{Name: "slow", Status: model.CheckStatusOK},
+				{Name: "shutdown", Status: model.CheckStatusFail, Error: ErrDraining.Error()},
+			},
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := time.Unix(0, 0)
+			r := NewReadiness(time.Second).(*readiness)
+			r.now = func() time.Time { return clock }
+
+			calls := 0
+			r.Register("redis", time.Second, func(ctx context.Context) error {
+				calls++
+				return ctx.Err()
+			})
+			r.Register("slow", time.Second, func(ctx context.Context) error {
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			})
+
+			report := tc.run(r, &clock)
+
+			assert.Equal(t, tc.expectedStatus, report.Status)
+			assert.Equal(t, tc.expectedCalls, calls)
+			assert.Len(t, report.Checks, len(tc.expectedChecks))
+			for i, expected := range tc.expectedChecks {
+				assert.Equal(t, expected.Name, report.Checks[i].Name)
+				assert.Equal(t, expected.Status, report.Checks[i].Status)
+				assert.Equal(t, expected.Error, report.Checks[i].Error)
+			}
+			assert.GreaterOrEqual(t, report.Checks[1].Latency, 5*time.Millisecond)
+		})
+	}
+}
+
+func TestReadiness_Check_staleWorker(t *testing.T) {
+	t.Parallel()
+
+	clock := time.Unix(0, 0)
+	h := NewHeartbeat().(*heartbeat)
+	h.now = func() time.Time { return clock }
+	r := NewReadiness(0)
+	r.Register("workers", time.Second, h.Check)
+
+	h.Start("trash purger", time.Minute)
+	clock = clock.Add(30 * time.Second)
+	h.Beat("trash purger")
+	assert.Equal(t, model.CheckStatusOK, r.Check(context.Background()).Status)
+
+	// The worker is stuck in a pass and no longer beats.
+	clock = clock.Add(2 * time.Minute)
+	report := r.Check(context.Background())
+	assert.Equal(t, model.CheckStatusFail, report.Status)
+	assert.Len(t, report.Checks, 1)
+	assert.Equal(t, model.CheckStatusFail, report.Checks[0].Status)
+	assert.Equal(t, `worker "trash purger" missed its heartbeat, last beat 2m0s ago`, report.Checks[0].Error)
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"slices"
+)
+
+var tracer = otel.Tracer("github.com/lhducc/bookmark-management/internal/service")
+
+// endSpan marks span as failed with err, unless err is nil or one of the expected errors, and ends the span.
+func endSpan(span trace.Span, err error, expected ...error) {
+	if err != nil && !slices.ContainsFunc(expected, func(e error) bool { return errors.Is(err, e) }) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// MaxTrashPageSize bounds the trashed links listed at once.
+const MaxTrashPageSize = 100
+
+// ErrTrashedLinkNotFound is returned when a link is not in the trash, or was purged from it.
+var ErrTrashedLinkNotFound = errors.New("trashed link not found")
+
+// Trash deletes the links of the workspaces and of the owners of global links to a trash they can be restored from.
+//
+//go:generate mockery --name Trash --filename trash.go
+type Trash interface {
+	DeleteLink(ctx context.Context, workspace, owner, urlCode string) error
+	List(ctx context.Context, workspace, owner string) ([]model.TrashedLink, error)
+	Restore(ctx context.Context, workspace, owner, urlCode string) (model.TrashedLink, error)
+	Purge(ctx context.Context, workspace, owner, urlCode string) error
+}
+
+type trash struct {
+	links    repository.UrlStorage
+	trash    repository.LinkTrash
+	expiry   repository.LinkExpiryIndex
+	cache    UrlCache
+	webhooks Webhook
+	now      func() time.Time
+}
+
+// NewTrash returns a new instance of the trash, which implements the Trash interface.
+// The cache is optional, when it is nil there is no cached link to invalidate on delete.
+// The webhooks are optional, when it is nil no link.deleted event is published.
+func NewTrash(links repository.UrlStorage, linkTrash repository.LinkTrash, expiry repository.LinkExpiryIndex, cache UrlCache, webhooks Webhook) Trash {
+	return &trash{links: links, trash: linkTrash, expiry: expiry, cache: cache, webhooks: webhooks, now: time.Now}
+}
+
+// DeleteLink moves the link urlCode of workspace to the trash, or the global link urlCode when workspace is empty,
+// which only its owner can delete. The link stops redirecting and its expiry events are canceled, but its code stays
+// taken until the link is purged. It returns ErrCodeNotFound if there is no such link. The link is moved back when it
+// cannot be added to the trash, so it is never left unlisted and unpurged.
+func (s *trash) DeleteLink(ctx context.Context, workspace, owner, urlCode string) (err error) {
+	ctx, span := tracer.Start(ctx, "Trash.DeleteLink", trace.WithAttributes(attribute.String("url.code", urlCode), attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrCodeNotFound) }()
+
+	link, err := s.links.GetLink(ctx, workspace, urlCode)
+	if errors.Is(err, redis.Nil) || (err == nil && workspace == "" && (owner == "" || link.Owner != owner)) {
+		return ErrCodeNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	moved, err := s.links.TrashLink(ctx, workspace, urlCode)
+	if err != nil {
+		return err
+	}
+	if !moved {
+		return ErrCodeNotFound
+	}
+
+	now := s.now()
+	summary := model.LinkSummary{Workspace: workspace, Code: urlCode, URL: link.URL, Tags: link.Tags}
+	if err := s.trash.Add(ctx, workspace, link.Owner, summary, now); err != nil {
+		if _, restoreErr := s.links.RestoreLink(ctx, workspace, urlCode); restoreErr != nil {
+			log.Ctx(ctx).Error().Err(restoreErr).Str("code", urlCode).Msg("Cannot move back the link that could not be trashed")
+		}
+		return err
+	}
+	if err := s.expiry.Remove(ctx, model.LinkExpiry{Workspace: workspace, Owner: link.Owner, Code: urlCode}); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("code", urlCode).Msg("Cannot cancel the expiry events of the deleted link")
+	}
+	if s.cache != nil {
+		if err := s.cache.Invalidate(ctx, urlCacheKey(workspace, urlCode)); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("code", urlCode).Msg("Cannot invalidate the deleted link")
+		}
+	}
+	s.publish(ctx, model.Event{Type: model.EventLinkDeleted, OccurredAt: now, Workspace: workspace, Owner: link.Owner, Code: urlCode, URL: link.URL})
+	return nil
+}
+
+// List returns the links in the trash of workspace, or the global links of owner in the trash when workspace is empty,
+// the latest deleted first, up to MaxTrashPageSize.
+func (s *trash) List(ctx context.Context, workspace, owner string) (_ []model.TrashedLink, err error) {
+	ctx, span := tracer.Start(ctx, "Trash.List", trace.WithAttributes(attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err) }()
+
+	links, err := s.trash.List(ctx, workspace, owner, MaxTrashPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if links == nil {
+		links = []model.TrashedLink{}
+	}
+	return links, nil
+}
+
+// Restore moves the link urlCode of workspace, or the global link urlCode of owner when workspace is empty, back from
+// the trash. It redirects again and gets its expiry events back. It returns ErrTrashedLinkNotFound if the link is not
+// in the trash, or expired there.
+func (s *trash) Restore(ctx context.Context, workspace, owner, urlCode string) (_ model.TrashedLink, err error) {
+	ctx, span := tracer.Start(ctx, "Trash.Restore", trace.WithAttributes(attribute.String("url.code", urlCode), attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrTrashedLinkNotFound) }()
+
+	if _, err := s.get(ctx, workspace, owner, urlCode); err != nil {
+		return model.TrashedLink{}, err
+	}
+
+	restored, err := s.links.RestoreLink(ctx, workspace, urlCode)
+	if err != nil {
+		return model.TrashedLink{}, err
+	}
+	if !restored {
+		if _, err := s.trash.Remove(ctx, workspace, owner, urlCode); err != nil {
+			return model.TrashedLink{}, err
+		}
+		return model.TrashedLink{}, ErrTrashedLinkNotFound
+	}
+
+	link, err := s.trash.Restore(ctx, workspace, owner, urlCode)
+	if err != nil {
+		return model.TrashedLink{}, err
+	}
+	if !link.ExpiresAt.IsZero() {
+		if err := s.expiry.Add(ctx, model.LinkExpiry{Workspace: workspace, Owner: link.Owner, Code: urlCode, ExpiresAt: link.ExpiresAt}); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("code", urlCode).Msg("Cannot reschedule the expiry events of the restored link")
+		}
+	}
+	return link, nil
+}
+
+// Purge deletes the link urlCode of workspace, or the global link urlCode of owner when workspace is empty, from the
+// trash for good, its code can then be handed out again. It returns ErrTrashedLinkNotFound if the link is not in the trash.
+func (s *trash) Purge(ctx context.Context, workspace, owner, urlCode string) (err error) {
+	ctx, span := tracer.Start(ctx, "Trash.Purge", trace.WithAttributes(attribute.String("url.code", urlCode), attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrTrashedLinkNotFound) }()
+
+	if _, err := s.get(ctx, workspace, owner, urlCode); err != nil {
+		return err
+	}
+	if err := s.links.PurgeLink(ctx, workspace, urlCode); err != nil {
+		return err
+	}
+	_, err = s.trash.Remove(ctx, workspace, owner, urlCode)
+	return err
+}
+
+// get returns the trashed link urlCode of workspace or owner, ErrTrashedLinkNotFound if there is none.
+func (s *trash) get(ctx context.Context, workspace, owner, urlCode string) (model.TrashedLink, error) {
+	if workspace == "" && owner == "" {
+		return model.TrashedLink{}, ErrTrashedLinkNotFound
+	}
+	link, err := s.trash.Get(ctx, workspace, owner, urlCode)
+	if errors.Is(err, redis.Nil) {
+		return model.TrashedLink{}, ErrTrashedLinkNotFound
+	}
+	return link, err
+}
+
+// publish queues event for the webhooks, a failure is logged and does not fail the deletion.
+func (s *trash) publish(ctx context.Context, event model.Event) {
+	if s.webhooks == nil {
+		return
+	}
+	if err := s.webhooks.Publish(ctx, event); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("code", event.Code).Str("event", event.Type).Msg("Cannot publish the webhook event")
+	}
+}
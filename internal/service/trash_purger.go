@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/repository"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// trashPurgeBatch bounds the links popped at once by the trash purger.
+const trashPurgeBatch = 100
+
+// TrashPurger purges the links deleted for longer than the retention of the trash.
+//
+//go:generate mockery --name TrashPurger --filename trash_purger.go
+type TrashPurger interface {
+	Run(ctx context.Context, beat func()) error
+}
+
+type trashPurger struct {
+	links     repository.UrlStorage
+	trash     repository.LinkTrash
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewTrashPurger returns a new instance of the trashPurger, which implements the TrashPurger interface.
+// Every interval it purges the links deleted for longer than retention.
+func NewTrashPurger(links repository.UrlStorage, linkTrash repository.LinkTrash, retention, interval time.Duration) TrashPurger {
+	return &trashPurger{links: links, trash: linkTrash, retention: retention, interval: interval, now: time.Now}
+}
+
+// Run purges the links past the retention every interval until ctx is canceled, it only returns then.
+// Every instance runs it, each link is popped by a single instance.
+// beat is called on every tick and after every batch of links, so a stuck purger can be told apart.
+func (p *trashPurger) Run(ctx context.Context, beat func()) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		beat()
+		p.purge(ctx, beat)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge purges the links deleted by the retention ago. Errors are logged, the links that could not be purged are
+// rescheduled and the run stops after their batch, so they are retried on the next tick rather than popped again at once.
+func (p *trashPurger) purge(ctx context.Context, beat func()) {
+	deletedBy := p.now().Add(-p.retention)
+	for ctx.Err() == nil {
+		links, err := p.trash.PopDeleted(ctx, deletedBy, trashPurgeBatch)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Cannot pop the trashed links")
+			return
+		}
+		failed := 0
+		for _, link := range links {
+			if err := p.links.PurgeLink(ctx, link.Workspace, link.Code); err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("code", link.Code).Msg("Cannot purge the trashed link")
+				failed++
+				if err := p.trash.Reschedule(ctx, link); err != nil {
+					log.Ctx(ctx).Error().Err(err).Str("code", link.Code).Msg("Cannot reschedule the purge of the trashed link")
+				}
+				continue
+			}
+			if _, err := p.trash.Remove(ctx, link.Workspace, link.Owner, link.Code); err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("code", link.Code).Msg("Cannot remove the purged link from the trash")
+			}
+		}
+		beat()
+		if failed > 0 || len(links) < trashPurgeBatch {
+			return
+		}
+	}
+}
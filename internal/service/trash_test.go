@@ -0,0 +1,200 @@
+package service
+
+import (
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	serviceMocks "github.com/lhducc/bookmark-management/internal/service/mocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestTrash_DeleteLink(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	launch := model.Link{URL: "https://example.com", Owner: "u1", Tags: []string{"promo"}}
+
+	testCases := []struct {
+		name string
+
+		workspace string
+		owner     string
+		setupMock func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex, webhooks *serviceMocks.Webhook)
+		expectErr error
+	}{
+		{
+			name: "workspace link deleted by a member",
+
+			workspace: "ws1",
+			owner:     "u2",
+			setupMock: func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex, webhooks *serviceMocks.Webhook) {
+				links.On("GetLink", mock.Anything, "ws1", "launch").Return(launch, nil).Once()
+				links.On("TrashLink", mock.Anything, "ws1", "launch").Return(true, nil).Once()
+				linkTrash.On("Add", mock.Anything, "ws1", "u1", model.LinkSummary{Workspace: "ws1", Code: "launch", URL: launch.URL, Tags: launch.Tags}, now).Return(nil).Once()
+				expiry.On("Remove", mock.Anything, model.LinkExpiry{Workspace: "ws1", Owner: "u1", Code: "launch"}).Return(nil).Once()
+				webhooks.On("Publish", mock.Anything, model.Event{
+					Type:       model.EventLinkDeleted,
+					OccurredAt: now,
+					Workspace:  "ws1",
+					Owner:      "u1",
+					Code:       "launch",
+					URL:        launch.URL,
+				}).Return(nil).Once()
+			},
+		},
+		{
+			name: "global link of another owner",
+
+			owner: "u2",
+			setupMock: func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex, webhooks *serviceMocks.Webhook) {
+				links.On("GetLink", mock.Anything, "", "launch").Return(launch, nil).Once()
+			},
+			expectErr: ErrCodeNotFound,
+		},
+		{
+			name: "anonymous global link",
+
+			owner: "u1",
+			setupMock: func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex, webhooks *serviceMocks.Webhook) {
+				links.On("GetLink", mock.Anything, "", "launch").Return(model.Link{URL: launch.URL}, nil).Once()
+			},
+			expectErr: ErrCodeNotFound,
+		},
+		{
+			name: "link not found",
+
+			workspace: "ws1",
+			owner:     "u1",
+			setupMock: func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex, webhooks *serviceMocks.Webhook) {
+				links.On("GetLink", mock.Anything, "ws1", "launch").Return(model.Link{}, redis.Nil).Once()
+			},
+			expectErr: ErrCodeNotFound,
+		},
+		{
+			name: "link deleted concurrently",
+
+			owner: "u1",
+			setupMock: func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex, webhooks *serviceMocks.Webhook) {
+				links.On("GetLink", mock.Anything, "", "launch").Return(launch, nil).Once()
+				links.On("TrashLink", mock.Anything, "", "launch").Return(false, nil).Once()
+			},
+			expectErr: ErrCodeNotFound,
+		},
+		{
+			name: "link moved back when the trash fails",
+
+			owner: "u1",
+			setupMock: func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex, webhooks *serviceMocks.Webhook) {
+				links.On("GetLink", mock.Anything, "", "launch").Return(launch, nil).Once()
+				links.On("TrashLink", mock.Anything, "", "launch").Return(true, nil).Once()
+				linkTrash.On("Add", mock.Anything, "", "u1", model.LinkSummary{Code: "launch", URL: launch.URL, Tags: launch.Tags}, now).Return(testError).Once()
+				links.On("RestoreLink", mock.Anything, "", "launch").Return(true, nil).Once()
+			},
+			expectErr: testError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			links, linkTrash, expiry, webhooks := mocks.NewUrlStorage(t), mocks.NewLinkTrash(t), mocks.NewLinkExpiryIndex(t), serviceMocks.NewWebhook(t)
+			tc.setupMock(t, links, linkTrash, expiry, webhooks)
+			testSvc := &trash{links: links, trash: linkTrash, expiry: expiry, webhooks: webhooks, now: func() time.Time { return now }}
+
+			err := testSvc.DeleteLink(t.Context(), tc.workspace, tc.owner, "launch")
+			assert.ErrorIs(t, err, tc.expectErr)
+		})
+	}
+}
+
+func TestTrash_Restore(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	trashed := model.TrashedLink{
+		LinkSummary: model.LinkSummary{Workspace: "ws1", Code: "launch", URL: "https://example.com", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		Owner:       "u1",
+		DeletedAt:   now.Add(time.Minute),
+	}
+
+	testCases := []struct {
+		name string
+
+		setupMock func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex)
+
+		expectedLink model.TrashedLink
+		expectErr    error
+	}{
+		{
+			name: "normal case",
+
+			setupMock: func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex) {
+				linkTrash.On("Get", mock.Anything, "ws1", "u2", "launch").Return(trashed, nil).Once()
+				links.On("RestoreLink", mock.Anything, "ws1", "launch").Return(true, nil).Once()
+				linkTrash.On("Restore", mock.Anything, "ws1", "u2", "launch").Return(trashed, nil).Once()
+				expiry.On("Add", mock.Anything, model.LinkExpiry{Workspace: "ws1", Owner: "u1", Code: "launch", ExpiresAt: trashed.ExpiresAt}).Return(nil).Once()
+			},
+
+			expectedLink: trashed,
+		},
+		{
+			name: "not in the trash",
+
+			setupMock: func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex) {
+				linkTrash.On("Get", mock.Anything, "ws1", "u2", "launch").Return(model.TrashedLink{}, redis.Nil).Once()
+			},
+
+			expectErr: ErrTrashedLinkNotFound,
+		},
+		{
+			name: "expired in the trash",
+
+			setupMock: func(t *testing.T, links *mocks.UrlStorage, linkTrash *mocks.LinkTrash, expiry *mocks.LinkExpiryIndex) {
+				linkTrash.On("Get", mock.Anything, "ws1", "u2", "launch").Return(trashed, nil).Once()
+				links.On("RestoreLink", mock.Anything, "ws1", "launch").Return(false, nil).Once()
+				linkTrash.On("Remove", mock.Anything, "ws1", "u2", "launch").Return(true, nil).Once()
+			},
+
+			expectErr: ErrTrashedLinkNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			links, linkTrash, expiry := mocks.NewUrlStorage(t), mocks.NewLinkTrash(t), mocks.NewLinkExpiryIndex(t)
+			tc.setupMock(t, links, linkTrash, expiry)
+			testSvc := NewTrash(links, linkTrash, expiry, nil, nil)
+
+			link, err := testSvc.Restore(t.Context(), "ws1", "u2", "launch")
+			assert.ErrorIs(t, err, tc.expectErr)
+			assert.Equal(t, tc.expectedLink, link)
+		})
+	}
+}
+
+func TestTrashPurger_Purge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	retention := 24 * time.Hour
+	global := model.TrashedLink{LinkSummary: model.LinkSummary{Code: "old"}, Owner: "u1", DeletedAt: now.Add(-2 * retention)}
+	failed := model.TrashedLink{LinkSummary: model.LinkSummary{Workspace: "ws1", Code: "stuck"}, DeletedAt: now.Add(-2 * retention)}
+
+	linkTrash := mocks.NewLinkTrash(t)
+	linkTrash.On("PopDeleted", mock.Anything, now.Add(-retention), trashPurgeBatch).Return([]model.TrashedLink{global, failed}, nil).Once()
+	linkTrash.On("Remove", mock.Anything, "", "u1", "old").Return(true, nil).Once()
+	linkTrash.On("Reschedule", mock.Anything, failed).Return(nil).Once()
+
+	links := mocks.NewUrlStorage(t)
+	links.On("PurgeLink", mock.Anything, "", "old").Return(nil).Once()
+	links.On("PurgeLink", mock.Anything, "ws1", "stuck").Return(redis.ErrClosed).Once()
+
+	testSvc := &trashPurger{links: links, trash: linkTrash, retention: retention, now: func() time.Time { return now }}
+	testSvc.purge(t.Context(), func() {})
+}
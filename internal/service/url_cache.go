@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository"
+	"github.com/lhducc/bookmark-management/pkg/lrucache"
+	"time"
+)
+
+const (
+	// urlCacheRetryBase and urlCacheRetryMax bound the exponential backoff of Watch between two subscriptions.
+	urlCacheRetryBase = time.Second
+	urlCacheRetryMax  = 30 * time.Second
+)
+
+// UrlCache is an in-process cache of resolved codes and their links.
+// Entries are evicted locally on Invalidate and on every instance through Watch.
+//
+//go:generate mockery --name UrlCache --filename url_cache.go
+type UrlCache interface {
+	Get(code string) (model.Link, bool)
+	Set(code string, link model.Link)
+	Invalidate(ctx context.Context, code string) error
+	Watch(ctx context.Context, beat func(), fail func(err error)) error
+}
+
+type urlCache struct {
+	local        *lrucache.Cache[string, model.Link]
+	invalidation repository.UrlInvalidation
+	retryBase    time.Duration
+	retryMax     time.Duration
+}
+
+// NewUrlCache returns a new instance of the urlCache, which implements the UrlCache interface.
+// The cache holds at most size links, each for at most ttl and never past the expiry of the link, so a missed invalidation
+// can only serve a stale link for ttl.
+func NewUrlCache(size int, ttl time.Duration, invalidation repository.UrlInvalidation) UrlCache {
+	return &urlCache{
+		local:        lrucache.New[string, model.Link](size, ttl),
+		invalidation: invalidation,
+		retryBase:    urlCacheRetryBase,
+		retryMax:     urlCacheRetryMax,
+	}
+}
+
+// Get returns the cached link for code, and false if it is not cached.
+func (c *urlCache) Get(code string) (model.Link, bool) {
+	return c.local.Get(code)
+}
+
+// Set caches link under code, until the link expires if that is sooner than the TTL of the cache.
+// A link that has already expired is not cached.
+func (c *urlCache) Set(code string, link model.Link) {
+	if link.ExpiresAt.IsZero() {
+		c.local.Set(code, link)
+		return
+	}
+	ttl := time.Until(link.ExpiresAt)
+	if ttl <= 0 {
+		c.local.Delete(code)
+		return
+	}
+	c.local.SetWithTTL(code, link, ttl)
+}
+
+// Invalidate evicts code from the local cache and asks every other instance to do the same.
+func (c *urlCache) Invalidate(ctx context.Context, code string) error {
+	c.local.Delete(code)
+	return c.invalidation.Publish(ctx, code)
+}
+
+// Watch evicts every code published by Invalidate on any instance until ctx is done, it only returns ctx.Err() then.
+// The local cache is purged every time the subscription is established, since invalidations may have been missed before it.
+// A subscription that cannot be established or is lost is retried with an exponential backoff, fail is called with the
+// error meanwhile and beat once subscribed again.
+func (c *urlCache) Watch(ctx context.Context, beat func(), fail func(err error)) error {
+	delay := c.retryBase
+	for {
+		codes, err := c.invalidation.Subscribe(ctx)
+		if err == nil {
+			c.local.Purge()
+			beat()
+			delay = c.retryBase
+			for code := range codes {
+				c.local.Delete(code)
+			}
+			err = errors.New("invalidation subscription lost")
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fail(err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, c.retryMax)
+	}
+}
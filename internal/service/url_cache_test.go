@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestUrlCache_Invalidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func(t *testing.T) *mocks.UrlInvalidation
+
+		expectErr error
+	}{
+		{
+			name: "normal case",
+
+			setupMock: func(t *testing.T) *mocks.UrlInvalidation {
+				repo := mocks.NewUrlInvalidation(t)
+				repo.On("Publish", mock.Anything, "abc1234").Return(nil).Once()
+				return repo
+			},
+		},
+		{
+			name: "publish error is returned after local eviction",
+
+			setupMock: func(t *testing.T) *mocks.UrlInvalidation {
+				repo := mocks.NewUrlInvalidation(t)
+				repo.On("Publish", mock.Anything, "abc1234").Return(testError).Once()
+				return repo
+			},
+
+			expectErr: testError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cache := NewUrlCache(10, time.Minute, tc.setupMock(t))
+			cache.Set("abc1234", model.Link{URL: "https://google.com"})
+
+			err := cache.Invalidate(context.Background(), "abc1234")
+			assert.Equal(t, tc.expectErr, err)
+
+			_, ok := cache.Get("abc1234")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestUrlCache_Set(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		expiresIn time.Duration
+		wait      time.Duration
+
+		expectCached bool
+	}{
+		{
+			name: "link without expiry is cached for the cache ttl",
+
+			wait: 20 * time.Millisecond,
+
+			expectCached: true,
+		},
+		{
+			name: "link expiring after the cache ttl",
+
+			expiresIn: time.Hour,
+			wait:      20 * time.Millisecond,
+
+			expectCached: true,
+		},
+		{
+			name: "link is evicted when it expires",
+
+			expiresIn: 10 * time.Millisecond,
+			wait:      20 * time.Millisecond,
+
+			expectCached: false,
+		},
+		{
+			name: "expired link is not cached",
+
+			expiresIn: -time.Second,
+
+			expectCached: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			link := model.Link{URL: "https://google.com"}
+			if tc.expiresIn != 0 {
+				link.ExpiresAt = time.Now().Add(tc.expiresIn)
+			}
+			cache := NewUrlCache(10, time.Minute, mocks.NewUrlInvalidation(t))
+			cache.Set("abc1234", link)
+			time.Sleep(tc.wait)
+
+			cached, ok := cache.Get("abc1234")
+			assert.Equal(t, tc.expectCached, ok)
+			if tc.expectCached {
+				assert.Equal(t, link, cached)
+			}
+		})
+	}
+}
+
+func TestUrlCache_Watch(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func(t *testing.T, codes chan string) *mocks.UrlInvalidation
+
+		expectedFails []string
+		expectedBeats int
+	}{
+		{
+			name: "evicts published codes",
+
+			setupMock: func(t *testing.T, codes chan string) *mocks.UrlInvalidation {
+				repo := mocks.NewUrlInvalidation(t)
+				repo.On("Subscribe", mock.Anything).Return((<-chan string)(codes), nil).Once()
+				return repo
+			},
+
+			expectedBeats: 1,
+		},
+		{
+			name: "subscribe error is retried",
+
+			setupMock: func(t *testing.T, codes chan string) *mocks.UrlInvalidation {
+				repo := mocks.NewUrlInvalidation(t)
+				repo.On("Subscribe", mock.Anything).Return(nil, testError).Twice()
+				repo.On("Subscribe", mock.Anything).Return((<-chan string)(codes), nil).Once()
+				return repo
+			},
+
+			expectedFails: []string{testError.Error(), testError.Error()},
+			expectedBeats: 1,
+		},
+		{
+			name: "lost subscription is retried",
+
+			setupMock: func(t *testing.T, codes chan string) *mocks.UrlInvalidation {
+				lost := make(chan string)
+				close(lost)
+				repo := mocks.NewUrlInvalidation(t)
+				repo.On("Subscribe", mock.Anything).Return((<-chan string)(lost), nil).Once()
+				repo.On("Subscribe", mock.Anything).Return((<-chan string)(codes), nil).Once()
+				return repo
+			},
+
+			expectedFails: []string{"invalidation subscription lost"},
+			expectedBeats: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			codes := make(chan string)
+			cache := NewUrlCache(10, time.Minute, tc.setupMock(t, codes))
+			cache.(*urlCache).retryBase = time.Millisecond
+
+			var fails []string
+			beats := make(chan struct{}, 10)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- cache.Watch(ctx, func() { beats <- struct{}{} }, func(err error) { fails = append(fails, err.Error()) })
+			}()
+
+			codes <- "warmup"
+			cache.Set("abc1234", model.Link{URL: "https://google.com"})
+			cache.Set("keep123", model.Link{URL: "https://example.com"})
+			codes <- "abc1234"
+			cancel()
+			close(codes)
+
+			assert.Equal(t, context.Canceled, <-done)
+			assert.Equal(t, tc.expectedFails, fails)
+			assert.Len(t, beats, tc.expectedBeats)
+			_, ok := cache.Get("abc1234")
+			assert.False(t, ok)
+			link, ok := cache.Get("keep123")
+			assert.True(t, ok)
+			assert.Equal(t, model.Link{URL: "https://example.com"}, link)
+		})
+	}
+}
@@ -3,64 +3,248 @@ package service
 import (
 	"context"
 	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
 	"github.com/lhducc/bookmark-management/internal/repository"
 	"github.com/lhducc/bookmark-management/pkg/stringutils"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
 )
 
 const (
-	urlCodeLength = 7
+	// UrlCodeLength is the minimum length of generated URL codes.
+	UrlCodeLength = 7
 	maxRetry      = 5
 )
 
-var errShortenURLFailed = errors.New("failed to shorten URL")
+var (
+	// ErrShortenURLFailed is returned when every generated code collided with an existing one.
+	ErrShortenURLFailed = errors.New("failed to shorten URL")
+	// ErrInvalidAlias is returned when an alias is not 3 to 64 letters, digits, '-' or '_'.
+	ErrInvalidAlias = errors.New("invalid alias")
+	// ErrAliasTaken is returned when an alias is already in use in its workspace.
+	ErrAliasTaken = errors.New("alias already taken")
+	// ErrAliasReserved is returned when an alias would be shadowed by a route of the service.
+	ErrAliasReserved = errors.New("alias is reserved")
+	// ErrInvalidRedirectStatus is returned when the redirect status of a link is not 301, 302, 307 or 308.
+	ErrInvalidRedirectStatus = errors.New("invalid redirect status")
+	// ErrInvalidLinkParams is returned when a link has more than 20 params, a param without a name or over 2 KiB of params.
+	ErrInvalidLinkParams = errors.New("invalid link params")
+	// ErrInvalidLinkRules is returned when a link has more than 20 rules or a rule with an invalid URL or condition, or no condition.
+	ErrInvalidLinkRules = errors.New("invalid link rules")
+	// ErrInvalidLinkVariants is returned when a link has a single variant or more than 10, or a variant with an invalid name,
+	// URL or weight, or two variants of the same name.
+	ErrInvalidLinkVariants = errors.New("invalid link variants")
+	// ErrInvalidLinkTags is returned when a link has more than 10 tags, a tag that is not 1 to 32 lowercase letters, digits,
+	// '-' or '_', or the same tag twice.
+	ErrInvalidLinkTags = errors.New("invalid link tags")
+)
+
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,64}$`)
 
 //go:generate mockery --name ShortenUrl --filename urlstorage.go
 type ShortenUrl interface {
-	ShortenUrl(ctx context.Context, url string, exp int) (string, error)
-	GetUrl(cxt context.Context, urlCode string) (string, error)
+	ShortenUrl(ctx context.Context, req model.ShortenRequest) (string, error)
+	GetLink(ctx context.Context, workspace, urlCode string) (model.Link, error)
+	RecordClick(ctx context.Context, workspace, owner, urlCode, variant string) error
+	GetClicks(ctx context.Context, workspace, user, urlCode string) (model.ClickStats, error)
 }
 
 type shortenUrl struct {
-	repo   repository.UrlStorage
-	keyGen stringutils.KeyGen
+	repo     repository.UrlStorage
+	keyGen   stringutils.KeyGen
+	cache    UrlCache
+	monitor  KeyspaceMonitor
+	reserved map[string]struct{}
 }
 
-func NewShortenUrl(repo repository.UrlStorage, keyGen stringutils.KeyGen) ShortenUrl {
-	return &shortenUrl{repo: repo, keyGen: keyGen}
+// NewShortenUrl returns a new instance of the shortenUrl, which implements the ShortenUrl interface.
+// The cache is optional, when it is nil every GetUrl call goes to the repository. Stored codes are invalidated in it.
+// The monitor is optional, when it is nil collisions are not tracked and codes are always UrlCodeLength characters long.
+// reserved lists the codes that are never handed out, since routes of the service served at the root would shadow them.
+func NewShortenUrl(repo repository.UrlStorage, keyGen stringutils.KeyGen, cache UrlCache, monitor KeyspaceMonitor, reserved []string) ShortenUrl {
+	s := &shortenUrl{repo: repo, keyGen: keyGen, cache: cache, monitor: monitor, reserved: make(map[string]struct{}, len(reserved))}
+	for _, code := range reserved {
+		s.reserved[code] = struct{}{}
+	}
+	return s
 }
 
 // ShortenUrl shortens a given URL and returns a shortened URL code.
-// The method generates a random URL code of length urlCodeLength, stores the given URL with the generated URL code in the repository, and returns the generated URL code.
+// The method generates a URL code of at least UrlCodeLength characters, or of the length picked by the keyspace monitor, stores the given URL with the generated URL code in the repository, and returns the generated URL code.
 // If an error occurs while generating the URL code, it returns an empty string and the error immediately.
 // If an error occurs while storing the URL in the repository, it returns an empty string and the error immediately.
-// The returned URL code is a string of length urlCodeLength, and does not contain any whitespace or special characters.
+// The returned URL code is a string of at least UrlCodeLength characters, and does not contain any whitespace or special characters.
 // The URL code is case-sensitive and can be used to retrieve the original URL from the repository.
-func (s *shortenUrl) ShortenUrl(ctx context.Context, url string, exp int) (string, error) {
+// Codes are unique within the workspace of req only. When req has an alias it is stored as is instead of a generated code,
+// and ErrInvalidAlias, ErrAliasReserved or ErrAliasTaken is returned if it is malformed, reserved or already in use in the workspace.
+// A generated code that happens to be reserved is discarded like a collision.
+// The options of the link are stored along with its URL, ErrInvalidRedirectStatus is returned for an unsupported redirect status,
+// ErrInvalidLinkParams for params that are too many or too large, ErrInvalidLinkRules for invalid rules,
+// ErrInvalidLinkVariants for invalid variants and ErrInvalidLinkTags for invalid tags.
+func (s *shortenUrl) ShortenUrl(ctx context.Context, req model.ShortenRequest) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "ShortenUrl.ShortenUrl", trace.WithAttributes(attribute.String("workspace.id", req.Workspace)))
+	defer func() {
+		endSpan(span, err, ErrInvalidAlias, ErrAliasReserved, ErrAliasTaken, ErrInvalidRedirectStatus, ErrInvalidLinkParams, ErrInvalidLinkRules, ErrInvalidLinkVariants, ErrInvalidLinkTags)
+	}()
+
+	if !model.ValidRedirectStatus(req.RedirectStatus) {
+		return "", ErrInvalidRedirectStatus
+	}
+	if !validLinkParams(req.Params) {
+		return "", ErrInvalidLinkParams
+	}
+	if !validLinkRules(req.Rules) {
+		return "", ErrInvalidLinkRules
+	}
+	if !validLinkVariants(req.Variants) {
+		return "", ErrInvalidLinkVariants
+	}
+	if !validLinkTags(req.Tags) {
+		return "", ErrInvalidLinkTags
+	}
+
+	if req.Alias != "" {
+		return s.storeAlias(ctx, req)
+	}
+
+	length := UrlCodeLength
+	if s.monitor != nil {
+		if err := s.monitor.Refresh(ctx); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Cannot refresh the code length, keeping the current one")
+		}
+		length = s.monitor.CodeLength()
+	}
+
 	for i := 0; i < maxRetry; i++ {
-		urlCode, err := s.keyGen.GenerateCode(urlCodeLength)
+		urlCode, err := s.keyGen.GenerateCode(ctx, length)
 		if err != nil {
 			return "", err
 		}
+		if s.isReserved(urlCode) {
+			continue
+		}
 
-		ok, err := s.repo.StoreURLIfNotExists(ctx, urlCode, url, exp)
+		ok, err := s.repo.StoreLinkIfNotExists(ctx, req.Workspace, urlCode, req.Link, req.Exp)
 		if err != nil {
 			return "", err
 		}
 
+		if s.monitor != nil {
+			s.monitor.Record(ctx, len(urlCode), !ok)
+		}
 		if ok {
+			span.SetAttributes(attribute.String("url.code", urlCode), attribute.Int("url.attempts", i+1))
+			s.invalidate(ctx, req.Workspace, urlCode)
 			return urlCode, nil
 		}
 	}
-	return "", errShortenURLFailed
+	return "", ErrShortenURLFailed
+}
+
+// storeAlias stores the link of req under its alias.
+func (s *shortenUrl) storeAlias(ctx context.Context, req model.ShortenRequest) (string, error) {
+	if !aliasPattern.MatchString(req.Alias) {
+		return "", ErrInvalidAlias
+	}
+	if s.isReserved(req.Alias) {
+		return "", ErrAliasReserved
+	}
+
+	ok, err := s.repo.StoreLinkIfNotExists(ctx, req.Workspace, req.Alias, req.Link, req.Exp)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrAliasTaken
+	}
+	s.invalidate(ctx, req.Workspace, req.Alias)
+	return req.Alias, nil
+}
+
+// invalidate evicts the newly stored urlCode of workspace from the cache of every instance, so an instance that missed the
+// invalidation of a link previously stored under the code does not keep serving it. A failure is only logged.
+func (s *shortenUrl) invalidate(ctx context.Context, workspace, urlCode string) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Invalidate(ctx, urlCacheKey(workspace, urlCode)); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("code", urlCode).Msg("Cannot invalidate the stored link")
+	}
 }
 
 var ErrCodeNotFound = errors.New("code not found")
 
-func (s *shortenUrl) GetUrl(ctx context.Context, urlCode string) (string, error) {
-	url, err := s.repo.GetURL(ctx, urlCode)
+// GetLink returns the link stored under urlCode in workspace, an empty workspace is the global namespace.
+// When a cache is configured, hot codes are served from it and only misses reach the repository.
+// It returns ErrCodeNotFound if no link is stored under urlCode.
+func (s *shortenUrl) GetLink(ctx context.Context, workspace, urlCode string) (_ model.Link, err error) {
+	ctx, span := tracer.Start(ctx, "ShortenUrl.GetLink", trace.WithAttributes(attribute.String("url.code", urlCode), attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrCodeNotFound) }()
+
+	cacheKey := urlCacheKey(workspace, urlCode)
+	if s.cache != nil {
+		if link, ok := s.cache.Get(cacheKey); ok {
+			span.SetAttributes(attribute.Bool("url.cache_hit", true))
+			return link, nil
+		}
+	}
+
+	link, err := s.repo.GetLink(ctx, workspace, urlCode)
 	if errors.Is(err, redis.Nil) {
-		return "", ErrCodeNotFound
+		return model.Link{}, ErrCodeNotFound
+	}
+	if err != nil {
+		return model.Link{}, err
+	}
+
+	if s.cache != nil {
+		s.cache.Set(cacheKey, link)
+	}
+	return link, nil
+}
+
+// RecordClick counts a redirect of urlCode in workspace in its click analytics, owner is the owner of the link, empty for
+// anonymous links, and variant is the name of the variant served, empty for none.
+func (s *shortenUrl) RecordClick(ctx context.Context, workspace, owner, urlCode, variant string) (err error) {
+	ctx, span := tracer.Start(ctx, "ShortenUrl.RecordClick", trace.WithAttributes(attribute.String("url.code", urlCode), attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err) }()
+
+	return s.repo.IncrClicks(ctx, workspace, owner, urlCode, variant)
+}
+
+// GetClicks returns the click analytics of urlCode in workspace, ErrCodeNotFound if no link is stored under urlCode.
+// The analytics of a global link created by a user are only returned to that user, other callers, user being empty for
+// anonymous ones, get ErrCodeNotFound as well, so they cannot tell the link exists.
+func (s *shortenUrl) GetClicks(ctx context.Context, workspace, user, urlCode string) (_ model.ClickStats, err error) {
+	ctx, span := tracer.Start(ctx, "ShortenUrl.GetClicks", trace.WithAttributes(attribute.String("url.code", urlCode), attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrCodeNotFound) }()
+
+	link, err := s.repo.GetLink(ctx, workspace, urlCode)
+	if errors.Is(err, redis.Nil) {
+		return model.ClickStats{}, ErrCodeNotFound
+	}
+	if err != nil {
+		return model.ClickStats{}, err
+	}
+	if workspace == "" && link.Owner != "" && link.Owner != user {
+		return model.ClickStats{}, ErrCodeNotFound
+	}
+	return s.repo.GetClicks(ctx, workspace, urlCode)
+}
+
+// isReserved reports whether code is one of the reserved codes.
+func (s *shortenUrl) isReserved(code string) bool {
+	_, ok := s.reserved[code]
+	return ok
+}
+
+// urlCacheKey returns the key of urlCode in workspace in the UrlCache, global codes are cached under the code alone.
+func urlCacheKey(workspace, urlCode string) string {
+	if workspace == "" {
+		return urlCode
 	}
-	return url, err
+	return workspace + "/" + urlCode
 }
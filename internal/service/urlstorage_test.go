@@ -3,8 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
 	"github.com/lhducc/bookmark-management/internal/repository"
 	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	serviceMocks "github.com/lhducc/bookmark-management/internal/service/mocks"
 	mockKeyGen "github.com/lhducc/bookmark-management/pkg/stringutils/mocks"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -21,11 +23,18 @@ func TestShortenUrl_ShortenUrl(t *testing.T) {
 	testCases := []struct {
 		name string
 
-		url string
-		exp int
+		workspace      string
+		url            string
+		alias          string
+		redirectStatus int
+		params         map[string]string
+		rules          []model.Rule
+		exp            int
 
 		setupMockRepo   func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage
 		setupMockKeyGen func() *mockKeyGen.KeyGen
+		setupMonitor    func(t *testing.T) KeyspaceMonitor
+		setupCache      func(t *testing.T) UrlCache
 
 		expectedCode string
 		expectErr    error
@@ -40,17 +49,18 @@ func TestShortenUrl_ShortenUrl(t *testing.T) {
 			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
 				repoMock := mocks.NewUrlStorage(t)
 				repoMock.On(
-					"StoreURLIfNotExists",
-					ctx,
+					"StoreLinkIfNotExists",
+					mock.Anything,
+					"",
 					mock.AnythingOfType("string"),
-					url,
+					model.Link{URL: url},
 					exp,
 				).Return(true, nil)
 				return repoMock
 			},
 			setupMockKeyGen: func() *mockKeyGen.KeyGen {
 				keyGenMock := mockKeyGen.NewKeyGen(t)
-				keyGenMock.On("GenerateCode", urlCodeLength).Return("abc1237", nil)
+				keyGenMock.On("GenerateCode", mock.Anything, UrlCodeLength).Return("abc1237", nil)
 				return keyGenMock
 			},
 
@@ -71,13 +81,266 @@ func TestShortenUrl_ShortenUrl(t *testing.T) {
 			},
 			setupMockKeyGen: func() *mockKeyGen.KeyGen {
 				keyGenMock := mockKeyGen.NewKeyGen(t)
-				keyGenMock.On("GenerateCode", urlCodeLength).Return("", testError)
+				keyGenMock.On("GenerateCode", mock.Anything, UrlCodeLength).Return("", testError)
 				return keyGenMock
 			},
 
 			expectedCode: "",
 			expectErr:    testError,
 		},
+		{
+			name: "collision is recorded and length comes from monitor, even when it cannot refresh",
+
+			url: "https://www.google.com",
+			exp: 10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				repoMock := mocks.NewUrlStorage(t)
+				repoMock.On("StoreLinkIfNotExists", mock.Anything, "", "abc12345", model.Link{URL: url}, exp).Return(false, nil).Once()
+				repoMock.On("StoreLinkIfNotExists", mock.Anything, "", "xyz98765", model.Link{URL: url}, exp).Return(true, nil).Once()
+				return repoMock
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				keyGenMock := mockKeyGen.NewKeyGen(t)
+				keyGenMock.On("GenerateCode", mock.Anything, 8).Return("abc12345", nil).Once()
+				keyGenMock.On("GenerateCode", mock.Anything, 8).Return("xyz98765", nil).Once()
+				return keyGenMock
+			},
+			setupMonitor: func(t *testing.T) KeyspaceMonitor {
+				monitor := serviceMocks.NewKeyspaceMonitor(t)
+				monitor.On("Refresh", mock.Anything).Return(testError).Once()
+				monitor.On("CodeLength").Return(8).Once()
+				monitor.On("Record", mock.Anything, 8, true).Once()
+				monitor.On("Record", mock.Anything, 8, false).Once()
+				return monitor
+			},
+
+			expectedCode: "xyz98765",
+			expectedLen:  8,
+			expectErr:    nil,
+		},
+		{
+			name: "too many collisions",
+
+			url: "https://www.google.com",
+			exp: 10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				repoMock := mocks.NewUrlStorage(t)
+				repoMock.On("StoreLinkIfNotExists", mock.Anything, "", "abc1237", model.Link{URL: url}, exp).Return(false, nil).Times(maxRetry)
+				return repoMock
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				keyGenMock := mockKeyGen.NewKeyGen(t)
+				keyGenMock.On("GenerateCode", mock.Anything, UrlCodeLength).Return("abc1237", nil).Times(maxRetry)
+				return keyGenMock
+			},
+
+			expectedCode: "",
+			expectErr:    ErrShortenURLFailed,
+		},
+		{
+			name: "alias in workspace",
+
+			workspace:      "team-a",
+			url:            "https://www.google.com",
+			alias:          "launch2025",
+			redirectStatus: 308,
+			exp:            10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				repoMock := mocks.NewUrlStorage(t)
+				repoMock.On("StoreLinkIfNotExists", mock.Anything, "team-a", "launch2025", model.Link{URL: url, RedirectStatus: 308}, exp).
+					Return(true, nil).Once()
+				return repoMock
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				return mockKeyGen.NewKeyGen(t)
+			},
+
+			expectedCode: "launch2025",
+			expectedLen:  10,
+			expectErr:    nil,
+		},
+		{
+			name: "alias taken",
+
+			workspace: "team-a",
+			url:       "https://www.google.com",
+			alias:     "launch",
+			exp:       10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				repoMock := mocks.NewUrlStorage(t)
+				repoMock.On("StoreLinkIfNotExists", mock.Anything, "team-a", "launch", model.Link{URL: url}, exp).Return(false, nil).Once()
+				return repoMock
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				return mockKeyGen.NewKeyGen(t)
+			},
+
+			expectedCode: "",
+			expectErr:    ErrAliasTaken,
+		},
+		{
+			name: "stored code is invalidated in the cache",
+
+			url: "https://www.google.com",
+			exp: 10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				repoMock := mocks.NewUrlStorage(t)
+				repoMock.On("StoreLinkIfNotExists", mock.Anything, "", "abc1237", model.Link{URL: url}, exp).Return(true, nil).Once()
+				return repoMock
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				keyGenMock := mockKeyGen.NewKeyGen(t)
+				keyGenMock.On("GenerateCode", mock.Anything, UrlCodeLength).Return("abc1237", nil).Once()
+				return keyGenMock
+			},
+			setupCache: func(t *testing.T) UrlCache {
+				cache := serviceMocks.NewUrlCache(t)
+				cache.On("Invalidate", mock.Anything, "abc1237").Return(nil).Once()
+				return cache
+			},
+
+			expectedCode: "abc1237",
+			expectedLen:  7,
+			expectErr:    nil,
+		},
+		{
+			name: "stored alias is invalidated under its workspace, a failure is ignored",
+
+			workspace: "team-a",
+			url:       "https://www.google.com",
+			alias:     "launch",
+			exp:       10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				repoMock := mocks.NewUrlStorage(t)
+				repoMock.On("StoreLinkIfNotExists", mock.Anything, "team-a", "launch", model.Link{URL: url}, exp).Return(true, nil).Once()
+				return repoMock
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				return mockKeyGen.NewKeyGen(t)
+			},
+			setupCache: func(t *testing.T) UrlCache {
+				cache := serviceMocks.NewUrlCache(t)
+				cache.On("Invalidate", mock.Anything, "team-a/launch").Return(testError).Once()
+				return cache
+			},
+
+			expectedCode: "launch",
+			expectedLen:  6,
+			expectErr:    nil,
+		},
+		{
+			name: "reserved alias",
+
+			url:   "https://www.google.com",
+			alias: "metrics",
+			exp:   10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				return mocks.NewUrlStorage(t)
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				return mockKeyGen.NewKeyGen(t)
+			},
+
+			expectedCode: "",
+			expectErr:    ErrAliasReserved,
+		},
+		{
+			name: "reserved generated code is skipped",
+
+			url: "https://www.google.com",
+			exp: 10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				repoMock := mocks.NewUrlStorage(t)
+				repoMock.On("StoreLinkIfNotExists", mock.Anything, "", "abc1237", model.Link{URL: url}, exp).Return(true, nil).Once()
+				return repoMock
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				keyGenMock := mockKeyGen.NewKeyGen(t)
+				keyGenMock.On("GenerateCode", mock.Anything, UrlCodeLength).Return("swagger", nil).Once()
+				keyGenMock.On("GenerateCode", mock.Anything, UrlCodeLength).Return("abc1237", nil).Once()
+				return keyGenMock
+			},
+
+			expectedCode: "abc1237",
+			expectedLen:  7,
+			expectErr:    nil,
+		},
+		{
+			name: "invalid redirect status",
+
+			url:            "https://www.google.com",
+			redirectStatus: 303,
+			exp:            10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				return mocks.NewUrlStorage(t)
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				return mockKeyGen.NewKeyGen(t)
+			},
+
+			expectedCode: "",
+			expectErr:    ErrInvalidRedirectStatus,
+		},
+		{
+			name: "param without a name",
+
+			url:    "https://www.google.com",
+			params: map[string]string{"": "news"},
+			exp:    10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				return mocks.NewUrlStorage(t)
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				return mockKeyGen.NewKeyGen(t)
+			},
+
+			expectedCode: "",
+			expectErr:    ErrInvalidLinkParams,
+		},
+		{
+			name: "rule without a condition",
+
+			url:   "https://www.google.com",
+			rules: []model.Rule{{URL: "https://example.com"}},
+			exp:   10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				return mocks.NewUrlStorage(t)
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				return mockKeyGen.NewKeyGen(t)
+			},
+
+			expectedCode: "",
+			expectErr:    ErrInvalidLinkRules,
+		},
+		{
+			name: "invalid alias",
+
+			url:   "https://www.google.com",
+			alias: "a/b",
+			exp:   10,
+
+			setupMockRepo: func(t *testing.T, ctx context.Context, url string, exp int) repository.UrlStorage {
+				return mocks.NewUrlStorage(t)
+			},
+			setupMockKeyGen: func() *mockKeyGen.KeyGen {
+				return mockKeyGen.NewKeyGen(t)
+			},
+
+			expectedCode: "",
+			expectErr:    ErrInvalidAlias,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -88,9 +351,22 @@ func TestShortenUrl_ShortenUrl(t *testing.T) {
 
 			urlStorageMock := tc.setupMockRepo(t, cxt, tc.url, tc.exp)
 			mockKeyGen := tc.setupMockKeyGen()
-			testSvc := NewShortenUrl(urlStorageMock, mockKeyGen)
+			var monitor KeyspaceMonitor
+			if tc.setupMonitor != nil {
+				monitor = tc.setupMonitor(t)
+			}
+			var cache UrlCache
+			if tc.setupCache != nil {
+				cache = tc.setupCache(t)
+			}
+			testSvc := NewShortenUrl(urlStorageMock, mockKeyGen, cache, monitor, []string{"metrics", "swagger"})
 
-			urlCode, err := testSvc.ShortenUrl(cxt, tc.url, tc.exp)
+			urlCode, err := testSvc.ShortenUrl(cxt, model.ShortenRequest{
+				Workspace: tc.workspace,
+				Alias:     tc.alias,
+				Exp:       tc.exp,
+				Link:      model.Link{URL: tc.url, RedirectStatus: tc.redirectStatus, Params: tc.params, Rules: tc.rules},
+			})
 
 			assert.Equal(t, tc.expectedLen, len(urlCode))
 			assert.Equal(t, tc.expectErr, err)
@@ -103,17 +379,19 @@ func TestShortenUrl_ShortenUrl(t *testing.T) {
 	}
 }
 
-func TestShortenUrl_GetUrl(t *testing.T) {
+func TestShortenUrl_GetLink(t *testing.T) {
 	t.Parallel()
 
 	testCases := []struct {
 		name string
 
-		code string
+		workspace string
+		code      string
 
-		setupMock func(t *testing.T) *mocks.UrlStorage
+		setupMock  func(t *testing.T) *mocks.UrlStorage
+		setupCache func(t *testing.T) UrlCache
 
-		expURL    string
+		expLink   model.Link
 		expectErr error
 	}{
 		{
@@ -124,13 +402,13 @@ func TestShortenUrl_GetUrl(t *testing.T) {
 			setupMock: func(t *testing.T) *mocks.UrlStorage {
 				repo := mocks.NewUrlStorage(t)
 				repo.
-					On("GetURL", mock.Anything, "abc1234").
-					Return("https://google.com", nil).
+					On("GetLink", mock.Anything, "", "abc1234").
+					Return(model.Link{URL: "https://google.com"}, nil).
 					Once()
 				return repo
 			},
 
-			expURL:    "https://google.com",
+			expLink:   model.Link{URL: "https://google.com"},
 			expectErr: nil,
 		},
 		{
@@ -141,13 +419,12 @@ func TestShortenUrl_GetUrl(t *testing.T) {
 			setupMock: func(t *testing.T) *mocks.UrlStorage {
 				repo := mocks.NewUrlStorage(t)
 				repo.
-					On("GetURL", mock.Anything, "notfound").
-					Return("", redis.Nil).
+					On("GetLink", mock.Anything, "", "notfound").
+					Return(model.Link{}, redis.Nil).
 					Once()
 				return repo
 			},
 
-			expURL:    "",
 			expectErr: ErrCodeNotFound,
 		},
 		{
@@ -158,15 +435,99 @@ func TestShortenUrl_GetUrl(t *testing.T) {
 			setupMock: func(t *testing.T) *mocks.UrlStorage {
 				repo := mocks.NewUrlStorage(t)
 				repo.
-					On("GetURL", mock.Anything, "errcode").
-					Return("", redis.ErrClosed).
+					On("GetLink", mock.Anything, "", "errcode").
+					Return(model.Link{}, redis.ErrClosed).
 					Once()
 				return repo
 			},
 
-			expURL:    "",
 			expectErr: redis.ErrClosed,
 		},
+		{
+			name: "cache hit -> repo not called",
+
+			code: "abc1234",
+
+			setupMock: func(t *testing.T) *mocks.UrlStorage {
+				return mocks.NewUrlStorage(t)
+			},
+			setupCache: func(t *testing.T) UrlCache {
+				cache := serviceMocks.NewUrlCache(t)
+				cache.On("Get", "abc1234").Return(model.Link{URL: "https://google.com"}, true).Once()
+				return cache
+			},
+
+			expLink:   model.Link{URL: "https://google.com"},
+			expectErr: nil,
+		},
+		{
+			name: "cache miss -> repo result is cached",
+
+			code: "abc1234",
+
+			setupMock: func(t *testing.T) *mocks.UrlStorage {
+				repo := mocks.NewUrlStorage(t)
+				repo.
+					On("GetLink", mock.Anything, "", "abc1234").
+					Return(model.Link{URL: "https://google.com"}, nil).
+					Once()
+				return repo
+			},
+			setupCache: func(t *testing.T) UrlCache {
+				cache := serviceMocks.NewUrlCache(t)
+				cache.On("Get", "abc1234").Return(model.Link{}, false).Once()
+				cache.On("Set", "abc1234", model.Link{URL: "https://google.com"}).Once()
+				return cache
+			},
+
+			expLink:   model.Link{URL: "https://google.com"},
+			expectErr: nil,
+		},
+		{
+			name: "cache miss and code not found -> nothing cached",
+
+			code: "notfound",
+
+			setupMock: func(t *testing.T) *mocks.UrlStorage {
+				repo := mocks.NewUrlStorage(t)
+				repo.
+					On("GetLink", mock.Anything, "", "notfound").
+					Return(model.Link{}, redis.Nil).
+					Once()
+				return repo
+			},
+			setupCache: func(t *testing.T) UrlCache {
+				cache := serviceMocks.NewUrlCache(t)
+				cache.On("Get", "notfound").Return(model.Link{}, false).Once()
+				return cache
+			},
+
+			expectErr: ErrCodeNotFound,
+		},
+		{
+			name: "workspace code is cached under its workspace",
+
+			workspace: "team-a",
+			code:      "abc1234",
+
+			setupMock: func(t *testing.T) *mocks.UrlStorage {
+				repo := mocks.NewUrlStorage(t)
+				repo.
+					On("GetLink", mock.Anything, "team-a", "abc1234").
+					Return(model.Link{URL: "https://google.com"}, nil).
+					Once()
+				return repo
+			},
+			setupCache: func(t *testing.T) UrlCache {
+				cache := serviceMocks.NewUrlCache(t)
+				cache.On("Get", "team-a/abc1234").Return(model.Link{}, false).Once()
+				cache.On("Set", "team-a/abc1234", model.Link{URL: "https://google.com"}).Once()
+				return cache
+			},
+
+			expLink:   model.Link{URL: "https://google.com"},
+			expectErr: nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -175,10 +536,14 @@ func TestShortenUrl_GetUrl(t *testing.T) {
 			ctx := context.Background()
 
 			repoMock := tc.setupMock(t)
+			var cache UrlCache
+			if tc.setupCache != nil {
+				cache = tc.setupCache(t)
+			}
 
-			svc := NewShortenUrl(repoMock, nil)
+			svc := NewShortenUrl(repoMock, nil, cache, nil, nil)
 
-			url, err := svc.GetUrl(ctx, tc.code)
+			link, err := svc.GetLink(ctx, tc.workspace, tc.code)
 
 			if tc.expectErr != nil {
 				assert.True(t, errors.Is(err, tc.expectErr), "expected error to match")
@@ -186,7 +551,111 @@ func TestShortenUrl_GetUrl(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			assert.Equal(t, tc.expURL, url)
+			assert.Equal(t, tc.expLink, link)
+		})
+	}
+}
+
+func TestShortenUrl_GetClicks(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		workspace string
+		user      string
+		setupMock func(t *testing.T) *mocks.UrlStorage
+
+		expStats  model.ClickStats
+		expectErr error
+	}{
+		{
+			name: "normal case",
+
+			workspace: "ws1",
+			setupMock: func(t *testing.T) *mocks.UrlStorage {
+				repo := mocks.NewUrlStorage(t)
+				repo.On("GetLink", mock.Anything, "ws1", "launch").Return(model.Link{URL: "https://google.com", Owner: "alice"}, nil).Once()
+				repo.On("GetClicks", mock.Anything, "ws1", "launch").
+					Return(model.ClickStats{Total: 3, Variants: map[string]int64{"b": 2}}, nil).
+					Once()
+				return repo
+			},
+
+			expStats: model.ClickStats{Total: 3, Variants: map[string]int64{"b": 2}},
+		},
+		{
+			name: "global link of the user",
+
+			user: "alice",
+			setupMock: func(t *testing.T) *mocks.UrlStorage {
+				repo := mocks.NewUrlStorage(t)
+				repo.On("GetLink", mock.Anything, "", "launch").Return(model.Link{URL: "https://google.com", Owner: "alice"}, nil).Once()
+				repo.On("GetClicks", mock.Anything, "", "launch").Return(model.ClickStats{Total: 3}, nil).Once()
+				return repo
+			},
+
+			expStats: model.ClickStats{Total: 3},
+		},
+		{
+			name: "anonymous global link",
+
+			setupMock: func(t *testing.T) *mocks.UrlStorage {
+				repo := mocks.NewUrlStorage(t)
+				repo.On("GetLink", mock.Anything, "", "launch").Return(model.Link{URL: "https://google.com"}, nil).Once()
+				repo.On("GetClicks", mock.Anything, "", "launch").Return(model.ClickStats{Total: 3}, nil).Once()
+				return repo
+			},
+
+			expStats: model.ClickStats{Total: 3},
+		},
+		{
+			name: "global link of another user",
+
+			user: "bob",
+			setupMock: func(t *testing.T) *mocks.UrlStorage {
+				repo := mocks.NewUrlStorage(t)
+				repo.On("GetLink", mock.Anything, "", "launch").Return(model.Link{URL: "https://google.com", Owner: "alice"}, nil).Once()
+				return repo
+			},
+
+			expectErr: ErrCodeNotFound,
+		},
+		{
+			name: "global link of a user, anonymous caller",
+
+			setupMock: func(t *testing.T) *mocks.UrlStorage {
+				repo := mocks.NewUrlStorage(t)
+				repo.On("GetLink", mock.Anything, "", "launch").Return(model.Link{URL: "https://google.com", Owner: "alice"}, nil).Once()
+				return repo
+			},
+
+			expectErr: ErrCodeNotFound,
+		},
+		{
+			name: "code not found",
+
+			workspace: "ws1",
+			setupMock: func(t *testing.T) *mocks.UrlStorage {
+				repo := mocks.NewUrlStorage(t)
+				repo.On("GetLink", mock.Anything, "ws1", "launch").Return(model.Link{}, redis.Nil).Once()
+				return repo
+			},
+
+			expectErr: ErrCodeNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			testSvc := NewShortenUrl(tc.setupMock(t), nil, nil, nil, nil)
+
+			stats, err := testSvc.GetClicks(t.Context(), tc.workspace, tc.user, "launch")
+
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expStats, stats)
 		})
 	}
 }
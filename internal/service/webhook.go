@@ -0,0 +1,210 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository"
+	"github.com/lhducc/bookmark-management/pkg/stringutils"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	webhookSecretLength = 32
+	// maxWebhooks bounds the webhooks of a workspace or a user, every event of their links is fanned out to all of them.
+	maxWebhooks = 20
+	// webhookDeliveryLogSize is the number of latest deliveries listed for a webhook.
+	webhookDeliveryLogSize = 100
+)
+
+var (
+	ErrInvalidWebhook  = errors.New("invalid webhook")
+	ErrTooManyWebhooks = errors.New("too many webhooks")
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+// Webhook manages the webhook subscriptions and queues the events of the links for them.
+// A webhook belongs to a workspace, or to a user for the global links they create when the workspace is empty.
+//
+//go:generate mockery --name Webhook --filename webhook.go
+type Webhook interface {
+	Create(ctx context.Context, workspace, owner, url string, events []string) (model.Webhook, error)
+	List(ctx context.Context, workspace, owner string) ([]model.Webhook, error)
+	Delete(ctx context.Context, workspace, owner, id string) error
+	Deliveries(ctx context.Context, workspace, owner, id string) ([]model.WebhookDelivery, error)
+	Publish(ctx context.Context, event model.Event) error
+}
+
+type webhook struct {
+	repo  repository.WebhookStorage
+	queue repository.WebhookQueue
+	now   func() time.Time
+}
+
+// NewWebhook returns a new instance of the webhook, which implements the Webhook interface.
+// Published events are only queued, a WebhookDispatcher delivers them.
+func NewWebhook(repo repository.WebhookStorage, queue repository.WebhookQueue) Webhook {
+	return &webhook{repo: repo, queue: queue, now: time.Now}
+}
+
+// Create subscribes url to events of the links of workspace, or of the global links of owner when workspace is empty,
+// with a random secret the payloads are signed with. The secret is only returned here.
+// It returns ErrInvalidWebhook if url is not an absolute http or https URL or events is empty or holds an unknown event,
+// and ErrTooManyWebhooks if the workspace or the user already has maxWebhooks webhooks.
+func (s *webhook) Create(ctx context.Context, workspace, owner, url string, events []string) (_ model.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "Webhook.Create", trace.WithAttributes(attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrInvalidWebhook, ErrTooManyWebhooks) }()
+
+	if !validAbsoluteURL(url) || len(events) == 0 || slices.ContainsFunc(events, func(e string) bool { return !model.ValidEvent(e) }) {
+		return model.Webhook{}, ErrInvalidWebhook
+	}
+
+	hooks, err := s.repo.List(ctx, workspace, owner)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	if len(hooks) >= maxWebhooks {
+		return model.Webhook{}, ErrTooManyWebhooks
+	}
+
+	secret, err := stringutils.GenerateCode(webhookSecretLength)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	events = slices.Clone(events)
+	slices.Sort(events)
+	hook := model.Webhook{
+		ID:        uuid.NewString(),
+		Workspace: workspace,
+		Owner:     owner,
+		URL:       url,
+		Secret:    secret,
+		Events:    slices.Compact(events),
+		CreatedAt: s.now().UTC().Truncate(time.Second),
+	}
+	if err := s.repo.Create(ctx, hook); err != nil {
+		return model.Webhook{}, err
+	}
+	return hook, nil
+}
+
+// List returns the webhooks of workspace, or of owner when workspace is empty, oldest first, without their secrets.
+func (s *webhook) List(ctx context.Context, workspace, owner string) (_ []model.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "Webhook.List", trace.WithAttributes(attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err) }()
+
+	hooks, err := s.repo.List(ctx, workspace, owner)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	slices.SortFunc(hooks, func(a, b model.Webhook) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+	return hooks, nil
+}
+
+// Delete removes the webhook id of workspace or owner, and returns ErrWebhookNotFound if it is not one of theirs.
+// Its queued deliveries are dropped.
+func (s *webhook) Delete(ctx context.Context, workspace, owner, id string) (err error) {
+	ctx, span := tracer.Start(ctx, "Webhook.Delete", trace.WithAttributes(attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrWebhookNotFound) }()
+
+	hook, err := s.get(ctx, workspace, owner, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, hook)
+}
+
+// Deliveries returns the latest deliveries of the webhook id of workspace or owner, newest first.
+// It returns ErrWebhookNotFound if the webhook is not one of theirs.
+func (s *webhook) Deliveries(ctx context.Context, workspace, owner, id string) (_ []model.WebhookDelivery, err error) {
+	ctx, span := tracer.Start(ctx, "Webhook.Deliveries", trace.WithAttributes(attribute.String("workspace.id", workspace)))
+	defer func() { endSpan(span, err, ErrWebhookNotFound) }()
+
+	if _, err := s.get(ctx, workspace, owner, id); err != nil {
+		return nil, err
+	}
+	return s.queue.Log(ctx, id, webhookDeliveryLogSize)
+}
+
+// Publish queues a delivery of event for every webhook subscribed to its type, the ones of the workspace of the
+// event, or of its owner for a global link. Events of the global links created anonymously are not delivered.
+// The ID and the time of the event are set when they are empty.
+func (s *webhook) Publish(ctx context.Context, event model.Event) (err error) {
+	ctx, span := tracer.Start(ctx, "Webhook.Publish", trace.WithAttributes(
+		attribute.String("workspace.id", event.Workspace),
+		attribute.String("event.type", event.Type),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if event.Workspace == "" && event.Owner == "" {
+		return nil
+	}
+	hooks, err := s.repo.List(ctx, event.Workspace, event.Owner)
+	if err != nil {
+		return err
+	}
+	hooks = slices.DeleteFunc(hooks, func(h model.Webhook) bool { return !slices.Contains(h.Events, event.Type) })
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	now := s.now().UTC()
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = now.Truncate(time.Second)
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, hook := range hooks {
+		err := s.queue.Enqueue(ctx, model.WebhookDelivery{
+			ID:            uuid.NewString(),
+			WebhookID:     hook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        model.DeliveryPending,
+			CreatedAt:     now.Truncate(time.Second),
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("enqueue delivery for webhook %s: %w", hook.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// get returns the webhook id if it belongs to workspace, or to owner for a webhook of global links, and
+// ErrWebhookNotFound otherwise.
+func (s *webhook) get(ctx context.Context, workspace, owner, id string) (model.Webhook, error) {
+	hook, err := s.repo.Get(ctx, id)
+	if errors.Is(err, redis.Nil) {
+		return model.Webhook{}, ErrWebhookNotFound
+	}
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	if hook.Workspace != workspace || (workspace == "" && hook.Owner != owner) {
+		return model.Webhook{}, ErrWebhookNotFound
+	}
+	return hook, nil
+}
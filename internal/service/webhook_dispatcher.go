@@ -0,0 +1,274 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Headers of the webhook requests. The signature is "sha256=" followed by the hex HMAC-SHA256, keyed with the secret of
+// the webhook, of the timestamp header, a '.' and the body, see SignWebhookPayload.
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	// webhookClaimBatch bounds the deliveries attempted at once by an instance.
+	webhookClaimBatch = 20
+	// webhookDefaultLease is how long claimed deliveries are hidden from the other instances when requests have no timeout.
+	webhookDefaultLease = time.Minute
+	// maxWebhookResponseDrain bounds the response body read to reuse the connection.
+	maxWebhookResponseDrain = 64 << 10
+)
+
+// ErrWebhookAddressNotPublic is returned for a webhook request to an address outside of the public internet.
+var ErrWebhookAddressNotPublic = errors.New("webhook address is not public")
+
+// nonPublicPrefixes lists the shared and reserved ranges not covered by the netip.Addr predicates.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// WebhookRetryPolicy bounds the attempts of a delivery. After a failed attempt n the next one waits BaseDelay*2^(n-1),
+// capped at MaxDelay, and a delivery is dead once MaxAttempts attempts failed.
+type WebhookRetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// delay returns how long to wait after the failed attempt number attempt.
+func (p WebhookRetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxDelay)
+}
+
+// WebhookDispatcher delivers the queued webhook deliveries.
+//
+//go:generate mockery --name WebhookDispatcher --filename webhook_dispatcher.go
+type WebhookDispatcher interface {
+	Run(ctx context.Context, beat func()) error
+}
+
+type webhookDispatcher struct {
+	hooks        repository.WebhookStorage
+	queue        repository.WebhookQueue
+	client       *http.Client
+	policy       WebhookRetryPolicy
+	pollInterval time.Duration
+	lease        time.Duration
+	now          func() time.Time
+}
+
+// NewWebhookDispatcher returns a new instance of the webhookDispatcher, which implements the WebhookDispatcher interface.
+// The queue is polled every pollInterval, and every request is bounded by timeout, 0 for none.
+// Redirects are not followed, a delivery answered with a redirect failed.
+// The requests are sent with transport, nil for one refusing to connect to addresses outside of the public internet,
+// see NewWebhookTransport.
+func NewWebhookDispatcher(hooks repository.WebhookStorage, queue repository.WebhookQueue, timeout time.Duration, policy WebhookRetryPolicy, pollInterval time.Duration, transport http.RoundTripper) WebhookDispatcher {
+	lease := webhookDefaultLease
+	if timeout > 0 {
+		lease = 2 * timeout
+	}
+	if transport == nil {
+		transport = NewWebhookTransport()
+	}
+	return &webhookDispatcher{
+		hooks: hooks,
+		queue: queue,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		policy:       policy,
+		pollInterval: pollInterval,
+		lease:        lease,
+		now:          time.Now,
+	}
+}
+
+// NewWebhookTransport returns the transport of the webhook requests. It refuses to connect to loopback, private,
+// link-local, such as the 169.254.169.254 metadata endpoint, and reserved addresses, so a webhook cannot reach the
+// network of the service. The address is checked when it is dialed, after the name resolution, so a DNS answer changing
+// after the webhook was created cannot get around it. Proxies are not used, they would be dialed instead of the webhook.
+func NewWebhookTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: dialPublicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// dialPublicOnly is a net.Dialer control function failing with ErrWebhookAddressNotPublic before connecting to an address
+// outside of the public internet.
+func dialPublicOnly(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddr(addrPort.Addr()) {
+		return fmt.Errorf("dial %s: %w", address, ErrWebhookAddressNotPublic)
+	}
+	return nil
+}
+
+// publicAddr reports whether ip is a unicast address of the public internet.
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// SignWebhookPayload returns the value of the signature header of a webhook request sent at timestamp, in Unix seconds,
+// with payload as its body.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run delivers the due deliveries every poll interval until ctx is canceled, it only returns then.
+// Every instance runs it, a delivery is claimed by one instance at a time and attempted again by any instance if the
+// outcome of an attempt is not saved before the lease ends. Errors are logged and the deliveries retried later.
+// beat is called on every tick and after every batch of deliveries, so a stuck dispatcher can be told apart.
+func (d *webhookDispatcher) Run(ctx context.Context, beat func()) error {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		beat()
+		d.dispatch(ctx, beat)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch attempts the deliveries due now, concurrently, until there are none left.
+func (d *webhookDispatcher) dispatch(ctx context.Context, beat func()) {
+	for ctx.Err() == nil {
+		deliveries, err := d.queue.Claim(ctx, d.now(), d.lease, webhookClaimBatch)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Cannot claim the webhook deliveries")
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.attempt(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+		beat()
+
+		if len(deliveries) < webhookClaimBatch {
+			return
+		}
+	}
+}
+
+// attempt sends delivery to its webhook and saves the outcome, a delivery to a deleted webhook is dead at once.
+func (d *webhookDispatcher) attempt(ctx context.Context, delivery model.WebhookDelivery) {
+	logger := log.Ctx(ctx).With().Str("webhook_id", delivery.WebhookID).Str("delivery_id", delivery.ID).Logger()
+
+	hook, err := d.hooks.Get(ctx, delivery.WebhookID)
+	switch {
+	case errors.Is(err, redis.Nil):
+		delivery.Status, delivery.LastStatus, delivery.LastError = model.DeliveryDead, 0, "webhook deleted"
+	case err != nil:
+		logger.Error().Err(err).Msg("Cannot get the webhook of the delivery")
+		return
+	default:
+		delivery.Attempts++
+		delivery.LastStatus, err = d.send(ctx, hook, delivery)
+		switch {
+		case err == nil:
+			delivery.Status, delivery.LastError = model.DeliveryDelivered, ""
+		case delivery.Attempts >= d.policy.MaxAttempts:
+			delivery.Status, delivery.LastError = model.DeliveryDead, err.Error()
+			logger.Warn().Err(err).Int("attempts", delivery.Attempts).Msg("Webhook delivery failed, giving up")
+		default:
+			delivery.Status, delivery.LastError = model.DeliveryPending, err.Error()
+			delivery.NextAttemptAt = d.now().Add(d.policy.delay(delivery.Attempts))
+		}
+	}
+
+	if err := d.queue.Save(ctx, delivery); err != nil {
+		logger.Error().Err(err).Msg("Cannot save the webhook delivery")
+	}
+}
+
+// send posts the payload of delivery to hook, and returns the response status, 0 when there is none.
+// It returns an error unless the status is 2xx.
+func (d *webhookDispatcher) send(ctx context.Context, hook model.Webhook, delivery model.WebhookDelivery) (int, error) {
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bookmark-management-webhooks")
+	req.Header.Set(WebhookIDHeader, hook.ID)
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(hook.Secret, timestamp, payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.CopyN(io.Discard, resp.Body, maxWebhookResponseDrain)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookRetryPolicy_Delay(t *testing.T) {
+	t.Parallel()
+
+	policy := WebhookRetryPolicy{MaxAttempts: 10, BaseDelay: 10 * time.Second, MaxDelay: time.Minute}
+	assert.Equal(t, 10*time.Second, policy.delay(1))
+	assert.Equal(t, 20*time.Second, policy.delay(2))
+	assert.Equal(t, 40*time.Second, policy.delay(3))
+	assert.Equal(t, time.Minute, policy.delay(4))
+	assert.Equal(t, time.Minute, policy.delay(60))
+}
+
+func TestWebhookDispatcher_Attempt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0).UTC()
+	policy := WebhookRetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Second, MaxDelay: time.Minute}
+
+	testCases := []struct {
+		name string
+
+		status   int
+		attempts int
+		deleted  bool
+
+		expected model.WebhookDelivery
+	}{
+		{
+			name: "delivered",
+
+			status: http.StatusNoContent,
+
+			expected: model.WebhookDelivery{Status: model.DeliveryDelivered, Attempts: 1, LastStatus: http.StatusNoContent},
+		},
+		{
+			name: "retried with backoff",
+
+			status:   http.StatusInternalServerError,
+			attempts: 1,
+
+			expected: model.WebhookDelivery{
+				Status:        model.DeliveryPending,
+				Attempts:      2,
+				LastStatus:    http.StatusInternalServerError,
+				LastError:     "unexpected status 500",
+				NextAttemptAt: now.Add(20 * time.Second),
+			},
+		},
+		{
+			name: "redirect is a failure",
+
+			status: http.StatusFound,
+
+			expected: model.WebhookDelivery{
+				Status:        model.DeliveryPending,
+				Attempts:      1,
+				LastStatus:    http.StatusFound,
+				LastError:     "unexpected status 302",
+				NextAttemptAt: now.Add(10 * time.Second),
+			},
+		},
+		{
+			name: "dead after max attempts",
+
+			status:   http.StatusServiceUnavailable,
+			attempts: 2,
+
+			expected: model.WebhookDelivery{Status: model.DeliveryDead, Attempts: 3, LastStatus: http.StatusServiceUnavailable, LastError: "unexpected status 503"},
+		},
+		{
+			name: "webhook deleted",
+
+			deleted: true,
+
+			expected: model.WebhookDelivery{Status: model.DeliveryDead, LastError: "webhook deleted"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			const payload = `{"id":"ev1","type":"link.clicked","code":"abc"}`
+			var received *http.Request
+			var body []byte
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				body, _ = io.ReadAll(r.Body)
+				if tc.status == http.StatusFound {
+					w.Header().Set("Location", "/elsewhere")
+				}
+				w.WriteHeader(tc.status)
+			}))
+			t.Cleanup(receiver.Close)
+
+			hooks, queue := mocks.NewWebhookStorage(t), mocks.NewWebhookQueue(t)
+			if tc.deleted {
+				hooks.On("Get", mock.Anything, "wh1").Return(model.Webhook{}, redis.Nil).Once()
+			} else {
+				hooks.On("Get", mock.Anything, "wh1").Return(model.Webhook{ID: "wh1", URL: receiver.URL, Secret: "s3cret"}, nil).Once()
+			}
+			delivery := model.WebhookDelivery{ID: "d1", WebhookID: "wh1", EventType: model.EventLinkClicked, Payload: payload, Attempts: tc.attempts}
+			expected := tc.expected
+			expected.ID, expected.WebhookID, expected.EventType, expected.Payload = "d1", "wh1", model.EventLinkClicked, payload
+			queue.On("Save", mock.Anything, expected).Return(nil).Once()
+
+			d := NewWebhookDispatcher(hooks, queue, time.Second, policy, time.Second, http.DefaultTransport).(*webhookDispatcher)
+			d.now = func() time.Time { return now }
+			d.attempt(t.Context(), delivery)
+
+			if tc.deleted {
+				assert.Nil(t, received)
+				return
+			}
+			assert.Equal(t, payload, string(body))
+			assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+			assert.Equal(t, "wh1", received.Header.Get(WebhookIDHeader))
+			assert.Equal(t, model.EventLinkClicked, received.Header.Get(WebhookEventHeader))
+			assert.Equal(t, "d1", received.Header.Get(WebhookDeliveryHeader))
+			assert.Equal(t, strconv.FormatInt(now.Unix(), 10), received.Header.Get(WebhookTimestampHeader))
+			assert.Equal(t, SignWebhookPayload("s3cret", now.Unix(), []byte(payload)), received.Header.Get(WebhookSignatureHeader))
+		})
+	}
+}
+
+func TestWebhookDispatcher_Attempt_notPublic(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0).UTC()
+	policy := WebhookRetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Second, MaxDelay: time.Minute}
+
+	received := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	t.Cleanup(receiver.Close)
+
+	hooks, queue := mocks.NewWebhookStorage(t), mocks.NewWebhookQueue(t)
+	hooks.On("Get", mock.Anything, "wh1").Return(model.Webhook{ID: "wh1", URL: receiver.URL, Secret: "s3cret"}, nil).Once()
+	queue.On("Save", mock.Anything, mock.MatchedBy(func(d model.WebhookDelivery) bool {
+		return d.Status == model.DeliveryPending && d.Attempts == 1 && d.LastStatus == 0 &&
+			strings.Contains(d.LastError, ErrWebhookAddressNotPublic.Error()) && d.NextAttemptAt.Equal(now.Add(10*time.Second))
+	})).Return(nil).Once()
+
+	d := NewWebhookDispatcher(hooks, queue, time.Second, policy, time.Second, nil).(*webhookDispatcher)
+	d.now = func() time.Time { return now }
+	d.attempt(t.Context(), model.WebhookDelivery{ID: "d1", WebhookID: "wh1", EventType: model.EventLinkClicked, Payload: `{}`})
+
+	assert.False(t, received, "the loopback receiver is never dialed")
+}
+
+func TestPublicAddr(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		addr     string
+		expected bool
+	}{
+		{addr: "93.184.216.34", expected: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", expected: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "::ffff:169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "fd00::1"},
+		{addr: "100.64.0.1"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+		{addr: "224.0.0.1"},
+		{addr: "255.255.255.255"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.addr, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, publicAddr(netip.MustParseAddr(tc.addr)))
+		})
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	t.Parallel()
+
+	// echo -n '1700000000.{"code":"abc"}' | openssl dgst -sha256 -hmac s3cret
+	assert.Equal(t,
+		"sha256=268bf3f19424727ee2d0bdbbef4c5ecf4a797d6e5b5f162ddfc10936bc347431",
+		SignWebhookPayload("s3cret", 1700000000, []byte(`{"code":"abc"}`)),
+	)
+}
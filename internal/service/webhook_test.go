@@ -0,0 +1,237 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Create(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		url       string
+		events    []string
+		setupMock func(t *testing.T) *mocks.WebhookStorage
+
+		expectedEvents []string
+		expectErr      error
+	}{
+		{
+			name: "normal case",
+
+			url:    "https://hooks.example.com/in",
+			events: []string{model.EventLinkCreated, model.EventLinkClicked, model.EventLinkCreated},
+			setupMock: func(t *testing.T) *mocks.WebhookStorage {
+				repo := mocks.NewWebhookStorage(t)
+				repo.On("List", mock.Anything, "ws1", "u1").Return(nil, nil).Once()
+				repo.On("Create", mock.Anything, mock.MatchedBy(func(h model.Webhook) bool {
+					return h.ID != "" && h.Workspace == "ws1" && h.Owner == "u1" && len(h.Secret) == webhookSecretLength
+				})).Return(nil).Once()
+				return repo
+			},
+
+			expectedEvents: []string{model.EventLinkClicked, model.EventLinkCreated},
+		},
+		{
+			name: "relative url",
+
+			url:    "/in",
+			events: []string{model.EventLinkCreated},
+			setupMock: func(t *testing.T) *mocks.WebhookStorage {
+				return mocks.NewWebhookStorage(t)
+			},
+
+			expectErr: ErrInvalidWebhook,
+		},
+		{
+			name: "unknown event",
+
+			url:    "https://hooks.example.com/in",
+			events: []string{"bookmark.created"},
+			setupMock: func(t *testing.T) *mocks.WebhookStorage {
+				return mocks.NewWebhookStorage(t)
+			},
+
+			expectErr: ErrInvalidWebhook,
+		},
+		{
+			name: "too many webhooks",
+
+			url:    "https://hooks.example.com/in",
+			events: []string{model.EventLinkCreated},
+			setupMock: func(t *testing.T) *mocks.WebhookStorage {
+				repo := mocks.NewWebhookStorage(t)
+				repo.On("List", mock.Anything, "ws1", "u1").Return(make([]model.Webhook, maxWebhooks), nil).Once()
+				return repo
+			},
+
+			expectErr: ErrTooManyWebhooks,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewWebhook(tc.setupMock(t), mocks.NewWebhookQueue(t))
+			hook, err := svc.Create(t.Context(), "ws1", "u1", tc.url, tc.events)
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectedEvents, hook.Events)
+		})
+	}
+}
+
+func TestWebhook_Deliveries(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		workspace string
+		owner     string
+		setupMock func(t *testing.T) (*mocks.WebhookStorage, *mocks.WebhookQueue)
+
+		expectErr error
+	}{
+		{
+			name: "user webhook",
+
+			owner: "u1",
+			setupMock: func(t *testing.T) (*mocks.WebhookStorage, *mocks.WebhookQueue) {
+				repo, queue := mocks.NewWebhookStorage(t), mocks.NewWebhookQueue(t)
+				repo.On("Get", mock.Anything, "wh1").Return(model.Webhook{ID: "wh1", Owner: "u1"}, nil).Once()
+				queue.On("Log", mock.Anything, "wh1", webhookDeliveryLogSize).Return([]model.WebhookDelivery{{ID: "d1"}}, nil).Once()
+				return repo, queue
+			},
+		},
+		{
+			name: "webhook of another user",
+
+			owner: "u2",
+			setupMock: func(t *testing.T) (*mocks.WebhookStorage, *mocks.WebhookQueue) {
+				repo := mocks.NewWebhookStorage(t)
+				repo.On("Get", mock.Anything, "wh1").Return(model.Webhook{ID: "wh1", Owner: "u1"}, nil).Once()
+				return repo, mocks.NewWebhookQueue(t)
+			},
+
+			expectErr: ErrWebhookNotFound,
+		},
+		{
+			name: "webhook of another workspace",
+
+			workspace: "ws2",
+			owner:     "u1",
+			setupMock: func(t *testing.T) (*mocks.WebhookStorage, *mocks.WebhookQueue) {
+				repo := mocks.NewWebhookStorage(t)
+				repo.On("Get", mock.Anything, "wh1").Return(model.Webhook{ID: "wh1", Workspace: "ws1", Owner: "u1"}, nil).Once()
+				return repo, mocks.NewWebhookQueue(t)
+			},
+
+			expectErr: ErrWebhookNotFound,
+		},
+		{
+			name: "not found",
+
+			owner: "u1",
+			setupMock: func(t *testing.T) (*mocks.WebhookStorage, *mocks.WebhookQueue) {
+				repo := mocks.NewWebhookStorage(t)
+				repo.On("Get", mock.Anything, "wh1").Return(model.Webhook{}, redis.Nil).Once()
+				return repo, mocks.NewWebhookQueue(t)
+			},
+
+			expectErr: ErrWebhookNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewWebhook(tc.setupMock(t))
+			_, err := svc.Deliveries(t.Context(), tc.workspace, tc.owner, "wh1")
+			assert.Equal(t, tc.expectErr, err)
+		})
+	}
+}
+
+func TestWebhook_Publish(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0).UTC()
+
+	testCases := []struct {
+		name string
+
+		event     model.Event
+		setupMock func(t *testing.T) (*mocks.WebhookStorage, *mocks.WebhookQueue)
+
+		expectErr error
+	}{
+		{
+			name: "subscribed webhooks only",
+
+			event: model.Event{Type: model.EventLinkClicked, Workspace: "ws1", Code: "abc", URL: "https://example.com", Variant: "a"},
+			setupMock: func(t *testing.T) (*mocks.WebhookStorage, *mocks.WebhookQueue) {
+				repo, queue := mocks.NewWebhookStorage(t), mocks.NewWebhookQueue(t)
+				repo.On("List", mock.Anything, "ws1", "").Return([]model.Webhook{
+					{ID: "wh1", Events: []string{model.EventLinkClicked}},
+					{ID: "wh2", Events: []string{model.EventLinkCreated}},
+				}, nil).Once()
+				queue.On("Enqueue", mock.Anything, mock.MatchedBy(func(d model.WebhookDelivery) bool {
+					var event model.Event
+					require.NoError(t, json.Unmarshal([]byte(d.Payload), &event))
+					return d.WebhookID == "wh1" && d.Status == model.DeliveryPending && d.NextAttemptAt.Equal(now) &&
+						event.ID == d.EventID && event.Type == model.EventLinkClicked && event.Code == "abc" &&
+						event.Variant == "a" && event.OccurredAt.Equal(now)
+				})).Return(nil).Once()
+				return repo, queue
+			},
+		},
+		{
+			name: "anonymous global link",
+
+			event: model.Event{Type: model.EventLinkCreated, Code: "abc"},
+			setupMock: func(t *testing.T) (*mocks.WebhookStorage, *mocks.WebhookQueue) {
+				return mocks.NewWebhookStorage(t), mocks.NewWebhookQueue(t)
+			},
+		},
+		{
+			name: "enqueue error",
+
+			event: model.Event{Type: model.EventLinkCreated, Owner: "u1", Code: "abc"},
+			setupMock: func(t *testing.T) (*mocks.WebhookStorage, *mocks.WebhookQueue) {
+				repo, queue := mocks.NewWebhookStorage(t), mocks.NewWebhookQueue(t)
+				repo.On("List", mock.Anything, "", "u1").Return([]model.Webhook{{ID: "wh1", Events: []string{model.EventLinkCreated}}}, nil).Once()
+				queue.On("Enqueue", mock.Anything, mock.Anything).Return(redis.ErrClosed).Once()
+				return repo, queue
+			},
+
+			expectErr: redis.ErrClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &webhook{now: func() time.Time { return now }}
+			svc.repo, svc.queue = tc.setupMock(t)
+			err := svc.Publish(t.Context(), tc.event)
+			if tc.expectErr != nil {
+				assert.True(t, errors.Is(err, tc.expectErr), err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"slices"
+	"strings"
+	"time"
+)
+
+const maxWorkspaceNameLength = 100
+
+var (
+	ErrWorkspaceNotFound    = errors.New("workspace not found")
+	ErrNotWorkspaceMember   = errors.New("not a member of the workspace")
+	ErrInvalidWorkspaceName = errors.New("invalid workspace name")
+	ErrInvalidRole          = errors.New("invalid role")
+	ErrInsufficientRole     = errors.New("insufficient role")
+	ErrLastOwner            = errors.New("a workspace must keep an owner")
+)
+
+// Workspace manages workspaces and the roles of their members.
+//
+//go:generate mockery --name Workspace --filename workspace.go
+type Workspace interface {
+	Create(ctx context.Context, name, ownerID string) (model.Workspace, error)
+	Get(ctx context.Context, id string) (model.Workspace, error)
+	ListForUser(ctx context.Context, userID string) ([]model.Workspace, error)
+	Role(ctx context.Context, id, userID string) (string, error)
+	Members(ctx context.Context, id string) ([]model.WorkspaceMember, error)
+	SetMember(ctx context.Context, id, actorRole, userID, role string) error
+	RemoveMember(ctx context.Context, id, actorRole, userID string) error
+}
+
+type workspace struct {
+	repo repository.WorkspaceStorage
+	now  func() time.Time
+}
+
+// NewWorkspace returns a new instance of the workspace, which implements the Workspace interface.
+func NewWorkspace(repo repository.WorkspaceStorage) Workspace {
+	return &workspace{repo: repo, now: time.Now}
+}
+
+// Create creates a workspace named name, with ownerID as its only member and owner.
+// It returns ErrInvalidWorkspaceName if name is blank or longer than 100 characters.
+func (s *workspace) Create(ctx context.Context, name, ownerID string) (_ model.Workspace, err error) {
+	ctx, span := tracer.Start(ctx, "Workspace.Create")
+	defer func() { endSpan(span, err, ErrInvalidWorkspaceName) }()
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxWorkspaceNameLength {
+		return model.Workspace{}, ErrInvalidWorkspaceName
+	}
+
+	ws := model.Workspace{ID: uuid.NewString(), Name: name, CreatedAt: s.now().UTC().Truncate(time.Second)}
+	if err := s.repo.Create(ctx, ws, ownerID); err != nil {
+		return model.Workspace{}, err
+	}
+	span.SetAttributes(attribute.String("workspace.id", ws.ID))
+	return ws, nil
+}
+
+// Get returns the workspace id, and ErrWorkspaceNotFound if it does not exist.
+func (s *workspace) Get(ctx context.Context, id string) (_ model.Workspace, err error) {
+	ctx, span := tracer.Start(ctx, "Workspace.Get", trace.WithAttributes(attribute.String("workspace.id", id)))
+	defer func() { endSpan(span, err, ErrWorkspaceNotFound) }()
+
+	ws, err := s.repo.Get(ctx, id)
+	if errors.Is(err, redis.Nil) {
+		return model.Workspace{}, ErrWorkspaceNotFound
+	}
+	return ws, err
+}
+
+// ListForUser returns the workspaces userID is a member of, sorted by name.
+func (s *workspace) ListForUser(ctx context.Context, userID string) (_ []model.Workspace, err error) {
+	ctx, span := tracer.Start(ctx, "Workspace.ListForUser")
+	defer func() { endSpan(span, err) }()
+
+	ids, err := s.repo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := make([]model.Workspace, 0, len(ids))
+	for _, id := range ids {
+		ws, err := s.repo.Get(ctx, id)
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, ws)
+	}
+	slices.SortFunc(workspaces, func(a, b model.Workspace) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return workspaces, nil
+}
+
+// Role returns the role of userID in the workspace id, and ErrNotWorkspaceMember if they are not a member.
+// A workspace that does not exist has no member, so it is reported the same way and its existence is not leaked.
+func (s *workspace) Role(ctx context.Context, id, userID string) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "Workspace.Role", trace.WithAttributes(attribute.String("workspace.id", id)))
+	defer func() { endSpan(span, err, ErrNotWorkspaceMember) }()
+
+	role, err := s.repo.Role(ctx, id, userID)
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotWorkspaceMember
+	}
+	return role, err
+}
+
+// Members returns the members of the workspace id sorted by user ID.
+func (s *workspace) Members(ctx context.Context, id string) (_ []model.WorkspaceMember, err error) {
+	ctx, span := tracer.Start(ctx, "Workspace.Members", trace.WithAttributes(attribute.String("workspace.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	roles, err := s.repo.Members(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]model.WorkspaceMember, 0, len(roles))
+	for userID, role := range roles {
+		members = append(members, model.WorkspaceMember{UserID: userID, Role: role})
+	}
+	slices.SortFunc(members, func(a, b model.WorkspaceMember) int {
+		return strings.Compare(a.UserID, b.UserID)
+	})
+	return members, nil
+}
+
+// SetMember adds userID to the workspace id with role, or changes their role, on behalf of a member with actorRole.
+// Admins and owners manage members, but nobody grants a role above their own or changes the role of a member above them,
+// so only owners promote owners. It returns ErrLastOwner when the change would leave the workspace without an owner.
+func (s *workspace) SetMember(ctx context.Context, id, actorRole, userID, role string) (err error) {
+	ctx, span := tracer.Start(ctx, "Workspace.SetMember", trace.WithAttributes(attribute.String("workspace.id", id)))
+	defer func() { endSpan(span, err, ErrInvalidRole, ErrInsufficientRole, ErrLastOwner) }()
+
+	if !model.ValidRole(role) {
+		return ErrInvalidRole
+	}
+	if model.RoleRank(actorRole) < model.RoleRank(model.RoleAdmin) || model.RoleRank(role) > model.RoleRank(actorRole) {
+		return ErrInsufficientRole
+	}
+
+	current, err := s.repo.Role(ctx, id, userID)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if model.RoleRank(current) > model.RoleRank(actorRole) {
+		return ErrInsufficientRole
+	}
+	if current == model.RoleOwner && role != model.RoleOwner {
+		if err := s.checkOtherOwner(ctx, id, userID); err != nil {
+			return err
+		}
+	}
+	return s.repo.SetMember(ctx, id, userID, role)
+}
+
+// RemoveMember removes userID from the workspace id on behalf of a member with actorRole, following the rules of SetMember.
+// It returns ErrNotWorkspaceMember if userID is not a member.
+func (s *workspace) RemoveMember(ctx context.Context, id, actorRole, userID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Workspace.RemoveMember", trace.WithAttributes(attribute.String("workspace.id", id)))
+	defer func() { endSpan(span, err, ErrNotWorkspaceMember, ErrInsufficientRole, ErrLastOwner) }()
+
+	if model.RoleRank(actorRole) < model.RoleRank(model.RoleAdmin) {
+		return ErrInsufficientRole
+	}
+
+	current, err := s.repo.Role(ctx, id, userID)
+	if errors.Is(err, redis.Nil) {
+		return ErrNotWorkspaceMember
+	}
+	if err != nil {
+		return err
+	}
+	if model.RoleRank(current) > model.RoleRank(actorRole) {
+		return ErrInsufficientRole
+	}
+	if current == model.RoleOwner {
+		if err := s.checkOtherOwner(ctx, id, userID); err != nil {
+			return err
+		}
+	}
+	return s.repo.RemoveMember(ctx, id, userID)
+}
+
+// checkOtherOwner returns ErrLastOwner unless the workspace id has an owner other than userID.
+func (s *workspace) checkOtherOwner(ctx context.Context, id, userID string) error {
+	roles, err := s.repo.Members(ctx, id)
+	if err != nil {
+		return err
+	}
+	for member, role := range roles {
+		if member != userID && role == model.RoleOwner {
+			return nil
+		}
+	}
+	return ErrLastOwner
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/model"
+	"github.com/lhducc/bookmark-management/internal/repository/mocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestWorkspace_Create(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	testCases := []struct {
+		name string
+
+		wsName    string
+		setupMock func(t *testing.T) *mocks.WorkspaceStorage
+
+		expectedName string
+		expectErr    error
+	}{
+		{
+			name: "normal case",
+
+			wsName: "  Team A ",
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Create", mock.Anything, mock.MatchedBy(func(ws model.Workspace) bool {
+					return ws.ID != "" && ws.Name == "Team A" && ws.CreatedAt.Equal(now.Truncate(time.Second))
+				}), "alice").Return(nil).Once()
+				return repo
+			},
+
+			expectedName: "Team A",
+		},
+		{
+			name: "blank name",
+
+			wsName: "   ",
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				return mocks.NewWorkspaceStorage(t)
+			},
+
+			expectErr: ErrInvalidWorkspaceName,
+		},
+		{
+			name: "repository error",
+
+			wsName: "Team A",
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Create", mock.Anything, mock.Anything, "alice").Return(testError).Once()
+				return repo
+			},
+
+			expectErr: testError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &workspace{repo: tc.setupMock(t), now: func() time.Time { return now }}
+
+			ws, err := svc.Create(context.Background(), tc.wsName, "alice")
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectedName, ws.Name)
+		})
+	}
+}
+
+func TestWorkspace_ListForUser(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func(t *testing.T) *mocks.WorkspaceStorage
+
+		expected  []model.Workspace
+		expectErr error
+	}{
+		{
+			name: "sorted by name, deleted workspaces skipped",
+
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("ListForUser", mock.Anything, "alice").Return([]string{"ws1", "ws2", "ws3"}, nil).Once()
+				repo.On("Get", mock.Anything, "ws1").Return(model.Workspace{ID: "ws1", Name: "Zeta"}, nil).Once()
+				repo.On("Get", mock.Anything, "ws2").Return(model.Workspace{}, redis.Nil).Once()
+				repo.On("Get", mock.Anything, "ws3").Return(model.Workspace{ID: "ws3", Name: "Alpha"}, nil).Once()
+				return repo
+			},
+
+			expected: []model.Workspace{{ID: "ws3", Name: "Alpha"}, {ID: "ws1", Name: "Zeta"}},
+		},
+		{
+			name: "repository error",
+
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("ListForUser", mock.Anything, "alice").Return(nil, testError).Once()
+				return repo
+			},
+
+			expectErr: testError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewWorkspace(tc.setupMock(t))
+
+			workspaces, err := svc.ListForUser(context.Background(), "alice")
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expected, workspaces)
+		})
+	}
+}
+
+func TestWorkspace_Role(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupMock func(t *testing.T) *mocks.WorkspaceStorage
+
+		expectedRole string
+		expectErr    error
+	}{
+		{
+			name: "member",
+
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "alice").Return(model.RoleAdmin, nil).Once()
+				return repo
+			},
+
+			expectedRole: model.RoleAdmin,
+		},
+		{
+			name: "not a member",
+
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "alice").Return("", redis.Nil).Once()
+				return repo
+			},
+
+			expectErr: ErrNotWorkspaceMember,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewWorkspace(tc.setupMock(t))
+
+			role, err := svc.Role(context.Background(), "ws1", "alice")
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectedRole, role)
+		})
+	}
+}
+
+func TestWorkspace_SetMember(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		actorRole string
+		role      string
+		setupMock func(t *testing.T) *mocks.WorkspaceStorage
+
+		expectErr error
+	}{
+		{
+			name: "admin adds a member",
+
+			actorRole: model.RoleAdmin,
+			role:      model.RoleMember,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "bob").Return("", redis.Nil).Once()
+				repo.On("SetMember", mock.Anything, "ws1", "bob", model.RoleMember).Return(nil).Once()
+				return repo
+			},
+		},
+		{
+			name: "owner promotes an owner",
+
+			actorRole: model.RoleOwner,
+			role:      model.RoleOwner,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "bob").Return(model.RoleAdmin, nil).Once()
+				repo.On("SetMember", mock.Anything, "ws1", "bob", model.RoleOwner).Return(nil).Once()
+				return repo
+			},
+		},
+		{
+			name: "invalid role",
+
+			actorRole: model.RoleOwner,
+			role:      "superuser",
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				return mocks.NewWorkspaceStorage(t)
+			},
+
+			expectErr: ErrInvalidRole,
+		},
+		{
+			name: "member cannot manage members",
+
+			actorRole: model.RoleMember,
+			role:      model.RoleViewer,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				return mocks.NewWorkspaceStorage(t)
+			},
+
+			expectErr: ErrInsufficientRole,
+		},
+		{
+			name: "admin cannot grant owner",
+
+			actorRole: model.RoleAdmin,
+			role:      model.RoleOwner,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				return mocks.NewWorkspaceStorage(t)
+			},
+
+			expectErr: ErrInsufficientRole,
+		},
+		{
+			name: "admin cannot demote an owner",
+
+			actorRole: model.RoleAdmin,
+			role:      model.RoleMember,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "bob").Return(model.RoleOwner, nil).Once()
+				return repo
+			},
+
+			expectErr: ErrInsufficientRole,
+		},
+		{
+			name: "last owner cannot be demoted",
+
+			actorRole: model.RoleOwner,
+			role:      model.RoleAdmin,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "bob").Return(model.RoleOwner, nil).Once()
+				repo.On("Members", mock.Anything, "ws1").Return(map[string]string{"bob": model.RoleOwner, "carol": model.RoleAdmin}, nil).Once()
+				return repo
+			},
+
+			expectErr: ErrLastOwner,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewWorkspace(tc.setupMock(t))
+
+			err := svc.SetMember(context.Background(), "ws1", tc.actorRole, "bob", tc.role)
+			assert.Equal(t, tc.expectErr, err)
+		})
+	}
+}
+
+func TestWorkspace_RemoveMember(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		actorRole string
+		setupMock func(t *testing.T) *mocks.WorkspaceStorage
+
+		expectErr error
+	}{
+		{
+			name: "admin removes a member",
+
+			actorRole: model.RoleAdmin,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "bob").Return(model.RoleMember, nil).Once()
+				repo.On("RemoveMember", mock.Anything, "ws1", "bob").Return(nil).Once()
+				return repo
+			},
+		},
+		{
+			name: "owner removes an owner",
+
+			actorRole: model.RoleOwner,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "bob").Return(model.RoleOwner, nil).Once()
+				repo.On("Members", mock.Anything, "ws1").Return(map[string]string{"alice": model.RoleOwner, "bob": model.RoleOwner}, nil).Once()
+				repo.On("RemoveMember", mock.Anything, "ws1", "bob").Return(nil).Once()
+				return repo
+			},
+		},
+		{
+			name: "not a member",
+
+			actorRole: model.RoleAdmin,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "bob").Return("", redis.Nil).Once()
+				return repo
+			},
+
+			expectErr: ErrNotWorkspaceMember,
+		},
+		{
+			name: "admin cannot remove an owner",
+
+			actorRole: model.RoleAdmin,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "bob").Return(model.RoleOwner, nil).Once()
+				return repo
+			},
+
+			expectErr: ErrInsufficientRole,
+		},
+		{
+			name: "last owner cannot be removed",
+
+			actorRole: model.RoleOwner,
+			setupMock: func(t *testing.T) *mocks.WorkspaceStorage {
+				repo := mocks.NewWorkspaceStorage(t)
+				repo.On("Role", mock.Anything, "ws1", "bob").Return(model.RoleOwner, nil).Once()
+				repo.On("Members", mock.Anything, "ws1").Return(map[string]string{"bob": model.RoleOwner}, nil).Once()
+				return repo
+			},
+
+			expectErr: ErrLastOwner,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewWorkspace(tc.setupMock(t))
+
+			err := svc.RemoveMember(context.Background(), "ws1", tc.actorRole, "bob")
+			assert.Equal(t, tc.expectErr, err)
+		})
+	}
+}
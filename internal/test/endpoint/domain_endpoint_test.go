@@ -0,0 +1,162 @@
+package endpoint
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubResolver answers TXT lookups from its records, and with a not found error for any other name.
+type stubResolver map[string][]string
+
+func (r stubResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if records, ok := r[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+// registerDomain registers go.team.io for a new workspace of alice holding the alias launch, and returns the workspace and the verification record value.
+func registerDomain(t *testing.T, app api.Engine) (string, string) {
+	ws := createWorkspace(t, app, "alice")
+	rec := serveAs(app, http.MethodPost, "/v1/workspaces/"+ws+"/links/shorten", "alice",
+		`{"url":"https://example.com","exp":604800,"alias":"launch"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serveAs(app, http.MethodPost, "/v1/workspaces/"+ws+"/domains", "alice", `{"host":"go.team.io"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var d struct {
+		Verification struct {
+			Value string `json:"value"`
+		} `json:"verification"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &d))
+	return ws, d.Verification.Value
+}
+
+func TestDomainEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		publish bool
+		host    string
+
+		expectedVerifyStatus   int
+		expectedRedirectStatus int
+		expectedLocation       string
+	}{
+		{
+			name: "verified domain serves the codes of its workspace",
+
+			publish: true,
+			host:    "go.team.io",
+
+			expectedVerifyStatus:   http.StatusOK,
+			expectedRedirectStatus: http.StatusFound,
+			expectedLocation:       "https://example.com",
+		},
+		{
+			name: "host with a port",
+
+			publish: true,
+			host:    "GO.team.io:8080",
+
+			expectedVerifyStatus:   http.StatusOK,
+			expectedRedirectStatus: http.StatusFound,
+			expectedLocation:       "https://example.com",
+		},
+		{
+			name: "unverified domain",
+
+			host: "go.team.io",
+
+			expectedVerifyStatus:   http.StatusUnprocessableEntity,
+			expectedRedirectStatus: http.StatusNotFound,
+		},
+		{
+			name: "service host",
+
+			publish: true,
+			host:    "example.com",
+
+			expectedVerifyStatus:   http.StatusOK,
+			expectedRedirectStatus: http.StatusNotFound,
+		},
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		panic(err)
+	}
+	trustGateway(cfg)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolver := stubResolver{}
+			app := api.New(cfg, redisPkg.InitMockRedis(t), api.WithTXTResolver(resolver))
+			ws, value := registerDomain(t, app)
+			if tc.publish {
+				resolver["_bookmark-verify.go.team.io"] = []string{value}
+			}
+
+			rec := serveAs(app, http.MethodPost, "/v1/workspaces/"+ws+"/domains/go.team.io/verify", "alice", "")
+			assert.Equal(t, tc.expectedVerifyStatus, rec.Code)
+
+			req := httptest.NewRequest(http.MethodGet, "/launch", nil)
+			req.Host = tc.host
+			rec = httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedRedirectStatus, rec.Code)
+			assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
+		})
+	}
+}
+
+func TestDomainEndpoint_Takeover(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+	resolver := stubResolver{}
+	app := api.New(cfg, redisPkg.InitMockRedis(t), api.WithTXTResolver(resolver))
+
+	// mallory registers the domain of alice first, but cannot publish its record.
+	squatter := createWorkspace(t, app, "mallory")
+	rec := serveAs(app, http.MethodPost, "/v1/workspaces/"+squatter+"/domains", "mallory", `{"host":"go.team.io"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	ws, value := registerDomain(t, app)
+	resolver["_bookmark-verify.go.team.io"] = []string{value}
+
+	rec = serveAs(app, http.MethodPost, "/v1/workspaces/"+squatter+"/domains/go.team.io/verify", "mallory", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	rec = serveAs(app, http.MethodPost, "/v1/workspaces/"+ws+"/domains/go.team.io/verify", "alice", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/launch", nil)
+	req.Host = "go.team.io"
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://example.com", rec.Header().Get("Location"))
+
+	rec = serveAs(app, http.MethodGet, "/v1/workspaces/"+squatter+"/domains", "mallory", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"domains":[]}`, rec.Body.String())
+	rec = serveAs(app, http.MethodPost, "/v1/workspaces/"+squatter+"/domains", "mallory", `{"host":"go.team.io"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
@@ -48,7 +48,7 @@ func TestHealthCheckEndpoint(t *testing.T) {
 		},
 	}
 
-	cfg, err := api.NewConfig()
+	cfg, err := loadConfig()
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,66 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLinkExpiryEndpoint(t *testing.T) {
+	t.Parallel()
+
+	receiver := &webhookReceiver{statuses: []int{http.StatusOK}}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	// Links expire in a week at least, a notice of 8 days makes them due on the first run.
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+	cfg.WebhookPollInterval = 10 * time.Millisecond
+	cfg.LinkExpiryPollInterval = 10 * time.Millisecond
+	cfg.LinkExpiryNotice = 8 * 24 * time.Hour
+	app := api.New(cfg, redisPkg.InitMockRedis(t), api.WithWebhookTransport(http.DefaultTransport))
+
+	createWebhook(t, app, "", "u1", server.URL, `["link.expiring"]`)
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "u1", `{"url":"https://example.com","exp":604800,"alias":"week"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serveAs(app, http.MethodPost, "/v1/links/shorten", "u1", `{"url":"https://example.com/later","exp":1209600,"alias":"fortnight"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serveAs(app, http.MethodGet, "/v1/links?expiring_within=192h", "u1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Links []struct {
+			Code      string    `json:"code"`
+			URL       string    `json:"url"`
+			ExpiresAt time.Time `json:"expiresAt"`
+		} `json:"links"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Links, 1)
+	assert.Equal(t, "week", list.Links[0].Code)
+	assert.Equal(t, "https://example.com", list.Links[0].URL)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), list.Links[0].ExpiresAt, time.Minute)
+
+	rec = serveAs(app, http.MethodGet, "/v1/links?expiring_within=192h", "u2", "")
+	assert.JSONEq(t, `{"links":[]}`, rec.Body.String())
+	rec = serveAs(app, http.MethodGet, "/v1/links?expiring_within=192h", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = serveAs(app, http.MethodGet, "/v1/links?expiring_within=soon", "u1", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(receiver.received()[0].body, &event))
+	assert.Equal(t, "link.expiring", event["type"])
+	assert.Equal(t, "week", event["code"])
+	assert.Equal(t, "https://example.com", event["url"])
+	assert.NotEmpty(t, event["expiresAt"])
+}
@@ -0,0 +1,122 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+// listLinks lists the links at path with query as userID, and returns their codes and the next cursor.
+func listLinks(t *testing.T, app api.Engine, path, userID string, query url.Values) ([]string, string) {
+	rec := serveAs(app, http.MethodGet, path+"?"+query.Encode(), userID, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var page struct {
+		Links []struct {
+			Code string `json:"code"`
+		} `json:"links"`
+		Next string `json:"next"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	codes := []string{}
+	for _, link := range page.Links {
+		codes = append(codes, link.Code)
+	}
+	return codes, page.Next
+}
+
+func TestLinkListEndpoint(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+	for _, body := range []string{
+		`{"url":"https://example.com/a","exp":604800,"alias":"first","tags":["promo"]}`,
+		`{"url":"https://other.com/b","exp":1209600,"alias":"second"}`,
+		`{"url":"https://EXAMPLE.com/c","exp":2419200,"alias":"third","tags":["promo","q3"]}`,
+	} {
+		rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "u1", body)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "u2", `{"url":"https://example.com","exp":604800,"alias":"stranger"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serveAs(app, http.MethodPost, "/v1/links/shorten", "u1", `{"url":"https://example.com","exp":604800,"tags":["Promo"]}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	for _, code := range []string{"second", "second", "first"} {
+		rec = serveAs(app, http.MethodGet, "/"+code, "", "")
+		require.Equal(t, http.StatusFound, rec.Code)
+	}
+
+	// Every link of u1 is walked through, page by page, newest first.
+	var codes []string
+	query := url.Values{"limit": {"2"}}
+	for {
+		page, next := listLinks(t, app, "/v1/links", "u1", query)
+		codes = append(codes, page...)
+		if next == "" {
+			break
+		}
+		query.Set("cursor", next)
+	}
+	assert.Equal(t, []string{"third", "second", "first"}, codes)
+
+	// Clicks are recorded in the background.
+	assert.Eventually(t, func() bool {
+		codes, _ = listLinks(t, app, "/v1/links", "u1", url.Values{"sort": {"clicks"}})
+		return slices.Equal([]string{"second", "first", "third"}, codes)
+	}, 5*time.Second, 10*time.Millisecond)
+	codes, _ = listLinks(t, app, "/v1/links", "u1", url.Values{"sort": {"expiry"}, "host": {"example.com"}})
+	assert.Equal(t, []string{"first", "third"}, codes)
+	codes, _ = listLinks(t, app, "/v1/links", "u1", url.Values{"tag": {"q3"}, "status": {"active"}})
+	assert.Equal(t, []string{"third"}, codes)
+	codes, _ = listLinks(t, app, "/v1/links", "u1", url.Values{"status": {"expired"}})
+	assert.Empty(t, codes)
+
+	rec = serveAs(app, http.MethodGet, "/v1/links?sort=clicks", "u1", "")
+	var page struct {
+		Links []struct {
+			Code   string   `json:"code"`
+			Tags   []string `json:"tags"`
+			Clicks int64    `json:"clicks"`
+			Status string   `json:"status"`
+		} `json:"links"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, int64(2), page.Links[0].Clicks)
+	assert.Equal(t, "active", page.Links[0].Status)
+	assert.Equal(t, []string{}, page.Links[0].Tags)
+
+	rec = serveAs(app, http.MethodGet, "/v1/links?sort=clicks&cursor="+query.Get("cursor"), "u1", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "a cursor only resumes its own sort")
+	rec = serveAs(app, http.MethodGet, "/v1/links?limit=101", "u1", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestLinkListEndpoint_Workspace(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+	workspace := createWorkspace(t, app, "owner")
+	rec := serveAs(app, http.MethodPost, "/v1/workspaces/"+workspace+"/links/shorten", "owner", `{"url":"https://example.com","exp":604800,"alias":"team"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	codes, _ := listLinks(t, app, "/v1/workspaces/"+workspace+"/links", "owner", url.Values{})
+	assert.Equal(t, []string{"team"}, codes)
+	codes, _ = listLinks(t, app, "/v1/links", "owner", url.Values{})
+	assert.Empty(t, codes, "workspace links are not listed among the global links of their creator")
+
+	rec = serveAs(app, http.MethodGet, "/v1/workspaces/"+workspace+"/links", "stranger", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,63 @@
+package endpoint
+
+import (
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLinkParamsEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		body string
+		path string
+
+		expectedLocation string
+	}{
+		{
+			name: "params merged into the destination",
+
+			body: `{"url":"https://example.com/spring?utm_source=site#top","exp":604800,"alias":"spring",` +
+				`"params":{"utm_source":"newsletter","utm_campaign":"spring sale"}}`,
+			path: "/spring?utm_source=ads",
+
+			expectedLocation: "https://example.com/spring?utm_campaign=spring+sale&utm_source=newsletter#top",
+		},
+		{
+			name: "query of the request passed through",
+
+			body: `{"url":"https://example.com/spring","exp":604800,"alias":"spring",` +
+				`"params":{"utm_source":"newsletter"},"passQuery":true}`,
+			path: "/v1/links/redirect/spring?utm_source=ads&gclid=a%2Fb",
+
+			expectedLocation: "https://example.com/spring?utm_source=ads&gclid=a%2Fb",
+		},
+	}
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := api.New(cfg, redisPkg.InitMockRedis(t))
+			rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "", tc.body)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			rec = httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, http.StatusFound, rec.Code)
+			assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package endpoint
+
+import (
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLinkPreviewEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		path string
+
+		expectedStatus   int
+		expectedLocation string
+		expectedBody     string
+	}{
+		{
+			name: "redirect with the status of the link",
+
+			path: "/launch2025",
+
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://example.com/spring",
+		},
+		{
+			name: "plus suffix",
+
+			path: "/launch2025+",
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   "<h1>Spring launch</h1>",
+		},
+		{
+			name: "preview query on the long path",
+
+			path: "/v1/links/redirect/launch2025?preview=1",
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   `<p class="destination">https://example.com/spring</p>`,
+		},
+		{
+			name: "preview of an unknown code",
+
+			path: "/unknown+",
+
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"url not found"}`,
+		},
+	}
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := api.New(cfg, redisPkg.InitMockRedis(t))
+			rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "",
+				`{"url":"https://example.com/spring","exp":604800,"alias":"launch2025","redirectStatus":301,"title":"Spring launch"}`)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			rec = httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestLinkPreviewEndpoint_InvalidRedirectStatus(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "", `{"url":"https://example.com","exp":604800,"redirectStatus":303}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"message":"Invalid redirect status"}`, rec.Body.String())
+}
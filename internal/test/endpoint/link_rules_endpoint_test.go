@@ -0,0 +1,115 @@
+package endpoint
+
+import (
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLinkRulesEndpoint(t *testing.T) {
+	t.Parallel()
+
+	// The GeoIP fixture maps 203.0.113.0/24 to VN and 198.51.100.0/24 to US.
+	const body = `{"url":"https://example.com","exp":604800,"alias":"app","params":{"utm_source":"qr"},"rules":[` +
+		`{"url":"https://apps.apple.com/app/id1","platforms":["ios"]},` +
+		`{"url":"https://play.google.com/store/apps/details?id=app","platforms":["android"]},` +
+		`{"url":"https://example.com/vi","countries":["VN"]},` +
+		`{"url":"https://example.com/fr","languages":["fr"]}]}`
+
+	testCases := []struct {
+		name string
+
+		userAgent      string
+		acceptLanguage string
+		remoteAddr     string
+
+		expectedLocation string
+	}{
+		{
+			name: "ios",
+
+			userAgent:  "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)",
+			remoteAddr: "203.0.113.7:1234",
+
+			expectedLocation: "https://apps.apple.com/app/id1?utm_source=qr",
+		},
+		{
+			name: "android",
+
+			userAgent:  "Mozilla/5.0 (Linux; Android 14; Pixel 8)",
+			remoteAddr: "198.51.100.1:1234",
+
+			expectedLocation: "https://play.google.com/store/apps/details?id=app&utm_source=qr",
+		},
+		{
+			name: "country",
+
+			userAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+			acceptLanguage: "fr-FR",
+			remoteAddr:     "203.0.113.7:1234",
+
+			expectedLocation: "https://example.com/vi?utm_source=qr",
+		},
+		{
+			name: "language",
+
+			userAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+			acceptLanguage: "fr-FR",
+			remoteAddr:     "198.51.100.1:1234",
+
+			expectedLocation: "https://example.com/fr?utm_source=qr",
+		},
+		{
+			name: "fallback",
+
+			userAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+			remoteAddr: "198.51.100.1:1234",
+
+			expectedLocation: "https://example.com?utm_source=qr",
+		},
+	}
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+	cfg.GeoIPDatabase = "../../../pkg/geoip/testdata/country-test.mmdb"
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := api.New(cfg, redisPkg.InitMockRedis(t))
+			rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "", body)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			req := httptest.NewRequest(http.MethodGet, "/app", nil)
+			req.Header.Set("User-Agent", tc.userAgent)
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+			req.RemoteAddr = tc.remoteAddr
+			rec = httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusFound, rec.Code)
+			assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
+		})
+	}
+}
+
+func TestLinkRulesEndpoint_InvalidRules(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "",
+		`{"url":"https://example.com","exp":604800,"rules":[{"url":"https://example.com/night","time":{"from":"22:00","to":"06:00","timezone":"Mars/Olympus"}}]}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"message":"Invalid rules"}`, rec.Body.String())
+}
@@ -0,0 +1,139 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// listTrash returns the codes of the links in the trash at path, as userID.
+func listTrash(t *testing.T, app api.Engine, path, userID string) []string {
+	rec := serveAs(app, http.MethodGet, path, userID, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var trash struct {
+		Links []struct {
+			Code      string    `json:"code"`
+			DeletedAt time.Time `json:"deletedAt"`
+		} `json:"links"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &trash))
+	var codes []string
+	for _, link := range trash.Links {
+		assert.False(t, link.DeletedAt.IsZero())
+		codes = append(codes, link.Code)
+	}
+	return codes
+}
+
+func TestLinkTrashEndpoint(t *testing.T) {
+	t.Parallel()
+
+	receiver := &webhookReceiver{statuses: []int{http.StatusOK}}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	app := newWebhookApp(t)
+	createWebhook(t, app, "", "u1", server.URL, `["link.deleted"]`)
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "u1", `{"url":"https://example.com","exp":604800,"alias":"launch"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serveAs(app, http.MethodDelete, "/v1/links/launch", "u2", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "only the owner deletes a global link")
+	rec = serveAs(app, http.MethodDelete, "/v1/links/launch", "u1", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = serveAs(app, http.MethodDelete, "/v1/links/launch", "u1", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serveAs(app, http.MethodGet, "/v1/links/redirect/launch", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	codes, _ := listLinks(t, app, "/v1/links", "u1", url.Values{})
+	assert.Empty(t, codes)
+	codes, _ = listLinks(t, app, "/v1/links", "u1", url.Values{"status": {"revoked"}})
+	assert.Equal(t, []string{"launch"}, codes, "deleted links are listed as revoked")
+	assert.Equal(t, []string{"launch"}, listTrash(t, app, "/v1/trash", "u1"))
+	assert.Empty(t, listTrash(t, app, "/v1/trash", "u2"))
+	rec = serveAs(app, http.MethodPost, "/v1/links/shorten", "u2", `{"url":"https://example.org","exp":604800,"alias":"launch"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code, "the code of a trashed link stays taken")
+
+	rec = serveAs(app, http.MethodPost, "/v1/trash/launch/restore", "u2", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serveAs(app, http.MethodPost, "/v1/trash/launch/restore", "u1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serveAs(app, http.MethodGet, "/v1/links/redirect/launch", "", "")
+	assert.Equal(t, http.StatusFound, rec.Code)
+	codes, _ = listLinks(t, app, "/v1/links", "u1", url.Values{})
+	assert.Equal(t, []string{"launch"}, codes)
+	assert.Empty(t, listTrash(t, app, "/v1/trash", "u1"))
+
+	require.Equal(t, http.StatusNoContent, serveAs(app, http.MethodDelete, "/v1/links/launch", "u1", "").Code)
+	rec = serveAs(app, http.MethodDelete, "/v1/trash/launch", "u1", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = serveAs(app, http.MethodPost, "/v1/trash/launch/restore", "u1", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serveAs(app, http.MethodPost, "/v1/links/shorten", "u2", `{"url":"https://example.org","exp":604800,"alias":"launch"}`)
+	assert.Equal(t, http.StatusOK, rec.Code, "the code of a purged link is free")
+
+	require.Eventually(t, func() bool { return len(receiver.received()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(receiver.received()[0].body, &event))
+	assert.Equal(t, "link.deleted", event["type"])
+	assert.Equal(t, "launch", event["code"])
+	assert.Equal(t, "https://example.com", event["url"])
+}
+
+func TestLinkTrashEndpoint_Workspace(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+	workspace := createWorkspace(t, app, "owner")
+	rec := serveAs(app, http.MethodPut, "/v1/workspaces/"+workspace+"/members/bob", "owner", `{"role":"viewer"}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = serveAs(app, http.MethodPost, "/v1/workspaces/"+workspace+"/links/shorten", "owner", `{"url":"https://example.com","exp":604800,"alias":"team"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serveAs(app, http.MethodDelete, "/v1/workspaces/"+workspace+"/links/team", "stranger", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serveAs(app, http.MethodDelete, "/v1/workspaces/"+workspace+"/links/team", "bob", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = serveAs(app, http.MethodDelete, "/v1/workspaces/"+workspace+"/links/team", "owner", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	assert.Equal(t, []string{"team"}, listTrash(t, app, "/v1/workspaces/"+workspace+"/trash", "bob"))
+	assert.Empty(t, listTrash(t, app, "/v1/trash", "owner"), "workspace links are not in the trash of their creator")
+	rec = serveAs(app, http.MethodDelete, "/v1/workspaces/"+workspace+"/trash/team", "bob", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = serveAs(app, http.MethodPost, "/v1/workspaces/"+workspace+"/trash/team/restore", "owner", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serveAs(app, http.MethodGet, "/v1/workspaces/"+workspace+"/links/redirect/team", "", "")
+	assert.Equal(t, http.StatusFound, rec.Code)
+}
+
+func TestLinkTrashEndpoint_Purger(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+	cfg.TrashRetention = 0
+	cfg.TrashPurgeInterval = 10 * time.Millisecond
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "u1", `{"url":"https://example.com","exp":604800,"alias":"launch"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, http.StatusNoContent, serveAs(app, http.MethodDelete, "/v1/links/launch", "u1", "").Code)
+
+	require.Eventually(t, func() bool {
+		return serveAs(app, http.MethodPost, "/v1/links/shorten", "u2", `{"url":"https://example.org","exp":604800,"alias":"launch"}`).Code == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond, "the code of a purged link is free")
+	assert.Empty(t, listTrash(t, app, "/v1/trash", "u1"))
+}
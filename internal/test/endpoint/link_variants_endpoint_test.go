@@ -0,0 +1,109 @@
+package endpoint
+
+import (
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkVariantsEndpoint(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "",
+		`{"url":"https://example.com","exp":604800,"alias":"split","variants":[`+
+			`{"name":"a","url":"https://example.com/a","weight":70},{"name":"b","url":"https://example.com/b","weight":30}]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	served := map[string]int{}
+	for i := 0; i < 20; i++ {
+		rec = httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/split", nil))
+		require.Equal(t, http.StatusFound, rec.Code)
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		location := rec.Header().Get("Location")
+		served[location]++
+
+		// The visitor keeps the variant first assigned to them, and gets no new cookie.
+		for j := 0; j < 3; j++ {
+			req := httptest.NewRequest(http.MethodGet, "/v1/links/redirect/split", nil)
+			req.AddCookie(cookies[0])
+			rec = httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			assert.Equal(t, location, rec.Header().Get("Location"))
+			assert.Empty(t, rec.Result().Cookies())
+			served[location]++
+		}
+	}
+	assert.Len(t, served, 2, "20 visitors should see both variants")
+
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/split+", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Clicks are recorded in the background.
+	require.Eventually(t, func() bool {
+		rec = serveAs(app, http.MethodGet, "/v1/links/clicks/split", "", "")
+		return rec.Code == http.StatusOK && strings.HasPrefix(rec.Body.String(), `{"clicks":80,`)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.JSONEq(t,
+		`{"clicks":80,"variants":{"a":`+strconv.Itoa(served["https://example.com/a"])+`,"b":`+strconv.Itoa(served["https://example.com/b"])+`}}`,
+		rec.Body.String())
+}
+
+func TestLinkVariantsEndpoint_InvalidVariants(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "",
+		`{"url":"https://example.com","exp":604800,"variants":[{"name":"a","url":"https://example.com/a","weight":100}]}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"message":"Invalid variants"}`, rec.Body.String())
+}
+
+func TestLinkClicksEndpoint_NotFound(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+	rec := serveAs(app, http.MethodGet, "/v1/links/clicks/missing", "", "")
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestLinkClicksEndpoint_Owner(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "alice", `{"url":"https://example.com","exp":604800,"alias":"mine"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	for userID, expected := range map[string]int{"alice": http.StatusOK, "bob": http.StatusNotFound, "": http.StatusNotFound} {
+		rec = serveAs(app, http.MethodGet, "/v1/links/clicks/mine", userID, "")
+		assert.Equal(t, expected, rec.Code, userID)
+	}
+}
@@ -0,0 +1,67 @@
+package endpoint
+
+import (
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupTestHTTP func(api api.Engine) *httptest.ResponseRecorder
+
+		expectedStatus  int
+		expectedMetrics []string
+	}{
+		{
+			name: "counts previous requests",
+
+			setupTestHTTP: func(api api.Engine) *httptest.ResponseRecorder {
+				api.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health-check", nil))
+				api.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/links/redirect/notfound", nil))
+
+				req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+				respRec := httptest.NewRecorder()
+				api.ServeHTTP(respRec, req)
+				return respRec
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedMetrics: []string{
+				`bookmark_http_requests_total{method="GET",route="/health-check",status="200"} 1`,
+				`bookmark_http_requests_total{method="GET",route="/v1/links/redirect/:code",status="404"} 1`,
+				`bookmark_redirects_total{result="not_found"} 1`,
+				`bookmark_health_check_up 1`,
+				// The link is read in one pipeline, then the code is looked up under the legacy bare code key in another.
+				`bookmark_redis_command_duration_seconds_count{command="pipeline",status="ok"} 2`,
+				`bookmark_keyspace_code_length 7`,
+			},
+		},
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := api.New(cfg, redisPkg.InitMockRedis(t))
+			rec := tc.setupTestHTTP(app)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			for _, expected := range tc.expectedMetrics {
+				assert.Contains(t, rec.Body.String(), expected)
+			}
+		})
+	}
+}
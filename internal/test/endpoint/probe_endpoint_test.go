@@ -0,0 +1,119 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbeEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		path        string
+		setupClient func(client *redis.Client)
+
+		expectedStatus int
+		expectedBody   map[string]any
+		expectedChecks map[string]string
+	}{
+		{
+			name: "livez",
+
+			path: "/livez",
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]any{"status": "ok"},
+		},
+		{
+			name: "livez ignores redis",
+
+			path: "/livez",
+			setupClient: func(client *redis.Client) {
+				_ = client.Close()
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]any{"status": "ok"},
+		},
+		{
+			name: "readyz",
+
+			path: "/readyz",
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]any{"status": "ok"},
+		},
+		{
+			name: "readyz verbose",
+
+			path: "/readyz?verbose",
+
+			expectedStatus: http.StatusOK,
+			expectedChecks: map[string]string{"redis": "ok", "storage": "ok", "workers": "ok"},
+		},
+		{
+			name: "readyz fails without redis",
+
+			path: "/readyz?verbose=1",
+			setupClient: func(client *redis.Client) {
+				_ = client.Close()
+			},
+
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedChecks: map[string]string{"redis": "fail", "storage": "fail", "workers": "ok"},
+		},
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := redisPkg.InitMockRedis(t)
+			app := api.New(cfg, client)
+			if tc.setupClient != nil {
+				tc.setupClient(client)
+			}
+
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+
+			var resp struct {
+				Status string `json:"status"`
+				Checks []struct {
+					Name   string `json:"name"`
+					Status string `json:"status"`
+				} `json:"checks"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+			if tc.expectedBody != nil {
+				var body map[string]any
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, tc.expectedBody, body)
+			}
+			if tc.expectedChecks != nil {
+				checks := make(map[string]string, len(resp.Checks))
+				for _, check := range resp.Checks {
+					checks[check.Name] = check.Status
+				}
+				assert.Equal(t, tc.expectedChecks, checks)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package endpoint
+
+import (
+	"bytes"
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQRCodeEndpoint(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+	cfg.PublicURL = "https://sho.rt"
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "", `{"url":"https://example.com","exp":604800,"alias":"poster"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serveAs(app, http.MethodGet, "/v1/links/poster/qr?size=300", "", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	req := httptest.NewRequest(http.MethodGet, "/v1/links/poster/qr?size=300", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = serveAs(app, http.MethodGet, "/v1/links/poster/qr?format=svg&ecc=H", "", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/svg+xml", rec.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "<svg"))
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+
+	rec = serveAs(app, http.MethodGet, "/v1/links/missing/qr", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serveAs(app, http.MethodGet, "/v1/links/poster/qr?format=gif", "", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
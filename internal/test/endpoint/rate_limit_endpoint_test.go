@@ -0,0 +1,120 @@
+package endpoint
+
+import (
+	"github.com/lhducc/bookmark-management/internal/api"
+	"github.com/lhducc/bookmark-management/internal/model"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimitEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupTestHTTP func(api api.Engine) *httptest.ResponseRecorder
+
+		expectedStatus    int
+		expectedRemaining string
+		expectedRetry     string
+	}{
+		{
+			name: "within the limit",
+
+			setupTestHTTP: func(api api.Engine) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, "/v1/links/redirect/notfound", nil)
+				respRec := httptest.NewRecorder()
+				api.ServeHTTP(respRec, req)
+				return respRec
+			},
+
+			expectedStatus:    http.StatusNotFound,
+			expectedRemaining: "1",
+		},
+		{
+			name: "over the limit",
+
+			setupTestHTTP: func(api api.Engine) *httptest.ResponseRecorder {
+				for i := 0; i < 2; i++ {
+					api.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/links/redirect/notfound", nil))
+				}
+
+				req := httptest.NewRequest(http.MethodGet, "/v1/links/redirect/notfound", nil)
+				respRec := httptest.NewRecorder()
+				api.ServeHTTP(respRec, req)
+				return respRec
+			},
+
+			expectedStatus:    http.StatusTooManyRequests,
+			expectedRemaining: "0",
+			expectedRetry:     "1800",
+		},
+		{
+			name: "clients are limited separately",
+
+			setupTestHTTP: func(api api.Engine) *httptest.ResponseRecorder {
+				for i := 0; i < 2; i++ {
+					api.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/links/redirect/notfound", nil))
+				}
+
+				req := httptest.NewRequest(http.MethodGet, "/v1/links/redirect/notfound", nil)
+				req.RemoteAddr = "198.51.100.7:1234"
+				respRec := httptest.NewRecorder()
+				api.ServeHTTP(respRec, req)
+				return respRec
+			},
+
+			expectedStatus:    http.StatusNotFound,
+			expectedRemaining: "1",
+		},
+		{
+			name: "user header from a client is ignored",
+
+			setupTestHTTP: func(api api.Engine) *httptest.ResponseRecorder {
+				for _, userID := range []string{"alice", "bob"} {
+					req := httptest.NewRequest(http.MethodGet, "/v1/links/redirect/notfound", nil)
+					req.Header.Set("X-User-ID", userID)
+					api.ServeHTTP(httptest.NewRecorder(), req)
+				}
+
+				req := httptest.NewRequest(http.MethodGet, "/v1/links/redirect/notfound", nil)
+				req.Header.Set("X-User-ID", "carol")
+				respRec := httptest.NewRecorder()
+				api.ServeHTTP(respRec, req)
+				return respRec
+			},
+
+			expectedStatus:    http.StatusTooManyRequests,
+			expectedRemaining: "0",
+			expectedRetry:     "1800",
+		},
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		panic(err)
+	}
+	cfg.RateLimitRedirectIP = model.RateLimit{Limit: 2, Period: time.Hour}
+	// Only the gateway at 10.0.0.1 sets the user, httptest requests come from 192.0.2.1.
+	cfg.AuthUserHeader = "X-User-ID"
+	cfg.TrustedProxies = []string{"10.0.0.1"}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := api.New(cfg, redisPkg.InitMockRedis(t))
+			rec := tc.setupTestHTTP(app)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+			assert.Equal(t, tc.expectedRemaining, rec.Header().Get("RateLimit-Remaining"))
+			assert.Equal(t, tc.expectedRetry, rec.Header().Get("Retry-After"))
+		})
+	}
+}
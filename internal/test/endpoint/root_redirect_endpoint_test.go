@@ -0,0 +1,107 @@
+package endpoint
+
+import (
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRootRedirectEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		rootRedirect bool
+		path         string
+
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{
+			name: "root path",
+
+			rootRedirect: true,
+			path:         "/launch2025",
+
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://example.com",
+		},
+		{
+			name: "long path",
+
+			rootRedirect: true,
+			path:         "/v1/links/redirect/launch2025",
+
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://example.com",
+		},
+		{
+			name: "system route is not shadowed",
+
+			rootRedirect: true,
+			path:         "/health-check",
+
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "root path disabled",
+
+			path: "/launch2025",
+
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "long path with root path disabled",
+
+			path: "/v1/links/redirect/launch2025",
+
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := loadConfig()
+			if err != nil {
+				panic(err)
+			}
+			trustGateway(cfg)
+			cfg.RootRedirect = tc.rootRedirect
+
+			app := api.New(cfg, redisPkg.InitMockRedis(t))
+			rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "", `{"url":"https://example.com","exp":604800,"alias":"launch2025"}`)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			rec = httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRootRedirectEndpoint_ReservedAlias(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		panic(err)
+	}
+	trustGateway(cfg)
+	cfg.ReservedAliases = []string{"pricing"}
+
+	app := api.New(cfg, redisPkg.InitMockRedis(t))
+	for _, alias := range []string{"swagger", "health-check", "pricing"} {
+		rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "", `{"url":"https://example.com","exp":604800,"alias":"`+alias+`"}`)
+		assert.Equal(t, http.StatusConflict, rec.Code, alias)
+		assert.JSONEq(t, `{"message":"alias is reserved"}`, rec.Body.String(), alias)
+	}
+}
@@ -67,7 +67,7 @@ func TestUrlShortenEndpoint(t *testing.T) {
 		},
 	}
 
-	cfg, err := api.NewConfig()
+	cfg, err := loadConfig()
 	if err != nil {
 		panic(err)
 	}
@@ -147,7 +147,7 @@ func TestUrlRedirectEndpoint(t *testing.T) {
 		},
 	}
 
-	cfg, err := api.NewConfig()
+	cfg, err := loadConfig()
 	if err != nil {
 		panic(err)
 	}
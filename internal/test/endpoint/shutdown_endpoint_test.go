@@ -0,0 +1,78 @@
+package endpoint
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShutdownEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		drainDelay time.Duration
+
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name: "health reports draining during the drain delay",
+
+			drainDelay: time.Second,
+
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedMessage: "DRAINING",
+		},
+		{
+			name: "health keeps reporting draining after shutdown",
+
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedMessage: "DRAINING",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := loadConfig()
+			require.NoError(t, err)
+			cfg.ShutdownDrainDelay = tc.drainDelay
+
+			redisClient := redisPkg.InitMockRedis(t)
+			app := api.New(cfg, redisClient)
+
+			shutdownErr := make(chan error, 1)
+			go func() {
+				shutdownErr <- app.Shutdown(context.Background())
+			}()
+			if tc.drainDelay == 0 {
+				require.NoError(t, <-shutdownErr)
+			}
+
+			assert.Eventually(t, func() bool {
+				rec := httptest.NewRecorder()
+				app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health-check", nil))
+
+				var resp map[string]string
+				_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+				return rec.Code == tc.expectedStatus && resp["message"] == tc.expectedMessage
+			}, tc.drainDelay+time.Second, 10*time.Millisecond)
+
+			if tc.drainDelay > 0 {
+				require.NoError(t, <-shutdownErr)
+			}
+			assert.ErrorIs(t, redisClient.Ping(context.Background()).Err(), redis.ErrClosed)
+		})
+	}
+}
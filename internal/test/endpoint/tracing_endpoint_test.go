@@ -0,0 +1,47 @@
+package endpoint
+
+import (
+	"context"
+	"github.com/lhducc/bookmark-management/internal/api"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/lhducc/bookmark-management/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestTracingEndpoint checks that a redirect produces one trace spanning gin, the service and redis,
+// continuing the trace of the incoming traceparent header.
+// It installs a global tracer provider, so it does not run in parallel.
+func TestTracingEndpoint(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	exporter := tracing.InitMockTracing(t)
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+
+	redisClient := redisPkg.InitMockRedis(t)
+	require.NoError(t, redisClient.Set(context.Background(), "1234567", "https://google.com", time.Minute).Err())
+	app := api.New(cfg, redisClient)
+	exporter.Reset()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/links/redirect/1234567", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusFound, rec.Code)
+
+	spans := map[string]string{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span.SpanContext.TraceID().String()
+	}
+
+	for _, name := range []string{"GET /v1/links/redirect/:code", "ShortenUrl.GetLink", "redis.pipeline get pttl hgetall"} {
+		assert.Equal(t, traceID, spans[name], name)
+	}
+}
@@ -0,0 +1,181 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"github.com/lhducc/bookmark-management/internal/api"
+	"github.com/lhducc/bookmark-management/internal/service"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver records the webhook requests and answers them with the next of statuses, the last one repeating.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []webhookRequest
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, webhookRequest{header: req.Header.Clone(), body: body})
+	status := r.statuses[min(len(r.requests), len(r.statuses))-1]
+	w.WriteHeader(status)
+}
+
+func (r *webhookReceiver) received() []webhookRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]webhookRequest(nil), r.requests...)
+}
+
+// newWebhookApp returns an api polling the webhook queue every 10ms, and retrying failed deliveries after 10ms once.
+// Its webhooks may target the loopback receivers of the tests.
+func newWebhookApp(t *testing.T) api.Engine {
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	trustGateway(cfg)
+	cfg.WebhookPollInterval = 10 * time.Millisecond
+	cfg.WebhookRetryBase = 10 * time.Millisecond
+	cfg.WebhookRetryMax = 10 * time.Millisecond
+	cfg.WebhookMaxAttempts = 2
+	return api.New(cfg, redisPkg.InitMockRedis(t), api.WithWebhookTransport(http.DefaultTransport))
+}
+
+// createWebhook subscribes url to events for userID, or for workspace when it is not empty, and returns its ID and secret.
+func createWebhook(t *testing.T, app api.Engine, workspace, userID, url, events string) (string, string) {
+	path := "/v1/webhooks"
+	if workspace != "" {
+		path = "/v1/workspaces/" + workspace + "/webhooks"
+	}
+	rec := serveAs(app, http.MethodPost, path, userID, `{"url":"`+url+`","events":`+events+`}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var hook struct {
+		ID     string `json:"id"`
+		Secret string `json:"secret"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &hook))
+	require.NotEmpty(t, hook.Secret)
+	return hook.ID, hook.Secret
+}
+
+func TestWebhookEndpoint(t *testing.T) {
+	t.Parallel()
+
+	receiver := &webhookReceiver{statuses: []int{http.StatusOK}}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	app := newWebhookApp(t)
+
+	hookID, secret := createWebhook(t, app, "", "u1", server.URL, `["link.created","link.clicked"]`)
+
+	rec := serveAs(app, http.MethodPost, "/v1/links/shorten", "u1", `{"url":"https://example.com","exp":604800,"alias":"hooked"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serveAs(app, http.MethodGet, "/hooked", "", "")
+	require.Equal(t, http.StatusFound, rec.Code)
+	// Links created by another user, or anonymously, are not delivered to the webhooks of u1.
+	rec = serveAs(app, http.MethodPost, "/v1/links/shorten", "u2", `{"url":"https://example.com","exp":604800}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.Eventually(t, func() bool { return len(receiver.received()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	events := map[string]map[string]any{}
+	for _, req := range receiver.received() {
+		timestamp, err := strconv.ParseInt(req.header.Get(service.WebhookTimestampHeader), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, service.SignWebhookPayload(secret, timestamp, req.body), req.header.Get(service.WebhookSignatureHeader))
+		assert.Equal(t, hookID, req.header.Get(service.WebhookIDHeader))
+
+		var event map[string]any
+		require.NoError(t, json.Unmarshal(req.body, &event))
+		assert.Equal(t, req.header.Get(service.WebhookEventHeader), event["type"])
+		events[event["type"].(string)] = event
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, "hooked", events["link.created"]["code"])
+	assert.Equal(t, "u1", events["link.created"]["owner"])
+	assert.Equal(t, "https://example.com", events["link.clicked"]["url"])
+
+	require.Eventually(t, func() bool {
+		rec = serveAs(app, http.MethodGet, "/v1/webhooks/"+hookID+"/deliveries", "u1", "")
+		return rec.Code == http.StatusOK && countDeliveries(t, rec.Body.Bytes(), "delivered") == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	rec = serveAs(app, http.MethodGet, "/v1/webhooks/"+hookID+"/deliveries", "u2", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serveAs(app, http.MethodGet, "/v1/webhooks", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = serveAs(app, http.MethodDelete, "/v1/webhooks/"+hookID, "u1", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = serveAs(app, http.MethodGet, "/v1/webhooks", "u1", "")
+	assert.JSONEq(t, `{"webhooks":[]}`, rec.Body.String())
+}
+
+func TestWebhookEndpoint_Dead(t *testing.T) {
+	t.Parallel()
+
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	app := newWebhookApp(t)
+
+	workspace := createWorkspace(t, app, "owner")
+	rec := serveAs(app, http.MethodPost, "/v1/workspaces/"+workspace+"/webhooks", "stranger", `{"url":"`+server.URL+`","events":["link.created"]}`)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	hookID, _ := createWebhook(t, app, workspace, "owner", server.URL, `["link.created"]`)
+
+	rec = serveAs(app, http.MethodPost, "/v1/workspaces/"+workspace+"/links/shorten", "owner", `{"url":"https://example.com","exp":604800}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	path := "/v1/workspaces/" + workspace + "/webhooks/" + hookID + "/deliveries"
+	require.Eventually(t, func() bool {
+		rec = serveAs(app, http.MethodGet, path, "owner", "")
+		return rec.Code == http.StatusOK && countDeliveries(t, rec.Body.Bytes(), "dead") == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var log struct {
+		Deliveries []struct {
+			Attempts   int    `json:"attempts"`
+			LastStatus int    `json:"lastStatus"`
+			LastError  string `json:"lastError"`
+		} `json:"deliveries"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &log))
+	assert.Equal(t, 2, log.Deliveries[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, log.Deliveries[0].LastStatus)
+	assert.Equal(t, "unexpected status 500", log.Deliveries[0].LastError)
+	assert.Len(t, receiver.received(), 2)
+}
+
+// countDeliveries returns the number of deliveries in status in the delivery log body.
+func countDeliveries(t *testing.T, body []byte, status string) int {
+	var log struct {
+		Deliveries []struct {
+			Status string `json:"status"`
+		} `json:"deliveries"`
+	}
+	require.NoError(t, json.Unmarshal(body, &log))
+	n := 0
+	for _, d := range log.Deliveries {
+		if d.Status == status {
+			n++
+		}
+	}
+	return n
+}
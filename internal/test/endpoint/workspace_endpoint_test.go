@@ -0,0 +1,190 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"github.com/lhducc/bookmark-management/internal/api"
+	"github.com/lhducc/bookmark-management/internal/config"
+	redisPkg "github.com/lhducc/bookmark-management/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// loadConfig returns the API section of the config loaded from the environment, as the service does without a config file.
+func loadConfig() (*api.Config, error) {
+	conf, err := config.Load("")
+	if err != nil {
+		return nil, err
+	}
+	return &conf.API, nil
+}
+
+// trustGateway makes the requests of the tests play the gateway setting the user ID, httptest requests come from 192.0.2.1.
+func trustGateway(cfg *api.Config) {
+	cfg.AuthUserHeader = "X-User-ID"
+	cfg.TrustedProxies = []string{"192.0.2.1"}
+}
+
+// serveAs serves a request with body on behalf of userID, an empty userID is anonymous.
+func serveAs(app api.Engine, method, path, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	return rec
+}
+
+// createWorkspace creates a workspace owned by userID and returns its ID.
+func createWorkspace(t *testing.T, app api.Engine, userID string) string {
+	rec := serveAs(app, http.MethodPost, "/v1/workspaces", userID, `{"name":"Team"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var ws struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &ws))
+	return ws.ID
+}
+
+func TestWorkspaceEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupTestHTTP func(t *testing.T, app api.Engine) *httptest.ResponseRecorder
+
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{
+			name: "owner shortens with an alias, anyone follows it",
+
+			setupTestHTTP: func(t *testing.T, app api.Engine) *httptest.ResponseRecorder {
+				ws := createWorkspace(t, app, "alice")
+				rec := serveAs(app, http.MethodPost, "/v1/workspaces/"+ws+"/links/shorten", "alice",
+					`{"url":"https://example.com","exp":604800,"alias":"launch"}`)
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				return serveAs(app, http.MethodGet, "/v1/workspaces/"+ws+"/links/redirect/launch", "", "")
+			},
+
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://example.com",
+		},
+		{
+			name: "same alias in two workspaces",
+
+			setupTestHTTP: func(t *testing.T, app api.Engine) *httptest.ResponseRecorder {
+				wsA := createWorkspace(t, app, "alice")
+				wsB := createWorkspace(t, app, "bob")
+				rec := serveAs(app, http.MethodPost, "/v1/workspaces/"+wsA+"/links/shorten", "alice",
+					`{"url":"https://a.example.com","exp":604800,"alias":"launch"}`)
+				require.Equal(t, http.StatusOK, rec.Code)
+				rec = serveAs(app, http.MethodPost, "/v1/workspaces/"+wsB+"/links/shorten", "bob",
+					`{"url":"https://b.example.com","exp":604800,"alias":"launch"}`)
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				return serveAs(app, http.MethodGet, "/v1/workspaces/"+wsB+"/links/redirect/launch", "", "")
+			},
+
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://b.example.com",
+		},
+		{
+			name: "alias taken in the workspace",
+
+			setupTestHTTP: func(t *testing.T, app api.Engine) *httptest.ResponseRecorder {
+				ws := createWorkspace(t, app, "alice")
+				body := `{"url":"https://example.com","exp":604800,"alias":"launch"}`
+				rec := serveAs(app, http.MethodPost, "/v1/workspaces/"+ws+"/links/shorten", "alice", body)
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				return serveAs(app, http.MethodPost, "/v1/workspaces/"+ws+"/links/shorten", "alice", body)
+			},
+
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"message":"alias already taken"}`,
+		},
+		{
+			name: "viewer cannot shorten",
+
+			setupTestHTTP: func(t *testing.T, app api.Engine) *httptest.ResponseRecorder {
+				ws := createWorkspace(t, app, "alice")
+				rec := serveAs(app, http.MethodPut, "/v1/workspaces/"+ws+"/members/bob", "alice", `{"role":"viewer"}`)
+				require.Equal(t, http.StatusNoContent, rec.Code)
+
+				return serveAs(app, http.MethodPost, "/v1/workspaces/"+ws+"/links/shorten", "bob",
+					`{"url":"https://example.com","exp":604800}`)
+			},
+
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"message":"insufficient role"}`,
+		},
+		{
+			name: "non-member cannot see the workspace",
+
+			setupTestHTTP: func(t *testing.T, app api.Engine) *httptest.ResponseRecorder {
+				ws := createWorkspace(t, app, "alice")
+
+				return serveAs(app, http.MethodGet, "/v1/workspaces/"+ws+"/members", "mallory", "")
+			},
+
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"workspace not found"}`,
+		},
+		{
+			name: "members are listed",
+
+			setupTestHTTP: func(t *testing.T, app api.Engine) *httptest.ResponseRecorder {
+				ws := createWorkspace(t, app, "alice")
+				rec := serveAs(app, http.MethodPut, "/v1/workspaces/"+ws+"/members/bob", "alice", `{"role":"member"}`)
+				require.Equal(t, http.StatusNoContent, rec.Code)
+
+				return serveAs(app, http.MethodGet, "/v1/workspaces/"+ws+"/members", "bob", "")
+			},
+
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"members":[{"userId":"alice","role":"owner"},{"userId":"bob","role":"member"}]}`,
+		},
+		{
+			name: "anonymous cannot create a workspace",
+
+			setupTestHTTP: func(t *testing.T, app api.Engine) *httptest.ResponseRecorder {
+				return serveAs(app, http.MethodPost, "/v1/workspaces", "", `{"name":"Team"}`)
+			},
+
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"message":"authentication required"}`,
+		},
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		panic(err)
+	}
+	trustGateway(cfg)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := api.New(cfg, redisPkg.InitMockRedis(t))
+			rec := tc.setupTestHTTP(t, app)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedLocation != "" {
+				assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
+			}
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package geoip
+
+import (
+	"fmt"
+	"github.com/oschwald/maxminddb-golang/v2"
+	"net/netip"
+)
+
+// Reader looks up the country of IP addresses in a local MaxMind DB file, such as GeoLite2-Country or GeoIP2-City.
+// It is safe for concurrent use.
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// Open opens the MaxMind DB file at path.
+func Open(path string) (*Reader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open geoip database %s: %w", path, err)
+	}
+	return &Reader{db: db}, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of ip, and an empty string if the database does not know ip.
+// IPv4-mapped IPv6 addresses are looked up as IPv4 addresses.
+func (r *Reader) Country(ip netip.Addr) (string, error) {
+	var record countryRecord
+	if err := r.db.Lookup(ip.Unmap()).Decode(&record); err != nil {
+		return "", err
+	}
+	return record.Country.ISOCode, nil
+}
+
+// Close releases the database file, Country must not be called afterwards.
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,72 @@
+package geoip
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/netip"
+	"testing"
+)
+
+// testdata/country-test.mmdb maps 203.0.113.0/24 to VN, 198.51.100.0/24 to US and 2001:db8:1::/48 to FR.
+
+func TestReader_Country(t *testing.T) {
+	t.Parallel()
+
+	reader, err := Open("testdata/country-test.mmdb")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = reader.Close() })
+
+	testCases := []struct {
+		name string
+
+		ip string
+
+		expectedCountry string
+	}{
+		{
+			name: "ipv4",
+
+			ip: "203.0.113.7",
+
+			expectedCountry: "VN",
+		},
+		{
+			name: "ipv4-mapped ipv6",
+
+			ip: "::ffff:198.51.100.1",
+
+			expectedCountry: "US",
+		},
+		{
+			name: "ipv6",
+
+			ip: "2001:db8:1::42",
+
+			expectedCountry: "FR",
+		},
+		{
+			name: "unknown address",
+
+			ip: "192.0.2.1",
+
+			expectedCountry: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			country, err := reader.Country(netip.MustParseAddr(tc.ip))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCountry, country)
+		})
+	}
+}
+
+func TestOpen_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	_, err := Open("testdata/missing.mmdb")
+	assert.Error(t, err)
+}
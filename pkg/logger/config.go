@@ -0,0 +1,19 @@
+package logger
+
+import (
+	"fmt"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	// Level is a zerolog level name, empty means info.
+	Level string `default:"info" envconfig:"LOG_LEVEL" yaml:"level"`
+}
+
+// Validate returns an error if the level is not a zerolog level name.
+func (c *Config) Validate() error {
+	if _, err := zerolog.ParseLevel(c.Level); err != nil {
+		return fmt.Errorf("unknown LOG_LEVEL %q", c.Level)
+	}
+	return nil
+}
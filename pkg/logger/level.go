@@ -2,14 +2,16 @@ package logger
 
 import (
 	"github.com/rs/zerolog"
-	"os"
+	"github.com/rs/zerolog/log"
 )
 
-// SetLogLevel sets the global log level based on the LOG_LEVEL environment variable.
-// If the variable is not set, or if the value cannot be parsed into a log level,
-// the global log level is set to InfoLevel.
-func SetLogLevel() {
-	level, err := zerolog.ParseLevel(os.Getenv("LOG_LEVEL"))
+// SetLevel sets the global log level to the level called name, the Level of the Config loaded by internal/config.
+// If name cannot be parsed into a log level, the global log level is set to InfoLevel.
+// It also makes log.Ctx fall back to the global logger for contexts without a request logger.
+func SetLevel(name string) {
+	zerolog.DefaultContextLogger = &log.Logger
+
+	level, err := zerolog.ParseLevel(name)
 	if err != nil || level == zerolog.NoLevel {
 		level = zerolog.InfoLevel
 	}
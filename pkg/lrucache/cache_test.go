@@ -0,0 +1,139 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		size int
+		ttl  time.Duration
+		run  func(c *Cache[string, string], clock *time.Time)
+
+		expectedValues map[string]string
+		expectedLen    int
+	}{
+		{
+			name: "get after set",
+
+			size: 2,
+			run: func(c *Cache[string, string], clock *time.Time) {
+				c.Set("a", "1")
+				c.Set("b", "2")
+			},
+
+			expectedValues: map[string]string{"a": "1", "b": "2"},
+			expectedLen:    2,
+		},
+		{
+			name: "evicts least recently used",
+
+			size: 2,
+			run: func(c *Cache[string, string], clock *time.Time) {
+				c.Set("a", "1")
+				c.Set("b", "2")
+				c.Get("a")
+				c.Set("c", "3")
+			},
+
+			expectedValues: map[string]string{"a": "1", "c": "3"},
+			expectedLen:    2,
+		},
+		{
+			name: "overwrite keeps size",
+
+			size: 2,
+			run: func(c *Cache[string, string], clock *time.Time) {
+				c.Set("a", "1")
+				c.Set("a", "2")
+			},
+
+			expectedValues: map[string]string{"a": "2"},
+			expectedLen:    1,
+		},
+		{
+			name: "expired entry is dropped",
+
+			size: 2,
+			ttl:  time.Second,
+			run: func(c *Cache[string, string], clock *time.Time) {
+				c.Set("a", "1")
+				*clock = clock.Add(time.Second)
+				c.Set("b", "2")
+			},
+
+			expectedValues: map[string]string{"b": "2"},
+			expectedLen:    1,
+		},
+		{
+			name: "shorter entry ttl expires first",
+
+			size: 3,
+			ttl:  time.Minute,
+			run: func(c *Cache[string, string], clock *time.Time) {
+				c.SetWithTTL("a", "1", time.Second)
+				c.SetWithTTL("b", "2", time.Hour)
+				c.SetWithTTL("c", "3", 0)
+				*clock = clock.Add(time.Second)
+			},
+
+			expectedValues: map[string]string{"b": "2", "c": "3"},
+			expectedLen:    2,
+		},
+		{
+			name: "entry ttl is capped by the cache ttl",
+
+			size: 2,
+			ttl:  time.Minute,
+			run: func(c *Cache[string, string], clock *time.Time) {
+				c.SetWithTTL("a", "1", time.Hour)
+				*clock = clock.Add(time.Minute)
+			},
+
+			expectedValues: map[string]string{},
+			expectedLen:    0,
+		},
+		{
+			name: "delete and purge",
+
+			size: 3,
+			run: func(c *Cache[string, string], clock *time.Time) {
+				c.Set("a", "1")
+				c.Set("b", "2")
+				c.Delete("a")
+				c.Delete("missing")
+				c.Purge()
+				c.Set("c", "3")
+			},
+
+			expectedValues: map[string]string{"c": "3"},
+			expectedLen:    1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := time.Unix(0, 0)
+			c := New[string, string](tc.size, tc.ttl)
+			c.now = func() time.Time { return clock }
+
+			tc.run(c, &clock)
+
+			for _, key := range []string{"a", "b", "c"} {
+				value, ok := c.Get(key)
+				expected, expectedOK := tc.expectedValues[key]
+				assert.Equal(t, expectedOK, ok, key)
+				assert.Equal(t, expected, value, key)
+			}
+			assert.Equal(t, tc.expectedLen, c.Len())
+		})
+	}
+}
@@ -1,25 +1,81 @@
 package redis
 
-import "github.com/redis/go-redis/v9"
-
-// NewClient returns a new instance of the redis.Client, which is used to interact with the Redis server.
-// It takes an environment prefix string as an argument, which is used to load the configuration for the Redis client from the environment variables.
-// The configuration is loaded using the newConfig function, which returns an error if there was an issue loading the configuration.
-// If an error occurs while loading the configuration, the error is returned immediately and the returned redis.Client is nil.
-// The returned redis.Client is ready to be used and does not require any additional setup before interacting with the Redis server.
-// It is created with the configuration loaded from the environment variables, and is used to connect to the Redis server at the specified address, using the specified password and database.
-// The returned redis.Client is an instance of the github.com/redis/go-redis/v9.Client struct, which is used to interact with the Redis server.
-func NewClient(envPrefix string) (*redis.Client, error) {
-	cfg, err := newConfig(envPrefix)
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"os"
+)
+
+// NewClientWithConfig returns a new instance of the redis.UniversalClient configured by cfg, which is loaded by
+// internal/config along with the rest of the configuration. It connects to a single server, a Sentinel monitored master
+// or a Redis Cluster depending on the Mode of cfg.
+// The client is a *redis.Client in standalone and sentinel mode, and a *redis.ClusterClient in cluster mode.
+// It returns an error if the mode is unknown or the TLS files of cfg cannot be loaded, the connections themselves are established lazily.
+func NewClientWithConfig(cfg *Config) (redis.UniversalClient, error) {
+	tlsConfig, err := newTLSConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     cfg.Address,
-		Password: cfg.Password,
-		DB:       cfg.DB,
-	})
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.Addrs(),
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		DB:               cfg.DB,
+		MasterName:       cfg.MasterName,
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		PoolTimeout:      cfg.PoolTimeout,
+		TLSConfig:        tlsConfig,
+	}
+
+	switch cfg.Mode {
+	case ModeStandalone, "":
+		return redis.NewClient(opts.Simple()), nil
+	case ModeSentinel:
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case ModeCluster:
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		return nil, fmt.Errorf("unknown REDIS_MODE %q", cfg.Mode)
+	}
+}
+
+func newTLSConfig(cfg *Config) (*tls.Config, error) {
+	if !cfg.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.TLSServerName,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read REDIS_TLS_CA_FILE: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in REDIS_TLS_CA_FILE %q", cfg.TLSCAFile)
+		}
+	}
 
-	return redisClient, nil
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load REDIS_TLS_CERT_FILE and REDIS_TLS_KEY_FILE: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
 }
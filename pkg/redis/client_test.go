@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewClientWithConfig(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		cfg Config
+
+		expectedClient    any
+		expectedValidErrs []string
+	}{
+		{
+			name: "standalone",
+
+			cfg: Config{Mode: ModeStandalone, Address: "localhost:6379"},
+
+			expectedClient: &redis.Client{},
+		},
+		{
+			name: "sentinel",
+
+			cfg: Config{Mode: ModeSentinel, Address: "sentinel-1:26379, sentinel-2:26379", MasterName: "mymaster"},
+
+			expectedClient: &redis.Client{},
+		},
+		{
+			name: "cluster",
+
+			cfg: Config{Mode: ModeCluster, Address: "node-1:6379,node-2:6379"},
+
+			expectedClient: &redis.ClusterClient{},
+		},
+		{
+			name: "sentinel without master name",
+
+			cfg: Config{Mode: ModeSentinel, Address: "sentinel-1:26379"},
+
+			expectedValidErrs: []string{"REDIS_MASTER_NAME is required in sentinel mode"},
+		},
+		{
+			name: "several addresses in standalone mode",
+
+			cfg: Config{Mode: ModeStandalone, Address: "a:6379,b:6379", MasterName: "mymaster"},
+
+			expectedValidErrs: []string{
+				"REDIS_ADDR must be a single address in standalone mode, got 2",
+				"REDIS_MASTER_NAME and REDIS_SENTINEL_* options require sentinel mode",
+			},
+		},
+		{
+			name: "database in cluster mode",
+
+			cfg: Config{Mode: ModeCluster, Address: "node-1:6379", DB: 1},
+
+			expectedValidErrs: []string{"REDIS_DB must be 0 in cluster mode, got 1"},
+		},
+		{
+			name: "unknown mode",
+
+			cfg: Config{Mode: "replicated", Address: "localhost:6379"},
+
+			expectedValidErrs: []string{`unknown REDIS_MODE "replicated"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.cfg.Validate()
+			if len(tc.expectedValidErrs) > 0 {
+				require.Error(t, err)
+				for _, expected := range tc.expectedValidErrs {
+					assert.Contains(t, err.Error(), expected)
+				}
+				return
+			}
+			require.NoError(t, err)
+
+			client, err := NewClientWithConfig(&tc.cfg)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = client.Close() })
+			assert.IsType(t, tc.expectedClient, client)
+		})
+	}
+}
@@ -1,18 +1,116 @@
 package redis
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
-type config struct {
-	Address  string `default:"localhost:6379" envconfig:"REDIS_ADDR"`
-	Password string `default:"" envconfig:"REDIS_PASSWORD"`
-	DB       int    `default:"0" envconfig:"REDIS_DB"`
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// Config configures the Redis client, zero pool sizes and timeouts keep the go-redis defaults.
+type Config struct {
+	// Mode is one of standalone, sentinel or cluster.
+	// Address is the server in standalone mode, and a comma separated list of sentinels or cluster seed nodes otherwise.
+	Mode     string `default:"standalone" envconfig:"REDIS_MODE" yaml:"mode"`
+	Address  string `default:"localhost:6379" envconfig:"REDIS_ADDR" yaml:"address"`
+	Username string `default:"" envconfig:"REDIS_USERNAME" yaml:"username"`
+	Password string `default:"" envconfig:"REDIS_PASSWORD" yaml:"password" secret:"true"`
+	DB       int    `default:"0" envconfig:"REDIS_DB" yaml:"db"`
+
+	// MasterName is the name of the master monitored by the sentinels, the Sentinel* credentials authenticate to the sentinels themselves.
+	MasterName       string `default:"" envconfig:"REDIS_MASTER_NAME" yaml:"master_name"`
+	SentinelUsername string `default:"" envconfig:"REDIS_SENTINEL_USERNAME" yaml:"sentinel_username"`
+	SentinelPassword string `default:"" envconfig:"REDIS_SENTINEL_PASSWORD" yaml:"sentinel_password" secret:"true"`
+
+	PoolSize     int           `default:"0" envconfig:"REDIS_POOL_SIZE" yaml:"pool_size"`
+	MinIdleConns int           `default:"0" envconfig:"REDIS_MIN_IDLE_CONNS" yaml:"min_idle_conns"`
+	DialTimeout  time.Duration `default:"5s" envconfig:"REDIS_DIAL_TIMEOUT" yaml:"dial_timeout"`
+	ReadTimeout  time.Duration `default:"3s" envconfig:"REDIS_READ_TIMEOUT" yaml:"read_timeout"`
+	WriteTimeout time.Duration `default:"3s" envconfig:"REDIS_WRITE_TIMEOUT" yaml:"write_timeout"`
+	PoolTimeout  time.Duration `default:"0" envconfig:"REDIS_POOL_TIMEOUT" yaml:"pool_timeout"`
+
+	// TLS* enable TLS, the CA file replaces the system roots and the cert and key files enable client authentication.
+	TLSEnabled            bool   `default:"false" envconfig:"REDIS_TLS_ENABLED" yaml:"tls_enabled"`
+	TLSCAFile             string `default:"" envconfig:"REDIS_TLS_CA_FILE" yaml:"tls_ca_file"`
+	TLSCertFile           string `default:"" envconfig:"REDIS_TLS_CERT_FILE" yaml:"tls_cert_file"`
+	TLSKeyFile            string `default:"" envconfig:"REDIS_TLS_KEY_FILE" yaml:"tls_key_file"`
+	TLSServerName         string `default:"" envconfig:"REDIS_TLS_SERVER_NAME" yaml:"tls_server_name"`
+	TLSInsecureSkipVerify bool   `default:"false" envconfig:"REDIS_TLS_INSECURE_SKIP_VERIFY" yaml:"tls_insecure_skip_verify"`
+}
+
+// Validate returns every invalid field of the config at once, joined with errors.Join.
+func (c *Config) Validate() error {
+	var errs []error
+	addrs := c.Addrs()
+	if len(addrs) == 0 {
+		errs = append(errs, errors.New("REDIS_ADDR must not be empty"))
+	}
+
+	switch c.Mode {
+	case ModeStandalone, "":
+		if len(addrs) > 1 {
+			errs = append(errs, fmt.Errorf("REDIS_ADDR must be a single address in %s mode, got %d", ModeStandalone, len(addrs)))
+		}
+	case ModeSentinel:
+		if c.MasterName == "" {
+			errs = append(errs, fmt.Errorf("REDIS_MASTER_NAME is required in %s mode", ModeSentinel))
+		}
+	case ModeCluster:
+		if c.DB != 0 {
+			errs = append(errs, fmt.Errorf("REDIS_DB must be 0 in %s mode, got %d", ModeCluster, c.DB))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown REDIS_MODE %q", c.Mode))
+	}
+	if c.Mode != ModeSentinel && (c.MasterName != "" || c.SentinelUsername != "" || c.SentinelPassword != "") {
+		errs = append(errs, fmt.Errorf("REDIS_MASTER_NAME and REDIS_SENTINEL_* options require %s mode", ModeSentinel))
+	}
+	if c.DB < 0 {
+		errs = append(errs, fmt.Errorf("REDIS_DB must not be negative, got %d", c.DB))
+	}
+	if c.PoolSize < 0 {
+		errs = append(errs, fmt.Errorf("REDIS_POOL_SIZE must not be negative, got %d", c.PoolSize))
+	}
+	if c.MinIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("REDIS_MIN_IDLE_CONNS must not be negative, got %d", c.MinIdleConns))
+	}
+
+	for _, timeout := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"REDIS_DIAL_TIMEOUT", c.DialTimeout},
+		{"REDIS_READ_TIMEOUT", c.ReadTimeout},
+		{"REDIS_WRITE_TIMEOUT", c.WriteTimeout},
+		{"REDIS_POOL_TIMEOUT", c.PoolTimeout},
+	} {
+		if timeout.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", timeout.name, timeout.value))
+		}
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("REDIS_TLS_CERT_FILE and REDIS_TLS_KEY_FILE must be set together"))
+	}
+	if !c.TLSEnabled && (c.TLSCAFile != "" || c.TLSCertFile != "" || c.TLSKeyFile != "" || c.TLSServerName != "" || c.TLSInsecureSkipVerify) {
+		errs = append(errs, errors.New("REDIS_TLS_* options require REDIS_TLS_ENABLED"))
+	}
+	return errors.Join(errs...)
 }
 
-func newConfig(envPrefix string) (*config, error) {
-	cfg := &config{}
-	err := envconfig.Process(envPrefix, cfg)
-	if err != nil {
-		return nil, err
+// Addrs returns the addresses listed in Address.
+func (c *Config) Addrs() []string {
+	var addrs []string
+	for _, addr := range strings.Split(c.Address, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
 	}
-	return cfg, nil
+	return addrs
 }
@@ -1,52 +1,80 @@
 package stringutils
 
 import (
-	"bytes"
+	"context"
 	"crypto/rand"
-	"math/big"
 )
 
 const (
 	charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+	// Base62Alphabet is the default alphabet for generated codes.
+	Base62Alphabet = charset
+	// HumanFriendlyAlphabet is Base62Alphabet without the easily confused '0', 'O', '1' and 'l'.
+	HumanFriendlyAlphabet = "ABCDEFGHIJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789"
 )
 
+// KeyGen generates short codes.
+// The length passed to GenerateCode is the minimum length of the code, sequential strategies return longer codes
+// once every code of that length has been handed out.
 type KeyGen interface {
-	GenerateCode(length int) (string, error)
+	GenerateCode(ctx context.Context, length int) (string, error)
 }
 
 type keyGen struct {
+	alphabet string
 }
 
+// NewKeyGen returns a new instance of the keyGen, which implements the KeyGen interface.
+// The returned keyGen generates random codes from the Base62Alphabet.
+//
 //go:generate mockery --name KeyGen --filename keygen.go
 func NewKeyGen() KeyGen {
-	return &keyGen{}
+	return NewRandomKeyGen(Base62Alphabet)
 }
 
-// GenerateCode generates a random string of the given length, using characters from the character set 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789'.
-// The generated code is returned as a string, or an error is returned if there was an issue generating the code.
-// The character set used for generating the code is constant and does not change across different implementations of the interface.
-// The length of the generated code is constant and does not change across different implementations of the interface.
+// NewRandomKeyGen returns a new instance of the keyGen, which implements the KeyGen interface.
+// The returned keyGen generates random codes of exactly the requested length from the given alphabet,
+// collisions have to be handled by the caller.
+func NewRandomKeyGen(alphabet string) KeyGen {
+	return &keyGen{alphabet: alphabet}
+}
+
+// GenerateCode generates a random string of the given length, using characters from the alphabet of the keyGen.
 // If an error occurs while generating the code, the error is returned immediately and the generated code is an empty string.
-func (k *keyGen) GenerateCode(length int) (string, error) {
-	return GenerateCode(length)
+func (k *keyGen) GenerateCode(_ context.Context, length int) (string, error) {
+	return randomString(k.alphabet, length)
 }
 
 // GenerateCode generates a random string of the given length, using characters from the character set 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789'.
 // The generated code is returned as a string, or an error is returned if there was an issue generating the code.
-// The character set used for generating the code is constant and does not change across different implementations of the interface.
-// The length of the generated code is constant and does not change across different implementations of the interface.
 // If an error occurs while generating the code, the error is returned immediately and the generated code is an empty string.
 func GenerateCode(length int) (string, error) {
-	var strBuilder bytes.Buffer
+	return randomString(charset, length)
+}
 
-	for i := 0; i < length; i++ {
-		randomIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
-		if err != nil {
+// randomString reads random bytes in batches and maps them onto alphabet.
+// Bytes at or above the largest multiple of len(alphabet) are rejected so every character is equally likely.
+func randomString(alphabet string, length int) (string, error) {
+	limit := 256 - 256%len(alphabet)
+	code := make([]byte, 0, length)
+	buf := make([]byte, length+length/2+1)
+
+	for len(code) < length {
+		if _, err := rand.Read(buf); err != nil {
 			return "", err
 		}
 
-		strBuilder.WriteByte(charset[randomIndex.Int64()])
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			code = append(code, alphabet[int(b)%len(alphabet)])
+			if len(code) == length {
+				break
+			}
+		}
 	}
 
-	return strBuilder.String(), nil
+	return string(code), nil
 }
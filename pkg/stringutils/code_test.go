@@ -0,0 +1,162 @@
+package stringutils
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+var testSeqError = errors.New("sequence unavailable")
+
+type testSequence struct {
+	next uint64
+	err  error
+}
+
+func (s *testSequence) Next(_ context.Context) (uint64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.next++
+	return s.next, nil
+}
+
+func TestKeyGen_GenerateCode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		setupKeyGen func() KeyGen
+		length      int
+		count       int
+
+		expectedAlphabet string
+		expectedLens     []int
+		expectErr        error
+	}{
+		{
+			name: "random base62",
+
+			setupKeyGen: func() KeyGen { return NewKeyGen() },
+			length:      7,
+			count:       200,
+
+			expectedAlphabet: Base62Alphabet,
+			expectedLens:     []int{7},
+		},
+		{
+			name: "random human friendly",
+
+			setupKeyGen: func() KeyGen { return NewRandomKeyGen(HumanFriendlyAlphabet) },
+			length:      7,
+			count:       200,
+
+			expectedAlphabet: HumanFriendlyAlphabet,
+			expectedLens:     []int{7},
+		},
+		{
+			name: "counter grows once the length is exhausted",
+
+			setupKeyGen: func() KeyGen { return NewCounterKeyGen(&testSequence{}, "ab", nil) },
+			length:      2,
+			count:       8,
+
+			expectedAlphabet: "ab",
+			expectedLens:     []int{2, 2, 2, 3, 3, 3, 3, 4},
+		},
+		{
+			name: "counter with feistel shuffle",
+
+			setupKeyGen: func() KeyGen { return NewCounterKeyGen(&testSequence{}, Base62Alphabet, []byte("secret")) },
+			length:      2,
+			count:       3843,
+
+			expectedAlphabet: Base62Alphabet,
+			expectedLens:     []int{2},
+		},
+		{
+			name: "hashids",
+
+			setupKeyGen: func() KeyGen { return NewHashidsKeyGen(&testSequence{}, "salt") },
+			length:      3,
+			count:       3000,
+
+			expectedAlphabet: Base62Alphabet,
+			expectedLens:     []int{3},
+		},
+		{
+			name: "sequence error",
+
+			setupKeyGen: func() KeyGen { return NewCounterKeyGen(&testSequence{err: testSeqError}, Base62Alphabet, nil) },
+			length:      7,
+			count:       1,
+
+			expectErr: testSeqError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyGen := tc.setupKeyGen()
+			seen := make(map[string]bool, tc.count)
+
+			for i := 0; i < tc.count; i++ {
+				code, err := keyGen.GenerateCode(context.Background(), tc.length)
+				if tc.expectErr != nil {
+					assert.Equal(t, tc.expectErr, err)
+					assert.Empty(t, code)
+					return
+				}
+				require.NoError(t, err)
+
+				expectedLen := tc.expectedLens[min(i, len(tc.expectedLens)-1)]
+				assert.Len(t, code, expectedLen)
+				for _, r := range code {
+					assert.True(t, strings.ContainsRune(tc.expectedAlphabet, r), code)
+				}
+				assert.False(t, seen[code], "duplicate code %s", code)
+				seen[code] = true
+			}
+		})
+	}
+}
+
+func TestFeistel(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+
+		key  []byte
+		size uint64
+	}{
+		{name: "even bit width", key: []byte("k"), size: 256},
+		{name: "odd bit width with cycle walking", key: []byte("k"), size: 1000},
+		{name: "other key", key: []byte("another key"), size: 62 * 62},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			seen := make(map[uint64]bool, tc.size)
+			identity := 0
+			for n := uint64(0); n < tc.size; n++ {
+				p := feistel(tc.key, n, tc.size)
+				assert.Less(t, p, tc.size)
+				assert.False(t, seen[p], "duplicate image %d", p)
+				seen[p] = true
+				if p == n {
+					identity++
+				}
+			}
+			assert.Less(t, identity, int(tc.size/10))
+		})
+	}
+}
@@ -2,16 +2,20 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // KeyGen is an autogenerated mock type for the KeyGen type
 type KeyGen struct {
 	mock.Mock
 }
 
-// GenerateCode provides a mock function with given fields: length
-func (_m *KeyGen) GenerateCode(length int) (string, error) {
-	ret := _m.Called(length)
+// GenerateCode provides a mock function with given fields: ctx, length
+func (_m *KeyGen) GenerateCode(ctx context.Context, length int) (string, error) {
+	ret := _m.Called(ctx, length)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GenerateCode")
@@ -19,17 +23,17 @@ func (_m *KeyGen) GenerateCode(length int) (string, error) {
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) (string, error)); ok {
-		return rf(length)
+	if rf, ok := ret.Get(0).(func(context.Context, int) (string, error)); ok {
+		return rf(ctx, length)
 	}
-	if rf, ok := ret.Get(0).(func(int) string); ok {
-		r0 = rf(length)
+	if rf, ok := ret.Get(0).(func(context.Context, int) string); ok {
+		r0 = rf(ctx, length)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(length)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, length)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,174 @@
+package stringutils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+const (
+	feistelRounds = 4
+	// hashidsLotteryMod matches the hashids "numbers hash" for a single number.
+	hashidsLotteryMod = 100
+)
+
+// Sequence hands out strictly increasing numbers, shared by every instance generating codes.
+type Sequence interface {
+	Next(ctx context.Context) (uint64, error)
+}
+
+type counterKeyGen struct {
+	seq      Sequence
+	alphabet string
+	key      []byte
+}
+
+// NewCounterKeyGen returns a new instance of the counterKeyGen, which implements the KeyGen interface.
+// Every code encodes the next number of seq in the given alphabet, so codes never collide.
+// When key is not empty the number is first run through a keyed Feistel permutation, so consecutive codes are not guessable.
+// The code length grows by one every time the sequence exhausts the codes of the current length.
+func NewCounterKeyGen(seq Sequence, alphabet string, key []byte) KeyGen {
+	return &counterKeyGen{seq: seq, alphabet: alphabet, key: key}
+}
+
+// GenerateCode returns the code for the next number of the sequence, at least length characters long.
+// It returns an error if the sequence cannot be advanced.
+func (k *counterKeyGen) GenerateCode(ctx context.Context, length int) (string, error) {
+	n, err := k.seq.Next(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	base := uint64(len(k.alphabet))
+	size, ok := keyspaceSize(base, length)
+	for ok && n >= size {
+		length++
+		size, ok = keyspaceSize(base, length)
+	}
+
+	if len(k.key) > 0 {
+		if !ok {
+			size = math.MaxUint64
+		}
+		n = feistel(k.key, n, size)
+	}
+
+	return encode(n, k.alphabet, length), nil
+}
+
+type hashidsKeyGen struct {
+	seq      Sequence
+	alphabet string
+	salt     string
+}
+
+// NewHashidsKeyGen returns a new instance of the hashidsKeyGen, which implements the KeyGen interface.
+// Like hashids, every number is encoded behind a lottery character in an alphabet shuffled by salt and the lottery,
+// which makes consecutive codes look unrelated while keeping them unique.
+func NewHashidsKeyGen(seq Sequence, salt string) KeyGen {
+	return &hashidsKeyGen{seq: seq, alphabet: consistentShuffle(Base62Alphabet, salt), salt: salt}
+}
+
+// GenerateCode returns the code for the next number of the sequence, at least length characters long.
+// It returns an error if the sequence cannot be advanced.
+func (k *hashidsKeyGen) GenerateCode(ctx context.Context, length int) (string, error) {
+	n, err := k.seq.Next(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	lottery := k.alphabet[(n%hashidsLotteryMod)%uint64(len(k.alphabet))]
+	alphabet := consistentShuffle(k.alphabet, (string(lottery) + k.salt + k.alphabet)[:len(k.alphabet)])
+
+	digits := max(length-1, 1)
+	for size, ok := keyspaceSize(uint64(len(alphabet)), digits); ok && n >= size; size, ok = keyspaceSize(uint64(len(alphabet)), digits) {
+		digits++
+	}
+
+	return string(lottery) + encode(n, alphabet, digits), nil
+}
+
+// keyspaceSize returns base^length, and false if it does not fit in an uint64.
+func keyspaceSize(base uint64, length int) (uint64, bool) {
+	size := uint64(1)
+	for i := 0; i < length; i++ {
+		hi, lo := bits.Mul64(size, base)
+		if hi != 0 {
+			return 0, false
+		}
+		size = lo
+	}
+	return size, true
+}
+
+// encode writes n in the positional numeral system of alphabet, left padded with alphabet[0] to length characters.
+func encode(n uint64, alphabet string, length int) string {
+	base := uint64(len(alphabet))
+
+	var digits []byte
+	for n > 0 {
+		digits = append(digits, alphabet[n%base])
+		n /= base
+	}
+	for len(digits) < length {
+		digits = append(digits, alphabet[0])
+	}
+
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits)
+}
+
+// feistel maps n < size onto another number < size, the mapping is a bijection for a given key and size.
+// It runs a balanced Feistel network over the smallest even number of bits covering size
+// and cycle-walks until the result falls back into range.
+func feistel(key []byte, n, size uint64) uint64 {
+	width := bits.Len64(size - 1)
+	if width%2 == 1 {
+		width++
+	}
+	half := uint(width / 2)
+	mask := uint64(1)<<half - 1
+
+	for {
+		left, right := n>>half, n&mask
+		for round := byte(0); round < feistelRounds; round++ {
+			left, right = right, left^(roundFunc(key, round, right)&mask)
+		}
+		n = left<<half | right
+
+		if n < size {
+			return n
+		}
+	}
+}
+
+func roundFunc(key []byte, round byte, value uint64) uint64 {
+	var msg [9]byte
+	msg[0] = round
+	binary.BigEndian.PutUint64(msg[1:], value)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(msg[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+// consistentShuffle is the hashids salt based shuffle, it always returns the same permutation of alphabet for a given salt.
+func consistentShuffle(alphabet, salt string) string {
+	if salt == "" {
+		return alphabet
+	}
+
+	result := []byte(alphabet)
+	for i, v, p := len(result)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		p += int(salt[v])
+		j := (int(salt[v]) + v + p) % i
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result)
+}
@@ -0,0 +1,29 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Config struct {
+	// Exporter is one of otlp, stdout or none, the OTLP exporter is configured by the standard OTEL_EXPORTER_OTLP_* variables.
+	Exporter string `default:"none" envconfig:"OTEL_TRACES_EXPORTER" yaml:"exporter"`
+	// Propagators is a comma separated list of tracecontext, baggage or none.
+	Propagators string `default:"tracecontext,baggage" envconfig:"OTEL_PROPAGATORS" yaml:"propagators"`
+	// ServiceName overrides the service name passed to Setup.
+	ServiceName string `default:"" envconfig:"OTEL_SERVICE_NAME" yaml:"service_name"`
+}
+
+// Validate returns every invalid field of the config at once, joined with errors.Join.
+func (c *Config) Validate() error {
+	var errs []error
+	switch c.Exporter {
+	case ExporterOTLP, ExporterStdout, ExporterNone, "":
+	default:
+		errs = append(errs, fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q", c.Exporter))
+	}
+	if _, err := newPropagator(c.Propagators); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"strings"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+)
+
+// SetupWithConfig installs the global OpenTelemetry tracer provider and propagator, cfg is loaded by internal/config.
+// The exporter is selected by the Exporter of cfg and the propagators by its Propagators,
+// the OTLP exporter itself reads the standard OTEL_EXPORTER_OTLP_* variables and the sampler OTEL_TRACES_SAMPLER.
+// With the none exporter only the propagator is installed, so incoming trace context is still forwarded.
+// The returned function flushes pending spans and shuts the provider down, it must be called before the process exits.
+func SetupWithConfig(ctx context.Context, cfg *Config, serviceName string) (func(context.Context) error, error) {
+	propagator, err := newPropagator(cfg.Propagators)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTextMapPropagator(propagator)
+
+	if cfg.ServiceName != "" {
+		serviceName = cfg.ServiceName
+	}
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	default:
+		err = fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewProvider(serviceName, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider returns a tracer provider whose spans carry serviceName as the service.name resource attribute.
+// The span processors, usually sdktrace.WithBatcher or sdktrace.WithSyncer, are passed as opts.
+func NewProvider(serviceName string, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res, _ := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", serviceName)))
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, opts...)...)
+}
+
+func newPropagator(names string) (propagation.TextMapPropagator, error) {
+	var propagators []propagation.TextMapPropagator
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "none", "":
+		default:
+			return nil, fmt.Errorf("unknown OTEL_PROPAGATORS entry %q", name)
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
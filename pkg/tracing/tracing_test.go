@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"testing"
+)
+
+// TestSetupWithConfig sets environment variables and the global provider, so it does not run in parallel.
+func TestSetupWithConfig(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		cfg Config
+		env map[string]string
+
+		expectErr           bool
+		expectedPropagators []string
+	}{
+		{
+			name: "defaults",
+
+			cfg: Config{Exporter: ExporterNone, Propagators: "tracecontext,baggage"},
+
+			expectedPropagators: []string{"traceparent", "tracestate", "baggage"},
+		},
+		{
+			name: "stdout exporter without propagation",
+
+			cfg: Config{Exporter: ExporterStdout, Propagators: "none"},
+
+			expectedPropagators: nil,
+		},
+		{
+			name: "otlp exporter",
+
+			cfg: Config{Exporter: ExporterOTLP, Propagators: "tracecontext,baggage"},
+			env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318"},
+
+			expectedPropagators: []string{"traceparent", "tracestate", "baggage"},
+		},
+		{
+			name: "unknown exporter",
+
+			cfg: Config{Exporter: "zipkin", Propagators: "tracecontext,baggage"},
+
+			expectErr: true,
+		},
+		{
+			name: "unknown propagator",
+
+			cfg: Config{Exporter: ExporterNone, Propagators: "b3"},
+
+			expectErr: true,
+		},
+	}
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			shutdown, err := SetupWithConfig(context.Background(), &tc.cfg, "test")
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectedPropagators, otel.GetTextMapPropagator().Fields())
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}